@@ -32,7 +32,11 @@ var doc = `{
             "get": {
                 "description": "Get all articles stored in the database",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "text/csv",
+                    "application/vnd.api+json"
                 ],
                 "tags": [
                     "Articles"
@@ -44,6 +48,42 @@ var doc = `{
                         "description": "id of the page to be retrieved",
                         "name": "page_id",
                         "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only list articles with tracked stock of at most this quantity",
+                        "name": "low_stock",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only list articles of this category or one of its subcategories",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list articles with this tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated ids of the articles to list instead of a page",
+                        "name": "ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return, such as id,name",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated relations to embed, such as categories",
+                        "name": "expand",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -64,13 +104,26 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     }
                 }
             },
             "put": {
-                "description": "PutArticle writes an article to the database\nTo write a new article, leave the id empty. To update an existing one, use the id of the article to be updated",
+                "description": "PutArticle writes an article to the database\nTo write a new article, leave the id empty. To update an existing one, use the id of the article to be updated\nA list of articles is written in a single transaction and the written list is returned.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "Articles"
@@ -94,6 +147,122 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/articles/search": {
+            "get": {
+                "description": "Search the names of all articles. Words of the query match the words of names starting with them,\nnames with typos match by similarity. Results are ordered by relevance and the matching words are highlighted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Articles"
+                ],
+                "summary": "Search articles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "the search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of the page to be retrieved",
+                        "name": "page_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ArticleSearchResultList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/articles/stream": {
+            "get": {
+                "description": "StreamArticles streams every created, updated and deleted article as a server-sent event\nThe id of every event is its position in the change log. Send it as Last-Event-ID header or last_event_id query parameter to resume a stream, new streams start with the next change.\nConsumers that fall too far behind are disconnected and have to resume the stream.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Articles"
+                ],
+                "summary": "Stream article changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of the last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of the last received event",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created",
+                            "updated",
+                            "deleted"
+                        ],
+                        "type": "string",
+                        "description": "comma separated actions to receive",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated article ids to receive",
+                        "name": "ids",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     }
                 }
             }
@@ -102,7 +271,9 @@ var doc = `{
             "get": {
                 "description": "GetArticle returns a single article by id",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "Articles"
@@ -115,6 +286,18 @@ var doc = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return, such as id,name",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated relations to embed, such as categories",
+                        "name": "expand",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -130,6 +313,43 @@ var doc = `{
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "DeleteArticle deletes a single article and its images by id",
+                "tags": [
+                    "Articles"
+                ],
+                "summary": "Delete article by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -139,29 +359,30 @@ var doc = `{
                 }
             }
         },
-        "/orders": {
+        "/articles/{id}/images": {
             "get": {
-                "description": "Get all orders stored in the database",
+                "description": "Get all images of an article, oldest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Articles"
                 ],
-                "summary": "List all orders",
+                "summary": "List the images of an article",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id of the page to be retrieved",
-                        "name": "page_id",
-                        "in": "query"
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/OrderList"
+                            "$ref": "#/definitions/ArticleImageList"
                         }
                     },
                     "400": {
@@ -178,20 +399,39 @@ var doc = `{
                     }
                 }
             },
-            "put": {
-                "description": "PutOrder writes an order to the database\nTo write a new order, leave the id empty. To update an existing one, use the id of the order to be updated",
+            "post": {
+                "description": "PostArticleImage stores a jpeg, png or gif image of at most 5 MiB together with a thumbnail.\nThe type is sniffed from the content of the file.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Articles"
+                ],
+                "summary": "Upload an image of an article",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "the image",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    }
                 ],
-                "summary": "Add an order to the database",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/Order"
+                            "$ref": "#/definitions/ArticleImage"
                         }
                     },
                     "400": {
@@ -205,36 +445,107 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/orders/{id}": {
+        "/articles/{id}/images/{imageID}": {
             "get": {
-                "description": "GetOrder returns a single order by id",
+                "description": "GetArticleImage returns the uploaded image file",
                 "produces": [
-                    "application/json"
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif"
                 ],
                 "tags": [
-                    "Orders"
+                    "Articles"
                 ],
-                "summary": "Get order by id",
+                "summary": "Download an image of an article",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "order id",
+                        "description": "article id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "image id",
+                        "name": "imageID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/Order"
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "DeleteArticleImage deletes an image and its thumbnail",
+                "tags": [
+                    "Articles"
+                ],
+                "summary": "Delete an image of an article",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "image id",
+                        "name": "imageID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {},
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -249,37 +560,3384 @@ var doc = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "Article": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "description": "The unique id of this item",
+        },
+        "/articles/{id}/images/{imageID}/thumbnail": {
+            "get": {
+                "description": "GetArticleImageThumbnail returns the thumbnail of an image, which fits into 200x200 pixels",
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "Articles"
+                ],
+                "summary": "Download the thumbnail of an image of an article",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "image id",
+                        "name": "imageID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/articles/{id}/prices": {
+            "get": {
+                "description": "Get the price history of an article including its scheduled prices, latest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prices"
+                ],
+                "summary": "List the prices of an article",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of the page to be retrieved",
+                        "name": "page_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ArticlePriceList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "PostArticlePrice records a future price of an article, which is applied to the article once it takes effect.\nA scheduled price replaces another price of the article that takes effect at the same time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Prices"
+                ],
+                "summary": "Schedule a price change of an article",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "the price and the time it takes effect",
+                        "name": "price",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ArticlePrice"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ArticlePrice"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/articles/{id}/stock": {
+            "get": {
+                "description": "GetArticleStock returns the available and reserved quantity of an article",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "Get the stock of an article",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Stock"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/articles/{id}/stock/adjustments": {
+            "get": {
+                "description": "Get the inventory ledger of an article, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "List the stock adjustments of an article",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of the page to be retrieved",
+                        "name": "page_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StockAdjustmentList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "PostStockAdjustment changes the available quantity of an article by delta and records it in the inventory ledger\nThe first adjustment of an article starts tracking its stock. The available quantity can't become negative.\nSet the variant id to adjust the stock of a variant of the article instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "Adjust the stock of an article",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "the adjustment",
+                        "name": "adjustment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/StockAdjustment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Stock"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/articles/{id}/variants": {
+            "get": {
+                "description": "Get all variants of an article, such as its sizes or flavours",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Variants"
+                ],
+                "summary": "List the variants of an article",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/VariantList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "PutVariant writes a variant of an article to the database\nTo write a new variant, leave the id empty. To update an existing one, use the id of the variant to be updated\nSKUs are stored in upper case and must not be used by another variant. The price of the article applies if the price is empty.\nThe stock of a variant is only changed by stock adjustments and orders.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Variants"
+                ],
+                "summary": "Add a variant of an article to the database",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "the variant",
+                        "name": "variant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/Variant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Variant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/articles/{id}/variants/{variantID}": {
+            "get": {
+                "description": "GetVariant returns a single variant of an article by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Variants"
+                ],
+                "summary": "Get a variant of an article",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "variant id",
+                        "name": "variantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Variant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "DeleteVariant deletes a single variant of an article by id. Orders keep referencing it.",
+                "tags": [
+                    "Variants"
+                ],
+                "summary": "Delete a variant of an article",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "variant id",
+                        "name": "variantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/articles/{id}/variants/{variantID}/stock": {
+            "get": {
+                "description": "GetVariantStock returns the available and reserved quantity of a variant",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "Get the stock of a variant of an article",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "article id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "variant id",
+                        "name": "variantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Stock"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/articles:export": {
+            "get": {
+                "description": "ExportArticles streams all articles straight from the database as csv, ndjson or a json array\nThe format is taken from the format query parameter or, if that is empty, from the Accept header",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Articles"
+                ],
+                "summary": "Export all articles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of the first article to be exported",
+                        "name": "page_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only export articles with tracked stock of at most this quantity",
+                        "name": "low_stock",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only export articles of this category or one of its subcategories",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only export articles with this tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "export format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/Article"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/audit": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the recorded changes of all resources, latest first. Changes are recorded with the actor of the\napi token of their request, anonymous for requests without a token and system for changes the api\nmade on its own, such as applied scheduled prices.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "List the audit log",
+                "parameters": [
+                    {
+                        "enum": [
+                            "article",
+                            "article_image",
+                            "article_price",
+                            "variant",
+                            "stock_adjustment",
+                            "order",
+                            "customer",
+                            "coupon",
+                            "category",
+                            "tag",
+                            "webhook"
+                        ],
+                        "type": "string",
+                        "description": "kind of the changed resource",
+                        "name": "resource",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "id of the changed resource",
+                        "name": "resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "actor who requested the change",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "earliest time of the change in RFC 3339 format",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "time before the latest change in RFC 3339 format",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of the page to be retrieved",
+                        "name": "page_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/AuditEntryList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/batch": {
+            "post": {
+                "description": "Batch runs the operations one after another as if they were sent as separate requests and returns their responses.\nAtomic batch requests run all operations in a single database transaction. If an operation fails, the changes\nof all operations are rolled back and the remaining operations aren't run, which is reported with status 424.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Batch"
+                ],
+                "summary": "Run a batch of requests",
+                "parameters": [
+                    {
+                        "description": "the operations",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/BatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cache/stats": {
+            "get": {
+                "description": "GetCacheStats returns the hits and misses of the cache of articles and orders since the start of the api",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cache"
+                ],
+                "summary": "Get the statistics of the database cache",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CacheStats"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "Get all categories stored in the database",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "List all categories",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of the page to be retrieved",
+                        "name": "page_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CategoryList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "PutCategory writes a category to the database\nTo write a new category, leave the id empty. To update an existing one, use the id of the category to be updated\nCategories form a tree, the parent must exist and must not be one of the subcategories of the category.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Add a category to the database",
+                "parameters": [
+                    {
+                        "description": "the category",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/Category"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Category"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{id}": {
+            "get": {
+                "description": "GetCategory returns a single category by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Get category by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "category id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Category"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "DeleteCategory deletes a single category by id and removes it from its articles. Categories that still have subcategories can't be deleted.",
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Delete category by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "category id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{id}/articles": {
+            "get": {
+                "description": "Get all articles of a category including the articles of all of its subcategories",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "List the articles of a category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "category id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of the page to be retrieved",
+                        "name": "page_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ArticleList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/coupons": {
+            "get": {
+                "description": "Get all coupons stored in the database",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "List all coupons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of the page to be retrieved",
+                        "name": "page_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CouponList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "PutCoupon writes a coupon to the database\nTo write a new coupon, leave the id empty. To update an existing one, use the id of the coupon to be updated\nThe code must not be used by another coupon. The number of uses is only changed by orders.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Add a coupon to the database",
+                "parameters": [
+                    {
+                        "description": "the coupon",
+                        "name": "coupon",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/Coupon"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Coupon"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/coupons/{id}": {
+            "get": {
+                "description": "GetCoupon returns a single coupon by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Get coupon by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "coupon id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Coupon"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "DeleteCoupon deletes a single coupon by id. Orders keep the code of the coupon.",
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Delete coupon by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "coupon id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers": {
+            "get": {
+                "description": "Get all customers stored in the database",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "List all customers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of the page to be retrieved",
+                        "name": "page_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CustomerList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "PutCustomer writes a customer and its addresses to the database\nTo write a new customer, leave the id empty. To update an existing one, use the id of the customer to be updated\nThe addresses of an existing customer are replaced. The email must not be used by another customer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Add a customer to the database",
+                "parameters": [
+                    {
+                        "description": "the customer",
+                        "name": "customer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/Customer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Customer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}": {
+            "get": {
+                "description": "GetCustomer returns a single customer and its addresses by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Get customer by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Customer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "DeleteCustomer deletes a single customer and its addresses by id. Customers that still own orders can't be deleted.",
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Delete customer by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/customers/{id}/orders": {
+            "get": {
+                "description": "Get all orders owned by a customer",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "List the orders of a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of the page to be retrieved",
+                        "name": "page_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/OrderList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "post": {
+                "description": "GraphQL executes queries and mutations on articles and orders. The schema can be explored with the playground at /graphiql.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "GraphQL"
+                ],
+                "summary": "Execute a graphql request",
+                "parameters": [
+                    {
+                        "description": "the graphql request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/graphql.Params"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/graphql.Result"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/graphql.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders": {
+            "get": {
+                "description": "Get all orders stored in the database",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "text/csv",
+                    "application/vnd.api+json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "List all orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of the page to be retrieved",
+                        "name": "page_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only list orders of this customer",
+                        "name": "customer",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated ids of the orders to list instead of a page",
+                        "name": "ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return, such as id,total",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated relations to embed, such as customer or items.article",
+                        "name": "expand",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/OrderList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "PutOrder writes an order to the database\nTo write a new order, leave the id empty. To update an existing one, use the id of the order to be updated\nThe stock of the ordered articles is reserved. If an article doesn't have enough stock left, the order is rejected.\nA list of orders is written in a single transaction and the written list is returned.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Add an order to the database",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/stream": {
+            "get": {
+                "description": "StreamOrders streams every created, updated and deleted order as a server-sent event\nThe id of every event is its position in the change log. Send it as Last-Event-ID header or last_event_id query parameter to resume a stream, new streams start with the next change.\nConsumers that fall too far behind are disconnected and have to resume the stream.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Stream order changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of the last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of the last received event",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created",
+                            "updated",
+                            "deleted"
+                        ],
+                        "type": "string",
+                        "description": "comma separated actions to receive",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated order ids to receive",
+                        "name": "ids",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}": {
+            "get": {
+                "description": "GetOrder returns a single order by id",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Get order by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return, such as id,total",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated relations to embed, such as customer or items.article",
+                        "name": "expand",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "DeleteOrder cancels a single order by deleting it and its items by id. Its reserved stock is released.",
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Delete order by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders:export": {
+            "get": {
+                "description": "ExportOrders streams all orders straight from the database as csv, ndjson or a json array\nThe format is taken from the format query parameter or, if that is empty, from the Accept header",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Export all orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of the first order to be exported",
+                        "name": "page_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only export orders of this customer",
+                        "name": "customer",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "export format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/Order"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders:quote": {
+            "post": {
+                "description": "QuoteOrder calculates the subtotal, discount, tax and total of an order without writing it to the database\nArticles are priced at the time of the order, or now if it is empty. Taxes depend on the region and the tax category of the articles.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Price an order",
+                "parameters": [
+                    {
+                        "description": "the order to be priced",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/Order"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Quote"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/skus/{sku}": {
+            "get": {
+                "description": "GetVariantBySKU returns the variant with the SKU in any case, including the article it is a variant of",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Variants"
+                ],
+                "summary": "Look up a variant by its SKU",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "stock keeping unit",
+                        "name": "sku",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Variant"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "Get all tags stored in the database",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "List all tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of the page to be retrieved",
+                        "name": "page_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/TagList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "PutTag writes a tag to the database\nTo write a new tag, leave the id empty. To rename an existing one, use the id of the tag to be renamed\nNames are stored in lower case and must not be used by another tag.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Add a tag to the database",
+                "parameters": [
+                    {
+                        "description": "the tag",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/Tag"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}": {
+            "get": {
+                "description": "GetTag returns a single tag by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Get tag by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tag id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "DeleteTag deletes a single tag by id and removes it from its articles",
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Delete tag by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tag id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all webhooks stored in the database",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List all webhooks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id of the page to be retrieved",
+                        "name": "page_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/WebhookList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "PutWebhook writes a webhook to the database\nTo write a new webhook, leave the id empty. To update an existing one, use the id of the webhook to be updated\nEvery delivery is signed with the secret, see the X-Webhook-Signature header",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Subscribe a webhook to events",
+                "parameters": [
+                    {
+                        "description": "the webhook",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "GetWebhook returns a single webhook by id. The secret is never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get webhook by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "DeleteWebhook deletes a single webhook and its deliveries by id",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete webhook by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {},
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all deliveries of a webhook, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List all deliveries of a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of the page to be retrieved",
+                        "name": "page_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/WebhookDeliveryList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{deliveryID}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "RedeliverWebhookDelivery resets a delivery to pending, including dead deliveries, so that it is sent again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Redeliver a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "delivery id",
+                        "name": "deliveryID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "WebSocket upgrades the connection to the WebSocket protocol. The token is checked during the handshake.\nClients send realtime.ClientMessage objects to subscribe to the orders or articles topic, optionally restricted to a single resource or a price range of articles.\nThe server answers with realtime.ServerMessage objects and pings the client periodically.",
+                "tags": [
+                    "Realtime"
+                ],
+                "summary": "Subscribe to changes over a WebSocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "api token, if it can't be sent as bearer token",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/ServerMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "Address": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "description": "The city",
+                    "type": "string",
+                    "example": "San Francisco"
+                },
+                "country": {
+                    "description": "The ISO 3166-1 alpha-2 country code",
+                    "type": "string",
+                    "example": "US"
+                },
+                "id": {
+                    "description": "The unique id of this address",
+                    "type": "integer",
+                    "example": 1
+                },
+                "kind": {
+                    "description": "What the address is used for",
+                    "type": "string",
+                    "enum": [
+                        "shipping",
+                        "billing"
+                    ],
+                    "example": "shipping"
+                },
+                "line1": {
+                    "description": "The street and house number",
+                    "type": "string",
+                    "example": "1 Main St"
+                },
+                "line2": {
+                    "description": "Additional address information",
+                    "type": "string",
+                    "example": "Apt 2"
+                },
+                "postal_code": {
+                    "description": "The postal code",
+                    "type": "string",
+                    "example": "94103"
+                }
+            }
+        },
+        "Article": {
+            "type": "object",
+            "properties": {
+                "_links": {
+                    "description": "The links to this item and its related resources, which are set when it is returned",
+                    "type": "object",
+                    "$ref": "#/definitions/types.Links"
+                },
+                "categories": {
+                    "description": "The categories of this item, which are only returned if they are expanded",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Category"
+                    }
+                },
+                "category_ids": {
+                    "description": "The ids of the categories of this item. Omit them to keep the current categories.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                },
+                "id": {
+                    "description": "The unique id of this item",
+                    "type": "integer",
+                    "example": 1
+                },
+                "images": {
+                    "description": "The product images of this item",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ArticleImage"
+                    }
+                },
+                "name": {
+                    "description": "The name of this item",
+                    "type": "string",
+                    "example": "Skittles"
+                },
+                "price": {
+                    "description": "The price of this item",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "tags": {
+                    "description": "The tags of this item. Omit them to keep the current tags.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "vegan",
+                        "sweet"
+                    ]
+                },
+                "tax_category": {
+                    "description": "The tax category of this item, which selects the tax rates that apply to it",
+                    "type": "string",
+                    "example": "food"
+                },
+                "variants": {
+                    "description": "The variants of this item, such as sizes or flavours",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Variant"
+                    }
+                }
+            }
+        },
+        "ArticleImage": {
+            "type": "object",
+            "properties": {
+                "article_id": {
+                    "description": "The id of the article shown in the image",
+                    "type": "integer",
+                    "example": 1
+                },
+                "content_type": {
+                    "description": "The content type sniffed from the uploaded file",
+                    "type": "string",
+                    "example": "image/png"
+                },
+                "created_at": {
+                    "description": "The time the image was uploaded",
+                    "type": "string",
+                    "example": "2020-10-01T12:00:00Z"
+                },
+                "height": {
+                    "description": "The height of the image in pixels",
+                    "type": "integer",
+                    "example": 600
+                },
+                "id": {
+                    "description": "The unique id of this image",
+                    "type": "integer",
+                    "example": 1
+                },
+                "size": {
+                    "description": "The size of the image in bytes",
+                    "type": "integer",
+                    "example": 48213
+                },
+                "thumbnail_url": {
+                    "description": "The url to download the thumbnail from",
+                    "type": "string",
+                    "example": "/articles/1/images/1/thumbnail"
+                },
+                "url": {
+                    "description": "The url to download the image from",
+                    "type": "string",
+                    "example": "/articles/1/images/1"
+                },
+                "width": {
+                    "description": "The width of the image in pixels",
+                    "type": "integer",
+                    "example": 800
+                }
+            }
+        },
+        "ArticleImageList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "A list of images",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ArticleImage"
+                    }
+                }
+            }
+        },
+        "ArticleList": {
+            "type": "object",
+            "properties": {
+                "_links": {
+                    "description": "The links to this page and to the next and previous page",
+                    "type": "object",
+                    "$ref": "#/definitions/types.Links"
+                },
+                "items": {
+                    "description": "A list of articles",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Article"
+                    }
+                },
+                "next_page_id": {
+                    "description": "The id to query the next page",
+                    "type": "integer",
+                    "example": 10
+                },
+                "prev_page_id": {
+                    "description": "The id to query the previous page, which is empty for the first page",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "ArticlePrice": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "description": "Whether the price was applied to the article, scheduled prices are applied once they take effect",
+                    "type": "boolean",
+                    "example": true
+                },
+                "article_id": {
+                    "description": "The id of the article",
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "description": "The time this price was recorded",
+                    "type": "string",
+                    "example": "2020-10-01T12:00:00Z"
+                },
+                "effective_from": {
+                    "description": "The time this price takes effect",
+                    "type": "string",
+                    "example": "2020-10-01T12:00:00Z"
+                },
+                "effective_to": {
+                    "description": "The time the next price takes effect, empty for the latest price",
+                    "type": "string",
+                    "example": "2020-11-01T00:00:00Z"
+                },
+                "id": {
+                    "description": "The unique id of this price",
+                    "type": "integer",
+                    "example": 1
+                },
+                "price": {
+                    "description": "The price of the article",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                }
+            }
+        },
+        "ArticlePriceList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "A list of article prices",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ArticlePrice"
+                    }
+                },
+                "next_page_id": {
+                    "description": "The id to query the next page",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "ArticleSearchResult": {
+            "type": "object",
+            "properties": {
+                "article": {
+                    "description": "The matching article",
+                    "type": "object",
+                    "$ref": "#/definitions/Article"
+                },
+                "highlight": {
+                    "description": "The name of the article with the matching words enclosed in \u003cmark\u003e tags",
+                    "type": "string",
+                    "example": "\u003cmark\u003eSkittles\u003c/mark\u003e"
+                },
+                "rank": {
+                    "description": "The relevance of the article, results are ordered by descending rank",
+                    "type": "number",
+                    "example": 0.66
+                }
+            }
+        },
+        "ArticleSearchResultList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "A list of search results",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ArticleSearchResult"
+                    }
+                },
+                "next_page_id": {
+                    "description": "The id to query the next page, which is the number of results on the previous pages",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "AuditChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "description": "The value after the change, omitted if the field doesn't exist anymore",
+                    "type": "object"
+                },
+                "before": {
+                    "description": "The value before the change, omitted if the field didn't exist before",
+                    "type": "object"
+                }
+            }
+        },
+        "AuditChanges": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/AuditChange"
+            }
+        },
+        "AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "The kind of the change",
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "updated",
+                        "deleted"
+                    ],
+                    "example": "updated"
+                },
+                "actor": {
+                    "description": "The name of the authenticated actor who requested the change, anonymous for requests without a token\nand system for changes the api made on its own",
+                    "type": "string",
+                    "example": "backoffice"
+                },
+                "changes": {
+                    "description": "The changed fields of the resource with their values before and after the change",
+                    "type": "object",
+                    "$ref": "#/definitions/AuditChanges"
+                },
+                "created_at": {
+                    "description": "The time of the change",
+                    "type": "string",
+                    "example": "2020-10-01T12:00:00Z"
+                },
+                "id": {
+                    "description": "The unique id of this entry",
+                    "type": "integer",
+                    "example": 1
+                },
+                "request_id": {
+                    "description": "The id of the request that made the change",
+                    "type": "string",
+                    "example": "host/abcdef-000001"
+                },
+                "resource": {
+                    "description": "The kind of the changed resource",
+                    "type": "string",
+                    "example": "article"
+                },
+                "resource_id": {
+                    "description": "The id of the changed resource",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "AuditEntryList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "A list of audit entries",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/AuditEntry"
+                    }
+                },
+                "next_page_id": {
+                    "description": "The id to query the next page",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "BatchOperation": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "description": "The json body of the request, bodies of other media types aren't supported",
+                    "type": "object"
+                },
+                "header": {
+                    "description": "The headers of the request, such as Accept. The Authorization header defaults to the one of the batch request.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "method": {
+                    "description": "The http method of the request",
+                    "type": "string",
+                    "enum": [
+                        "GET",
+                        "PUT",
+                        "POST",
+                        "DELETE"
+                    ],
+                    "example": "PUT"
+                },
+                "path": {
+                    "description": "The path of the request including its query",
+                    "type": "string",
+                    "example": "/articles"
+                }
+            }
+        },
+        "BatchRequest": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "description": "Whether to run all operations in a single database transaction. If an operation fails, the changes of all\noperations are rolled back and the remaining operations aren't run.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "operations": {
+                    "description": "The operations to run in order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/BatchOperation"
+                    }
+                }
+            }
+        },
+        "BatchResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "description": "The responses in the order of the operations",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/BatchResult"
+                    }
+                },
+                "rolled_back": {
+                    "description": "Whether the changes of the operations were rolled back, because an operation of an atomic batch request failed",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "BatchResult": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "description": "The body of the response, responses that aren't json are included as string",
+                    "type": "object"
+                },
+                "status": {
+                    "description": "The http status code of the response",
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "CacheStats": {
+            "type": "object",
+            "properties": {
+                "coalesced": {
+                    "description": "The number of lookups that waited for a concurrent load of the same entry instead of querying the database",
+                    "type": "integer",
+                    "example": 3
+                },
+                "entries": {
+                    "description": "The number of entries currently held in process",
+                    "type": "integer",
+                    "example": 42
+                },
+                "evictions": {
+                    "description": "The number of entries dropped to make room for new ones",
+                    "type": "integer",
+                    "example": 0
+                },
+                "hit_ratio": {
+                    "description": "The share of lookups answered by the cache",
+                    "type": "number",
+                    "example": 0.9
+                },
+                "hits": {
+                    "description": "The number of lookups answered by the cache",
+                    "type": "integer",
+                    "example": 90
+                },
+                "misses": {
+                    "description": "The number of lookups loaded from the database",
+                    "type": "integer",
+                    "example": 10
+                },
+                "remote_errors": {
+                    "description": "The number of failed requests to the remote cache",
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "Category": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "The unique id of this category",
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "The name of this category",
+                    "type": "string",
+                    "example": "Candy"
+                },
+                "parent_id": {
+                    "description": "The id of the parent category, top level categories don't have one",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "CategoryList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "A list of categories",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Category"
+                    }
+                },
+                "next_page_id": {
+                    "description": "The id to query the next page",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "Coupon": {
+            "type": "object",
+            "properties": {
+                "amount_off": {
+                    "description": "The amount taken off by fixed coupons, which only apply to orders of the same currency",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "code": {
+                    "description": "The code customers enter to apply the coupon, which is unique across all coupons",
+                    "type": "string",
+                    "example": "SAVE10"
+                },
+                "id": {
+                    "description": "The unique id of this coupon",
+                    "type": "integer",
+                    "example": 1
+                },
+                "kind": {
+                    "description": "Whether the coupon takes a percentage or a fixed amount off",
+                    "type": "string",
+                    "enum": [
+                        "percentage",
+                        "fixed"
+                    ],
+                    "example": "percentage"
+                },
+                "max_uses": {
+                    "description": "The number of orders the coupon can be applied to, unlimited if it is empty",
+                    "type": "integer",
+                    "example": 100
+                },
+                "percent_off": {
+                    "description": "The percentage taken off by percentage coupons",
+                    "type": "integer",
+                    "example": 10
+                },
+                "uses": {
+                    "description": "The number of orders the coupon was applied to",
+                    "type": "integer",
+                    "example": 3
+                },
+                "valid_from": {
+                    "description": "The coupon can't be applied before this time",
+                    "type": "string",
+                    "example": "2020-10-01T00:00:00Z"
+                },
+                "valid_until": {
+                    "description": "The coupon can't be applied after this time",
+                    "type": "string",
+                    "example": "2020-10-31T23:59:59Z"
+                }
+            }
+        },
+        "CouponList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "A list of coupons",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Coupon"
+                    }
+                },
+                "next_page_id": {
+                    "description": "The id to query the next page",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "Customer": {
+            "type": "object",
+            "properties": {
+                "addresses": {
+                    "description": "The addresses of this customer",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Address"
+                    }
+                },
+                "email": {
+                    "description": "The email address of this customer, which is unique across all customers",
+                    "type": "string",
+                    "example": "jane@example.com"
+                },
+                "id": {
+                    "description": "The unique id of this customer",
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "The full name of this customer",
+                    "type": "string",
+                    "example": "Jane Doe"
+                }
+            }
+        },
+        "CustomerList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "A list of customers",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Customer"
+                    }
+                },
+                "next_page_id": {
+                    "description": "The id to query the next page",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "application-specific error code",
+                    "type": "integer",
+                    "example": 404
+                },
+                "error": {
+                    "description": "application-level error message, for debugging",
+                    "type": "string",
+                    "example": "The requested resource was not found on the server"
+                },
+                "status": {
+                    "description": "user-level status message",
+                    "type": "string",
+                    "example": "Resource not found."
+                }
+            }
+        },
+        "Event": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "The time at which this event occurred",
+                    "type": "string",
+                    "example": "2020-10-01T12:00:00Z"
+                },
+                "data": {
+                    "description": "The changed resource",
+                    "type": "object"
+                },
+                "id": {
+                    "description": "The unique id of this event",
+                    "type": "string",
+                    "example": "9b2d4f7c1a0e3b5d"
+                },
+                "sequence": {
+                    "description": "The position of this event in the change log, set once the event is published",
+                    "type": "integer",
+                    "example": 42
+                },
+                "type": {
+                    "description": "The type of this event",
+                    "type": "string",
+                    "example": "article.created"
+                }
+            }
+        },
+        "Link": {
+            "type": "object",
+            "properties": {
+                "href": {
+                    "description": "The absolute url of the linked resource",
+                    "type": "string",
+                    "example": "https://example.com/articles/1"
+                }
+            }
+        },
+        "Money": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "The amount in minor units of the currency",
+                    "type": "string",
+                    "example": "1.99"
+                },
+                "currency": {
+                    "description": "The ISO 4217 code of the currency",
+                    "type": "string",
+                    "example": "USD"
+                }
+            }
+        },
+        "Order": {
+            "type": "object",
+            "properties": {
+                "_links": {
+                    "description": "The links to this order and its related resources, which are set when it is returned",
+                    "type": "object",
+                    "$ref": "#/definitions/types.Links"
+                },
+                "coupon_code": {
+                    "description": "The code of the coupon applied to this order",
+                    "type": "string",
+                    "example": "SAVE10"
+                },
+                "customer": {
+                    "description": "The customer who owns this order, which is only returned if it is expanded",
+                    "type": "object",
+                    "$ref": "#/definitions/Customer"
+                },
+                "customer_id": {
+                    "description": "The id of the customer who owns this order",
+                    "type": "integer",
+                    "example": 1
+                },
+                "discount": {
+                    "description": "The discount of the coupon, calculated when the order is written",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "id": {
+                    "description": "The unique id of this order",
+                    "type": "integer",
+                    "example": 1
+                },
+                "items": {
+                    "description": "The items of this order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/OrderItem"
+                    }
+                },
+                "lastUpdated": {
+                    "description": "DateTime is the date and time of this order, which is set when it is created",
+                    "type": "string",
+                    "example": "0001-01-01 00:00:00+00"
+                },
+                "region": {
+                    "description": "The region the order is shipped to, which selects the tax rates",
+                    "type": "string",
+                    "example": "US-CA"
+                },
+                "subtotal": {
+                    "description": "The price of all items before discounts and tax, calculated when the order is written",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "tax": {
+                    "description": "The tax of all items, calculated when the order is written",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "total": {
+                    "description": "The amount to be paid, calculated when the order is written",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                }
+            }
+        },
+        "OrderItem": {
+            "type": "object",
+            "properties": {
+                "_links": {
+                    "description": "The links to the related resources of this item, which are set when its order is returned",
+                    "type": "object",
+                    "$ref": "#/definitions/types.Links"
+                },
+                "article": {
+                    "description": "The ordered article, which is only returned if it is expanded",
+                    "type": "object",
+                    "$ref": "#/definitions/Article"
+                },
+                "article_id": {
+                    "description": "The id of the ordered article, which is taken from the variant if it is empty",
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "description": "The unique id of this order item",
+                    "type": "integer",
+                    "example": 1
+                },
+                "quantity": {
+                    "description": "The ordered quantity of the article",
+                    "type": "integer",
+                    "example": 2
+                },
+                "unit_price": {
+                    "description": "The price of a single unit of the article at the time of the order",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "variant": {
+                    "description": "The ordered variant, which is only returned if it is expanded",
+                    "type": "object",
+                    "$ref": "#/definitions/Variant"
+                },
+                "variant_id": {
+                    "description": "The id of the ordered variant of the article",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "OrderList": {
+            "type": "object",
+            "properties": {
+                "_links": {
+                    "description": "The links to this page and to the next and previous page",
+                    "type": "object",
+                    "$ref": "#/definitions/types.Links"
+                },
+                "items": {
+                    "description": "A list of orders",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Order"
+                    }
+                },
+                "next_page_id": {
+                    "description": "The id to query the next page",
+                    "type": "integer",
+                    "example": 10
+                },
+                "prev_page_id": {
+                    "description": "The id to query the previous page, which is empty for the first page",
                     "type": "integer",
                     "example": 1
+                }
+            }
+        },
+        "Quote": {
+            "type": "object",
+            "properties": {
+                "coupon_code": {
+                    "description": "The code of the applied coupon",
+                    "type": "string",
+                    "example": "SAVE10"
                 },
-                "name": {
-                    "description": "The name of this item",
+                "discount": {
+                    "description": "The sum of all discounts",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "items": {
+                    "description": "The priced items of the order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/QuoteItem"
+                    }
+                },
+                "region": {
+                    "description": "The region the tax was calculated for",
                     "type": "string",
-                    "example": "Skittles"
+                    "example": "US-CA"
                 },
-                "price": {
-                    "description": "The price of this item",
-                    "type": "number",
-                    "example": 1.99
+                "subtotal": {
+                    "description": "The sum of all items before discounts and tax",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "tax": {
+                    "description": "The sum of all taxes",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "total": {
+                    "description": "The amount to be paid, which is the subtotal minus the discount plus the tax",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
                 }
             }
         },
-        "ArticleList": {
+        "QuoteItem": {
+            "type": "object",
+            "properties": {
+                "article_id": {
+                    "description": "The id of the article",
+                    "type": "integer",
+                    "example": 1
+                },
+                "discount": {
+                    "description": "The share of the coupon discount of this item",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "quantity": {
+                    "description": "The quantity of the article",
+                    "type": "integer",
+                    "example": 2
+                },
+                "subtotal": {
+                    "description": "The unit price times the quantity",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "tax": {
+                    "description": "The tax of this item",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "tax_rate": {
+                    "description": "The tax rate in percent applied to the discounted subtotal",
+                    "type": "string",
+                    "example": "7.25"
+                },
+                "total": {
+                    "description": "The discounted subtotal plus the tax",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "unit_price": {
+                    "description": "The price of a single unit of the article",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "variant_id": {
+                    "description": "The id of the variant of the article",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "ServerMessage": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "The reason of an error message",
+                    "type": "string",
+                    "example": "unknown topic \"customers\""
+                },
+                "event": {
+                    "description": "The event of an event message",
+                    "type": "object",
+                    "$ref": "#/definitions/Event"
+                },
+                "id": {
+                    "description": "The id of the subscription the message refers to",
+                    "type": "string",
+                    "example": "my-order"
+                },
+                "type": {
+                    "description": "The type of the message",
+                    "type": "string",
+                    "enum": [
+                        "subscribed",
+                        "unsubscribed",
+                        "event",
+                        "error"
+                    ],
+                    "example": "event"
+                }
+            }
+        },
+        "Stock": {
+            "type": "object",
+            "properties": {
+                "article_id": {
+                    "description": "The id of the article",
+                    "type": "integer",
+                    "example": 1
+                },
+                "available": {
+                    "description": "The quantity that can still be ordered, only set for tracked articles",
+                    "type": "integer",
+                    "example": 42
+                },
+                "reserved": {
+                    "description": "The quantity reserved by orders",
+                    "type": "integer",
+                    "example": 3
+                },
+                "tracked": {
+                    "description": "Whether the stock of the article is tracked. Articles without tracked stock can always be ordered.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "variant_id": {
+                    "description": "The id of the variant of the article",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "StockAdjustment": {
+            "type": "object",
+            "properties": {
+                "article_id": {
+                    "description": "The id of the adjusted article",
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "description": "The time of this adjustment",
+                    "type": "string",
+                    "example": "2020-10-01T12:00:00Z"
+                },
+                "delta": {
+                    "description": "The change of the available quantity",
+                    "type": "integer",
+                    "example": 10
+                },
+                "id": {
+                    "description": "The unique id of this adjustment",
+                    "type": "integer",
+                    "example": 1
+                },
+                "note": {
+                    "description": "A free text note on this adjustment",
+                    "type": "string",
+                    "example": "delivery 2020-10-01"
+                },
+                "order_id": {
+                    "description": "The id of the order that reserved or released the stock",
+                    "type": "integer",
+                    "example": 1
+                },
+                "reason": {
+                    "description": "The reason of this adjustment",
+                    "type": "string",
+                    "enum": [
+                        "reservation",
+                        "release",
+                        "restock",
+                        "correction",
+                        "damage",
+                        "return"
+                    ],
+                    "example": "restock"
+                },
+                "variant_id": {
+                    "description": "The id of the adjusted variant, the stock of the article itself is adjusted if it is empty",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "StockAdjustmentList": {
             "type": "object",
             "properties": {
                 "items": {
-                    "description": "A list of articles",
+                    "description": "A list of stock adjustments",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/Article"
+                        "$ref": "#/definitions/StockAdjustment"
                     }
                 },
                 "next_page_id": {
@@ -289,49 +3947,205 @@ var doc = `{
                 }
             }
         },
-        "ErrorResponse": {
+        "Tag": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "application-specific error code",
+                "id": {
+                    "description": "The unique id of this tag",
                     "type": "integer",
-                    "example": 404
+                    "example": 1
                 },
-                "error": {
-                    "description": "application-level error message, for debugging",
+                "name": {
+                    "description": "The name of this tag, which is unique across all tags",
                     "type": "string",
-                    "example": "The requested resource was not found on the server"
+                    "example": "vegan"
+                }
+            }
+        },
+        "TagList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "A list of tags",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Tag"
+                    }
                 },
-                "status": {
-                    "description": "user-level status message",
+                "next_page_id": {
+                    "description": "The id to query the next page",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "Variant": {
+            "type": "object",
+            "properties": {
+                "article": {
+                    "description": "The article this is a variant of, only included when the variant is looked up by its SKU",
+                    "type": "object",
+                    "$ref": "#/definitions/Article"
+                },
+                "article_id": {
+                    "description": "The id of the article this is a variant of",
+                    "type": "integer",
+                    "example": 1
+                },
+                "attributes": {
+                    "description": "The properties that distinguish this variant from the other variants of the article",
+                    "type": "object"
+                },
+                "id": {
+                    "description": "The unique id of this variant",
+                    "type": "integer",
+                    "example": 1
+                },
+                "price": {
+                    "description": "The price of this variant, the price of the article applies if it is empty",
+                    "type": "object",
+                    "$ref": "#/definitions/Money"
+                },
+                "sku": {
+                    "description": "The stock keeping unit of this variant, which is unique across all variants",
                     "type": "string",
-                    "example": "Resource not found."
+                    "example": "SKITTLES-SOUR-100G"
                 }
             }
         },
-        "Order": {
+        "VariantList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "A list of variants",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Variant"
+                    }
+                }
+            }
+        },
+        "Webhook": {
             "type": "object",
             "properties": {
+                "events": {
+                    "description": "The event types this webhook is subscribed to, * subscribes to all events",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "article.created",
+                        "order.created"
+                    ]
+                },
                 "id": {
-                    "description": "The unique id of this order",
+                    "description": "The unique id of this webhook",
                     "type": "integer",
                     "example": 1
                 },
-                "lastUpdated": {
-                    "description": "DateTime is the date and time of this order",
+                "secret": {
+                    "description": "The secret used to sign the payloads. It is never returned by the api.",
                     "type": "string",
-                    "example": "0001-01-01 00:00:00+00"
+                    "example": "s3cr3t"
+                },
+                "url": {
+                    "description": "The url events are posted to",
+                    "type": "string",
+                    "example": "https://example.com/hooks"
                 }
             }
         },
-        "OrderList": {
+        "WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "The number of failed attempts",
+                    "type": "integer",
+                    "example": 0
+                },
+                "created_at": {
+                    "description": "The time this delivery was created",
+                    "type": "string",
+                    "example": "2020-10-01T12:00:00Z"
+                },
+                "delivered_at": {
+                    "description": "The time this delivery was acknowledged by the receiver",
+                    "type": "string",
+                    "example": "2020-10-01T12:00:00Z"
+                },
+                "event_type": {
+                    "description": "The type of the delivered event",
+                    "type": "string",
+                    "example": "article.created"
+                },
+                "id": {
+                    "description": "The unique id of this delivery",
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_error": {
+                    "description": "The error of the last failed attempt",
+                    "type": "string",
+                    "example": "unexpected status code 500"
+                },
+                "next_attempt_at": {
+                    "description": "The time of the next attempt",
+                    "type": "string",
+                    "example": "2020-10-01T12:00:00Z"
+                },
+                "payload": {
+                    "description": "The json encoded event that is posted to the webhook",
+                    "type": "string",
+                    "example": "{\"type\":\"article.created\"}"
+                },
+                "response_status": {
+                    "description": "The http status code of the last attempt",
+                    "type": "integer",
+                    "example": 200
+                },
+                "status": {
+                    "description": "The state of this delivery",
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "delivered",
+                        "dead"
+                    ],
+                    "example": "pending"
+                },
+                "webhook_id": {
+                    "description": "The id of the webhook this delivery is sent to",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "WebhookDeliveryList": {
             "type": "object",
             "properties": {
                 "items": {
-                    "description": "A list of orders",
+                    "description": "A list of webhook deliveries",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/Order"
+                        "$ref": "#/definitions/WebhookDelivery"
+                    }
+                },
+                "next_page_id": {
+                    "description": "The id to query the next page",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "WebhookList": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "A list of webhooks",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Webhook"
                     }
                 },
                 "next_page_id": {
@@ -340,6 +4154,79 @@ var doc = `{
                     "example": 10
                 }
             }
+        },
+        "graphql.Error": {
+            "type": "object",
+            "properties": {
+                "locations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/graphql.Location"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                }
+            }
+        },
+        "graphql.Location": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "type": "integer"
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "graphql.Params": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "graphql.Result": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/graphql.Error"
+                    }
+                }
+            }
+        },
+        "types.Links": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/Link"
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
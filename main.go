@@ -1,26 +1,40 @@
 package main
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/pflag"
 	"go.uber.org/zap"
 
 	"github.com/jonnylangefeld/go-api/docs"
 	"github.com/jonnylangefeld/go-api/pkg/api"
+	"github.com/jonnylangefeld/go-api/pkg/cache"
 	"github.com/jonnylangefeld/go-api/pkg/db"
+	grpcapi "github.com/jonnylangefeld/go-api/pkg/grpc"
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/outbox"
+	"github.com/jonnylangefeld/go-api/pkg/pricing"
+	"github.com/jonnylangefeld/go-api/pkg/realtime"
+	"github.com/jonnylangefeld/go-api/pkg/storage"
+	"github.com/jonnylangefeld/go-api/pkg/webhook"
 )
 
 var (
-	version string
-	addr    string
+	version  string
+	addr     string
+	grpcAddr string
 )
 
 func init() {
 	pflag.StringVarP(&addr, "address", "a", ":8080", "the address for the api to listen on. Host and port separated by ':'")
+	pflag.StringVar(&grpcAddr, "grpc-address", ":9090", "the address for the gRPC api to listen on. Host and port separated by ':'")
 	pflag.Parse()
 }
 
@@ -40,14 +54,107 @@ func main() {
 	// print current version
 	log.Info("starting up API...", zap.String("version", version))
 
-	dbClient := &db.Client{}
+	// tax orders with the configured rates
+	taxRates, err := pricing.ParseRateTable(os.Getenv("TAX_RATES"))
+	if err != nil {
+		log.Error("couldn't parse tax rates", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// tune the database connections with the configured options
+	dbOptions, err := db.ParseOptions(os.Getenv("DB_OPTIONS"))
+	if err != nil {
+		log.Error("couldn't parse database options", zap.Error(err))
+		os.Exit(1)
+	}
+
+	dbClient := &db.Client{Pricing: pricing.NewEngine(taxRates), Options: &dbOptions}
 	if err := dbClient.Connect(os.Getenv("DB_CONNECTION")); err != nil {
 		log.Error("couldn't connect to database", zap.Error(err))
 		os.Exit(1)
 	}
+	if replicas := os.Getenv("DB_REPLICAS"); replicas != "" {
+		for _, replica := range strings.Split(replicas, ",") {
+			// replicas that are down serve reads once they are back
+			if err := dbClient.AddReplica(strings.TrimSpace(replica)); err != nil {
+				log.Warn("couldn't connect to database replica", zap.Error(err))
+			}
+		}
+	}
+
+	// cache articles and orders for the configured time
+	var client db.ClientInterface = dbClient
+	var dbCache *cache.Client
+	cacheTTL := 30 * time.Second
+	if ttl := os.Getenv("CACHE_TTL"); ttl != "" {
+		cacheTTL, err = time.ParseDuration(ttl)
+		if err != nil {
+			log.Error("couldn't parse cache ttl", zap.Error(err))
+			os.Exit(1)
+		}
+	}
+	if cacheTTL > 0 {
+		dbCache = cache.New(dbClient, 10000, cacheTTL)
+		client = dbCache
+	}
+
+	// route reads away from replicas that don't respond
+	ctx, cancel := context.WithCancel(context.Background())
+	go dbClient.MonitorReplicas(ctx, 10*time.Second)
+
+	// deliver webhooks in the background
+	dispatcher := webhook.NewDispatcher(client, log)
+	go dispatcher.Run(ctx)
+
+	// publish the events of the outbox
+	broker := outbox.NewMemoryBroker()
+	relay := outbox.NewRelay(client, log,
+		&outbox.LogSink{Log: log},
+		&outbox.WebhookSink{Emitter: dispatcher},
+		&outbox.BrokerSink{Broker: broker},
+	)
+	go relay.Run(ctx)
+
+	// apply scheduled prices once they take effect
+	scheduler := pricing.NewScheduler(client, log)
+	go scheduler.Run(ctx)
+
+	// fan out events to websocket clients
+	hub := realtime.NewHub(broker, log)
+	go hub.Run(ctx)
+
+	// authenticate clients with the configured api tokens
+	tokens, err := m.ParseTokens(os.Getenv("API_TOKENS"))
+	if err != nil {
+		log.Error("couldn't parse api tokens", zap.Error(err))
+		os.Exit(1)
+	}
+	m.SetTokens(tokens)
+
+	// honour the forwarding headers of the configured proxies
+	proxies, err := m.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Error("couldn't parse trusted proxies", zap.Error(err))
+		os.Exit(1)
+	}
+	m.SetTrustedProxies(proxies)
+
+	// store article images in the configured blob storage
+	var blobStore storage.Store
+	if blobStorage := os.Getenv("BLOB_STORAGE"); blobStorage != "" {
+		blobStore, err = storage.Open(blobStorage)
+		if err != nil {
+			log.Error("couldn't open blob storage", zap.Error(err))
+			os.Exit(1)
+		}
+	}
 
 	// start the api server
-	r := api.GetRouter(log, dbClient)
+	r := api.GetRouter(log, client)
+	api.SetCache(dbCache)
+	api.SetBroker(broker)
+	api.SetHub(hub)
+	api.SetStorage(blobStore)
 	go func() {
 		if err := http.ListenAndServe(addr, r); err != nil {
 			log.Error("failed to start server", zap.Error(err))
@@ -55,8 +162,23 @@ func main() {
 		}
 	}()
 
-	log.Info("ready to serve requests on " + addr)
+	// start the gRPC server
+	grpcServer := grpcapi.NewServer(log, client)
+	go func() {
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err == nil {
+			err = grpcServer.Serve(lis)
+		}
+		if err != nil {
+			log.Error("failed to start gRPC server", zap.Error(err))
+			os.Exit(1)
+		}
+	}()
+
+	log.Info("ready to serve requests on " + addr + " and gRPC calls on " + grpcAddr)
 	<-c
 	log.Info("gracefully shutting down")
+	cancel()
+	grpcServer.GracefulStop()
 	os.Exit(0)
 }
@@ -3,14 +3,21 @@ package api
 import (
 	"net/http"
 
+	"github.com/jonnylangefeld/go-api/pkg/cache"
 	"github.com/jonnylangefeld/go-api/pkg/db"
+	"github.com/jonnylangefeld/go-api/pkg/graphql"
 	"go.uber.org/zap"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
 	httpSwagger "github.com/swaggo/http-swagger"
 
 	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/outbox"
+	"github.com/jonnylangefeld/go-api/pkg/realtime"
+	"github.com/jonnylangefeld/go-api/pkg/storage"
+	"github.com/jonnylangefeld/go-api/pkg/types"
 )
 
 var DBClient db.ClientInterface
@@ -20,6 +27,30 @@ func SetDBClient(c db.ClientInterface) {
 	m.SetDBClient(DBClient)
 }
 
+var EventBroker outbox.Broker
+
+func SetBroker(b outbox.Broker) {
+	EventBroker = b
+}
+
+var RealtimeHub *realtime.Hub
+
+func SetHub(h *realtime.Hub) {
+	RealtimeHub = h
+}
+
+var BlobStore storage.Store
+
+func SetStorage(s storage.Store) {
+	BlobStore = s
+}
+
+var DBCache *cache.Client
+
+func SetCache(c *cache.Client) {
+	DBCache = c
+}
+
 // GetRouter configures a chi router and starts the http server
 // @title My API
 // @description This API is a sample go-api.
@@ -28,43 +59,171 @@ func SetDBClient(c db.ClientInterface) {
 // @contact.email jonny.langefeld@gmail.com
 // @host example.com
 // @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 func GetRouter(log *zap.Logger, dbClient db.ClientInterface) *chi.Mux {
 	r := chi.NewRouter()
+	render.Respond = respond
+	render.Decode = decode
 	r.Use(middleware.RequestID)
+	r.Use(m.Forwarded)
 	SetDBClient(dbClient)
 	if log != nil {
 		r.Use(m.SetLogger(log))
 	}
+	r.Use(m.DatabaseAvailable)
+	r.Use(m.Identify)
+	r.Use(m.ReadYourWrites)
 	buildTree(r)
 
 	return r
 }
 
+var (
+	// negotiate offers the media types of single resources
+	negotiate = m.Negotiate(m.MediaTypeJSON, m.MediaTypeXML, m.MediaTypeMsgpack)
+	// negotiateList offers the media types of lists, which can also be returned as csv and JSON:API documents
+	negotiateList = m.Negotiate(m.MediaTypeJSON, m.MediaTypeXML, m.MediaTypeMsgpack, m.MediaTypeCSV, m.MediaTypeJSONAPI)
+
+	// selectArticles selects the fields and expanded relations of articles
+	selectArticles = m.Select(types.Article{}, types.ArticleRelations)
+	// selectOrders selects the fields and expanded relations of orders
+	selectOrders = m.Select(types.Order{}, types.OrderRelations)
+)
+
 func buildTree(r *chi.Mux) {
 	r.HandleFunc("/swagger", func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, r.RequestURI+"/", http.StatusMovedPermanently)
 	})
 	r.Get("/swagger*", httpSwagger.Handler())
+	r.Get("/graphiql", graphql.Playground("/graphql"))
+
+	r.Get("/graphql", GraphQL)
+	r.Post("/graphql", GraphQL)
 
 	r.Route("/articles", func(r chi.Router) {
-		r.With(m.Pagination).Get("/", ListArticles)
+		r.With(negotiateList, m.Pagination, m.ArticleFilter, m.IDs, selectArticles).Get("/", ListArticles)
+		r.Get("/stream", StreamArticles)
+		r.With(m.Pagination).Get("/search", SearchArticles)
 
 		r.Route("/{id}", func(r chi.Router) {
-			r.Use(m.Article)
-			r.Get("/", GetArticle)
+			// the selection has to be known before the article is loaded
+			r.With(negotiate, selectArticles, m.Article).Get("/", GetArticle)
+
+			r.Group(func(r chi.Router) {
+				r.Use(m.Article)
+				r.Delete("/", DeleteArticle)
+				r.Get("/stock", GetArticleStock)
+				r.With(m.Pagination).Get("/stock/adjustments", ListStockAdjustments)
+				r.Post("/stock/adjustments", PostStockAdjustment)
+				r.With(m.Pagination).Get("/prices", ListArticlePrices)
+				r.Post("/prices", PostArticlePrice)
+				r.Get("/images", ListArticleImages)
+				r.Post("/images", PostArticleImage)
+				r.Route("/images/{imageID}", func(r chi.Router) {
+					r.Use(m.ArticleImage)
+					r.Get("/", GetArticleImage)
+					r.Delete("/", DeleteArticleImage)
+					r.Get("/thumbnail", GetArticleImageThumbnail)
+				})
+				r.Get("/variants", ListVariants)
+				r.Put("/variants", PutVariant)
+				r.Route("/variants/{variantID}", func(r chi.Router) {
+					r.Use(m.Variant)
+					r.Get("/", GetVariant)
+					r.Delete("/", DeleteVariant)
+					r.Get("/stock", GetVariantStock)
+				})
+			})
 		})
 
-		r.Put("/", PutArticle)
+		r.With(negotiate).Put("/", PutArticle)
 	})
+	r.With(m.Pagination, m.ArticleFilter).Get("/articles:export", ExportArticles)
+	r.Get("/skus/{sku}", GetVariantBySKU)
+	r.Get("/cache/stats", GetCacheStats)
+	r.With(m.Authenticate, m.Pagination, m.AuditFilter).Get("/audit", ListAuditEntries)
+	r.Post("/batch", Batch(r))
 
 	r.Route("/orders", func(r chi.Router) {
-		r.With(m.Pagination).Get("/", ListOrders)
+		r.With(negotiateList, m.Pagination, m.OrderFilter, m.IDs, selectOrders).Get("/", ListOrders)
+		r.Get("/stream", StreamOrders)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.With(negotiate, selectOrders, m.Order).Get("/", GetOrder)
+			r.With(m.Order).Delete("/", DeleteOrder)
+		})
+
+		r.With(negotiate).Put("/", PutOrder)
+	})
+	r.With(m.Pagination, m.OrderFilter).Get("/orders:export", ExportOrders)
+	r.Post("/orders:quote", QuoteOrder)
+
+	r.Route("/coupons", func(r chi.Router) {
+		r.With(m.Pagination).Get("/", ListCoupons)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Use(m.Coupon)
+			r.Get("/", GetCoupon)
+			r.Delete("/", DeleteCoupon)
+		})
+
+		r.Put("/", PutCoupon)
+	})
+
+	r.Route("/categories", func(r chi.Router) {
+		r.With(m.Pagination).Get("/", ListCategories)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Use(m.Category)
+			r.Get("/", GetCategory)
+			r.Delete("/", DeleteCategory)
+			r.With(m.Pagination).Get("/articles", ListCategoryArticles)
+		})
+
+		r.Put("/", PutCategory)
+	})
+
+	r.Route("/tags", func(r chi.Router) {
+		r.With(m.Pagination).Get("/", ListTags)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Use(m.Tag)
+			r.Get("/", GetTag)
+			r.Delete("/", DeleteTag)
+		})
+
+		r.Put("/", PutTag)
+	})
+
+	r.Route("/customers", func(r chi.Router) {
+		r.With(m.Pagination).Get("/", ListCustomers)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Use(m.Customer)
+			r.Get("/", GetCustomer)
+			r.Delete("/", DeleteCustomer)
+			r.With(m.Pagination).Get("/orders", ListCustomerOrders)
+		})
+
+		r.Put("/", PutCustomer)
+	})
+
+	r.With(m.Authenticate).Get("/ws", WebSocket)
+
+	r.Route("/webhooks", func(r chi.Router) {
+		r.Use(m.Authenticate)
+		r.With(m.Pagination).Get("/", ListWebhooks)
 
 		r.Route("/{id}", func(r chi.Router) {
-			r.Use(m.Order)
-			r.Get("/", GetOrder)
+			r.Use(m.Webhook)
+			r.Get("/", GetWebhook)
+			r.Delete("/", DeleteWebhook)
+			r.With(m.Pagination).Get("/deliveries", ListWebhookDeliveries)
+			r.With(m.WebhookDelivery).Post("/deliveries/{deliveryID}/redeliver", RedeliverWebhookDelivery)
 		})
 
-		r.Put("/", PutOrder)
+		r.Put("/", PutWebhook)
 	})
 }
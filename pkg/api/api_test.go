@@ -2,12 +2,21 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"image"
+	"image/png"
 	"io"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jonnylangefeld/go-api/pkg/types"
 
@@ -17,18 +26,90 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jonnylangefeld/go-api/pkg/api/mocks"
+	"github.com/jonnylangefeld/go-api/pkg/db"
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/msgpack"
+	"github.com/jonnylangefeld/go-api/pkg/storage"
 )
 
 var (
 	testArticle1 = types.Article{
 		ID:    1,
 		Name:  "Skittles",
-		Price: 1.99,
+		Price: types.NewMoney(199, "USD"),
 	}
 	testArticle2 = types.Article{
 		ID:    2,
 		Name:  "Jelly Beans",
-		Price: 2.99,
+		Price: types.NewMoney(299, "USD"),
+	}
+	testCustomerID = 1
+	testCustomer1  = types.Customer{
+		ID:    1,
+		Name:  "Jane Doe",
+		Email: "jane@example.com",
+		Addresses: []*types.Address{
+			{ID: 1, CustomerID: 1, Kind: types.AddressShipping, Line1: "1 Main St", City: "San Francisco", Country: "US"},
+		},
+	}
+	testCoupon1 = types.Coupon{
+		ID:         1,
+		Code:       "SAVE10",
+		Kind:       types.CouponPercentage,
+		PercentOff: 10,
+	}
+	testParentCategoryID = 1
+	testCategory1        = types.Category{
+		ID:   1,
+		Name: "Candy",
+	}
+	testCategory2 = types.Category{
+		ID:       2,
+		Name:     "Chewy Candy",
+		ParentID: &testParentCategoryID,
+	}
+	testTag1 = types.Tag{
+		ID:   1,
+		Name: "vegan",
+	}
+	testImage1 = types.ArticleImage{
+		ID:                   1,
+		ArticleID:            1,
+		ContentType:          "image/png",
+		Size:                 4,
+		Width:                1,
+		Height:               1,
+		Key:                  "articles/1/image.png",
+		ThumbnailKey:         "articles/1/image-thumbnail.png",
+		ThumbnailContentType: "image/png",
+		URL:                  "/articles/1/images/1",
+		ThumbnailURL:         "/articles/1/images/1/thumbnail",
+	}
+	testVariant1 = types.Variant{
+		ID:         1,
+		ArticleID:  1,
+		SKU:        "SKITTLES-SOUR",
+		Attributes: types.Attributes{"flavour": "sour"},
+	}
+	testAuditEntry1 = types.AuditEntry{
+		ID:         1,
+		Resource:   "article",
+		ResourceID: 1,
+		Action:     types.AuditUpdated,
+		Actor:      "backoffice",
+		RequestID:  "host/abcdef-000001",
+		Changes: types.AuditChanges{
+			"price": {Before: json.RawMessage(`{"amount":"1.49","currency":"USD"}`), After: json.RawMessage(`{"amount":"1.99","currency":"USD"}`)},
+		},
+		CreatedAt: time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+	testOrder1 = types.Order{
+		ID:         1,
+		CustomerID: &testCustomerID,
+		Items: []*types.OrderItem{
+			{ID: 1, ArticleID: 1, Quantity: 2},
+			{ID: 2, ArticleID: 2, Quantity: 1},
+		},
 	}
 )
 
@@ -57,6 +138,226 @@ func TestGetRouter(t *testing.T) {
 			method: http.MethodGet,
 			path:   "/orders/id",
 		},
+		"GET /articles:export": {
+			method: http.MethodGet,
+			path:   "/articles:export",
+		},
+		"GET /orders:export": {
+			method: http.MethodGet,
+			path:   "/orders:export",
+		},
+		"GET /graphql": {
+			method: http.MethodGet,
+			path:   "/graphql",
+		},
+		"POST /graphql": {
+			method: http.MethodPost,
+			path:   "/graphql",
+		},
+		"GET /graphiql": {
+			method: http.MethodGet,
+			path:   "/graphiql",
+		},
+		"GET /articles/stream": {
+			method: http.MethodGet,
+			path:   "/articles/stream",
+		},
+		"GET /articles/search": {
+			method: http.MethodGet,
+			path:   "/articles/search",
+		},
+		"GET /articles/{id}/stock": {
+			method: http.MethodGet,
+			path:   "/articles/id/stock",
+		},
+		"GET /articles/{id}/stock/adjustments": {
+			method: http.MethodGet,
+			path:   "/articles/id/stock/adjustments",
+		},
+		"POST /articles/{id}/stock/adjustments": {
+			method: http.MethodPost,
+			path:   "/articles/id/stock/adjustments",
+		},
+		"GET /articles/{id}/prices": {
+			method: http.MethodGet,
+			path:   "/articles/id/prices",
+		},
+		"POST /articles/{id}/prices": {
+			method: http.MethodPost,
+			path:   "/articles/id/prices",
+		},
+		"GET /orders/stream": {
+			method: http.MethodGet,
+			path:   "/orders/stream",
+		},
+		"GET /ws": {
+			method: http.MethodGet,
+			path:   "/ws",
+		},
+		"DELETE /articles/{id}": {
+			method: http.MethodDelete,
+			path:   "/articles/id",
+		},
+		"DELETE /orders/{id}": {
+			method: http.MethodDelete,
+			path:   "/orders/id",
+		},
+		"POST /orders:quote": {
+			method: http.MethodPost,
+			path:   "/orders:quote",
+		},
+		"GET /coupons": {
+			method: http.MethodGet,
+			path:   "/coupons",
+		},
+		"PUT /coupons": {
+			method: http.MethodPut,
+			path:   "/coupons",
+		},
+		"GET /coupons/{id}": {
+			method: http.MethodGet,
+			path:   "/coupons/id",
+		},
+		"DELETE /coupons/{id}": {
+			method: http.MethodDelete,
+			path:   "/coupons/id",
+		},
+		"GET /articles/{id}/images": {
+			method: http.MethodGet,
+			path:   "/articles/id/images",
+		},
+		"POST /articles/{id}/images": {
+			method: http.MethodPost,
+			path:   "/articles/id/images",
+		},
+		"GET /articles/{id}/images/{imageID}": {
+			method: http.MethodGet,
+			path:   "/articles/id/images/imageID",
+		},
+		"DELETE /articles/{id}/images/{imageID}": {
+			method: http.MethodDelete,
+			path:   "/articles/id/images/imageID",
+		},
+		"GET /articles/{id}/images/{imageID}/thumbnail": {
+			method: http.MethodGet,
+			path:   "/articles/id/images/imageID/thumbnail",
+		},
+		"GET /articles/{id}/variants": {
+			method: http.MethodGet,
+			path:   "/articles/id/variants",
+		},
+		"PUT /articles/{id}/variants": {
+			method: http.MethodPut,
+			path:   "/articles/id/variants",
+		},
+		"GET /articles/{id}/variants/{variantID}": {
+			method: http.MethodGet,
+			path:   "/articles/id/variants/variantID",
+		},
+		"DELETE /articles/{id}/variants/{variantID}": {
+			method: http.MethodDelete,
+			path:   "/articles/id/variants/variantID",
+		},
+		"GET /articles/{id}/variants/{variantID}/stock": {
+			method: http.MethodGet,
+			path:   "/articles/id/variants/variantID/stock",
+		},
+		"GET /skus/{sku}": {
+			method: http.MethodGet,
+			path:   "/skus/sku",
+		},
+		"GET /cache/stats": {
+			method: http.MethodGet,
+			path:   "/cache/stats",
+		},
+		"GET /audit": {
+			method: http.MethodGet,
+			path:   "/audit",
+		},
+		"POST /batch": {
+			method: http.MethodPost,
+			path:   "/batch",
+		},
+		"GET /categories": {
+			method: http.MethodGet,
+			path:   "/categories",
+		},
+		"PUT /categories": {
+			method: http.MethodPut,
+			path:   "/categories",
+		},
+		"GET /categories/{id}": {
+			method: http.MethodGet,
+			path:   "/categories/id",
+		},
+		"DELETE /categories/{id}": {
+			method: http.MethodDelete,
+			path:   "/categories/id",
+		},
+		"GET /categories/{id}/articles": {
+			method: http.MethodGet,
+			path:   "/categories/id/articles",
+		},
+		"GET /tags": {
+			method: http.MethodGet,
+			path:   "/tags",
+		},
+		"PUT /tags": {
+			method: http.MethodPut,
+			path:   "/tags",
+		},
+		"GET /tags/{id}": {
+			method: http.MethodGet,
+			path:   "/tags/id",
+		},
+		"DELETE /tags/{id}": {
+			method: http.MethodDelete,
+			path:   "/tags/id",
+		},
+		"GET /customers": {
+			method: http.MethodGet,
+			path:   "/customers",
+		},
+		"PUT /customers": {
+			method: http.MethodPut,
+			path:   "/customers",
+		},
+		"GET /customers/{id}": {
+			method: http.MethodGet,
+			path:   "/customers/id",
+		},
+		"DELETE /customers/{id}": {
+			method: http.MethodDelete,
+			path:   "/customers/id",
+		},
+		"GET /customers/{id}/orders": {
+			method: http.MethodGet,
+			path:   "/customers/id/orders",
+		},
+		"GET /webhooks": {
+			method: http.MethodGet,
+			path:   "/webhooks",
+		},
+		"PUT /webhooks": {
+			method: http.MethodPut,
+			path:   "/webhooks",
+		},
+		"GET /webhooks/{id}": {
+			method: http.MethodGet,
+			path:   "/webhooks/id",
+		},
+		"DELETE /webhooks/{id}": {
+			method: http.MethodDelete,
+			path:   "/webhooks/id",
+		},
+		"GET /webhooks/{id}/deliveries": {
+			method: http.MethodGet,
+			path:   "/webhooks/id/deliveries",
+		},
+		"POST /webhooks/{id}/deliveries/{deliveryID}/redeliver": {
+			method: http.MethodPost,
+			path:   "/webhooks/id/deliveries/deliveryID/redeliver",
+		},
 		"GET /swagger": {
 			method: http.MethodGet,
 			path:   "/swagger",
@@ -77,19 +378,59 @@ func getDBClientMock(t *testing.T) *mocks.MockClientInterface {
 	ctrl := gomock.NewController(t)
 	dbClient := mocks.NewMockClientInterface(ctrl)
 
-	dbClient.EXPECT().GetArticles(gomock.Eq(0)).Return(&types.ArticleList{
+	dbClient.EXPECT().Available().Return(time.Duration(0), nil).AnyTimes()
+	dbClient.EXPECT().Audited(gomock.Any(), gomock.Any()).Return(dbClient).AnyTimes()
+	dbClient.EXPECT().Session(gomock.Any()).Return(dbClient).AnyTimes()
+	dbClient.EXPECT().LastWrite().Return(time.Time{}).AnyTimes()
+	dbClient.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(client db.ClientInterface) error) error {
+		return fn(dbClient)
+	}).AnyTimes()
+	dbClient.EXPECT().GetArticles(gomock.Eq(0), gomock.Eq(&types.ArticleFilter{})).Return(&types.ArticleList{
 		Items: []*types.Article{
 			&testArticle1,
 			&testArticle2,
 		},
 	})
 
-	dbClient.EXPECT().GetArticles(gomock.Eq(1)).Return(&types.ArticleList{
+	dbClient.EXPECT().GetArticles(gomock.Eq(1), gomock.Eq(&types.ArticleFilter{})).Return(&types.ArticleList{
+		Items: []*types.Article{
+			&testArticle2,
+		},
+	})
+
+	dbClient.EXPECT().GetArticles(gomock.Eq(2), gomock.Eq(&types.ArticleFilter{})).Return(&types.ArticleList{
+		Items: []*types.Article{
+			{ID: 2, Name: "Jelly Beans", Price: types.NewMoney(299, "USD")},
+		},
+		NextPageID: 3,
+		PrevPageID: 1,
+	}).AnyTimes()
+
+	lowStock := 5
+	dbClient.EXPECT().GetArticles(gomock.Eq(0), gomock.Eq(&types.ArticleFilter{LowStock: &lowStock})).Return(&types.ArticleList{
 		Items: []*types.Article{
 			&testArticle2,
 		},
 	})
 
+	dbClient.EXPECT().GetArticles(gomock.Eq(0), gomock.Eq(&types.ArticleFilter{CategoryID: &testCategory1.ID})).Return(&types.ArticleList{
+		Items: []*types.Article{
+			{ID: 2, Name: "Jelly Beans", Price: types.NewMoney(299, "USD"), CategoryIDs: []int{2}, Tags: []string{"vegan"}},
+		},
+	}).Times(2)
+
+	dbClient.EXPECT().GetArticles(gomock.Eq(0), gomock.Eq(&types.ArticleFilter{Tag: "vegan"})).Return(&types.ArticleList{
+		Items: []*types.Article{
+			{ID: 2, Name: "Jelly Beans", Price: types.NewMoney(299, "USD"), CategoryIDs: []int{2}, Tags: []string{"vegan"}},
+		},
+	})
+
+	dbClient.EXPECT().SearchArticles(gomock.Eq("skit"), gomock.Eq(0)).Return(&types.ArticleSearchResultList{
+		Items: []*types.ArticleSearchResult{
+			{Article: &testArticle1, Rank: 1.5, Highlight: "<mark>Skittles</mark>"},
+		},
+	})
+
 	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&testArticle1).AnyTimes()
 
 	dbClient.EXPECT().SetArticle(gomock.Any()).DoAndReturn(func(article *types.Article) error {
@@ -99,11 +440,238 @@ func getDBClientMock(t *testing.T) *mocks.MockClientInterface {
 		return nil
 	}).AnyTimes()
 
+	dbClient.EXPECT().GetArticlesByIDs(gomock.Eq([]int{1, 2})).Return([]*types.Article{
+		&testArticle1,
+		&testArticle2,
+	})
+
+	dbClient.EXPECT().GetArticlesByIDs(gomock.Eq([]int{2, 1})).Return([]*types.Article{
+		&testArticle1,
+		&testArticle2,
+	}).AnyTimes()
+
+	dbClient.EXPECT().SetArticles(gomock.Any()).DoAndReturn(func(articles []*types.Article) error {
+		for i, article := range articles {
+			if article.ID == 0 {
+				article.ID = i + 1
+			}
+		}
+		return nil
+	}).AnyTimes()
+
+	dbClient.EXPECT().GetOrderByID(gomock.Eq(1)).Return(&testOrder1).AnyTimes()
+
+	// expanded resources are loaded fresh, because expanding them changes them
+	dbClient.EXPECT().Select(gomock.Any()).Return(dbClient).AnyTimes()
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(5)).DoAndReturn(func(id int) *types.Article {
+		return &types.Article{ID: 5, Name: "Licorice", CategoryIDs: []int{1, 3}}
+	}).AnyTimes()
+	dbClient.EXPECT().GetOrderByID(gomock.Eq(4)).DoAndReturn(func(id int) *types.Order {
+		return &types.Order{ID: 4, CustomerID: &testCustomerID, Items: []*types.OrderItem{{ID: 6, ArticleID: 2, Quantity: 1}},
+			Total: types.NewMoney(299, "USD")}
+	}).AnyTimes()
+	dbClient.EXPECT().GetArticlesByIDs(gomock.Eq([]int{2})).DoAndReturn(func(ids []int) []*types.Article {
+		return []*types.Article{{ID: 2, Name: "Jelly Beans", Price: types.NewMoney(299, "USD")}}
+	}).AnyTimes()
+
+	dbClient.EXPECT().GetOrdersByIDs(gomock.Eq([]int{3})).Return([]*types.Order{
+		{ID: 3, Items: []*types.OrderItem{{ID: 5, ArticleID: 1, Quantity: 2, UnitPrice: types.NewMoney(199, "USD")}}},
+	}).AnyTimes()
+
+	dbClient.EXPECT().SetOrders(gomock.Any()).DoAndReturn(func(orders []*types.Order) error {
+		for _, order := range orders {
+			for _, item := range order.Items {
+				if item.Quantity > 10 {
+					return &types.InsufficientStockError{ArticleID: item.ArticleID, Requested: item.Quantity, Available: 10}
+				}
+			}
+		}
+		return nil
+	}).AnyTimes()
+
+	dbClient.EXPECT().StreamArticles(gomock.Eq(0), gomock.Eq(&types.ArticleFilter{}), gomock.Any()).DoAndReturn(func(pageID int, filter *types.ArticleFilter, fn func(article *types.Article) error) error {
+		// the shared articles get links when other responses render them, streamed articles come without
+		for _, article := range []types.Article{testArticle1, testArticle2} {
+			article.Links = nil
+			if err := fn(&article); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).AnyTimes()
+
+	dbClient.EXPECT().StreamArticles(gomock.Eq(0), gomock.Eq(&types.ArticleFilter{Tag: "vegan"}), gomock.Any()).DoAndReturn(func(pageID int, filter *types.ArticleFilter, fn func(article *types.Article) error) error {
+		return fn(&types.Article{ID: 2, Name: "Jelly Beans", Price: types.NewMoney(299, "USD")})
+	}).AnyTimes()
+
+	dbClient.EXPECT().DeleteArticle(gomock.Eq(1)).Return(nil).AnyTimes()
+
+	dbClient.EXPECT().StreamOrders(gomock.Eq(0), gomock.Eq(&types.OrderFilter{}), gomock.Any()).DoAndReturn(func(pageID int, filter *types.OrderFilter, fn func(order *types.Order) error) error {
+		orders := []*types.Order{
+			{ID: 1, Items: []*types.OrderItem{{ID: 1, ArticleID: 1, Quantity: 2}}, Total: types.NewMoney(398, "USD")},
+			{ID: 2, Items: []*types.OrderItem{{ID: 2, ArticleID: 2, Quantity: 1}}, Total: types.NewMoney(299, "USD")},
+		}
+		for _, order := range orders {
+			if err := fn(order); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).AnyTimes()
+
+	customerID := 1
+	dbClient.EXPECT().StreamOrders(gomock.Eq(0), gomock.Eq(&types.OrderFilter{CustomerID: &customerID}), gomock.Any()).DoAndReturn(func(pageID int, filter *types.OrderFilter, fn func(order *types.Order) error) error {
+		return fn(&types.Order{ID: 1, CustomerID: &customerID, Total: types.NewMoney(398, "USD")})
+	}).AnyTimes()
+
+	dbClient.EXPECT().SetOrder(gomock.Any()).DoAndReturn(func(order *types.Order) error {
+		for _, item := range order.Items {
+			if item.Quantity > 10 {
+				return &types.InsufficientStockError{ArticleID: item.ArticleID, Requested: item.Quantity, Available: 10}
+			}
+		}
+		return nil
+	}).AnyTimes()
+
+	dbClient.EXPECT().AdjustStock(gomock.Any()).DoAndReturn(func(adjustment *types.StockAdjustment) error {
+		return &types.InsufficientStockError{ArticleID: adjustment.ArticleID, Requested: -adjustment.Delta, Available: 0}
+	}).AnyTimes()
+
+	priceChange := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	dbClient.EXPECT().GetArticlePrices(gomock.Eq(1), gomock.Eq(0)).Return(&types.ArticlePriceList{
+		Items: []*types.ArticlePrice{
+			{ID: 2, ArticleID: 1, Price: types.NewMoney(199, "USD"), EffectiveFrom: priceChange, Applied: true},
+			{ID: 1, ArticleID: 1, Price: types.NewMoney(149, "USD"), EffectiveFrom: priceChange.AddDate(0, -1, 0), EffectiveTo: &priceChange, Applied: true},
+		},
+	}).AnyTimes()
+
+	dbClient.EXPECT().SchedulePrice(gomock.Any()).DoAndReturn(func(price *types.ArticlePrice) error {
+		price.ID = 3
+		return nil
+	}).AnyTimes()
+
+	dbClient.EXPECT().GetCustomerByID(gomock.Eq(1)).Return(&testCustomer1).AnyTimes()
+	dbClient.EXPECT().GetCustomerByID(gomock.Eq(2)).Return(nil).AnyTimes()
+	dbClient.EXPECT().GetCustomers(gomock.Eq(0)).Return(&types.CustomerList{Items: []*types.Customer{&testCustomer1}}).AnyTimes()
+	dbClient.EXPECT().GetCustomerOrders(gomock.Eq(1), gomock.Eq(0)).Return(&types.OrderList{Items: []*types.Order{&testOrder1}}).AnyTimes()
+	dbClient.EXPECT().DeleteCustomer(gomock.Eq(1)).Return(types.ErrCustomerHasOrders).AnyTimes()
+
+	dbClient.EXPECT().SetCustomer(gomock.Any()).DoAndReturn(func(customer *types.Customer) error {
+		if customer.Email == testCustomer1.Email && customer.ID != testCustomer1.ID {
+			return types.ErrEmailTaken
+		}
+		customer.ID = 2
+		return nil
+	}).AnyTimes()
+
+	dbClient.EXPECT().QuoteOrder(gomock.Any()).DoAndReturn(func(order *types.Order) (*types.Quote, error) {
+		if order.CouponCode != "" && order.CouponCode != testCoupon1.Code {
+			return nil, &types.CouponError{Code: order.CouponCode, Reason: "it doesn't exist"}
+		}
+		return &types.Quote{
+			Items: []*types.QuoteItem{{
+				ArticleID: 1,
+				Quantity:  2,
+				UnitPrice: types.NewMoney(199, "USD"),
+				Subtotal:  types.NewMoney(398, "USD"),
+				Discount:  types.NewMoney(40, "USD"),
+				TaxRate:   "7.25",
+				Tax:       types.NewMoney(26, "USD"),
+				Total:     types.NewMoney(384, "USD"),
+			}},
+			Region:     order.Region,
+			CouponCode: order.CouponCode,
+			Subtotal:   types.NewMoney(398, "USD"),
+			Discount:   types.NewMoney(40, "USD"),
+			Tax:        types.NewMoney(26, "USD"),
+			Total:      types.NewMoney(384, "USD"),
+		}, nil
+	}).AnyTimes()
+
+	dbClient.EXPECT().GetCouponByID(gomock.Eq(1)).Return(&testCoupon1).AnyTimes()
+	dbClient.EXPECT().GetCoupons(gomock.Eq(0)).Return(&types.CouponList{Items: []*types.Coupon{&testCoupon1}}).AnyTimes()
+	dbClient.EXPECT().DeleteCoupon(gomock.Eq(1)).Return(nil).AnyTimes()
+	dbClient.EXPECT().SetCoupon(gomock.Any()).DoAndReturn(func(coupon *types.Coupon) error {
+		if coupon.Code == testCoupon1.Code && coupon.ID != testCoupon1.ID {
+			return types.ErrCouponCodeTaken
+		}
+		coupon.ID = 2
+		return nil
+	}).AnyTimes()
+
+	dbClient.EXPECT().GetArticleImages(gomock.Eq(1)).Return([]*types.ArticleImage{&testImage1}).AnyTimes()
+	dbClient.EXPECT().GetArticleImageByID(gomock.Eq(1)).Return(&testImage1).AnyTimes()
+	dbClient.EXPECT().GetArticleImageByID(gomock.Eq(2)).Return(&types.ArticleImage{ID: 2, ArticleID: 2}).AnyTimes()
+	dbClient.EXPECT().DeleteArticleImage(gomock.Eq(1), gomock.Eq(1)).Return(nil).AnyTimes()
+	dbClient.EXPECT().AddArticleImage(gomock.Any()).DoAndReturn(func(image *types.ArticleImage) error {
+		image.ID = 3
+		image.SetURLs()
+		return nil
+	}).AnyTimes()
+
+	dbClient.EXPECT().GetVariants(gomock.Eq(1)).Return([]*types.Variant{&testVariant1}).AnyTimes()
+	dbClient.EXPECT().GetVariantByID(gomock.Eq(1)).Return(&testVariant1).AnyTimes()
+	dbClient.EXPECT().GetVariantByID(gomock.Eq(2)).Return(&types.Variant{ID: 2, ArticleID: 2, SKU: "JELLY-BEANS-XL"}).AnyTimes()
+	dbClient.EXPECT().DeleteVariant(gomock.Eq(1), gomock.Eq(1)).Return(nil).AnyTimes()
+	dbClient.EXPECT().SetVariant(gomock.Any()).DoAndReturn(func(variant *types.Variant) error {
+		if variant.SKU == testVariant1.SKU && variant.ID != testVariant1.ID {
+			return types.ErrSKUTaken
+		}
+		variant.ID = 3
+		return nil
+	}).AnyTimes()
+	dbClient.EXPECT().GetVariantBySKU(gomock.Any()).DoAndReturn(func(sku string) *types.Variant {
+		if types.NormalizeSKU(sku) != testVariant1.SKU {
+			return nil
+		}
+		variant := testVariant1
+		variant.Article = &testArticle1
+		return &variant
+	}).AnyTimes()
+
+	dbClient.EXPECT().GetCategoryByID(gomock.Eq(1)).Return(&testCategory1).AnyTimes()
+	dbClient.EXPECT().GetCategoryByID(gomock.Eq(3)).Return(nil).AnyTimes()
+	dbClient.EXPECT().GetCategories(gomock.Eq(0)).Return(&types.CategoryList{Items: []*types.Category{&testCategory1, &testCategory2}}).AnyTimes()
+	dbClient.EXPECT().DeleteCategory(gomock.Eq(1)).Return(types.ErrCategoryHasChildren).AnyTimes()
+	dbClient.EXPECT().SetCategory(gomock.Any()).DoAndReturn(func(category *types.Category) error {
+		if category.ParentID != nil && *category.ParentID != testCategory1.ID {
+			return fmt.Errorf("parent category %d doesn't exist", *category.ParentID)
+		}
+		category.ID = 3
+		return nil
+	}).AnyTimes()
+
+	dbClient.EXPECT().GetTagByID(gomock.Eq(1)).Return(&testTag1).AnyTimes()
+	dbClient.EXPECT().GetTags(gomock.Eq(0)).Return(&types.TagList{Items: []*types.Tag{&testTag1}}).AnyTimes()
+	dbClient.EXPECT().DeleteTag(gomock.Eq(1)).Return(nil).AnyTimes()
+	dbClient.EXPECT().SetTag(gomock.Any()).DoAndReturn(func(tag *types.Tag) error {
+		if tag.Name == testTag1.Name && tag.ID != testTag1.ID {
+			return types.ErrTagTaken
+		}
+		tag.ID = 2
+		return nil
+	}).AnyTimes()
+
+	dbClient.EXPECT().SetWebhook(gomock.Any()).DoAndReturn(func(webhook *types.Webhook) error {
+		if webhook.ID == 0 {
+			webhook.ID = 1
+		}
+		return nil
+	}).AnyTimes()
+
+	dbClient.EXPECT().GetAuditEntries(gomock.Eq(0), gomock.Eq(&types.AuditFilter{})).
+		Return(&types.AuditEntryList{Items: []*types.AuditEntry{&testAuditEntry1}}).AnyTimes()
+	dbClient.EXPECT().GetAuditEntries(gomock.Eq(0), gomock.Eq(&types.AuditFilter{Resource: "article", ResourceID: 1, Actor: "backoffice"})).
+		Return(&types.AuditEntryList{Items: []*types.AuditEntry{&testAuditEntry1}}).AnyTimes()
+	dbClient.EXPECT().GetAuditEntries(gomock.Eq(0), gomock.Any()).Return(&types.AuditEntryList{Items: []*types.AuditEntry{}}).AnyTimes()
+
 	return dbClient
 }
 
 // TestEndpoints ensures the expected results upon requests
 func TestEndpoints(t *testing.T) {
+	m.SetTokens(map[string]string{"s3cr3t": "backoffice"})
+	defer m.SetTokens(nil)
 	r := GetRouter(nil, getDBClientMock(t))
 	ts := httptest.NewServer(r)
 	defer ts.Close()
@@ -120,48 +688,1153 @@ func TestEndpoints(t *testing.T) {
 			method:   http.MethodGet,
 			path:     "/articles",
 			wantCode: http.StatusOK,
-			wantBody: `{"items":[{"id":1,"name":"Skittles","price":1.99},{"id":2,"name":"Jelly Beans","price":2.99}]}`,
+			wantBody: `{"items":[{"id":1,"name":"Skittles","price":{"amount":"1.99","currency":"USD"},` + articleLinks(1) + `},{"id":2,"name":"Jelly Beans","price":{"amount":"2.99","currency":"USD"},` + articleLinks(2) + `}],"_links":{"self":{"href":"http://api.example.com/articles"}}}`,
 		},
 		"GET /articles?page_id=1": {
 			method:   http.MethodGet,
 			path:     "/articles?page_id=1",
 			wantCode: http.StatusOK,
-			wantBody: `{"items":[{"id":2,"name":"Jelly Beans","price":2.99}]}`,
+			wantBody: `{"items":[{"id":2,"name":"Jelly Beans","price":{"amount":"2.99","currency":"USD"},` + articleLinks(2) + `}],"_links":{"prev":{"href":"http://api.example.com/articles"},"self":{"href":"http://api.example.com/articles?page_id=1"}}}`,
+		},
+		"GET /articles?ids=2,1 as JSON:API": {
+			method: http.MethodGet,
+			path:   "/articles?ids=2,1&fields=name",
+			header: map[string][]string{
+				"Accept": {"application/vnd.api+json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"data":[{"type":"articles","id":"1","attributes":{"name":"Skittles"},"relationships":{` +
+				`"images":{"links":{"related":"http://api.example.com/articles/1/images"}},"prices":{"links":{"related":"http://api.example.com/articles/1/prices"}},` +
+				`"stock":{"links":{"related":"http://api.example.com/articles/1/stock"}},"variants":{"links":{"related":"http://api.example.com/articles/1/variants"}}},` +
+				`"links":{"self":"http://api.example.com/articles/1"}},{"type":"articles","id":"2","attributes":{"name":"Jelly Beans"},"relationships":{` +
+				`"images":{"links":{"related":"http://api.example.com/articles/2/images"}},"prices":{"links":{"related":"http://api.example.com/articles/2/prices"}},` +
+				`"stock":{"links":{"related":"http://api.example.com/articles/2/stock"}},"variants":{"links":{"related":"http://api.example.com/articles/2/variants"}}},` +
+				`"links":{"self":"http://api.example.com/articles/2"}}],"links":{"self":"http://api.example.com/articles?ids=2,1\u0026fields=name"}}`,
+		},
+		"GET /orders?ids=3 as JSON:API": {
+			method: http.MethodGet,
+			path:   "/orders?ids=3&fields=total",
+			header: map[string][]string{
+				"Accept": {"application/vnd.api+json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"data":[{"type":"orders","id":"3","attributes":{"total":null},"links":{"self":"http://api.example.com/orders/3"}}],` +
+				`"links":{"self":"http://api.example.com/orders?ids=3\u0026fields=total"}}`,
 		},
 		"GET /articles/{id}": {
 			method:   http.MethodGet,
 			path:     "/articles/1",
 			wantCode: http.StatusOK,
-			wantBody: `{"id":1,"name":"Skittles","price":1.99}`,
+			wantBody: `{"id":1,"name":"Skittles","price":{"amount":"1.99","currency":"USD"},` + articleLinks(1) + `}`,
 		},
-		"PUT /articles": {
-			method: http.MethodPut,
-			path:   "/articles",
-			body:   `{"name":"Skittles","price":1.99}`,
+		"GET /articles?low_stock=5": {
+			method:   http.MethodGet,
+			path:     "/articles?low_stock=5",
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"id":2,"name":"Jelly Beans","price":{"amount":"2.99","currency":"USD"},` + articleLinks(2) + `}],"_links":{"self":{"href":"http://api.example.com/articles?low_stock=5"}}}`,
+		},
+		"GET /articles?low_stock=few": {
+			method:   http.MethodGet,
+			path:     "/articles?low_stock=few",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"couldn't read low_stock: strconv.Atoi: parsing \"few\": invalid syntax"}`,
+		},
+		"GET /articles/{id} as xml": {
+			method: http.MethodGet,
+			path:   "/articles/1",
 			header: map[string][]string{
-				"Content-Type": {"application/json"},
+				"Accept": {"text/html;q=0.9, application/xml"},
 			},
 			wantCode: http.StatusOK,
-			wantBody: `{"id":1,"name":"Skittles","price":1.99}`,
+			wantBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<article><id>1</id><name>Skittles</name><price><amount>1.99</amount><currency>USD</currency></price>` + articleXMLLinks(1) + `</article>`,
 		},
-		"Page Not Found": {
-			method:   http.MethodGet,
-			path:     "/blah",
-			wantCode: http.StatusNotFound,
-			wantBody: "404 page not found",
+		"GET /articles/{id} preferring json": {
+			method: http.MethodGet,
+			path:   "/articles/1",
+			header: map[string][]string{
+				"Accept": {"application/xml;q=0.5, */*"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":1,"name":"Skittles","price":{"amount":"1.99","currency":"USD"},` + articleLinks(1) + `}`,
 		},
-	}
-
-	for name, test := range testcases {
-		t.Run(name, func(t *testing.T) {
-			body := bytes.NewReader([]byte(test.body))
-			gotResponse, gotBody := testRequest(t, ts, test.method, test.path, body, test.header)
-			assert.Equal(t, test.wantCode, gotResponse.StatusCode)
-			if test.wantBody != "" {
-				assert.Equal(t, test.wantBody, gotBody, "body did not match")
-			}
-		})
-	}
+		"GET /articles/{id} as csv": {
+			method: http.MethodGet,
+			path:   "/articles/1",
+			header: map[string][]string{
+				"Accept": {"text/csv"},
+			},
+			wantCode: http.StatusNotAcceptable,
+			wantBody: `{"status":"Not acceptable.","error":"none of the accepted media types is offered, use one of application/json, application/xml, application/msgpack"}`,
+		},
+		"GET /articles as csv": {
+			method: http.MethodGet,
+			path:   "/articles?ids=2,1",
+			header: map[string][]string{
+				"Accept": {"text/csv"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: "id,name,price,currency\n1,Skittles,1.99,USD\n2,Jelly Beans,2.99,USD",
+		},
+		"GET /articles as xml": {
+			method: http.MethodGet,
+			path:   "/articles?ids=2,1",
+			header: map[string][]string{
+				"Accept": {"application/xml"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<articleList><items><article><id>1</id><name>Skittles</name><price><amount>1.99</amount><currency>USD</currency></price>` + articleXMLLinks(1) + `</article><article><id>2</id><name>Jelly Beans</name><price><amount>2.99</amount><currency>USD</currency></price>` + articleXMLLinks(2) + `</article></items><links><link rel="self" href="http://api.example.com/articles?ids=2,1"></link></links></articleList>`,
+		},
+		"GET /articles/{id} with fields": {
+			method:   http.MethodGet,
+			path:     "/articles/1?fields=name",
+			wantCode: http.StatusOK,
+			wantBody: `{` + articleLinks(1) + `,"id":1,"name":"Skittles"}`,
+		},
+		"GET /articles/{id} with fields as xml": {
+			method: http.MethodGet,
+			path:   "/articles/1?fields=name",
+			header: map[string][]string{
+				"Accept": {"application/xml"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<article><id>1</id><name>Skittles</name>` + articleXMLLinks(1) + `</article>`,
+		},
+		"GET /articles/{id} with an unknown field": {
+			method:   http.MethodGet,
+			path:     "/articles/1?fields=name,colour",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"colour isn't a field, use one of id, name, price, tax_category, category_ids, tags, variants, images, categories"}`,
+		},
+		"GET /articles/{id} expanding categories": {
+			method:   http.MethodGet,
+			path:     "/articles/5?fields=name&expand=categories",
+			wantCode: http.StatusOK,
+			wantBody: `{` + articleLinks(5) + `,"categories":[{"id":1,"name":"Candy"}],"id":5,"name":"Licorice"}`,
+		},
+		"GET /articles with fields": {
+			method:   http.MethodGet,
+			path:     "/articles?ids=2,1&fields=price",
+			wantCode: http.StatusOK,
+			wantBody: `{"_links":{"self":{"href":"http://api.example.com/articles?ids=2,1\u0026fields=price"}},"items":[{` + articleLinks(1) + `,"id":1,"price":{"amount":"1.99","currency":"USD"}},{` + articleLinks(2) + `,"id":2,"price":{"amount":"2.99","currency":"USD"}}]}`,
+		},
+		"GET /articles with fields as xml": {
+			method: http.MethodGet,
+			path:   "/articles?ids=2,1&fields=name",
+			header: map[string][]string{
+				"Accept": {"application/xml"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<articleList><items><article><id>1</id><name>Skittles</name>` + articleXMLLinks(1) + `</article><article><id>2</id><name>Jelly Beans</name>` + articleXMLLinks(2) + `</article></items><links><link rel="self" href="http://api.example.com/articles?ids=2,1&amp;fields=name"></link></links></articleList>`,
+		},
+		"GET /orders/{id} expanding the customer and articles": {
+			method:   http.MethodGet,
+			path:     "/orders/4?fields=total&expand=customer,items.article",
+			wantCode: http.StatusOK,
+			wantBody: `{"_links":{"customer":{"href":"http://api.example.com/customers/1"},"self":{"href":"http://api.example.com/orders/4"}},"customer":{"id":1,"name":"Jane Doe","email":"jane@example.com","addresses":[{"id":1,"kind":"shipping","line1":"1 Main St","city":"San Francisco","country":"US"}]},"id":4,"items":[{"id":6,"article_id":2,"quantity":1,"unit_price":null,"article":{"id":2,"name":"Jelly Beans","price":{"amount":"2.99","currency":"USD"},` + articleLinks(2) + `},"_links":{"article":{"href":"http://api.example.com/articles/2"}}}],"total":{"amount":"2.99","currency":"USD"}}`,
+		},
+		"GET /orders/{id} expanding too deep": {
+			method:   http.MethodGet,
+			path:     "/orders/1?expand=items.article.categories",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"items.article.categories can't be expanded, relations can only be expanded 2 levels deep"}`,
+		},
+		"GET /orders expanding an unknown relation": {
+			method:   http.MethodGet,
+			path:     "/orders?expand=coupon",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"coupon can't be expanded, coupon isn't a relation"}`,
+		},
+		"GET /articles as csv with an error": {
+			method: http.MethodGet,
+			path:   "/articles?ids=one",
+			header: map[string][]string{
+				"Accept": {"text/csv"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"couldn't read ids: strconv.Atoi: parsing \"one\": invalid syntax"}`,
+		},
+		"GET /articles?ids=2,1": {
+			method:   http.MethodGet,
+			path:     "/articles?ids=2,1",
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"id":1,"name":"Skittles","price":{"amount":"1.99","currency":"USD"},` + articleLinks(1) + `},{"id":2,"name":"Jelly Beans","price":{"amount":"2.99","currency":"USD"},` + articleLinks(2) + `}],"_links":{"self":{"href":"http://api.example.com/articles?ids=2,1"}}}`,
+		},
+		"GET /articles?ids=one": {
+			method:   http.MethodGet,
+			path:     "/articles?ids=one",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"couldn't read ids: strconv.Atoi: parsing \"one\": invalid syntax"}`,
+		},
+		"GET /articles/search": {
+			method:   http.MethodGet,
+			path:     "/articles/search?q=skit",
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"article":{"id":1,"name":"Skittles","price":{"amount":"1.99","currency":"USD"},` + articleLinks(1) + `},"rank":1.5,"highlight":"\u003cmark\u003eSkittles\u003c/mark\u003e"}]}`,
+		},
+		"GET /articles/search without a query": {
+			method:   http.MethodGet,
+			path:     "/articles/search?q=%20-",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"q must contain at least one word"}`,
+		},
+		"GET /articles?category=1": {
+			method:   http.MethodGet,
+			path:     "/articles?category=1",
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"id":2,"name":"Jelly Beans","price":{"amount":"2.99","currency":"USD"},"category_ids":[2],"tags":["vegan"],` + articleLinks(2) + `}],"_links":{"self":{"href":"http://api.example.com/articles?category=1"}}}`,
+		},
+		"GET /articles?tag=Vegan": {
+			method:   http.MethodGet,
+			path:     "/articles?tag=Vegan",
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"id":2,"name":"Jelly Beans","price":{"amount":"2.99","currency":"USD"},"category_ids":[2],"tags":["vegan"],` + articleLinks(2) + `}],"_links":{"self":{"href":"http://api.example.com/articles?tag=Vegan"}}}`,
+		},
+		"PUT /articles with categories and tags": {
+			method: http.MethodPut,
+			path:   "/articles",
+			body:   `{"name":"Gummy Bears","price":"1.49","category_ids":[2],"tags":["Vegan"," sweet ","vegan"]}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":1,"name":"Gummy Bears","price":{"amount":"1.49","currency":"USD"},"category_ids":[2],"tags":["sweet","vegan"],` + articleLinks(1) + `}`,
+		},
+		"PUT /articles with a list": {
+			method: http.MethodPut,
+			path:   "/articles",
+			body:   ` [{"id":1,"name":"Skittles","price":"1.99"},{"name":"Gummy Bears","price":"1.49","tags":["Vegan"]}]`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `[{"id":1,"name":"Skittles","price":{"amount":"1.99","currency":"USD"},` + articleLinks(1) + `},{"id":2,"name":"Gummy Bears","price":{"amount":"1.49","currency":"USD"},"tags":["vegan"],` + articleLinks(2) + `}]`,
+		},
+		"PUT /articles as xml": {
+			method: http.MethodPut,
+			path:   "/articles",
+			body:   `<article><name>Gummy Bears</name><price>1.49</price><tag>Vegan</tag></article>`,
+			header: map[string][]string{
+				"Content-Type": {"text/xml; charset=utf-8"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":1,"name":"Gummy Bears","price":{"amount":"1.49","currency":"USD"},"tags":["vegan"],` + articleLinks(1) + `}`,
+		},
+		"PUT /articles with a list as xml": {
+			method: http.MethodPut,
+			path:   "/articles",
+			body:   `<articles><article><id>1</id><name>Skittles</name></article><article><name>Gummy Bears</name></article></articles>`,
+			header: map[string][]string{
+				"Content-Type": {"application/xml"},
+				"Accept":       {"application/xml"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<articles><article><id>1</id><name>Skittles</name>` + articleXMLLinks(1) + `</article><article><id>2</id><name>Gummy Bears</name>` + articleXMLLinks(2) + `</article></articles>`,
+		},
+		"PUT /articles as plain text": {
+			method: http.MethodPut,
+			path:   "/articles",
+			body:   `Gummy Bears`,
+			header: map[string][]string{
+				"Content-Type": {"text/plain"},
+			},
+			wantCode: http.StatusUnsupportedMediaType,
+			wantBody: `{"status":"Unsupported media type.","error":"the content type \"text/plain\" isn't one of application/json, application/xml, application/msgpack"}`,
+		},
+		"PUT /articles with an empty list": {
+			method: http.MethodPut,
+			path:   "/articles",
+			body:   `[]`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"the list must not be empty"}`,
+		},
+		"GET /articles/{id}/stock": {
+			method:   http.MethodGet,
+			path:     "/articles/1/stock",
+			wantCode: http.StatusOK,
+			wantBody: `{"article_id":1,"tracked":false,"reserved":0}`,
+		},
+		"POST /articles/{id}/stock/adjustments": {
+			method: http.MethodPost,
+			path:   "/articles/1/stock/adjustments",
+			body:   `{"delta":-5,"reason":"damage"}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusConflict,
+			wantBody: `{"status":"Conflict.","error":"insufficient stock of article 1: requested 5, available 0"}`,
+		},
+		"POST /articles/{id}/stock/adjustments with reservation": {
+			method: http.MethodPost,
+			path:   "/articles/1/stock/adjustments",
+			body:   `{"delta":5,"reason":"reservation"}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"reason must be one of restock, correction, damage or return"}`,
+		},
+		"GET /articles/{id}/prices": {
+			method:   http.MethodGet,
+			path:     "/articles/1/prices",
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"id":2,"article_id":1,"price":{"amount":"1.99","currency":"USD"},"effective_from":"2020-10-01T12:00:00Z","applied":true,"created_at":"0001-01-01T00:00:00Z"},{"id":1,"article_id":1,"price":{"amount":"1.49","currency":"USD"},"effective_from":"2020-09-01T12:00:00Z","effective_to":"2020-10-01T12:00:00Z","applied":true,"created_at":"0001-01-01T00:00:00Z"}]}`,
+		},
+		"POST /articles/{id}/prices": {
+			method: http.MethodPost,
+			path:   "/articles/1/prices",
+			body:   `{"price":{"amount":"2.49","currency":"USD"},"effective_from":"2100-01-01T00:00:00Z"}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":3,"article_id":1,"price":{"amount":"2.49","currency":"USD"},"effective_from":"2100-01-01T00:00:00Z","applied":false,"created_at":"0001-01-01T00:00:00Z"}`,
+		},
+		"POST /articles/{id}/prices in the past": {
+			method: http.MethodPost,
+			path:   "/articles/1/prices",
+			body:   `{"price":"2.49","effective_from":"2020-01-01T00:00:00Z"}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"effective_from must be in the future"}`,
+		},
+		"PUT /orders": {
+			method: http.MethodPut,
+			path:   "/orders",
+			body:   `{"items":[{"article_id":1,"quantity":2}]}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":0,"lastUpdated":"0001-01-01T00:00:00Z","items":[{"id":0,"article_id":1,"quantity":2,"unit_price":null,"_links":{"article":{"href":"http://api.example.com/articles/1"}}}],"subtotal":null,"discount":null,"tax":null,"total":null}`,
+		},
+		"PUT /orders with insufficient stock": {
+			method: http.MethodPut,
+			path:   "/orders",
+			body:   `{"items":[{"article_id":1,"quantity":20}]}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusConflict,
+			wantBody: `{"status":"Conflict.","error":"insufficient stock of article 1: requested 20, available 10"}`,
+		},
+		"PUT /orders with a list and insufficient stock": {
+			method: http.MethodPut,
+			path:   "/orders",
+			body:   `[{"items":[{"article_id":1,"quantity":2}]},{"items":[{"article_id":2,"quantity":20}]}]`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusConflict,
+			wantBody: `{"status":"Conflict.","error":"insufficient stock of article 2: requested 20, available 10"}`,
+		},
+		"GET /orders?ids=3": {
+			method:   http.MethodGet,
+			path:     "/orders?ids=3",
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"id":3,"lastUpdated":"0001-01-01T00:00:00Z","items":[{"id":5,"article_id":1,"quantity":2,"unit_price":{"amount":"1.99","currency":"USD"},"_links":{"article":{"href":"http://api.example.com/articles/1"}}}],"subtotal":null,"discount":null,"tax":null,"total":null,"_links":{"self":{"href":"http://api.example.com/orders/3"}}}],"_links":{"self":{"href":"http://api.example.com/orders?ids=3"}}}`,
+		},
+		"PUT /orders with invalid quantity": {
+			method: http.MethodPut,
+			path:   "/orders",
+			body:   `{"items":[{"article_id":1,"quantity":0}]}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"quantity of article 1 must be positive"}`,
+		},
+		"PUT /articles": {
+			method: http.MethodPut,
+			path:   "/articles",
+			body:   `{"name":"Skittles","price":1.99}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":1,"name":"Skittles","price":{"amount":"1.99","currency":"USD"},` + articleLinks(1) + `}`,
+		},
+		"GET /articles:export": {
+			method:   http.MethodGet,
+			path:     "/articles:export",
+			wantCode: http.StatusOK,
+			wantBody: `[{"id":1,"name":"Skittles","price":{"amount":"1.99","currency":"USD"}},{"id":2,"name":"Jelly Beans","price":{"amount":"2.99","currency":"USD"}}]`,
+		},
+		"GET /articles:export?format=csv": {
+			method:   http.MethodGet,
+			path:     "/articles:export?format=csv",
+			wantCode: http.StatusOK,
+			wantBody: "id,name,price,currency\n1,Skittles,1.99,USD\n2,Jelly Beans,2.99,USD",
+		},
+		"GET /articles:export with ndjson Accept header": {
+			method: http.MethodGet,
+			path:   "/articles:export",
+			header: map[string][]string{
+				"Accept": {"application/x-ndjson"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: "{\"id\":1,\"name\":\"Skittles\",\"price\":{\"amount\":\"1.99\",\"currency\":\"USD\"}}\n{\"id\":2,\"name\":\"Jelly Beans\",\"price\":{\"amount\":\"2.99\",\"currency\":\"USD\"}}",
+		},
+		"GET /articles:export?format=xls": {
+			method:   http.MethodGet,
+			path:     "/articles:export?format=xls",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"unsupported export format \"xls\""}`,
+		},
+		"GET /orders:export?format=csv": {
+			method:   http.MethodGet,
+			path:     "/orders:export?format=csv",
+			wantCode: http.StatusOK,
+			wantBody: "id,lastUpdated,total,currency\n1,0001-01-01T00:00:00Z,3.98,USD\n2,0001-01-01T00:00:00Z,2.99,USD",
+		},
+		"GET /orders:export with ndjson Accept header": {
+			method: http.MethodGet,
+			path:   "/orders:export",
+			header: map[string][]string{
+				"Accept": {"application/x-ndjson"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":1,"lastUpdated":"0001-01-01T00:00:00Z","items":[{"id":1,"article_id":1,"quantity":2,"unit_price":null}],"subtotal":null,"discount":null,"tax":null,"total":{"amount":"3.98","currency":"USD"}}` + "\n" +
+				`{"id":2,"lastUpdated":"0001-01-01T00:00:00Z","items":[{"id":2,"article_id":2,"quantity":1,"unit_price":null}],"subtotal":null,"discount":null,"tax":null,"total":{"amount":"2.99","currency":"USD"}}`,
+		},
+		"GET /articles:export?tag=vegan&format=csv": {
+			method:   http.MethodGet,
+			path:     "/articles:export?tag=vegan&format=csv",
+			wantCode: http.StatusOK,
+			wantBody: "id,name,price,currency\n2,Jelly Beans,2.99,USD",
+		},
+		"GET /articles:export?low_stock=few": {
+			method:   http.MethodGet,
+			path:     "/articles:export?low_stock=few",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"couldn't read low_stock: strconv.Atoi: parsing \"few\": invalid syntax"}`,
+		},
+		"GET /orders:export?customer=1&format=csv": {
+			method:   http.MethodGet,
+			path:     "/orders:export?customer=1&format=csv",
+			wantCode: http.StatusOK,
+			wantBody: "id,lastUpdated,total,currency\n1,0001-01-01T00:00:00Z,3.98,USD",
+		},
+		"GET /orders/stream?types=sold": {
+			method:   http.MethodGet,
+			path:     "/orders/stream?types=sold",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"unknown type \"sold\""}`,
+		},
+		"GET /orders/stream without broker": {
+			method:   http.MethodGet,
+			path:     "/orders/stream",
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: `{"status":"Service unavailable.","error":"no event broker configured"}`,
+		},
+		"GET /ws without token": {
+			method:   http.MethodGet,
+			path:     "/ws",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"status":"Unauthorized."}`,
+		},
+		"DELETE /articles/{id}": {
+			method:   http.MethodDelete,
+			path:     "/articles/1",
+			wantCode: http.StatusNoContent,
+		},
+		"POST /orders:quote": {
+			method: http.MethodPost,
+			path:   "/orders:quote",
+			body:   `{"items":[{"article_id":1,"quantity":2}],"region":"US-CA","coupon_code":"save10"}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"article_id":1,"quantity":2,"unit_price":{"amount":"1.99","currency":"USD"},"subtotal":{"amount":"3.98","currency":"USD"},"discount":{"amount":"0.40","currency":"USD"},"tax_rate":"7.25","tax":{"amount":"0.26","currency":"USD"},"total":{"amount":"3.84","currency":"USD"}}],"region":"US-CA","coupon_code":"SAVE10","subtotal":{"amount":"3.98","currency":"USD"},"discount":{"amount":"0.40","currency":"USD"},"tax":{"amount":"0.26","currency":"USD"},"total":{"amount":"3.84","currency":"USD"}}`,
+		},
+		"POST /orders:quote with an unknown coupon": {
+			method: http.MethodPost,
+			path:   "/orders:quote",
+			body:   `{"items":[{"article_id":1,"quantity":2}],"coupon_code":"free"}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"coupon FREE can't be applied: it doesn't exist"}`,
+		},
+		"GET /coupons/{id}": {
+			method:   http.MethodGet,
+			path:     "/coupons/1",
+			wantCode: http.StatusOK,
+			wantBody: `{"id":1,"code":"SAVE10","kind":"percentage","percent_off":10,"amount_off":null,"uses":0}`,
+		},
+		"PUT /coupons": {
+			method: http.MethodPut,
+			path:   "/coupons",
+			body:   `{"code":"five","kind":"fixed","amount_off":{"amount":"5.00","currency":"USD"},"max_uses":100}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":2,"code":"FIVE","kind":"fixed","amount_off":{"amount":"5.00","currency":"USD"},"max_uses":100,"uses":0}`,
+		},
+		"PUT /coupons with a taken code": {
+			method: http.MethodPut,
+			path:   "/coupons",
+			body:   `{"code":"save10","kind":"percentage","percent_off":20}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusConflict,
+			wantBody: `{"status":"Conflict.","error":"code is already used by another coupon"}`,
+		},
+		"DELETE /coupons/{id}": {
+			method:   http.MethodDelete,
+			path:     "/coupons/1",
+			wantCode: http.StatusNoContent,
+		},
+		"GET /categories": {
+			method:   http.MethodGet,
+			path:     "/categories",
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"id":1,"name":"Candy"},{"id":2,"name":"Chewy Candy","parent_id":1}]}`,
+		},
+		"GET /categories/{id}": {
+			method:   http.MethodGet,
+			path:     "/categories/1",
+			wantCode: http.StatusOK,
+			wantBody: `{"id":1,"name":"Candy"}`,
+		},
+		"GET /categories/{id} not found": {
+			method:   http.MethodGet,
+			path:     "/categories/3",
+			wantCode: http.StatusNotFound,
+			wantBody: `{"status":"Resource not found."}`,
+		},
+		"GET /categories/{id}/articles": {
+			method:   http.MethodGet,
+			path:     "/categories/1/articles",
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"id":2,"name":"Jelly Beans","price":{"amount":"2.99","currency":"USD"},"category_ids":[2],"tags":["vegan"],` + articleLinks(2) + `}],"_links":{"self":{"href":"http://api.example.com/categories/1/articles"}}}`,
+		},
+		"PUT /categories": {
+			method: http.MethodPut,
+			path:   "/categories",
+			body:   `{"name":" Hard Candy ","parent_id":1}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":3,"name":"Hard Candy","parent_id":1}`,
+		},
+		"PUT /categories with an unknown parent": {
+			method: http.MethodPut,
+			path:   "/categories",
+			body:   `{"name":"Hard Candy","parent_id":5}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"parent category 5 doesn't exist"}`,
+		},
+		"PUT /categories as its own parent": {
+			method: http.MethodPut,
+			path:   "/categories",
+			body:   `{"id":1,"name":"Candy","parent_id":1}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"a category can't be its own parent"}`,
+		},
+		"DELETE /categories/{id} with subcategories": {
+			method:   http.MethodDelete,
+			path:     "/categories/1",
+			wantCode: http.StatusConflict,
+			wantBody: `{"status":"Conflict.","error":"category still has subcategories"}`,
+		},
+		"GET /tags": {
+			method:   http.MethodGet,
+			path:     "/tags",
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"id":1,"name":"vegan"}]}`,
+		},
+		"GET /tags/{id}": {
+			method:   http.MethodGet,
+			path:     "/tags/1",
+			wantCode: http.StatusOK,
+			wantBody: `{"id":1,"name":"vegan"}`,
+		},
+		"PUT /tags": {
+			method: http.MethodPut,
+			path:   "/tags",
+			body:   `{"name":"Gluten Free"}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":2,"name":"gluten free"}`,
+		},
+		"PUT /tags with a taken name": {
+			method: http.MethodPut,
+			path:   "/tags",
+			body:   `{"name":"VEGAN"}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusConflict,
+			wantBody: `{"status":"Conflict.","error":"name is already used by another tag"}`,
+		},
+		"DELETE /tags/{id}": {
+			method:   http.MethodDelete,
+			path:     "/tags/1",
+			wantCode: http.StatusNoContent,
+		},
+		"GET /customers": {
+			method:   http.MethodGet,
+			path:     "/customers",
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"id":1,"name":"Jane Doe","email":"jane@example.com","addresses":[{"id":1,"kind":"shipping","line1":"1 Main St","city":"San Francisco","country":"US"}]}]}`,
+		},
+		"GET /articles/{id}/variants": {
+			method:   http.MethodGet,
+			path:     "/articles/1/variants",
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"id":1,"article_id":1,"sku":"SKITTLES-SOUR","attributes":{"flavour":"sour"},"price":null}]}`,
+		},
+		"PUT /articles/{id}/variants": {
+			method: http.MethodPut,
+			path:   "/articles/1/variants",
+			body:   `{"sku":" skittles-tropical ","attributes":{"flavour":"tropical"},"price":{"amount":"2.49","currency":"USD"}}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":3,"article_id":1,"sku":"SKITTLES-TROPICAL","attributes":{"flavour":"tropical"},"price":{"amount":"2.49","currency":"USD"}}`,
+		},
+		"PUT /articles/{id}/variants with a taken sku": {
+			method: http.MethodPut,
+			path:   "/articles/1/variants",
+			body:   `{"sku":"skittles-sour"}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusConflict,
+			wantBody: `{"status":"Conflict.","error":"sku is already used by another variant"}`,
+		},
+		"PUT /articles/{id}/variants with an invalid sku": {
+			method: http.MethodPut,
+			path:   "/articles/1/variants",
+			body:   `{"sku":"sour skittles"}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"sku must consist of letters, digits, dots, dashes and underscores"}`,
+		},
+		"GET /articles/{id}/variants/{variantID}": {
+			method:   http.MethodGet,
+			path:     "/articles/1/variants/1",
+			wantCode: http.StatusOK,
+			wantBody: `{"id":1,"article_id":1,"sku":"SKITTLES-SOUR","attributes":{"flavour":"sour"},"price":null}`,
+		},
+		"GET /articles/{id}/variants/{variantID} of another article": {
+			method:   http.MethodGet,
+			path:     "/articles/1/variants/2",
+			wantCode: http.StatusNotFound,
+			wantBody: `{"status":"Resource not found."}`,
+		},
+		"GET /articles/{id}/variants/{variantID}/stock": {
+			method:   http.MethodGet,
+			path:     "/articles/1/variants/1/stock",
+			wantCode: http.StatusOK,
+			wantBody: `{"article_id":1,"variant_id":1,"tracked":false,"reserved":0}`,
+		},
+		"DELETE /articles/{id}/variants/{variantID}": {
+			method:   http.MethodDelete,
+			path:     "/articles/1/variants/1",
+			wantCode: http.StatusNoContent,
+		},
+		"GET /skus/{sku}": {
+			method:   http.MethodGet,
+			path:     "/skus/skittles-sour",
+			wantCode: http.StatusOK,
+			wantBody: `{"id":1,"article_id":1,"sku":"SKITTLES-SOUR","attributes":{"flavour":"sour"},"price":null,"article":{"id":1,"name":"Skittles","price":{"amount":"1.99","currency":"USD"},` + articleLinks(1) + `}}`,
+		},
+		"GET /cache/stats without a cache": {
+			method:   http.MethodGet,
+			path:     "/cache/stats",
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: `{"status":"Service unavailable.","error":"caching isn't enabled"}`,
+		},
+		"GET /audit": {
+			method: http.MethodGet,
+			path:   "/audit",
+			header: map[string][]string{
+				"Authorization": {"Bearer s3cr3t"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"id":1,"resource":"article","resource_id":1,"action":"updated","actor":"backoffice","request_id":"host/abcdef-000001",` +
+				`"changes":{"price":{"before":{"amount":"1.49","currency":"USD"},"after":{"amount":"1.99","currency":"USD"}}},"created_at":"2020-10-01T12:00:00Z"}]}`,
+		},
+		"GET /audit without token": {
+			method:   http.MethodGet,
+			path:     "/audit",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"status":"Unauthorized."}`,
+		},
+		"GET /audit?resource=article&resource_id=1&actor=backoffice": {
+			method: http.MethodGet,
+			path:   "/audit?resource=article&resource_id=1&actor=backoffice",
+			header: map[string][]string{
+				"Authorization": {"Bearer s3cr3t"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"id":1,"resource":"article","resource_id":1,"action":"updated","actor":"backoffice","request_id":"host/abcdef-000001",` +
+				`"changes":{"price":{"before":{"amount":"1.49","currency":"USD"},"after":{"amount":"1.99","currency":"USD"}}},"created_at":"2020-10-01T12:00:00Z"}]}`,
+		},
+		"GET /audit?from=2020-10-02T00:00:00Z": {
+			method: http.MethodGet,
+			path:   "/audit?from=2020-10-02T00:00:00Z",
+			header: map[string][]string{
+				"Authorization": {"Bearer s3cr3t"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[]}`,
+		},
+		"GET /audit?to=yesterday": {
+			method: http.MethodGet,
+			path:   "/audit?to=yesterday",
+			header: map[string][]string{
+				"Authorization": {"Bearer s3cr3t"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"couldn't read to: parsing time \"yesterday\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"yesterday\" as \"2006\""}`,
+		},
+		"POST /batch": {
+			method: http.MethodPost,
+			path:   "/batch",
+			body:   `{"operations":[{"method":"put","path":"/tags","body":{"name":"Gluten Free"}},{"method":"GET","path":"/skus/unknown"},{"method":"GET","path":"/tags/1"}]}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"results":[{"status":200,"body":{"id":2,"name":"gluten free"}},{"status":404,"body":{"status":"Resource not found."}},` +
+				`{"status":200,"body":{"id":1,"name":"vegan"}}]}`,
+		},
+		"POST /batch atomic": {
+			method: http.MethodPost,
+			path:   "/batch",
+			body:   `{"atomic":true,"operations":[{"method":"PUT","path":"/tags","body":{"name":"Gluten Free"}},{"method":"GET","path":"/skus/unknown"},{"method":"GET","path":"/tags/1"}]}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"results":[{"status":200,"body":{"id":2,"name":"gluten free"}},{"status":404,"body":{"status":"Resource not found."}},` +
+				`{"status":424}],"rolled_back":true}`,
+		},
+		"POST /batch with a stream": {
+			method: http.MethodPost,
+			path:   "/batch",
+			body:   `{"operations":[{"method":"GET","path":"/orders/stream"}]}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"operation 0 can't request /orders/stream in a batch"}`,
+		},
+		"POST /batch with an export": {
+			method: http.MethodPost,
+			path:   "/batch",
+			body:   `{"operations":[{"method":"GET","path":"/articles:export"}]}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"operation 0 can't request /articles:export in a batch"}`,
+		},
+		"POST /batch with headers": {
+			method: http.MethodPost,
+			path:   "/batch",
+			body:   `{"operations":[{"method":"GET","path":"/tags/1","header":{"Accept":"application/xml"}}]}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"results":[{"status":200,"body":"\u003c?xml version=\"1.0\" encoding=\"UTF-8\"?\u003e\n\u003cTag\u003e\u003cID\u003e1\u003c/ID\u003e\u003cName\u003evegan\u003c/Name\u003e\u003c/Tag\u003e"}]}`,
+		},
+		"POST /batch with xml": {
+			method: http.MethodPost,
+			path:   "/batch",
+			body:   `{"operations":[{"method":"PUT","path":"/tags","header":{"Content-Type":"application/xml"},"body":{}}]}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"content type of operation 0 must be application/json"}`,
+		},
+		"GET /skus/{sku} not found": {
+			method:   http.MethodGet,
+			path:     "/skus/unknown",
+			wantCode: http.StatusNotFound,
+			wantBody: `{"status":"Resource not found."}`,
+		},
+		"GET /customers/{id}": {
+			method:   http.MethodGet,
+			path:     "/customers/1",
+			wantCode: http.StatusOK,
+			wantBody: `{"id":1,"name":"Jane Doe","email":"jane@example.com","addresses":[{"id":1,"kind":"shipping","line1":"1 Main St","city":"San Francisco","country":"US"}]}`,
+		},
+		"GET /customers/{id} not found": {
+			method:   http.MethodGet,
+			path:     "/customers/2",
+			wantCode: http.StatusNotFound,
+			wantBody: `{"status":"Resource not found."}`,
+		},
+		"GET /customers/{id}/orders": {
+			method:   http.MethodGet,
+			path:     "/customers/1/orders",
+			wantCode: http.StatusOK,
+			wantBody: `{"items":[{"id":1,"lastUpdated":"0001-01-01T00:00:00Z","customer_id":1,"items":[{"id":1,"article_id":1,"quantity":2,"unit_price":null,"_links":{"article":{"href":"http://api.example.com/articles/1"}}},{"id":2,"article_id":2,"quantity":1,"unit_price":null,"_links":{"article":{"href":"http://api.example.com/articles/2"}}}],"subtotal":null,"discount":null,"tax":null,"total":null,"_links":{"customer":{"href":"http://api.example.com/customers/1"},"self":{"href":"http://api.example.com/orders/1"}}}],"_links":{"self":{"href":"http://api.example.com/customers/1/orders"}}}`,
+		},
+		"PUT /customers": {
+			method: http.MethodPut,
+			path:   "/customers",
+			body:   `{"name":"John Doe","email":"John@Example.com","addresses":[{"kind":"billing","line1":"2 Main St","city":"Oakland","country":"us"}]}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":2,"name":"John Doe","email":"john@example.com","addresses":[{"id":0,"kind":"billing","line1":"2 Main St","city":"Oakland","country":"US"}]}`,
+		},
+		"PUT /customers with a taken email": {
+			method: http.MethodPut,
+			path:   "/customers",
+			body:   `{"name":"Jane Smith","email":"jane@example.com"}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusConflict,
+			wantBody: `{"status":"Conflict.","error":"email is already used by another customer"}`,
+		},
+		"PUT /customers with an invalid email": {
+			method: http.MethodPut,
+			path:   "/customers",
+			body:   `{"name":"Jane Smith","email":"jane"}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"email \"jane\" is invalid"}`,
+		},
+		"DELETE /customers/{id} with orders": {
+			method:   http.MethodDelete,
+			path:     "/customers/1",
+			wantCode: http.StatusConflict,
+			wantBody: `{"status":"Conflict.","error":"customer still has orders"}`,
+		},
+		"PUT /webhooks": {
+			method: http.MethodPut,
+			path:   "/webhooks",
+			body:   `{"url":"https://example.com/hooks","events":["article.created"],"secret":"s3cr3t"}`,
+			header: map[string][]string{
+				"Content-Type":  {"application/json"},
+				"Authorization": {"Bearer s3cr3t"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"id":1,"url":"https://example.com/hooks","events":["article.created"]}`,
+		},
+		"GET /webhooks without token": {
+			method:   http.MethodGet,
+			path:     "/webhooks",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"status":"Unauthorized."}`,
+		},
+		"PUT /webhooks with a private address": {
+			method: http.MethodPut,
+			path:   "/webhooks",
+			body:   `{"url":"http://169.254.169.254/latest/meta-data","events":["article.created"],"secret":"s3cr3t"}`,
+			header: map[string][]string{
+				"Content-Type":  {"application/json"},
+				"Authorization": {"Bearer s3cr3t"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"url must not point to a loopback, private or link-local address"}`,
+		},
+		"PUT /webhooks with unknown event": {
+			method: http.MethodPut,
+			path:   "/webhooks",
+			body:   `{"url":"https://example.com/hooks","events":["article.sold"],"secret":"s3cr3t"}`,
+			header: map[string][]string{
+				"Content-Type":  {"application/json"},
+				"Authorization": {"Bearer s3cr3t"},
+			},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"Invalid request.","error":"unknown event type \"article.sold\""}`,
+		},
+		"POST /graphql": {
+			method: http.MethodPost,
+			path:   "/graphql",
+			body:   `{"query":"{ order(id: 1) { id items { quantity article { name } } } }"}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"data":{"order":{"id":1,"items":[{"quantity":2,"article":{"name":"Skittles"}},{"quantity":1,"article":{"name":"Jelly Beans"}}]}}}`,
+		},
+		"POST /graphql with too many nodes": {
+			method: http.MethodPost,
+			path:   "/graphql",
+			body:   `{"query":"{ articles(first: 101) { edges { node { id } } } }"}`,
+			header: map[string][]string{
+				"Content-Type": {"application/json"},
+			},
+			wantCode: http.StatusOK,
+			wantBody: `{"data":{"articles":null},"errors":[{"message":"first must not exceed 100","locations":[{"line":1,"column":3}],"path":["articles"]}]}`,
+		},
+		"GET /graphql": {
+			method:   http.MethodGet,
+			path:     "/graphql?query=%7Barticle(id:1)%7Bid%20name%20price%7Bamount%20currency%7D%7D%7D",
+			wantCode: http.StatusOK,
+			wantBody: `{"data":{"article":{"id":1,"name":"Skittles","price":{"amount":"1.99","currency":"USD"}}}}`,
+		},
+		"Page Not Found": {
+			method:   http.MethodGet,
+			path:     "/blah",
+			wantCode: http.StatusNotFound,
+			wantBody: "404 page not found",
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			body := bytes.NewReader([]byte(test.body))
+			gotResponse, gotBody := testRequest(t, ts, test.method, test.path, body, test.header)
+			assert.Equal(t, test.wantCode, gotResponse.StatusCode)
+			if test.wantBody != "" {
+				assert.Equal(t, test.wantBody, gotBody, "body did not match")
+			}
+		})
+	}
+}
+
+// TestArticleImages ensures that images are uploaded to and downloaded from the blob storage
+func TestArticleImages(t *testing.T) {
+	r := GetRouter(nil, getDBClientMock(t))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	// without storage images can't be uploaded
+	SetStorage(nil)
+	body, header := multipartImage(t, []byte("GIF89a"))
+	gotResponse, _ := testRequest(t, ts, http.MethodPost, "/articles/1/images", body, header)
+	assert.Equal(t, http.StatusServiceUnavailable, gotResponse.StatusCode)
+
+	dir, err := ioutil.TempDir("", "images")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store := storage.NewLocal(dir)
+	SetStorage(store)
+	defer SetStorage(nil)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 400, 300))
+	encoded := &bytes.Buffer{}
+	assert.NoError(t, png.Encode(encoded, img))
+
+	body, header = multipartImage(t, encoded.Bytes())
+	gotResponse, gotBody := testRequest(t, ts, http.MethodPost, "/articles/1/images", body, header)
+	assert.Equal(t, http.StatusCreated, gotResponse.StatusCode)
+	assert.Equal(t, fmt.Sprintf(`{"id":3,"article_id":1,"content_type":"image/png","size":%d,"width":400,"height":300,`+
+		`"url":"/articles/1/images/3","thumbnail_url":"/articles/1/images/3/thumbnail","created_at":"0001-01-01T00:00:00Z"}`, encoded.Len()), gotBody)
+
+	body, header = multipartImage(t, []byte("just some text"))
+	gotResponse, gotBody = testRequest(t, ts, http.MethodPost, "/articles/1/images", body, header)
+	assert.Equal(t, http.StatusUnsupportedMediaType, gotResponse.StatusCode)
+	assert.Equal(t, `{"status":"Unsupported media type.","error":"only jpeg, png and gif images are supported"}`, gotBody)
+
+	body, header = multipartImage(t, make([]byte, MaxImageSize+1))
+	gotResponse, _ = testRequest(t, ts, http.MethodPost, "/articles/1/images", body, header)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, gotResponse.StatusCode)
+
+	assert.NoError(t, store.Put(context.Background(), testImage1.Key, []byte("\x89PNG"), testImage1.ContentType))
+	gotResponse, gotBody = testRequest(t, ts, http.MethodGet, "/articles/1/images/1", nil, nil)
+	assert.Equal(t, http.StatusOK, gotResponse.StatusCode)
+	assert.Equal(t, "image/png", gotResponse.Header.Get("Content-Type"))
+	assert.Equal(t, "\x89PNG", gotBody)
+
+	gotResponse, _ = testRequest(t, ts, http.MethodGet, "/articles/1/images/1/thumbnail", nil, nil)
+	assert.Equal(t, http.StatusNotFound, gotResponse.StatusCode)
+
+	// images of other articles aren't found
+	gotResponse, _ = testRequest(t, ts, http.MethodGet, "/articles/1/images/2", nil, nil)
+	assert.Equal(t, http.StatusNotFound, gotResponse.StatusCode)
+
+	gotResponse, gotBody = testRequest(t, ts, http.MethodGet, "/articles/1/images", nil, nil)
+	assert.Equal(t, http.StatusOK, gotResponse.StatusCode)
+	assert.Equal(t, `{"items":[{"id":1,"article_id":1,"content_type":"image/png","size":4,"width":1,"height":1,`+
+		`"url":"/articles/1/images/1","thumbnail_url":"/articles/1/images/1/thumbnail","created_at":"0001-01-01T00:00:00Z"}]}`, gotBody)
+
+	gotResponse, _ = testRequest(t, ts, http.MethodDelete, "/articles/1/images/1", nil, nil)
+	assert.Equal(t, http.StatusNoContent, gotResponse.StatusCode)
+	_, err = store.Get(context.Background(), testImage1.Key)
+	assert.Equal(t, storage.ErrNotFound, err)
+}
+
+// TestDatabaseUnavailable ensures that requests fail fast while the database is down
+func TestDatabaseUnavailable(t *testing.T) {
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	dbClient.EXPECT().Available().Return(9500*time.Millisecond, db.ErrUnavailable)
+	ts := httptest.NewServer(GetRouter(nil, dbClient))
+	defer ts.Close()
+
+	gotResponse, gotBody := testRequest(t, ts, http.MethodGet, "/articles/1", nil, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, gotResponse.StatusCode)
+	assert.Equal(t, "10", gotResponse.Header.Get("Retry-After"))
+	assert.Equal(t, `{"status":"Service unavailable.","error":"database is unavailable"}`, gotBody)
+}
+
+// TestAuditActor ensures that changes are recorded with the actor of the api token and the id of the request
+func TestAuditActor(t *testing.T) {
+	m.SetTokens(map[string]string{"s3cr3t": "backoffice"})
+	defer m.SetTokens(nil)
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	dbClient.EXPECT().Available().Return(time.Duration(0), nil).AnyTimes()
+	dbClient.EXPECT().Session(gomock.Any()).Return(dbClient).AnyTimes()
+	dbClient.EXPECT().LastWrite().Return(time.Time{}).AnyTimes()
+	dbClient.EXPECT().Audited(gomock.Eq("backoffice"), gomock.Not(gomock.Eq(""))).Return(dbClient)
+	dbClient.EXPECT().Audited(gomock.Eq(types.AuditAnonymousActor), gomock.Not(gomock.Eq(""))).Return(dbClient)
+	dbClient.EXPECT().SetTag(gomock.Any()).Return(nil).Times(2)
+	ts := httptest.NewServer(GetRouter(nil, dbClient))
+	defer ts.Close()
+
+	header := http.Header{"Content-Type": {"application/json"}, "Authorization": {"Bearer s3cr3t"}}
+	gotResponse, _ := testRequest(t, ts, http.MethodPut, "/tags", strings.NewReader(`{"name":"vegan"}`), header)
+	assert.Equal(t, http.StatusOK, gotResponse.StatusCode)
+
+	// requests with unknown tokens are anonymous
+	header.Set("Authorization", "Bearer unknown")
+	gotResponse, _ = testRequest(t, ts, http.MethodPut, "/tags", strings.NewReader(`{"name":"vegan"}`), header)
+	assert.Equal(t, http.StatusOK, gotResponse.StatusCode)
+}
+
+// TestReadYourWrites ensures that the time of the last write of a client is kept in a cookie and continues its session
+func TestReadYourWrites(t *testing.T) {
+	lastWrite := time.Unix(0, time.Now().Add(-time.Second).UnixNano())
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	dbClient.EXPECT().Available().Return(time.Duration(0), nil).AnyTimes()
+	dbClient.EXPECT().Audited(gomock.Any(), gomock.Any()).Return(dbClient).AnyTimes()
+	dbClient.EXPECT().Session(gomock.Eq(time.Time{})).Return(dbClient)
+	dbClient.EXPECT().SetTag(gomock.Any()).Return(nil)
+	dbClient.EXPECT().LastWrite().Return(lastWrite)
+	ts := httptest.NewServer(GetRouter(nil, dbClient))
+	defer ts.Close()
+
+	header := http.Header{"Content-Type": {"application/json"}}
+	gotResponse, _ := testRequest(t, ts, http.MethodPut, "/tags", strings.NewReader(`{"name":"vegan"}`), header)
+	assert.Equal(t, http.StatusOK, gotResponse.StatusCode)
+	cookies := gotResponse.Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, m.LastWriteCookie, cookies[0].Name)
+	assert.Equal(t, strconv.FormatInt(lastWrite.UnixNano(), 10), cookies[0].Value)
+
+	// reads of the client continue the session without setting the cookie again
+	dbClient.EXPECT().Session(gomock.Eq(lastWrite)).Return(dbClient)
+	dbClient.EXPECT().GetTags(gomock.Eq(0)).Return(&types.TagList{})
+	dbClient.EXPECT().LastWrite().Return(lastWrite)
+	header = http.Header{"Cookie": {cookies[0].String()}}
+	gotResponse, _ = testRequest(t, ts, http.MethodGet, "/tags", nil, header)
+	assert.Equal(t, http.StatusOK, gotResponse.StatusCode)
+	assert.Empty(t, gotResponse.Cookies())
+
+	// writes in the future are ignored
+	dbClient.EXPECT().Session(gomock.Eq(time.Time{})).Return(dbClient)
+	dbClient.EXPECT().GetTags(gomock.Eq(0)).Return(&types.TagList{})
+	dbClient.EXPECT().LastWrite().Return(time.Time{})
+	header = http.Header{"Cookie": {m.LastWriteCookie + "=" + strconv.FormatInt(time.Now().Add(time.Hour).UnixNano(), 10)}}
+	gotResponse, _ = testRequest(t, ts, http.MethodGet, "/tags", nil, header)
+	assert.Equal(t, http.StatusOK, gotResponse.StatusCode)
+}
+
+// TestForwarded ensures that links point to the host and scheme of the forwarding headers of trusted proxies only
+func TestForwarded(t *testing.T) {
+	ts := httptest.NewServer(GetRouter(nil, getDBClientMock(t)))
+	defer ts.Close()
+
+	header := http.Header{"Forwarded": {`for=192.0.2.60;proto=https;host="shop.example.com", for=198.51.100.17`}}
+	gotResponse, gotBody := testRequest(t, ts, http.MethodGet, "/articles?page_id=2", nil, header)
+	assert.Equal(t, http.StatusOK, gotResponse.StatusCode)
+	assert.Contains(t, gotBody, `"next":{"href":"http://api.example.com/articles?page_id=3"}`)
+	assert.NotContains(t, gotBody, "shop.example.com")
+
+	proxies, err := m.ParseTrustedProxies("127.0.0.1, ::1")
+	assert.NoError(t, err)
+	m.SetTrustedProxies(proxies)
+	defer m.SetTrustedProxies(nil)
+
+	gotResponse, gotBody = testRequest(t, ts, http.MethodGet, "/articles?page_id=2", nil, header)
+	assert.Equal(t, http.StatusOK, gotResponse.StatusCode)
+	assert.Equal(t, `{"items":[{"id":2,"name":"Jelly Beans","price":{"amount":"2.99","currency":"USD"},"_links":{`+
+		`"images":{"href":"https://shop.example.com/articles/2/images"},"prices":{"href":"https://shop.example.com/articles/2/prices"},`+
+		`"self":{"href":"https://shop.example.com/articles/2"},"stock":{"href":"https://shop.example.com/articles/2/stock"},`+
+		`"variants":{"href":"https://shop.example.com/articles/2/variants"}}}],"next_page_id":3,"prev_page_id":1,"_links":{`+
+		`"next":{"href":"https://shop.example.com/articles?page_id=3"},"prev":{"href":"https://shop.example.com/articles?page_id=1"},`+
+		`"self":{"href":"https://shop.example.com/articles?page_id=2"}}}`, gotBody)
+
+	header = http.Header{"X-Forwarded-Proto": {"https"}, "X-Forwarded-Host": {"shop.example.com"}}
+	gotResponse, gotBody = testRequest(t, ts, http.MethodGet, "/articles/1", nil, header)
+	assert.Equal(t, http.StatusOK, gotResponse.StatusCode)
+	assert.Contains(t, gotBody, `"self":{"href":"https://shop.example.com/articles/1"}`)
+
+	_, err = m.ParseTrustedProxies("10.0.0.0/33")
+	assert.Error(t, err)
+}
+
+// TestMessagePack ensures that MessagePack bodies are decoded and MessagePack responses are returned if accepted
+func TestMessagePack(t *testing.T) {
+	ts := httptest.NewServer(GetRouter(nil, getDBClientMock(t)))
+	defer ts.Close()
+
+	header := http.Header{"Accept": {"application/x-msgpack"}}
+	gotResponse, gotBody := testRequest(t, ts, http.MethodGet, "/articles/1", nil, header)
+	assert.Equal(t, http.StatusOK, gotResponse.StatusCode)
+	assert.Equal(t, "application/msgpack", gotResponse.Header.Get("Content-Type"))
+	article := &types.Article{}
+	assert.NoError(t, msgpack.Unmarshal([]byte(gotBody), article))
+	assert.Equal(t, &testArticle1, article)
+
+	body, err := msgpack.Marshal(map[string]interface{}{"name": "Gummy Bears", "price": "1.49"})
+	assert.NoError(t, err)
+	header = http.Header{"Content-Type": {"application/msgpack"}}
+	gotResponse, gotBody = testRequest(t, ts, http.MethodPut, "/articles", bytes.NewReader(body), header)
+	assert.Equal(t, http.StatusOK, gotResponse.StatusCode)
+	assert.Equal(t, `{"id":1,"name":"Gummy Bears","price":{"amount":"1.49","currency":"USD"},`+articleLinks(1)+`}`, gotBody)
+}
+
+// multipartImage returns a multipart form uploading the data as image
+func multipartImage(t *testing.T, data []byte) (io.Reader, http.Header) {
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	file, err := form.CreateFormFile("image", "image.png")
+	assert.NoError(t, err)
+	_, _ = file.Write(data)
+	assert.NoError(t, form.Close())
+	return body, http.Header{"Content-Type": {form.FormDataContentType()}}
+}
+
+// articleLinks returns the json links of the article with the id as rendered for requests to api.example.com
+func articleLinks(id int) string {
+	return fmt.Sprintf(`"_links":{"images":{"href":"http://api.example.com/articles/%[1]d/images"},`+
+		`"prices":{"href":"http://api.example.com/articles/%[1]d/prices"},"self":{"href":"http://api.example.com/articles/%[1]d"},`+
+		`"stock":{"href":"http://api.example.com/articles/%[1]d/stock"},"variants":{"href":"http://api.example.com/articles/%[1]d/variants"}}`, id)
+}
+
+// articleXMLLinks returns the xml links of the article with the id as rendered for requests to api.example.com
+func articleXMLLinks(id int) string {
+	return fmt.Sprintf(`<links><link rel="images" href="http://api.example.com/articles/%[1]d/images"></link>`+
+		`<link rel="prices" href="http://api.example.com/articles/%[1]d/prices"></link>`+
+		`<link rel="self" href="http://api.example.com/articles/%[1]d"></link>`+
+		`<link rel="stock" href="http://api.example.com/articles/%[1]d/stock"></link>`+
+		`<link rel="variants" href="http://api.example.com/articles/%[1]d/variants"></link></links>`, id)
 }
 
 // testRequest is a helper function to exectute the http request against the server
@@ -173,6 +1846,8 @@ func testRequest(t *testing.T, ts *httptest.Server, method, path string, body io
 		return nil, ""
 	}
 	req.Header = header
+	// links in responses don't depend on the port of the test server
+	req.Host = "api.example.com"
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	"github.com/jonnylangefeld/go-api/pkg/db"
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// auditedClient returns the database client recording the actor and the id of the request in the context in the
+// audit log of its writes
+func auditedClient(ctx context.Context) db.ClientInterface {
+	actor, ok := ctx.Value(m.ActorCtxKey).(string)
+	if !ok {
+		actor = types.AuditAnonymousActor
+	}
+	return m.GetDBClient(ctx).Audited(actor, middleware.GetReqID(ctx))
+}
+
+// ListAuditEntries returns the audit log
+// @Summary List the audit log
+// @Description Get the recorded changes of all resources, latest first. Changes are recorded with the actor of the
+// @Description api token of their request, anonymous for requests without a token and system for changes the api
+// @Description made on its own, such as applied scheduled prices.
+// @Tags Audit
+// @Security BearerAuth
+// @Produce json
+// @Param resource query string false "kind of the changed resource" Enums(article, article_image, article_price, variant, stock_adjustment, order, customer, coupon, category, tag, webhook)
+// @Param resource_id query int false "id of the changed resource"
+// @Param actor query string false "actor who requested the change"
+// @Param from query string false "earliest time of the change in RFC 3339 format"
+// @Param to query string false "time before the latest change in RFC 3339 format"
+// @Param page_id query string false "id of the page to be retrieved"
+// @Router /audit [get]
+// @Success 200 {object} types.AuditEntryList
+// @Failure 400 {object} types.ErrResponse
+// @Failure 401 {object} types.ErrResponse
+func ListAuditEntries(w http.ResponseWriter, r *http.Request) {
+	pageID := r.Context().Value(m.PageIDKey)
+	filter := r.Context().Value(m.AuditFilterKey)
+	if err := render.Render(w, r, m.GetDBClient(r.Context()).GetAuditEntries(pageID.(int), filter.(*types.AuditFilter))); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
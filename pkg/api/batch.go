@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+
+	"github.com/jonnylangefeld/go-api/pkg/db"
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// errOperationFailed rolls back the transaction of an atomic batch request
+var errOperationFailed = errors.New("operation failed")
+
+// batchRecorder records the response to an operation of a batch request
+type batchRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// Header implements the http.ResponseWriter interface
+func (b *batchRecorder) Header() http.Header {
+	return b.header
+}
+
+// Write implements the http.ResponseWriter interface
+func (b *batchRecorder) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+// WriteHeader implements the http.ResponseWriter interface
+func (b *batchRecorder) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+// result returns the recorded response. Bodies that aren't json are included as json string.
+func (b *batchRecorder) result() *types.BatchResult {
+	result := &types.BatchResult{Status: b.status}
+	if result.Status == 0 {
+		result.Status = http.StatusOK
+	}
+	body := bytes.TrimSpace(b.body.Bytes())
+	switch {
+	case len(body) == 0:
+	case json.Valid(body):
+		result.Body = body
+	default:
+		result.Body, _ = json.Marshal(string(body))
+	}
+	return result
+}
+
+// Batch returns the handler of batch requests, which dispatches their operations to the router
+// @Summary Run a batch of requests
+// @Description Batch runs the operations one after another as if they were sent as separate requests and returns their responses.
+// @Description Atomic batch requests run all operations in a single database transaction. If an operation fails, the changes
+// @Description of all operations are rolled back and the remaining operations aren't run, which is reported with status 424.
+// @Tags Batch
+// @Accept json
+// @Produce json
+// @Param batch body types.BatchRequest true "the operations"
+// @Router /batch [post]
+// @Success 200 {object} types.BatchResponse
+// @Failure 400 {object} types.ErrResponse
+// @Failure 503 {object} types.ErrResponse
+func Batch(router chi.Router) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		batch := &types.BatchRequest{}
+		if err := render.Bind(r, batch); err != nil {
+			_ = render.Render(w, r, types.ErrInvalidRequest(err))
+			return
+		}
+
+		response := &types.BatchResponse{Results: make([]*types.BatchResult, 0, len(batch.Operations))}
+		if !batch.Atomic {
+			for _, operation := range batch.Operations {
+				response.Results = append(response.Results, dispatch(r.Context(), router, r, operation))
+			}
+		} else {
+			err := m.GetDBClient(r.Context()).Transaction(func(client db.ClientInterface) error {
+				ctx := context.WithValue(r.Context(), m.DBClientCtxKey, client)
+				for _, operation := range batch.Operations {
+					result := dispatch(ctx, router, r, operation)
+					response.Results = append(response.Results, result)
+					if result.Status >= http.StatusBadRequest {
+						return errOperationFailed
+					}
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, errOperationFailed) {
+				_ = render.Render(w, r, types.ErrUnavailable(fmt.Errorf("couldn't run the batch in a transaction: %w", err)))
+				return
+			}
+			if err != nil {
+				response.RolledBack = true
+				for len(response.Results) < len(batch.Operations) {
+					response.Results = append(response.Results, &types.BatchResult{Status: http.StatusFailedDependency})
+				}
+			}
+		}
+
+		if err := render.Render(w, r, response); err != nil {
+			_ = render.Render(w, r, types.ErrRender(err))
+			return
+		}
+	}
+}
+
+// dispatch serves the operation with the router as a request with the context and returns the response.
+// The operation is authenticated like the batch request unless it has its own Authorization header and shares the
+// request id of the batch request.
+func dispatch(ctx context.Context, router chi.Router, batch *http.Request, operation *types.BatchOperation) *types.BatchResult {
+	// a new routing context, because the router would otherwise continue to route the batch request
+	rctx := chi.NewRouteContext()
+	rctx.Routes = router
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+
+	req, err := http.NewRequestWithContext(ctx, operation.Method, operation.Path, bytes.NewReader(operation.Body))
+	if err != nil {
+		body, _ := json.Marshal(types.ErrInvalidRequest(err))
+		return &types.BatchResult{Status: http.StatusBadRequest, Body: body}
+	}
+	req.RequestURI = operation.Path
+	req.RemoteAddr = batch.RemoteAddr
+	for name, value := range operation.Header {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set(middleware.RequestIDHeader, middleware.GetReqID(batch.Context()))
+	if authorization := batch.Header.Get("Authorization"); authorization != "" && req.Header.Get("Authorization") == "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	if len(operation.Body) > 0 && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	recorder := &batchRecorder{header: http.Header{}}
+	router.ServeHTTP(recorder, req)
+	return recorder.result()
+}
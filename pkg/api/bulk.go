@@ -0,0 +1,53 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// putArticles writes the list of articles in the body to the database with a single bulk upsert
+func putArticles(w http.ResponseWriter, r *http.Request) {
+	articles := types.Articles{}
+	if err := render.Bind(r, &articles); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := auditedClient(r.Context()).SetArticles(articles); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := render.Render(w, r, articles); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// putOrders writes the list of orders in the body to the database with a single bulk upsert per table
+func putOrders(w http.ResponseWriter, r *http.Request) {
+	orders := types.Orders{}
+	if err := render.Bind(r, &orders); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := auditedClient(r.Context()).SetOrders(orders); err != nil {
+		var stockErr *types.InsufficientStockError
+		if errors.As(err, &stockErr) {
+			_ = render.Render(w, r, types.ErrConflict(err))
+			return
+		}
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := render.Render(w, r, orders); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// GetCacheStats renders the statistics of the database cache
+// @Summary Get the statistics of the database cache
+// @Description GetCacheStats returns the hits and misses of the cache of articles and orders since the start of the api
+// @Tags Cache
+// @Produce json
+// @Router /cache/stats [get]
+// @Success 200 {object} types.CacheStats
+// @Failure 503 {object} types.ErrResponse
+func GetCacheStats(w http.ResponseWriter, r *http.Request) {
+	if DBCache == nil {
+		_ = render.Render(w, r, types.ErrUnavailable(errors.New("caching isn't enabled")))
+		return
+	}
+
+	if err := render.Render(w, r, DBCache.Stats()); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// GetCategory renders the category from the context
+// @Summary Get category by id
+// @Description GetCategory returns a single category by id
+// @Tags Categories
+// @Produce json
+// @Param id path string true "category id"
+// @Router /categories/{id} [get]
+// @Success 200 {object} types.Category
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func GetCategory(w http.ResponseWriter, r *http.Request) {
+	category := r.Context().Value(m.CategoryCtxKey).(*types.Category)
+
+	if err := render.Render(w, r, category); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// PutCategory writes a category to the database
+// @Summary Add a category to the database
+// @Description PutCategory writes a category to the database
+// @Description To write a new category, leave the id empty. To update an existing one, use the id of the category to be updated
+// @Description Categories form a tree, the parent must exist and must not be one of the subcategories of the category.
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param category body types.Category true "the category"
+// @Router /categories [put]
+// @Success 200 {object} types.Category
+// @Failure 400 {object} types.ErrResponse
+func PutCategory(w http.ResponseWriter, r *http.Request) {
+	category := &types.Category{}
+	if err := render.Bind(r, category); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := auditedClient(r.Context()).SetCategory(category); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := render.Render(w, r, category); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// DeleteCategory deletes the category from the context
+// @Summary Delete category by id
+// @Description DeleteCategory deletes a single category by id and removes it from its articles. Categories that still have subcategories can't be deleted.
+// @Tags Categories
+// @Param id path string true "category id"
+// @Router /categories/{id} [delete]
+// @Success 204
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+// @Failure 409 {object} types.ErrResponse
+func DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	category := r.Context().Value(m.CategoryCtxKey).(*types.Category)
+
+	if err := auditedClient(r.Context()).DeleteCategory(category.ID); err != nil {
+		if errors.Is(err, types.ErrCategoryHasChildren) {
+			_ = render.Render(w, r, types.ErrConflict(err))
+			return
+		}
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	render.NoContent(w, r)
+}
+
+// ListCategories returns all categories in the database
+// @Summary List all categories
+// @Description Get all categories stored in the database
+// @Tags Categories
+// @Produce json
+// @Param page_id query string false "id of the page to be retrieved"
+// @Router /categories [get]
+// @Success 200 {object} types.CategoryList
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func ListCategories(w http.ResponseWriter, r *http.Request) {
+	pageID := r.Context().Value(m.PageIDKey)
+	if err := render.Render(w, r, m.GetDBClient(r.Context()).GetCategories(pageID.(int))); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// ListCategoryArticles returns the articles of the category from the context
+// @Summary List the articles of a category
+// @Description Get all articles of a category including the articles of all of its subcategories
+// @Tags Categories
+// @Produce json
+// @Param id path string true "category id"
+// @Param page_id query string false "id of the page to be retrieved"
+// @Router /categories/{id}/articles [get]
+// @Success 200 {object} types.ArticleList
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func ListCategoryArticles(w http.ResponseWriter, r *http.Request) {
+	category := r.Context().Value(m.CategoryCtxKey).(*types.Category)
+	pageID := r.Context().Value(m.PageIDKey)
+	filter := &types.ArticleFilter{CategoryID: &category.ID}
+	if err := render.Render(w, r, m.GetDBClient(r.Context()).GetArticles(pageID.(int), filter)); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// GetTag renders the tag from the context
+// @Summary Get tag by id
+// @Description GetTag returns a single tag by id
+// @Tags Tags
+// @Produce json
+// @Param id path string true "tag id"
+// @Router /tags/{id} [get]
+// @Success 200 {object} types.Tag
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func GetTag(w http.ResponseWriter, r *http.Request) {
+	tag := r.Context().Value(m.TagCtxKey).(*types.Tag)
+
+	if err := render.Render(w, r, tag); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// PutTag writes a tag to the database
+// @Summary Add a tag to the database
+// @Description PutTag writes a tag to the database
+// @Description To write a new tag, leave the id empty. To rename an existing one, use the id of the tag to be renamed
+// @Description Names are stored in lower case and must not be used by another tag.
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Param tag body types.Tag true "the tag"
+// @Router /tags [put]
+// @Success 200 {object} types.Tag
+// @Failure 400 {object} types.ErrResponse
+// @Failure 409 {object} types.ErrResponse
+func PutTag(w http.ResponseWriter, r *http.Request) {
+	tag := &types.Tag{}
+	if err := render.Bind(r, tag); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := auditedClient(r.Context()).SetTag(tag); err != nil {
+		if errors.Is(err, types.ErrTagTaken) {
+			_ = render.Render(w, r, types.ErrConflict(err))
+			return
+		}
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := render.Render(w, r, tag); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// DeleteTag deletes the tag from the context
+// @Summary Delete tag by id
+// @Description DeleteTag deletes a single tag by id and removes it from its articles
+// @Tags Tags
+// @Param id path string true "tag id"
+// @Router /tags/{id} [delete]
+// @Success 204
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func DeleteTag(w http.ResponseWriter, r *http.Request) {
+	tag := r.Context().Value(m.TagCtxKey).(*types.Tag)
+
+	if err := auditedClient(r.Context()).DeleteTag(tag.ID); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	render.NoContent(w, r)
+}
+
+// ListTags returns all tags in the database
+// @Summary List all tags
+// @Description Get all tags stored in the database
+// @Tags Tags
+// @Produce json
+// @Param page_id query string false "id of the page to be retrieved"
+// @Router /tags [get]
+// @Success 200 {object} types.TagList
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func ListTags(w http.ResponseWriter, r *http.Request) {
+	pageID := r.Context().Value(m.PageIDKey)
+	if err := render.Render(w, r, m.GetDBClient(r.Context()).GetTags(pageID.(int))); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// GetCustomer renders the customer from the context
+// @Summary Get customer by id
+// @Description GetCustomer returns a single customer and its addresses by id
+// @Tags Customers
+// @Produce json
+// @Param id path string true "customer id"
+// @Router /customers/{id} [get]
+// @Success 200 {object} types.Customer
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func GetCustomer(w http.ResponseWriter, r *http.Request) {
+	customer := r.Context().Value(m.CustomerCtxKey).(*types.Customer)
+
+	if err := render.Render(w, r, customer); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// PutCustomer writes a customer to the database
+// @Summary Add a customer to the database
+// @Description PutCustomer writes a customer and its addresses to the database
+// @Description To write a new customer, leave the id empty. To update an existing one, use the id of the customer to be updated
+// @Description The addresses of an existing customer are replaced. The email must not be used by another customer.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param customer body types.Customer true "the customer"
+// @Router /customers [put]
+// @Success 200 {object} types.Customer
+// @Failure 400 {object} types.ErrResponse
+// @Failure 409 {object} types.ErrResponse
+func PutCustomer(w http.ResponseWriter, r *http.Request) {
+	customer := &types.Customer{}
+	if err := render.Bind(r, customer); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := auditedClient(r.Context()).SetCustomer(customer); err != nil {
+		if errors.Is(err, types.ErrEmailTaken) {
+			_ = render.Render(w, r, types.ErrConflict(err))
+			return
+		}
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := render.Render(w, r, customer); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// DeleteCustomer deletes the customer from the context
+// @Summary Delete customer by id
+// @Description DeleteCustomer deletes a single customer and its addresses by id. Customers that still own orders can't be deleted.
+// @Tags Customers
+// @Param id path string true "customer id"
+// @Router /customers/{id} [delete]
+// @Success 204
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+// @Failure 409 {object} types.ErrResponse
+func DeleteCustomer(w http.ResponseWriter, r *http.Request) {
+	customer := r.Context().Value(m.CustomerCtxKey).(*types.Customer)
+
+	if err := auditedClient(r.Context()).DeleteCustomer(customer.ID); err != nil {
+		if errors.Is(err, types.ErrCustomerHasOrders) {
+			_ = render.Render(w, r, types.ErrConflict(err))
+			return
+		}
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	render.NoContent(w, r)
+}
+
+// ListCustomers returns all customers in the database
+// @Summary List all customers
+// @Description Get all customers stored in the database
+// @Tags Customers
+// @Produce json
+// @Param page_id query string false "id of the page to be retrieved"
+// @Router /customers [get]
+// @Success 200 {object} types.CustomerList
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func ListCustomers(w http.ResponseWriter, r *http.Request) {
+	pageID := r.Context().Value(m.PageIDKey)
+	if err := render.Render(w, r, m.GetDBClient(r.Context()).GetCustomers(pageID.(int))); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// ListCustomerOrders returns the orders of the customer from the context
+// @Summary List the orders of a customer
+// @Description Get all orders owned by a customer
+// @Tags Customers
+// @Produce json
+// @Param id path string true "customer id"
+// @Param page_id query string false "id of the page to be retrieved"
+// @Router /customers/{id}/orders [get]
+// @Success 200 {object} types.OrderList
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func ListCustomerOrders(w http.ResponseWriter, r *http.Request) {
+	customer := r.Context().Value(m.CustomerCtxKey).(*types.Customer)
+	pageID := r.Context().Value(m.PageIDKey)
+	if err := render.Render(w, r, m.GetDBClient(r.Context()).GetCustomerOrders(customer.ID, pageID.(int))); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
@@ -0,0 +1,269 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/render"
+
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+const (
+	// exportFlushInterval is the number of records after which the response is flushed to the client
+	exportFlushInterval = 100
+)
+
+// csvRecorder is implemented by all types that can be exported
+type csvRecorder interface {
+	CSVHeader() []string
+	CSVRecord() []string
+}
+
+// exportWriter writes a stream of records to the response body
+type exportWriter interface {
+	Write(record csvRecorder) error
+	Close() error
+}
+
+// exportFormat describes one of the supported export formats
+type exportFormat struct {
+	contentType string
+	extension   string
+	newWriter   func(w io.Writer, header []string) exportWriter
+}
+
+var exportFormats = map[string]exportFormat{
+	"csv": {
+		contentType: "text/csv",
+		extension:   "csv",
+		newWriter:   newCSVExportWriter,
+	},
+	"ndjson": {
+		contentType: "application/x-ndjson",
+		extension:   "ndjson",
+		newWriter:   newNDJSONExportWriter,
+	},
+	"json": {
+		contentType: "application/json",
+		extension:   "json",
+		newWriter:   newJSONExportWriter,
+	},
+}
+
+// ExportArticles streams all articles in the requested format
+// @Summary Export all articles
+// @Description ExportArticles streams all articles straight from the database as csv, ndjson or a json array
+// @Description The format is taken from the format query parameter or, if that is empty, from the Accept header
+// @Tags Articles
+// @Produce json,text/csv,application/x-ndjson
+// @Param page_id query string false "id of the first article to be exported"
+// @Param low_stock query int false "only export articles with tracked stock of at most this quantity"
+// @Param category query int false "only export articles of this category or one of its subcategories"
+// @Param tag query string false "only export articles with this tag"
+// @Param format query string false "export format" Enums(csv, ndjson, json)
+// @Router /articles:export [get]
+// @Success 200 {array} types.Article
+// @Failure 400 {object} types.ErrResponse
+func ExportArticles(w http.ResponseWriter, r *http.Request) {
+	pageID := r.Context().Value(m.PageIDKey)
+	filter := r.Context().Value(m.ArticleFilterKey).(*types.ArticleFilter)
+	ew, ok := startExport(w, r, "articles", (&types.Article{}).CSVHeader())
+	if !ok {
+		return
+	}
+
+	err := m.GetDBClient(r.Context()).StreamArticles(pageID.(int), filter, func(article *types.Article) error {
+		return ew.Write(article)
+	})
+	finishExport(ew, err)
+}
+
+// ExportOrders streams all orders in the requested format
+// @Summary Export all orders
+// @Description ExportOrders streams all orders straight from the database as csv, ndjson or a json array
+// @Description The format is taken from the format query parameter or, if that is empty, from the Accept header
+// @Tags Orders
+// @Produce json,text/csv,application/x-ndjson
+// @Param page_id query string false "id of the first order to be exported"
+// @Param customer query int false "only export orders of this customer"
+// @Param format query string false "export format" Enums(csv, ndjson, json)
+// @Router /orders:export [get]
+// @Success 200 {array} types.Order
+// @Failure 400 {object} types.ErrResponse
+func ExportOrders(w http.ResponseWriter, r *http.Request) {
+	pageID := r.Context().Value(m.PageIDKey)
+	filter := r.Context().Value(m.OrderFilterKey).(*types.OrderFilter)
+	ew, ok := startExport(w, r, "orders", (&types.Order{}).CSVHeader())
+	if !ok {
+		return
+	}
+
+	err := m.GetDBClient(r.Context()).StreamOrders(pageID.(int), filter, func(order *types.Order) error {
+		return ew.Write(order)
+	})
+	finishExport(ew, err)
+}
+
+// startExport negotiates the export format and writes the response headers.
+// It returns false if the request was already answered with an error.
+func startExport(w http.ResponseWriter, r *http.Request, name string, header []string) (exportWriter, bool) {
+	format, err := negotiateExportFormat(r)
+	if err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return nil, false
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format.extension))
+	w.WriteHeader(http.StatusOK)
+
+	return format.newWriter(&flushWriter{w: w}, header), true
+}
+
+// finishExport closes the export writer. Since the status code has already been sent at this point,
+// a failed export aborts the connection so that clients don't mistake a partial export for a complete one.
+func finishExport(ew exportWriter, err error) {
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	if err := ew.Close(); err != nil {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// negotiateExportFormat picks the export format from the format query parameter or the Accept header
+func negotiateExportFormat(r *http.Request) (exportFormat, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		format, ok := exportFormats[name]
+		if !ok {
+			return exportFormat{}, fmt.Errorf("unsupported export format %q", name)
+		}
+		return format, nil
+	}
+
+	accept := r.Header.Get("Accept")
+	for _, name := range []string{"csv", "ndjson"} {
+		if strings.Contains(accept, exportFormats[name].contentType) {
+			return exportFormats[name], nil
+		}
+	}
+	return exportFormats["json"], nil
+}
+
+// flushWriter flushes the underlying response writer every exportFlushInterval writes
+// so that clients receive data continuously instead of after the whole export
+type flushWriter struct {
+	w     http.ResponseWriter
+	count int
+}
+
+// Write implements the io.Writer interface
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.count++
+	if f.count%exportFlushInterval == 0 {
+		if flusher, ok := f.w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+	return n, err
+}
+
+// csvExportWriter writes records as comma separated values with a header line
+type csvExportWriter struct {
+	w      *csv.Writer
+	header []string
+	begun  bool
+}
+
+func newCSVExportWriter(w io.Writer, header []string) exportWriter {
+	return &csvExportWriter{w: csv.NewWriter(w), header: header}
+}
+
+// Write implements the exportWriter interface
+func (c *csvExportWriter) Write(record csvRecorder) error {
+	if err := c.begin(); err != nil {
+		return err
+	}
+	return c.w.Write(record.CSVRecord())
+}
+
+// Close implements the exportWriter interface
+func (c *csvExportWriter) Close() error {
+	if err := c.begin(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvExportWriter) begin() error {
+	if c.begun {
+		return nil
+	}
+	c.begun = true
+	return c.w.Write(c.header)
+}
+
+// ndjsonExportWriter writes one json document per line
+type ndjsonExportWriter struct {
+	enc *json.Encoder
+}
+
+func newNDJSONExportWriter(w io.Writer, _ []string) exportWriter {
+	return &ndjsonExportWriter{enc: json.NewEncoder(w)}
+}
+
+// Write implements the exportWriter interface
+func (n *ndjsonExportWriter) Write(record csvRecorder) error {
+	return n.enc.Encode(record)
+}
+
+// Close implements the exportWriter interface
+func (n *ndjsonExportWriter) Close() error {
+	return nil
+}
+
+// jsonExportWriter writes all records as a single json array without buffering them
+type jsonExportWriter struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONExportWriter(w io.Writer, _ []string) exportWriter {
+	return &jsonExportWriter{w: w}
+}
+
+// Write implements the exportWriter interface
+func (j *jsonExportWriter) Write(record csvRecorder) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	prefix := ","
+	if j.count == 0 {
+		prefix = "["
+	}
+	j.count++
+	if _, err := io.WriteString(j.w, prefix); err != nil {
+		return err
+	}
+	_, err = j.w.Write(b)
+	return err
+}
+
+// Close implements the exportWriter interface
+func (j *jsonExportWriter) Close() error {
+	closing := "]"
+	if j.count == 0 {
+		closing = "[]"
+	}
+	_, err := io.WriteString(j.w, closing)
+	return err
+}
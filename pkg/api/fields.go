@@ -0,0 +1,200 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/jonnylangefeld/go-api/pkg/db"
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// selection returns the selection of the m.Select middleware, which is empty for routes without it
+func selection(r *http.Request) *types.Selection {
+	if s, ok := r.Context().Value(m.SelectionKey).(*types.Selection); ok {
+		return s
+	}
+	return &types.Selection{}
+}
+
+// expandArticles embeds the expanded relations into the articles. Related resources are loaded once, even if
+// several articles share them.
+func expandArticles(client db.ClientInterface, s *types.Selection, articles ...*types.Article) {
+	if s.Expands("categories") != nil {
+		categories := map[int]*types.Category{}
+		for _, article := range articles {
+			article.Categories = []*types.Category{}
+			for _, id := range article.CategoryIDs {
+				category, ok := categories[id]
+				if !ok {
+					category = client.GetCategoryByID(id)
+					categories[id] = category
+				}
+				if category != nil {
+					article.Categories = append(article.Categories, category)
+				}
+			}
+		}
+	}
+}
+
+// expandOrders embeds the expanded relations into the orders. Related resources are loaded once, even if
+// several orders share them. The articles of all items are loaded with a single query.
+func expandOrders(client db.ClientInterface, s *types.Selection, orders ...*types.Order) {
+	if s.Expands("customer") != nil {
+		customers := map[int]*types.Customer{}
+		for _, order := range orders {
+			if order.CustomerID == nil {
+				continue
+			}
+			customer, ok := customers[*order.CustomerID]
+			if !ok {
+				customer = client.GetCustomerByID(*order.CustomerID)
+				customers[*order.CustomerID] = customer
+			}
+			order.Customer = customer
+		}
+	}
+
+	items := []*types.OrderItem{}
+	for _, order := range orders {
+		items = append(items, order.Items...)
+	}
+	if paths := s.Expands("items.article"); paths != nil {
+		ids := []int{}
+		for _, item := range items {
+			ids = append(ids, item.ArticleID)
+		}
+		articles := client.GetArticlesByIDs(ids)
+		byID := map[int]*types.Article{}
+		for _, article := range articles {
+			byID[article.ID] = article
+		}
+		for _, item := range items {
+			item.Article = byID[item.ArticleID]
+		}
+		expandArticles(client, &types.Selection{Expand: paths}, articles...)
+	}
+	if s.Expands("items.variant") != nil {
+		variants := map[int]*types.Variant{}
+		for _, item := range items {
+			if item.VariantID == 0 {
+				continue
+			}
+			variant, ok := variants[item.VariantID]
+			if !ok {
+				variant = client.GetVariantByID(item.VariantID)
+				variants[item.VariantID] = variant
+			}
+			item.Variant = variant
+		}
+	}
+}
+
+// sparseJSON returns the json representation of the resource or of the items of the list with only the selected
+// fields, which is marshaled in place of v. The values of the fields are kept as they are.
+func sparseJSON(v interface{}, fields []string, list bool) (interface{}, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	doc := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+	if !list {
+		return pick(doc, fields), nil
+	}
+
+	items := []map[string]json.RawMessage{}
+	if err := json.Unmarshal(doc["items"], &items); err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		items[i] = pick(item, fields)
+	}
+	if doc["items"], err = json.Marshal(items); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// pick removes the fields that aren't selected from the resource, its links are kept
+func pick(resource map[string]json.RawMessage, fields []string) map[string]json.RawMessage {
+	for name := range resource {
+		if name != types.LinksField && !contains(fields, name) {
+			delete(resource, name)
+		}
+	}
+	return resource
+}
+
+// sparseXML removes the elements of the fields that aren't selected from the xml document of the resource or of
+// the list of resources v, the links of the resources are kept
+func sparseXML(body []byte, v interface{}, fields []string, list bool) ([]byte, error) {
+	t := reflect.TypeOf(v).Elem()
+	// fields are children of the root element of resources, and of the resource elements in the items of lists
+	fieldDepth := 2
+	if list {
+		items, _ := t.FieldByName("Items")
+		t = items.Type.Elem().Elem()
+		fieldDepth = 4
+	}
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if name := strings.Split(sf.Tag.Get("json"), ",")[0]; name == types.LinksField || contains(fields, name) {
+			names[strings.Split(strings.Split(sf.Tag.Get("xml"), ",")[0], ">")[0]] = true
+		}
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(body))
+	buf := &bytes.Buffer{}
+	e := xml.NewEncoder(buf)
+	depth, skipped := 0, 0
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			depth++
+			if skipped == 0 && depth == fieldDepth && !names[token.Name.Local] {
+				skipped = depth
+			}
+		case xml.EndElement:
+			depth--
+			if skipped > depth {
+				skipped = 0
+				continue
+			}
+		}
+		if skipped == 0 {
+			if err := e.EncodeToken(token); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := e.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
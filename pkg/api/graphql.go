@@ -0,0 +1,346 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jonnylangefeld/go-api/pkg/graphql"
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+const (
+	// defaultConnectionSize is the number of nodes returned by connections if the first argument is omitted
+	defaultConnectionSize = 10
+	// maxConnectionSize is the largest first argument of connections, which bounds the nodes an operation loads
+	// since the complexity of an operation only counts the fields it selects
+	maxConnectionSize = 100
+	// maxGraphQLDepth is the deepest nesting of fields a graphql operation may select, which leaves room for the
+	// introspection queries of graphql tools
+	maxGraphQLDepth = 15
+	// maxGraphQLComplexity is the maximum number of fields a graphql operation may select
+	maxGraphQLComplexity = 500
+	// loadersKey refers to the context key that stores the graphql loaders of a request
+	loadersKey contextKey = "loaders"
+)
+
+type (
+	// contextKey is used for context values that are private to this package
+	contextKey string
+
+	// loaders holds the per request graphql loaders
+	loaders struct {
+		articles *graphql.Loader
+	}
+
+	// connection is a relay style connection of nodes
+	connection struct {
+		Edges    []*edge   `json:"edges"`
+		PageInfo *pageInfo `json:"pageInfo"`
+	}
+
+	// edge is a single node of a connection
+	edge struct {
+		Cursor string      `json:"cursor"`
+		Node   interface{} `json:"node"`
+	}
+
+	// pageInfo describes the position of a connection within all nodes
+	pageInfo struct {
+		HasNextPage     bool   `json:"hasNextPage"`
+		HasPreviousPage bool   `json:"hasPreviousPage"`
+		StartCursor     string `json:"startCursor,omitempty"`
+		EndCursor       string `json:"endCursor,omitempty"`
+	}
+
+	// page is one page of nodes as returned by the database client
+	page struct {
+		ids        []int
+		nodes      []interface{}
+		nextPageID int
+	}
+)
+
+var graphqlSchema = newGraphQLSchema()
+
+// GraphQL executes graphql queries and mutations
+// @Summary Execute a graphql request
+// @Description GraphQL executes queries and mutations on articles and orders. The schema can be explored with the playground at /graphiql.
+// @Tags GraphQL
+// @Accept json
+// @Produce json
+// @Param request body graphql.Params true "the graphql request"
+// @Router /graphql [post]
+// @Success 200 {object} graphql.Result
+// @Failure 400 {object} graphql.Result
+func GraphQL(w http.ResponseWriter, r *http.Request) {
+	ctx := context.WithValue(r.Context(), loadersKey, &loaders{
+		articles: graphql.NewLoader(batchArticles),
+	})
+	graphql.Handler(graphqlSchema).ServeHTTP(w, r.WithContext(ctx))
+}
+
+// moneyType exposes types.Money with the same exact decimal amount as the json api
+var moneyType = &graphql.Object{
+	Name:        "Money",
+	Description: "An exact amount of a currency",
+	Fields: []*graphql.Field{
+		{
+			Name:        "amount",
+			Description: "The amount in decimal notation",
+			Type:        graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(types.Money).Decimal(), nil
+			},
+		},
+		{
+			Name:        "currency",
+			Description: "The ISO 4217 code of the currency",
+			Type:        graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(types.Money).Currency, nil
+			},
+		},
+	},
+}
+
+// moneyInputType is decoded into types.Money through its json encoding
+var moneyInputType = &graphql.InputObject{
+	Name:        "MoneyInput",
+	Description: "An exact amount of a currency",
+	Fields: []*graphql.Argument{
+		{Name: "amount", Description: "The amount in decimal notation", Type: graphql.NewNonNull(graphql.String)},
+		{Name: "currency", Description: "The ISO 4217 code of the currency, defaults to " + types.DefaultCurrency, Type: graphql.String},
+	},
+}
+
+// newGraphQLSchema builds the graphql schema from the api types
+func newGraphQLSchema() *graphql.Schema {
+	mapper := graphql.NewMapper()
+	mapper.Register(types.Money{}, moneyType, moneyInputType)
+	article := mapper.Object(types.Article{})
+	order := mapper.Object(types.Order{})
+	orderItem := mapper.Object(types.OrderItem{})
+
+	orderItem.AddField(&graphql.Field{
+		Name:        "article",
+		Description: "The ordered article",
+		Type:        article,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			item := p.Source.(*types.OrderItem)
+			return p.Context.Value(loadersKey).(*loaders).articles.Load(p.Context, item.ArticleID), nil
+		},
+	})
+
+	pageInfoType := &graphql.Object{
+		Name: "PageInfo",
+		Fields: []*graphql.Field{
+			{Name: "hasNextPage", Type: graphql.NewNonNull(graphql.Boolean)},
+			{Name: "hasPreviousPage", Type: graphql.NewNonNull(graphql.Boolean)},
+			{Name: "startCursor", Type: graphql.String},
+			{Name: "endCursor", Type: graphql.String},
+		},
+	}
+	connectionArgs := []*graphql.Argument{
+		{Name: "first", Type: graphql.Int, DefaultValue: defaultConnectionSize},
+		{Name: "after", Type: graphql.String},
+	}
+	idArgs := []*graphql.Argument{
+		{Name: "id", Type: graphql.NewNonNull(graphql.Int)},
+	}
+
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.Field{
+			{
+				Name:        "article",
+				Description: "Get an article by id",
+				Type:        article,
+				Args:        idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if article := m.GetDBClient(p.Context).GetArticleByID(p.Args["id"].(int)); article != nil && article.ID != 0 {
+						return article, nil
+					}
+					return nil, nil
+				},
+			},
+			{
+				Name:        "articles",
+				Description: "List all articles",
+				Type:        graphql.NewNonNull(newConnectionType("Article", article, pageInfoType)),
+				Args:        connectionArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return newConnection("Article", p.Args, func(pageID int) page {
+						list := m.GetDBClient(p.Context).GetArticles(pageID, nil)
+						result := page{nextPageID: list.NextPageID}
+						for _, a := range list.Items {
+							result.ids = append(result.ids, a.ID)
+							result.nodes = append(result.nodes, a)
+						}
+						return result
+					})
+				},
+			},
+			{
+				Name:        "order",
+				Description: "Get an order by id",
+				Type:        order,
+				Args:        idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if order := m.GetDBClient(p.Context).GetOrderByID(p.Args["id"].(int)); order != nil && order.ID != 0 {
+						return order, nil
+					}
+					return nil, nil
+				},
+			},
+			{
+				Name:        "orders",
+				Description: "List all orders",
+				Type:        graphql.NewNonNull(newConnectionType("Order", order, pageInfoType)),
+				Args:        connectionArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return newConnection("Order", p.Args, func(pageID int) page {
+						list := m.GetDBClient(p.Context).GetOrders(pageID, nil)
+						result := page{nextPageID: list.NextPageID}
+						for _, o := range list.Items {
+							result.ids = append(result.ids, o.ID)
+							result.nodes = append(result.nodes, o)
+						}
+						return result
+					})
+				},
+			},
+		},
+	}
+
+	mutation := &graphql.Object{
+		Name: "Mutation",
+		Fields: []*graphql.Field{
+			{
+				Name:        "setArticle",
+				Description: "Write an article. To write a new article, leave the id empty.",
+				Type:        graphql.NewNonNull(article),
+				Args:        []*graphql.Argument{{Name: "input", Type: graphql.NewNonNull(mapper.InputObject(types.Article{}))}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					article := &types.Article{}
+					if err := graphql.Decode(p.Args["input"], article); err != nil {
+						return nil, err
+					}
+					return article, auditedClient(p.Context).SetArticle(article)
+				},
+			},
+			{
+				Name:        "setOrder",
+				Description: "Write an order. To write a new order, leave the id empty.",
+				Type:        graphql.NewNonNull(order),
+				Args:        []*graphql.Argument{{Name: "input", Type: graphql.NewNonNull(mapper.InputObject(types.Order{}))}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					order := &types.Order{}
+					if err := graphql.Decode(p.Args["input"], order); err != nil {
+						return nil, err
+					}
+					return order, auditedClient(p.Context).SetOrder(order)
+				},
+			},
+		},
+	}
+
+	schema, err := graphql.NewSchema(query, mutation)
+	if err != nil {
+		panic(err)
+	}
+	schema.MaxDepth, schema.MaxComplexity = maxGraphQLDepth, maxGraphQLComplexity
+	return schema
+}
+
+// newConnectionType returns the connection type for the given node type
+func newConnectionType(name string, node graphql.Type, pageInfoType *graphql.Object) *graphql.Object {
+	edgeType := &graphql.Object{
+		Name: name + "Edge",
+		Fields: []*graphql.Field{
+			{Name: "cursor", Type: graphql.NewNonNull(graphql.String)},
+			{Name: "node", Type: graphql.NewNonNull(node)},
+		},
+	}
+	return &graphql.Object{
+		Name: name + "Connection",
+		Fields: []*graphql.Field{
+			{Name: "edges", Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType)))},
+			{Name: "pageInfo", Type: graphql.NewNonNull(pageInfoType)},
+		},
+	}
+}
+
+// newConnection fetches pages from the database client until the connection contains the requested number of nodes
+func newConnection(kind string, args map[string]interface{}, fetch func(pageID int) page) (*connection, error) {
+	first := args["first"].(int)
+	if first < 0 {
+		return nil, fmt.Errorf("first must not be negative")
+	}
+	if first > maxConnectionSize {
+		return nil, fmt.Errorf("first must not exceed %d", maxConnectionSize)
+	}
+
+	conn := &connection{Edges: []*edge{}, PageInfo: &pageInfo{}}
+	pageID := 0
+	if after, ok := args["after"].(string); ok && after != "" {
+		id, err := decodeCursor(kind, after)
+		if err != nil {
+			return nil, err
+		}
+		pageID = id + 1
+		conn.PageInfo.HasPreviousPage = true
+	}
+
+	for {
+		p := fetch(pageID)
+		for i, node := range p.nodes {
+			if len(conn.Edges) == first {
+				conn.PageInfo.HasNextPage = true
+				break
+			}
+			conn.Edges = append(conn.Edges, &edge{Cursor: encodeCursor(kind, p.ids[i]), Node: node})
+		}
+		if conn.PageInfo.HasNextPage || p.nextPageID == 0 {
+			break
+		}
+		if len(conn.Edges) == first {
+			conn.PageInfo.HasNextPage = true
+			break
+		}
+		pageID = p.nextPageID
+	}
+
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+	return conn, nil
+}
+
+// encodeCursor returns an opaque cursor for the node with the given id
+func encodeCursor(kind string, id int) string {
+	return base64.StdEncoding.EncodeToString([]byte(kind + ":" + strconv.Itoa(id)))
+}
+
+// decodeCursor returns the id of the node the cursor points to
+func decodeCursor(kind, cursor string) (int, error) {
+	b, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(b), kind+":") {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return strconv.Atoi(strings.TrimPrefix(string(b), kind+":"))
+}
+
+// batchArticles loads all requested articles with a single query
+func batchArticles(ctx context.Context, ids []int) (map[int]interface{}, error) {
+	articles := map[int]interface{}{}
+	for _, article := range m.GetDBClient(ctx).GetArticlesByIDs(ids) {
+		articles[article.ID] = article
+	}
+	return articles, nil
+}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"github.com/jonnylangefeld/go-api/pkg/media"
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/storage"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// MaxImageSize is the maximum size of uploaded images in bytes
+const MaxImageSize = 5 << 20
+
+var errNoStorage = errors.New("image storage isn't configured")
+
+// ListArticleImages returns the images of the article from the context
+// @Summary List the images of an article
+// @Description Get all images of an article, oldest first
+// @Tags Articles
+// @Produce json
+// @Param id path string true "article id"
+// @Router /articles/{id}/images [get]
+// @Success 200 {object} types.ArticleImageList
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func ListArticleImages(w http.ResponseWriter, r *http.Request) {
+	article := r.Context().Value(m.ArticleCtxKey).(*types.Article)
+	if err := render.Render(w, r, &types.ArticleImageList{Items: m.GetDBClient(r.Context()).GetArticleImages(article.ID)}); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// PostArticleImage uploads an image of the article from the context
+// @Summary Upload an image of an article
+// @Description PostArticleImage stores a jpeg, png or gif image of at most 5 MiB together with a thumbnail.
+// @Description The type is sniffed from the content of the file.
+// @Tags Articles
+// @Accept mpfd
+// @Produce json
+// @Param id path string true "article id"
+// @Param image formData file true "the image"
+// @Router /articles/{id}/images [post]
+// @Success 201 {object} types.ArticleImage
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+// @Failure 413 {object} types.ErrResponse
+// @Failure 415 {object} types.ErrResponse
+// @Failure 503 {object} types.ErrResponse
+func PostArticleImage(w http.ResponseWriter, r *http.Request) {
+	article := r.Context().Value(m.ArticleCtxKey).(*types.Article)
+	if BlobStore == nil {
+		_ = render.Render(w, r, types.ErrUnavailable(errNoStorage))
+		return
+	}
+
+	// leave room for the multipart headers around the file
+	r.Body = http.MaxBytesReader(w, r.Body, MaxImageSize+1<<20)
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		if strings.Contains(err.Error(), "http: request body too large") {
+			_ = render.Render(w, r, types.ErrTooLarge(fmt.Errorf("image must be at most %d bytes", MaxImageSize)))
+			return
+		}
+		_ = render.Render(w, r, types.ErrInvalidRequest(fmt.Errorf("couldn't read image: %w", err)))
+		return
+	}
+	defer file.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(file, MaxImageSize+1))
+	if err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(fmt.Errorf("couldn't read image: %w", err)))
+		return
+	}
+	if len(data) > MaxImageSize {
+		_ = render.Render(w, r, types.ErrTooLarge(fmt.Errorf("image must be at most %d bytes", MaxImageSize)))
+		return
+	}
+
+	info, err := media.Inspect(data)
+	if err != nil {
+		if errors.Is(err, media.ErrUnsupportedType) {
+			_ = render.Render(w, r, types.ErrUnsupportedMediaType(err))
+			return
+		}
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+	thumbnail, thumbnailType, err := media.Thumbnail(data, media.ThumbnailSize)
+	if err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	name := randomName()
+	image := &types.ArticleImage{
+		ArticleID:            article.ID,
+		ContentType:          info.ContentType,
+		Size:                 len(data),
+		Width:                info.Width,
+		Height:               info.Height,
+		Key:                  fmt.Sprintf("articles/%d/%s%s", article.ID, name, info.Extension),
+		ThumbnailKey:         fmt.Sprintf("articles/%d/%s-thumbnail%s", article.ID, name, thumbnailExtension(thumbnailType)),
+		ThumbnailContentType: thumbnailType,
+	}
+	if err := BlobStore.Put(r.Context(), image.Key, data, image.ContentType); err != nil {
+		_ = render.Render(w, r, types.ErrUnavailable(err))
+		return
+	}
+	if err := BlobStore.Put(r.Context(), image.ThumbnailKey, thumbnail, image.ThumbnailContentType); err != nil {
+		_ = BlobStore.Delete(r.Context(), image.Key)
+		_ = render.Render(w, r, types.ErrUnavailable(err))
+		return
+	}
+	if err := auditedClient(r.Context()).AddArticleImage(image); err != nil {
+		deleteBlobs(r, image)
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	if err := render.Render(w, r, image); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// GetArticleImage downloads the image from the context
+// @Summary Download an image of an article
+// @Description GetArticleImage returns the uploaded image file
+// @Tags Articles
+// @Produce jpeg,png,gif
+// @Param id path string true "article id"
+// @Param imageID path string true "image id"
+// @Router /articles/{id}/images/{imageID} [get]
+// @Success 200 {file} file
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+// @Failure 503 {object} types.ErrResponse
+func GetArticleImage(w http.ResponseWriter, r *http.Request) {
+	image := r.Context().Value(m.ArticleImageCtxKey).(*types.ArticleImage)
+	serveBlob(w, r, image.Key, image.ContentType)
+}
+
+// GetArticleImageThumbnail downloads the thumbnail of the image from the context
+// @Summary Download the thumbnail of an image of an article
+// @Description GetArticleImageThumbnail returns the thumbnail of an image, which fits into 200x200 pixels
+// @Tags Articles
+// @Produce jpeg,png
+// @Param id path string true "article id"
+// @Param imageID path string true "image id"
+// @Router /articles/{id}/images/{imageID}/thumbnail [get]
+// @Success 200 {file} file
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+// @Failure 503 {object} types.ErrResponse
+func GetArticleImageThumbnail(w http.ResponseWriter, r *http.Request) {
+	image := r.Context().Value(m.ArticleImageCtxKey).(*types.ArticleImage)
+	serveBlob(w, r, image.ThumbnailKey, image.ThumbnailContentType)
+}
+
+// DeleteArticleImage deletes the image from the context
+// @Summary Delete an image of an article
+// @Description DeleteArticleImage deletes an image and its thumbnail
+// @Tags Articles
+// @Param id path string true "article id"
+// @Param imageID path string true "image id"
+// @Router /articles/{id}/images/{imageID} [delete]
+// @Success 204
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func DeleteArticleImage(w http.ResponseWriter, r *http.Request) {
+	image := r.Context().Value(m.ArticleImageCtxKey).(*types.ArticleImage)
+
+	if err := auditedClient(r.Context()).DeleteArticleImage(image.ArticleID, image.ID); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+	deleteBlobs(r, image)
+
+	render.NoContent(w, r)
+}
+
+// serveBlob copies the blob of the key to the response. Blobs never change, so they can be cached forever.
+func serveBlob(w http.ResponseWriter, r *http.Request, key, contentType string) {
+	if BlobStore == nil {
+		_ = render.Render(w, r, types.ErrUnavailable(errNoStorage))
+		return
+	}
+	blob, err := BlobStore.Get(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+		_ = render.Render(w, r, types.ErrUnavailable(err))
+		return
+	}
+	defer blob.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, _ = io.Copy(w, blob)
+}
+
+// deleteBlobs removes the image and its thumbnail from the storage. Failures only leave unreferenced blobs
+// behind, so they don't fail the request.
+func deleteBlobs(r *http.Request, image *types.ArticleImage) {
+	if BlobStore == nil {
+		return
+	}
+	_ = BlobStore.Delete(r.Context(), image.Key)
+	_ = BlobStore.Delete(r.Context(), image.ThumbnailKey)
+}
+
+// randomName returns a random name for blobs, so that uploads never overwrite each other
+func randomName() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+func thumbnailExtension(contentType string) string {
+	if contentType == "image/jpeg" {
+		return ".jpg"
+	}
+	return ".png"
+}
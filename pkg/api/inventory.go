@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// GetArticleStock renders the stock of the article from the context
+// @Summary Get the stock of an article
+// @Description GetArticleStock returns the available and reserved quantity of an article
+// @Tags Inventory
+// @Produce json
+// @Param id path string true "article id"
+// @Router /articles/{id}/stock [get]
+// @Success 200 {object} types.Stock
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func GetArticleStock(w http.ResponseWriter, r *http.Request) {
+	article := r.Context().Value(m.ArticleCtxKey).(*types.Article)
+
+	if err := render.Render(w, r, article.StockLevel()); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// PostStockAdjustment adjusts the stock of the article from the context
+// @Summary Adjust the stock of an article
+// @Description PostStockAdjustment changes the available quantity of an article by delta and records it in the inventory ledger
+// @Description The first adjustment of an article starts tracking its stock. The available quantity can't become negative.
+// @Description Set the variant id to adjust the stock of a variant of the article instead.
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param id path string true "article id"
+// @Param adjustment body types.StockAdjustment true "the adjustment"
+// @Router /articles/{id}/stock/adjustments [post]
+// @Success 200 {object} types.Stock
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+// @Failure 409 {object} types.ErrResponse
+func PostStockAdjustment(w http.ResponseWriter, r *http.Request) {
+	article := r.Context().Value(m.ArticleCtxKey).(*types.Article)
+	adjustment := &types.StockAdjustment{}
+	if err := render.Bind(r, adjustment); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+	adjustment.ID = 0
+	adjustment.ArticleID = article.ID
+	adjustment.OrderID = 0
+
+	if err := auditedClient(r.Context()).AdjustStock(adjustment); err != nil {
+		var stockErr *types.InsufficientStockError
+		if errors.As(err, &stockErr) {
+			_ = render.Render(w, r, types.ErrConflict(err))
+			return
+		}
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if adjustment.VariantID != 0 {
+		if variant := m.GetDBClient(r.Context()).GetVariantByID(adjustment.VariantID); variant != nil {
+			if err := render.Render(w, r, variant.StockLevel()); err != nil {
+				_ = render.Render(w, r, types.ErrRender(err))
+			}
+			return
+		}
+	}
+	if err := render.Render(w, r, m.GetDBClient(r.Context()).GetArticleByID(article.ID).StockLevel()); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// ListStockAdjustments returns the inventory ledger of the article from the context
+// @Summary List the stock adjustments of an article
+// @Description Get the inventory ledger of an article, newest first
+// @Tags Inventory
+// @Produce json
+// @Param id path string true "article id"
+// @Param page_id query string false "id of the page to be retrieved"
+// @Router /articles/{id}/stock/adjustments [get]
+// @Success 200 {object} types.StockAdjustmentList
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func ListStockAdjustments(w http.ResponseWriter, r *http.Request) {
+	article := r.Context().Value(m.ArticleCtxKey).(*types.Article)
+	pageID := r.Context().Value(m.PageIDKey)
+	if err := render.Render(w, r, m.GetDBClient(r.Context()).GetStockAdjustments(article.ID, pageID.(int))); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// jsonAPIDocument is a JSON:API document of a list of resources
+type jsonAPIDocument struct {
+	Data  []*jsonAPIResource `json:"data"`
+	Links map[string]string  `json:"links,omitempty"`
+}
+
+// jsonAPIResource is a resource object of a JSON:API document
+type jsonAPIResource struct {
+	Type          string                          `json:"type"`
+	ID            string                          `json:"id"`
+	Attributes    map[string]json.RawMessage      `json:"attributes"`
+	Relationships map[string]*jsonAPIRelationship `json:"relationships,omitempty"`
+	Links         map[string]string               `json:"links,omitempty"`
+}
+
+// jsonAPIRelationship is a relationship of a resource object, which links to the related resource
+type jsonAPIRelationship struct {
+	Links map[string]string `json:"links"`
+}
+
+// jsonAPIType returns the JSON:API type of the resources of the list
+func jsonAPIType(v interface{}) (string, bool) {
+	switch v.(type) {
+	case *types.ArticleList:
+		return "articles", true
+	case *types.OrderList:
+		return "orders", true
+	}
+	return "", false
+}
+
+// marshalJSONAPI returns the JSON:API document of the list of resources of the given type, which is a list or the
+// sparse json representation of one. The json fields of the resources become their attributes, their links become
+// the links to the resources themselves and relationships to the related resources.
+func marshalJSONAPI(v interface{}, resourceType string) ([]byte, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	list := struct {
+		Items []map[string]json.RawMessage `json:"items"`
+		Links types.Links                  `json:"_links"`
+	}{}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+
+	doc := &jsonAPIDocument{Data: []*jsonAPIResource{}, Links: hrefs(list.Links)}
+	for _, item := range list.Items {
+		id := 0
+		if err := json.Unmarshal(item["id"], &id); err != nil {
+			return nil, err
+		}
+		links := types.Links{}
+		if raw, ok := item[types.LinksField]; ok {
+			if err := json.Unmarshal(raw, &links); err != nil {
+				return nil, err
+			}
+		}
+		delete(item, "id")
+		delete(item, types.LinksField)
+
+		resource := &jsonAPIResource{Type: resourceType, ID: strconv.Itoa(id), Attributes: item}
+		for rel, link := range links {
+			if rel == "self" {
+				resource.Links = map[string]string{"self": link.Href}
+				continue
+			}
+			if resource.Relationships == nil {
+				resource.Relationships = map[string]*jsonAPIRelationship{}
+			}
+			resource.Relationships[rel] = &jsonAPIRelationship{Links: map[string]string{"related": link.Href}}
+		}
+		doc.Data = append(doc.Data, resource)
+	}
+	return json.Marshal(doc)
+}
+
+// hrefs returns the urls of the links by their relation, which is how JSON:API documents represent links
+func hrefs(links types.Links) map[string]string {
+	if len(links) == 0 {
+		return nil
+	}
+	urls := map[string]string{}
+	for rel, link := range links {
+		urls[rel] = link.Href
+	}
+	return urls
+}
@@ -5,10 +5,11 @@
 package mocks
 
 import (
-	reflect "reflect"
-
 	gomock "github.com/golang/mock/gomock"
+	db "github.com/jonnylangefeld/go-api/pkg/db"
 	types "github.com/jonnylangefeld/go-api/pkg/types"
+	reflect "reflect"
+	time "time"
 )
 
 // MockClientInterface is a mock of ClientInterface interface
@@ -34,6 +35,78 @@ func (m *MockClientInterface) EXPECT() *MockClientInterfaceMockRecorder {
 	return m.recorder
 }
 
+// AddArticleImage mocks base method
+func (m *MockClientInterface) AddArticleImage(arg0 *types.ArticleImage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddArticleImage", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddArticleImage indicates an expected call of AddArticleImage
+func (mr *MockClientInterfaceMockRecorder) AddArticleImage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddArticleImage", reflect.TypeOf((*MockClientInterface)(nil).AddArticleImage), arg0)
+}
+
+// AdjustStock mocks base method
+func (m *MockClientInterface) AdjustStock(arg0 *types.StockAdjustment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustStock", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustStock indicates an expected call of AdjustStock
+func (mr *MockClientInterfaceMockRecorder) AdjustStock(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStock", reflect.TypeOf((*MockClientInterface)(nil).AdjustStock), arg0)
+}
+
+// ApplyScheduledPrices mocks base method
+func (m *MockClientInterface) ApplyScheduledPrices(arg0 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyScheduledPrices", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyScheduledPrices indicates an expected call of ApplyScheduledPrices
+func (mr *MockClientInterfaceMockRecorder) ApplyScheduledPrices(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyScheduledPrices", reflect.TypeOf((*MockClientInterface)(nil).ApplyScheduledPrices), arg0)
+}
+
+// Audited mocks base method
+func (m *MockClientInterface) Audited(arg0, arg1 string) db.ClientInterface {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Audited", arg0, arg1)
+	ret0, _ := ret[0].(db.ClientInterface)
+	return ret0
+}
+
+// Audited indicates an expected call of Audited
+func (mr *MockClientInterfaceMockRecorder) Audited(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Audited", reflect.TypeOf((*MockClientInterface)(nil).Audited), arg0, arg1)
+}
+
+// Available mocks base method
+func (m *MockClientInterface) Available() (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Available")
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Available indicates an expected call of Available
+func (mr *MockClientInterfaceMockRecorder) Available() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Available", reflect.TypeOf((*MockClientInterface)(nil).Available))
+}
+
 // Connect mocks base method
 func (m *MockClientInterface) Connect(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -48,6 +121,132 @@ func (mr *MockClientInterfaceMockRecorder) Connect(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Connect", reflect.TypeOf((*MockClientInterface)(nil).Connect), arg0)
 }
 
+// DeleteArticle mocks base method
+func (m *MockClientInterface) DeleteArticle(arg0 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteArticle", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteArticle indicates an expected call of DeleteArticle
+func (mr *MockClientInterfaceMockRecorder) DeleteArticle(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteArticle", reflect.TypeOf((*MockClientInterface)(nil).DeleteArticle), arg0)
+}
+
+// DeleteArticleImage mocks base method
+func (m *MockClientInterface) DeleteArticleImage(arg0, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteArticleImage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteArticleImage indicates an expected call of DeleteArticleImage
+func (mr *MockClientInterfaceMockRecorder) DeleteArticleImage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteArticleImage", reflect.TypeOf((*MockClientInterface)(nil).DeleteArticleImage), arg0, arg1)
+}
+
+// DeleteCategory mocks base method
+func (m *MockClientInterface) DeleteCategory(arg0 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategory", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCategory indicates an expected call of DeleteCategory
+func (mr *MockClientInterfaceMockRecorder) DeleteCategory(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockClientInterface)(nil).DeleteCategory), arg0)
+}
+
+// DeleteCoupon mocks base method
+func (m *MockClientInterface) DeleteCoupon(arg0 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCoupon", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCoupon indicates an expected call of DeleteCoupon
+func (mr *MockClientInterfaceMockRecorder) DeleteCoupon(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCoupon", reflect.TypeOf((*MockClientInterface)(nil).DeleteCoupon), arg0)
+}
+
+// DeleteCustomer mocks base method
+func (m *MockClientInterface) DeleteCustomer(arg0 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCustomer", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCustomer indicates an expected call of DeleteCustomer
+func (mr *MockClientInterfaceMockRecorder) DeleteCustomer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCustomer", reflect.TypeOf((*MockClientInterface)(nil).DeleteCustomer), arg0)
+}
+
+// DeleteOrder mocks base method
+func (m *MockClientInterface) DeleteOrder(arg0 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrder", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrder indicates an expected call of DeleteOrder
+func (mr *MockClientInterfaceMockRecorder) DeleteOrder(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrder", reflect.TypeOf((*MockClientInterface)(nil).DeleteOrder), arg0)
+}
+
+// DeleteTag mocks base method
+func (m *MockClientInterface) DeleteTag(arg0 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTag", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTag indicates an expected call of DeleteTag
+func (mr *MockClientInterfaceMockRecorder) DeleteTag(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockClientInterface)(nil).DeleteTag), arg0)
+}
+
+// DeleteVariant mocks base method
+func (m *MockClientInterface) DeleteVariant(arg0, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVariant", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVariant indicates an expected call of DeleteVariant
+func (mr *MockClientInterfaceMockRecorder) DeleteVariant(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVariant", reflect.TypeOf((*MockClientInterface)(nil).DeleteVariant), arg0, arg1)
+}
+
+// DeleteWebhook mocks base method
+func (m *MockClientInterface) DeleteWebhook(arg0 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook
+func (mr *MockClientInterfaceMockRecorder) DeleteWebhook(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockClientInterface)(nil).DeleteWebhook), arg0)
+}
+
 // GetArticleByID mocks base method
 func (m *MockClientInterface) GetArticleByID(arg0 int) *types.Article {
 	m.ctrl.T.Helper()
@@ -62,18 +261,214 @@ func (mr *MockClientInterfaceMockRecorder) GetArticleByID(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticleByID", reflect.TypeOf((*MockClientInterface)(nil).GetArticleByID), arg0)
 }
 
+// GetArticleImageByID mocks base method
+func (m *MockClientInterface) GetArticleImageByID(arg0 int) *types.ArticleImage {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArticleImageByID", arg0)
+	ret0, _ := ret[0].(*types.ArticleImage)
+	return ret0
+}
+
+// GetArticleImageByID indicates an expected call of GetArticleImageByID
+func (mr *MockClientInterfaceMockRecorder) GetArticleImageByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticleImageByID", reflect.TypeOf((*MockClientInterface)(nil).GetArticleImageByID), arg0)
+}
+
+// GetArticleImages mocks base method
+func (m *MockClientInterface) GetArticleImages(arg0 int) []*types.ArticleImage {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArticleImages", arg0)
+	ret0, _ := ret[0].([]*types.ArticleImage)
+	return ret0
+}
+
+// GetArticleImages indicates an expected call of GetArticleImages
+func (mr *MockClientInterfaceMockRecorder) GetArticleImages(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticleImages", reflect.TypeOf((*MockClientInterface)(nil).GetArticleImages), arg0)
+}
+
+// GetArticlePrices mocks base method
+func (m *MockClientInterface) GetArticlePrices(arg0, arg1 int) *types.ArticlePriceList {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArticlePrices", arg0, arg1)
+	ret0, _ := ret[0].(*types.ArticlePriceList)
+	return ret0
+}
+
+// GetArticlePrices indicates an expected call of GetArticlePrices
+func (mr *MockClientInterfaceMockRecorder) GetArticlePrices(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticlePrices", reflect.TypeOf((*MockClientInterface)(nil).GetArticlePrices), arg0, arg1)
+}
+
 // GetArticles mocks base method
-func (m *MockClientInterface) GetArticles(arg0 int) *types.ArticleList {
+func (m *MockClientInterface) GetArticles(arg0 int, arg1 *types.ArticleFilter) *types.ArticleList {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetArticles", arg0)
+	ret := m.ctrl.Call(m, "GetArticles", arg0, arg1)
 	ret0, _ := ret[0].(*types.ArticleList)
 	return ret0
 }
 
 // GetArticles indicates an expected call of GetArticles
-func (mr *MockClientInterfaceMockRecorder) GetArticles(arg0 interface{}) *gomock.Call {
+func (mr *MockClientInterfaceMockRecorder) GetArticles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticles", reflect.TypeOf((*MockClientInterface)(nil).GetArticles), arg0, arg1)
+}
+
+// GetArticlesByIDs mocks base method
+func (m *MockClientInterface) GetArticlesByIDs(arg0 []int) []*types.Article {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArticlesByIDs", arg0)
+	ret0, _ := ret[0].([]*types.Article)
+	return ret0
+}
+
+// GetArticlesByIDs indicates an expected call of GetArticlesByIDs
+func (mr *MockClientInterfaceMockRecorder) GetArticlesByIDs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticlesByIDs", reflect.TypeOf((*MockClientInterface)(nil).GetArticlesByIDs), arg0)
+}
+
+// GetAuditEntries mocks base method
+func (m *MockClientInterface) GetAuditEntries(arg0 int, arg1 *types.AuditFilter) *types.AuditEntryList {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEntries", arg0, arg1)
+	ret0, _ := ret[0].(*types.AuditEntryList)
+	return ret0
+}
+
+// GetAuditEntries indicates an expected call of GetAuditEntries
+func (mr *MockClientInterfaceMockRecorder) GetAuditEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEntries", reflect.TypeOf((*MockClientInterface)(nil).GetAuditEntries), arg0, arg1)
+}
+
+// GetCategories mocks base method
+func (m *MockClientInterface) GetCategories(arg0 int) *types.CategoryList {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategories", arg0)
+	ret0, _ := ret[0].(*types.CategoryList)
+	return ret0
+}
+
+// GetCategories indicates an expected call of GetCategories
+func (mr *MockClientInterfaceMockRecorder) GetCategories(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategories", reflect.TypeOf((*MockClientInterface)(nil).GetCategories), arg0)
+}
+
+// GetCategoryByID mocks base method
+func (m *MockClientInterface) GetCategoryByID(arg0 int) *types.Category {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryByID", arg0)
+	ret0, _ := ret[0].(*types.Category)
+	return ret0
+}
+
+// GetCategoryByID indicates an expected call of GetCategoryByID
+func (mr *MockClientInterfaceMockRecorder) GetCategoryByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryByID", reflect.TypeOf((*MockClientInterface)(nil).GetCategoryByID), arg0)
+}
+
+// GetCouponByID mocks base method
+func (m *MockClientInterface) GetCouponByID(arg0 int) *types.Coupon {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCouponByID", arg0)
+	ret0, _ := ret[0].(*types.Coupon)
+	return ret0
+}
+
+// GetCouponByID indicates an expected call of GetCouponByID
+func (mr *MockClientInterfaceMockRecorder) GetCouponByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCouponByID", reflect.TypeOf((*MockClientInterface)(nil).GetCouponByID), arg0)
+}
+
+// GetCoupons mocks base method
+func (m *MockClientInterface) GetCoupons(arg0 int) *types.CouponList {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCoupons", arg0)
+	ret0, _ := ret[0].(*types.CouponList)
+	return ret0
+}
+
+// GetCoupons indicates an expected call of GetCoupons
+func (mr *MockClientInterfaceMockRecorder) GetCoupons(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArticles", reflect.TypeOf((*MockClientInterface)(nil).GetArticles), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCoupons", reflect.TypeOf((*MockClientInterface)(nil).GetCoupons), arg0)
+}
+
+// GetCustomerByID mocks base method
+func (m *MockClientInterface) GetCustomerByID(arg0 int) *types.Customer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomerByID", arg0)
+	ret0, _ := ret[0].(*types.Customer)
+	return ret0
+}
+
+// GetCustomerByID indicates an expected call of GetCustomerByID
+func (mr *MockClientInterfaceMockRecorder) GetCustomerByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerByID", reflect.TypeOf((*MockClientInterface)(nil).GetCustomerByID), arg0)
+}
+
+// GetCustomerOrders mocks base method
+func (m *MockClientInterface) GetCustomerOrders(arg0, arg1 int) *types.OrderList {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomerOrders", arg0, arg1)
+	ret0, _ := ret[0].(*types.OrderList)
+	return ret0
+}
+
+// GetCustomerOrders indicates an expected call of GetCustomerOrders
+func (mr *MockClientInterfaceMockRecorder) GetCustomerOrders(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerOrders", reflect.TypeOf((*MockClientInterface)(nil).GetCustomerOrders), arg0, arg1)
+}
+
+// GetCustomers mocks base method
+func (m *MockClientInterface) GetCustomers(arg0 int) *types.CustomerList {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomers", arg0)
+	ret0, _ := ret[0].(*types.CustomerList)
+	return ret0
+}
+
+// GetCustomers indicates an expected call of GetCustomers
+func (mr *MockClientInterfaceMockRecorder) GetCustomers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomers", reflect.TypeOf((*MockClientInterface)(nil).GetCustomers), arg0)
+}
+
+// GetDueWebhookDeliveries mocks base method
+func (m *MockClientInterface) GetDueWebhookDeliveries(arg0 time.Time, arg1 int) []*types.WebhookDelivery {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]*types.WebhookDelivery)
+	return ret0
+}
+
+// GetDueWebhookDeliveries indicates an expected call of GetDueWebhookDeliveries
+func (mr *MockClientInterfaceMockRecorder) GetDueWebhookDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueWebhookDeliveries", reflect.TypeOf((*MockClientInterface)(nil).GetDueWebhookDeliveries), arg0, arg1)
+}
+
+// GetLastOutboxEventID mocks base method
+func (m *MockClientInterface) GetLastOutboxEventID() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastOutboxEventID")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// GetLastOutboxEventID indicates an expected call of GetLastOutboxEventID
+func (mr *MockClientInterfaceMockRecorder) GetLastOutboxEventID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastOutboxEventID", reflect.TypeOf((*MockClientInterface)(nil).GetLastOutboxEventID))
 }
 
 // GetOrderByID mocks base method
@@ -91,17 +486,242 @@ func (mr *MockClientInterfaceMockRecorder) GetOrderByID(arg0 interface{}) *gomoc
 }
 
 // GetOrders mocks base method
-func (m *MockClientInterface) GetOrders(arg0 int) *types.OrderList {
+func (m *MockClientInterface) GetOrders(arg0 int, arg1 *types.OrderFilter) *types.OrderList {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrders", arg0)
+	ret := m.ctrl.Call(m, "GetOrders", arg0, arg1)
 	ret0, _ := ret[0].(*types.OrderList)
 	return ret0
 }
 
 // GetOrders indicates an expected call of GetOrders
-func (mr *MockClientInterfaceMockRecorder) GetOrders(arg0 interface{}) *gomock.Call {
+func (mr *MockClientInterfaceMockRecorder) GetOrders(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockClientInterface)(nil).GetOrders), arg0, arg1)
+}
+
+// GetOrdersByIDs mocks base method
+func (m *MockClientInterface) GetOrdersByIDs(arg0 []int) []*types.Order {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrdersByIDs", arg0)
+	ret0, _ := ret[0].([]*types.Order)
+	return ret0
+}
+
+// GetOrdersByIDs indicates an expected call of GetOrdersByIDs
+func (mr *MockClientInterfaceMockRecorder) GetOrdersByIDs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersByIDs", reflect.TypeOf((*MockClientInterface)(nil).GetOrdersByIDs), arg0)
+}
+
+// GetOutboxEventsAfter mocks base method
+func (m *MockClientInterface) GetOutboxEventsAfter(arg0, arg1 int) []*types.OutboxEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboxEventsAfter", arg0, arg1)
+	ret0, _ := ret[0].([]*types.OutboxEvent)
+	return ret0
+}
+
+// GetOutboxEventsAfter indicates an expected call of GetOutboxEventsAfter
+func (mr *MockClientInterfaceMockRecorder) GetOutboxEventsAfter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxEventsAfter", reflect.TypeOf((*MockClientInterface)(nil).GetOutboxEventsAfter), arg0, arg1)
+}
+
+// GetStockAdjustments mocks base method
+func (m *MockClientInterface) GetStockAdjustments(arg0, arg1 int) *types.StockAdjustmentList {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStockAdjustments", arg0, arg1)
+	ret0, _ := ret[0].(*types.StockAdjustmentList)
+	return ret0
+}
+
+// GetStockAdjustments indicates an expected call of GetStockAdjustments
+func (mr *MockClientInterfaceMockRecorder) GetStockAdjustments(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockClientInterface)(nil).GetOrders), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStockAdjustments", reflect.TypeOf((*MockClientInterface)(nil).GetStockAdjustments), arg0, arg1)
+}
+
+// GetTagByID mocks base method
+func (m *MockClientInterface) GetTagByID(arg0 int) *types.Tag {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTagByID", arg0)
+	ret0, _ := ret[0].(*types.Tag)
+	return ret0
+}
+
+// GetTagByID indicates an expected call of GetTagByID
+func (mr *MockClientInterfaceMockRecorder) GetTagByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTagByID", reflect.TypeOf((*MockClientInterface)(nil).GetTagByID), arg0)
+}
+
+// GetTags mocks base method
+func (m *MockClientInterface) GetTags(arg0 int) *types.TagList {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTags", arg0)
+	ret0, _ := ret[0].(*types.TagList)
+	return ret0
+}
+
+// GetTags indicates an expected call of GetTags
+func (mr *MockClientInterfaceMockRecorder) GetTags(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*MockClientInterface)(nil).GetTags), arg0)
+}
+
+// GetUnpublishedOutboxEvents mocks base method
+func (m *MockClientInterface) GetUnpublishedOutboxEvents(arg0 int) []*types.OutboxEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnpublishedOutboxEvents", arg0)
+	ret0, _ := ret[0].([]*types.OutboxEvent)
+	return ret0
+}
+
+// GetUnpublishedOutboxEvents indicates an expected call of GetUnpublishedOutboxEvents
+func (mr *MockClientInterfaceMockRecorder) GetUnpublishedOutboxEvents(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnpublishedOutboxEvents", reflect.TypeOf((*MockClientInterface)(nil).GetUnpublishedOutboxEvents), arg0)
+}
+
+// GetVariantByID mocks base method
+func (m *MockClientInterface) GetVariantByID(arg0 int) *types.Variant {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariantByID", arg0)
+	ret0, _ := ret[0].(*types.Variant)
+	return ret0
+}
+
+// GetVariantByID indicates an expected call of GetVariantByID
+func (mr *MockClientInterfaceMockRecorder) GetVariantByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariantByID", reflect.TypeOf((*MockClientInterface)(nil).GetVariantByID), arg0)
+}
+
+// GetVariantBySKU mocks base method
+func (m *MockClientInterface) GetVariantBySKU(arg0 string) *types.Variant {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariantBySKU", arg0)
+	ret0, _ := ret[0].(*types.Variant)
+	return ret0
+}
+
+// GetVariantBySKU indicates an expected call of GetVariantBySKU
+func (mr *MockClientInterfaceMockRecorder) GetVariantBySKU(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariantBySKU", reflect.TypeOf((*MockClientInterface)(nil).GetVariantBySKU), arg0)
+}
+
+// GetVariants mocks base method
+func (m *MockClientInterface) GetVariants(arg0 int) []*types.Variant {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariants", arg0)
+	ret0, _ := ret[0].([]*types.Variant)
+	return ret0
+}
+
+// GetVariants indicates an expected call of GetVariants
+func (mr *MockClientInterfaceMockRecorder) GetVariants(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariants", reflect.TypeOf((*MockClientInterface)(nil).GetVariants), arg0)
+}
+
+// GetWebhookByID mocks base method
+func (m *MockClientInterface) GetWebhookByID(arg0 int) *types.Webhook {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookByID", arg0)
+	ret0, _ := ret[0].(*types.Webhook)
+	return ret0
+}
+
+// GetWebhookByID indicates an expected call of GetWebhookByID
+func (mr *MockClientInterfaceMockRecorder) GetWebhookByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookByID", reflect.TypeOf((*MockClientInterface)(nil).GetWebhookByID), arg0)
+}
+
+// GetWebhookDeliveries mocks base method
+func (m *MockClientInterface) GetWebhookDeliveries(arg0, arg1 int) *types.WebhookDeliveryList {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].(*types.WebhookDeliveryList)
+	return ret0
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries
+func (mr *MockClientInterfaceMockRecorder) GetWebhookDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockClientInterface)(nil).GetWebhookDeliveries), arg0, arg1)
+}
+
+// GetWebhookDeliveryByID mocks base method
+func (m *MockClientInterface) GetWebhookDeliveryByID(arg0 int) *types.WebhookDelivery {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveryByID", arg0)
+	ret0, _ := ret[0].(*types.WebhookDelivery)
+	return ret0
+}
+
+// GetWebhookDeliveryByID indicates an expected call of GetWebhookDeliveryByID
+func (mr *MockClientInterfaceMockRecorder) GetWebhookDeliveryByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveryByID", reflect.TypeOf((*MockClientInterface)(nil).GetWebhookDeliveryByID), arg0)
+}
+
+// GetWebhooks mocks base method
+func (m *MockClientInterface) GetWebhooks(arg0 int) *types.WebhookList {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", arg0)
+	ret0, _ := ret[0].(*types.WebhookList)
+	return ret0
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks
+func (mr *MockClientInterfaceMockRecorder) GetWebhooks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockClientInterface)(nil).GetWebhooks), arg0)
+}
+
+// GetWebhooksForEvent mocks base method
+func (m *MockClientInterface) GetWebhooksForEvent(arg0 string) []*types.Webhook {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooksForEvent", arg0)
+	ret0, _ := ret[0].([]*types.Webhook)
+	return ret0
+}
+
+// GetWebhooksForEvent indicates an expected call of GetWebhooksForEvent
+func (mr *MockClientInterfaceMockRecorder) GetWebhooksForEvent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooksForEvent", reflect.TypeOf((*MockClientInterface)(nil).GetWebhooksForEvent), arg0)
+}
+
+// LastWrite mocks base method
+func (m *MockClientInterface) LastWrite() time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastWrite")
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// LastWrite indicates an expected call of LastWrite
+func (mr *MockClientInterfaceMockRecorder) LastWrite() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastWrite", reflect.TypeOf((*MockClientInterface)(nil).LastWrite))
+}
+
+// LockOutbox mocks base method
+func (m *MockClientInterface) LockOutbox(arg0 func(db.ClientInterface) error) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockOutbox", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockOutbox indicates an expected call of LockOutbox
+func (mr *MockClientInterfaceMockRecorder) LockOutbox(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockOutbox", reflect.TypeOf((*MockClientInterface)(nil).LockOutbox), arg0)
 }
 
 // Ping mocks base method
@@ -118,6 +738,77 @@ func (mr *MockClientInterfaceMockRecorder) Ping() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockClientInterface)(nil).Ping))
 }
 
+// QuoteOrder mocks base method
+func (m *MockClientInterface) QuoteOrder(arg0 *types.Order) (*types.Quote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuoteOrder", arg0)
+	ret0, _ := ret[0].(*types.Quote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuoteOrder indicates an expected call of QuoteOrder
+func (mr *MockClientInterfaceMockRecorder) QuoteOrder(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuoteOrder", reflect.TypeOf((*MockClientInterface)(nil).QuoteOrder), arg0)
+}
+
+// SchedulePrice mocks base method
+func (m *MockClientInterface) SchedulePrice(arg0 *types.ArticlePrice) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchedulePrice", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SchedulePrice indicates an expected call of SchedulePrice
+func (mr *MockClientInterfaceMockRecorder) SchedulePrice(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchedulePrice", reflect.TypeOf((*MockClientInterface)(nil).SchedulePrice), arg0)
+}
+
+// SearchArticles mocks base method
+func (m *MockClientInterface) SearchArticles(arg0 string, arg1 int) *types.ArticleSearchResultList {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchArticles", arg0, arg1)
+	ret0, _ := ret[0].(*types.ArticleSearchResultList)
+	return ret0
+}
+
+// SearchArticles indicates an expected call of SearchArticles
+func (mr *MockClientInterfaceMockRecorder) SearchArticles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchArticles", reflect.TypeOf((*MockClientInterface)(nil).SearchArticles), arg0, arg1)
+}
+
+// Select mocks base method
+func (m *MockClientInterface) Select(arg0 []string) db.ClientInterface {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Select", arg0)
+	ret0, _ := ret[0].(db.ClientInterface)
+	return ret0
+}
+
+// Select indicates an expected call of Select
+func (mr *MockClientInterfaceMockRecorder) Select(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Select", reflect.TypeOf((*MockClientInterface)(nil).Select), arg0)
+}
+
+// Session mocks base method
+func (m *MockClientInterface) Session(arg0 time.Time) db.ClientInterface {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Session", arg0)
+	ret0, _ := ret[0].(db.ClientInterface)
+	return ret0
+}
+
+// Session indicates an expected call of Session
+func (mr *MockClientInterfaceMockRecorder) Session(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Session", reflect.TypeOf((*MockClientInterface)(nil).Session), arg0)
+}
+
 // SetArticle mocks base method
 func (m *MockClientInterface) SetArticle(arg0 *types.Article) error {
 	m.ctrl.T.Helper()
@@ -132,6 +823,62 @@ func (mr *MockClientInterfaceMockRecorder) SetArticle(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetArticle", reflect.TypeOf((*MockClientInterface)(nil).SetArticle), arg0)
 }
 
+// SetArticles mocks base method
+func (m *MockClientInterface) SetArticles(arg0 []*types.Article) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetArticles", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetArticles indicates an expected call of SetArticles
+func (mr *MockClientInterfaceMockRecorder) SetArticles(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetArticles", reflect.TypeOf((*MockClientInterface)(nil).SetArticles), arg0)
+}
+
+// SetCategory mocks base method
+func (m *MockClientInterface) SetCategory(arg0 *types.Category) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCategory", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCategory indicates an expected call of SetCategory
+func (mr *MockClientInterfaceMockRecorder) SetCategory(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCategory", reflect.TypeOf((*MockClientInterface)(nil).SetCategory), arg0)
+}
+
+// SetCoupon mocks base method
+func (m *MockClientInterface) SetCoupon(arg0 *types.Coupon) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCoupon", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCoupon indicates an expected call of SetCoupon
+func (mr *MockClientInterfaceMockRecorder) SetCoupon(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCoupon", reflect.TypeOf((*MockClientInterface)(nil).SetCoupon), arg0)
+}
+
+// SetCustomer mocks base method
+func (m *MockClientInterface) SetCustomer(arg0 *types.Customer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCustomer", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCustomer indicates an expected call of SetCustomer
+func (mr *MockClientInterfaceMockRecorder) SetCustomer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCustomer", reflect.TypeOf((*MockClientInterface)(nil).SetCustomer), arg0)
+}
+
 // SetOrder mocks base method
 func (m *MockClientInterface) SetOrder(arg0 *types.Order) error {
 	m.ctrl.T.Helper()
@@ -145,3 +892,129 @@ func (mr *MockClientInterfaceMockRecorder) SetOrder(arg0 interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrder", reflect.TypeOf((*MockClientInterface)(nil).SetOrder), arg0)
 }
+
+// SetOrders mocks base method
+func (m *MockClientInterface) SetOrders(arg0 []*types.Order) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOrders", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetOrders indicates an expected call of SetOrders
+func (mr *MockClientInterfaceMockRecorder) SetOrders(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOrders", reflect.TypeOf((*MockClientInterface)(nil).SetOrders), arg0)
+}
+
+// SetOutboxEvent mocks base method
+func (m *MockClientInterface) SetOutboxEvent(arg0 *types.OutboxEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOutboxEvent", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetOutboxEvent indicates an expected call of SetOutboxEvent
+func (mr *MockClientInterfaceMockRecorder) SetOutboxEvent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOutboxEvent", reflect.TypeOf((*MockClientInterface)(nil).SetOutboxEvent), arg0)
+}
+
+// SetTag mocks base method
+func (m *MockClientInterface) SetTag(arg0 *types.Tag) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTag", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTag indicates an expected call of SetTag
+func (mr *MockClientInterfaceMockRecorder) SetTag(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTag", reflect.TypeOf((*MockClientInterface)(nil).SetTag), arg0)
+}
+
+// SetVariant mocks base method
+func (m *MockClientInterface) SetVariant(arg0 *types.Variant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVariant", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetVariant indicates an expected call of SetVariant
+func (mr *MockClientInterfaceMockRecorder) SetVariant(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVariant", reflect.TypeOf((*MockClientInterface)(nil).SetVariant), arg0)
+}
+
+// SetWebhook mocks base method
+func (m *MockClientInterface) SetWebhook(arg0 *types.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWebhook", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWebhook indicates an expected call of SetWebhook
+func (mr *MockClientInterfaceMockRecorder) SetWebhook(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWebhook", reflect.TypeOf((*MockClientInterface)(nil).SetWebhook), arg0)
+}
+
+// SetWebhookDelivery mocks base method
+func (m *MockClientInterface) SetWebhookDelivery(arg0 *types.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWebhookDelivery", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWebhookDelivery indicates an expected call of SetWebhookDelivery
+func (mr *MockClientInterfaceMockRecorder) SetWebhookDelivery(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWebhookDelivery", reflect.TypeOf((*MockClientInterface)(nil).SetWebhookDelivery), arg0)
+}
+
+// StreamArticles mocks base method
+func (m *MockClientInterface) StreamArticles(arg0 int, arg1 *types.ArticleFilter, arg2 func(*types.Article) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamArticles", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamArticles indicates an expected call of StreamArticles
+func (mr *MockClientInterfaceMockRecorder) StreamArticles(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamArticles", reflect.TypeOf((*MockClientInterface)(nil).StreamArticles), arg0, arg1, arg2)
+}
+
+// StreamOrders mocks base method
+func (m *MockClientInterface) StreamOrders(arg0 int, arg1 *types.OrderFilter, arg2 func(*types.Order) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamOrders", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamOrders indicates an expected call of StreamOrders
+func (mr *MockClientInterfaceMockRecorder) StreamOrders(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamOrders", reflect.TypeOf((*MockClientInterface)(nil).StreamOrders), arg0, arg1, arg2)
+}
+
+// Transaction mocks base method
+func (m *MockClientInterface) Transaction(arg0 func(db.ClientInterface) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction
+func (mr *MockClientInterfaceMockRecorder) Transaction(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockClientInterface)(nil).Transaction), arg0)
+}
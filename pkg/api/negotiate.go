@@ -0,0 +1,176 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-chi/render"
+
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/msgpack"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// csvLister is implemented by lists that can be returned as csv
+type csvLister interface {
+	CSVRecords() [][]string
+}
+
+// respond writes the response in the media type negotiated by the m.Negotiate middleware. Responses of routes
+// without negotiation are written by the default responder of render. Lists are written as csv or JSON:API
+// documents if negotiated, other responses such as errors fall back to json. Articles, orders and their lists
+// only contain the fields selected by the m.Select middleware, except for csv, whose columns are fixed.
+func respond(w http.ResponseWriter, r *http.Request, v interface{}) {
+	mediaType, ok := r.Context().Value(m.MediaTypeKey).(string)
+	if !ok {
+		render.DefaultResponder(w, r, v)
+		return
+	}
+
+	fields := selection(r).Fields
+	list := false
+	switch v.(type) {
+	case *types.ArticleList, *types.OrderList:
+		list = true
+	case *types.Article, *types.Order:
+	default:
+		fields = nil
+	}
+
+	var body []byte
+	var err error
+	switch mediaType {
+	case m.MediaTypeXML:
+		body, err = marshalXML(v)
+		if err == nil && fields != nil {
+			body, err = sparseXML(body, v, fields, list)
+		}
+		mediaType += "; charset=utf-8"
+	case m.MediaTypeMsgpack:
+		if fields != nil {
+			v, err = sparseJSON(v, fields, list)
+		}
+		if err == nil {
+			body, err = msgpack.Marshal(v)
+		}
+	case m.MediaTypeCSV:
+		list, ok := v.(csvLister)
+		if !ok {
+			render.JSON(w, r, v)
+			return
+		}
+		body, err = marshalCSV(list)
+		mediaType += "; charset=utf-8"
+	case m.MediaTypeJSONAPI:
+		resourceType, ok := jsonAPIType(v)
+		if !ok {
+			render.JSON(w, r, v)
+			return
+		}
+		if fields != nil {
+			v, err = sparseJSON(v, fields, list)
+		}
+		if err == nil {
+			body, err = marshalJSONAPI(v, resourceType)
+		}
+	default:
+		if fields != nil {
+			v, err = sparseJSON(v, fields, list)
+		}
+		if err == nil {
+			render.JSON(w, r, v)
+			return
+		}
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	if status, ok := r.Context().Value(render.StatusCtxKey).(int); ok {
+		w.WriteHeader(status)
+	}
+	_, _ = w.Write(body)
+}
+
+// marshalXML returns the xml document of v. The root element is named after the type of v, such as article
+// for types.Article.
+func marshalXML(v interface{}) ([]byte, error) {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	first, size := utf8.DecodeRuneInString(t.Name())
+	root := string(unicode.ToLower(first)) + t.Name()[size:]
+
+	buf := bytes.NewBufferString(xml.Header)
+	if err := xml.NewEncoder(buf).EncodeElement(v, xml.StartElement{Name: xml.Name{Local: root}}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// marshalCSV returns the csv document of the list
+func marshalCSV(list csvLister) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	cw := csv.NewWriter(buf)
+	if err := cw.WriteAll(list.CSVRecords()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decode decodes the request body into v by its Content-Type, which is json, xml or MessagePack
+func decode(r *http.Request, v interface{}) error {
+	switch m.MediaType(r.Header.Get("Content-Type")) {
+	case m.MediaTypeJSON:
+		return render.DecodeJSON(r.Body, v)
+	case m.MediaTypeXML:
+		return render.DecodeXML(r.Body, v)
+	case m.MediaTypeMsgpack:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		return msgpack.Unmarshal(body, v)
+	}
+	return errors.New("render: unable to automatically decode the request content type")
+}
+
+// isList reports whether the body of the request is a list rather than a single resource. Lists are json and
+// MessagePack arrays or xml documents whose root element has the given name.
+// The body is restored, so that it can be decoded afterwards.
+func isList(r *http.Request, name string) (bool, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return false, err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	switch m.MediaType(r.Header.Get("Content-Type")) {
+	case m.MediaTypeXML:
+		d := xml.NewDecoder(bytes.NewReader(body))
+		for {
+			token, err := d.Token()
+			if err != nil {
+				return false, fmt.Errorf("couldn't read the xml document: %w", err)
+			}
+			if start, ok := token.(xml.StartElement); ok {
+				return start.Name.Local == name, nil
+			}
+		}
+	case m.MediaTypeMsgpack:
+		return msgpack.IsArray(body), nil
+	}
+	body = bytes.TrimSpace(body)
+	return len(body) > 0 && body[0] == '[', nil
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/render"
@@ -13,14 +14,18 @@ import (
 // @Summary Get article by id
 // @Description GetArticle returns a single article by id
 // @Tags Articles
-// @Produce json
+// @Produce json,xml,application/msgpack
 // @Param id path string true "article id"
+// @Param fields query string false "comma separated fields to return, such as id,name"
+// @Param expand query string false "comma separated relations to embed, such as categories"
 // @Router /articles/{id} [get]
 // @Success 200 {object} types.Article
 // @Failure 400 {object} types.ErrResponse
 // @Failure 404 {object} types.ErrResponse
+// @Failure 406 {object} types.ErrResponse
 func GetArticle(w http.ResponseWriter, r *http.Request) {
 	article := r.Context().Value(m.ArticleCtxKey).(*types.Article)
+	expandArticles(m.GetDBClient(r.Context()), selection(r), article)
 
 	if err := render.Render(w, r, article); err != nil {
 		_ = render.Render(w, r, types.ErrRender(err))
@@ -32,20 +37,34 @@ func GetArticle(w http.ResponseWriter, r *http.Request) {
 // @Summary Add an article to the database
 // @Description PutArticle writes an article to the database
 // @Description To write a new article, leave the id empty. To update an existing one, use the id of the article to be updated
+// @Description A list of articles is written in a single transaction and the written list is returned.
 // @Tags Articles
-// @Produce json
+// @Accept json,xml,application/msgpack
+// @Produce json,xml,application/msgpack
 // @Router /articles [put]
 // @Success 200 {object} types.Article
 // @Failure 400 {object} types.ErrResponse
 // @Failure 404 {object} types.ErrResponse
+// @Failure 406 {object} types.ErrResponse
+// @Failure 415 {object} types.ErrResponse
 func PutArticle(w http.ResponseWriter, r *http.Request) {
+	list, err := isList(r, "articles")
+	if err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+	if list {
+		putArticles(w, r)
+		return
+	}
+
 	article := &types.Article{}
 	if err := render.Bind(r, article); err != nil {
 		_ = render.Render(w, r, types.ErrInvalidRequest(err))
 		return
 	}
 
-	if err := DBClient.SetArticle(article); err != nil {
+	if err := auditedClient(r.Context()).SetArticle(article); err != nil {
 		_ = render.Render(w, r, types.ErrInvalidRequest(err))
 		return
 	}
@@ -56,19 +75,58 @@ func PutArticle(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// DeleteArticle deletes the article from the context
+// @Summary Delete article by id
+// @Description DeleteArticle deletes a single article and its images by id
+// @Tags Articles
+// @Param id path string true "article id"
+// @Router /articles/{id} [delete]
+// @Success 204
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func DeleteArticle(w http.ResponseWriter, r *http.Request) {
+	article := r.Context().Value(m.ArticleCtxKey).(*types.Article)
+
+	if err := auditedClient(r.Context()).DeleteArticle(article.ID); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+	for _, image := range article.Images {
+		deleteBlobs(r, image)
+	}
+
+	render.NoContent(w, r)
+}
+
 // ListArticles returns all articles in the database
 // @Summary List all articles
 // @Description Get all articles stored in the database
 // @Tags Articles
-// @Produce json
+// @Produce json,xml,application/msgpack,text/csv,application/vnd.api+json
 // @Param page_id query string false "id of the page to be retrieved"
+// @Param low_stock query int false "only list articles with tracked stock of at most this quantity"
+// @Param category query int false "only list articles of this category or one of its subcategories"
+// @Param tag query string false "only list articles with this tag"
+// @Param ids query string false "comma separated ids of the articles to list instead of a page"
+// @Param fields query string false "comma separated fields to return, such as id,name"
+// @Param expand query string false "comma separated relations to embed, such as categories"
 // @Router /articles [get]
 // @Success 200 {object} types.ArticleList
 // @Failure 400 {object} types.ErrResponse
 // @Failure 404 {object} types.ErrResponse
+// @Failure 406 {object} types.ErrResponse
 func ListArticles(w http.ResponseWriter, r *http.Request) {
 	pageID := r.Context().Value(m.PageIDKey)
-	if err := render.Render(w, r, DBClient.GetArticles(pageID.(int))); err != nil {
+	filter := r.Context().Value(m.ArticleFilterKey).(*types.ArticleFilter)
+	client := m.GetSelectingDBClient(r.Context())
+	var list *types.ArticleList
+	if ids := r.Context().Value(m.IDsKey).([]int); ids != nil {
+		list = &types.ArticleList{Items: client.GetArticlesByIDs(ids)}
+	} else {
+		list = client.GetArticles(pageID.(int), filter)
+	}
+	expandArticles(m.GetDBClient(r.Context()), selection(r), list.Items...)
+	if err := render.Render(w, r, list); err != nil {
 		_ = render.Render(w, r, types.ErrRender(err))
 		return
 	}
@@ -78,14 +136,18 @@ func ListArticles(w http.ResponseWriter, r *http.Request) {
 // @Summary Get order by id
 // @Description GetOrder returns a single order by id
 // @Tags Orders
-// @Produce json
+// @Produce json,xml,application/msgpack
 // @Param id path string true "order id"
+// @Param fields query string false "comma separated fields to return, such as id,total"
+// @Param expand query string false "comma separated relations to embed, such as customer or items.article"
 // @Router /orders/{id} [get]
 // @Success 200 {object} types.Order
 // @Failure 400 {object} types.ErrResponse
 // @Failure 404 {object} types.ErrResponse
+// @Failure 406 {object} types.ErrResponse
 func GetOrder(w http.ResponseWriter, r *http.Request) {
 	order := r.Context().Value(m.OrderCtxKey).(*types.Order)
+	expandOrders(m.GetDBClient(r.Context()), selection(r), order)
 
 	if err := render.Render(w, r, order); err != nil {
 		_ = render.Render(w, r, types.ErrRender(err))
@@ -97,20 +159,41 @@ func GetOrder(w http.ResponseWriter, r *http.Request) {
 // @Summary Add an order to the database
 // @Description PutOrder writes an order to the database
 // @Description To write a new order, leave the id empty. To update an existing one, use the id of the order to be updated
+// @Description The stock of the ordered articles is reserved. If an article doesn't have enough stock left, the order is rejected.
+// @Description A list of orders is written in a single transaction and the written list is returned.
 // @Tags Orders
-// @Produce json
+// @Accept json,xml,application/msgpack
+// @Produce json,xml,application/msgpack
 // @Router /orders [put]
 // @Success 200 {object} types.Order
 // @Failure 400 {object} types.ErrResponse
 // @Failure 404 {object} types.ErrResponse
+// @Failure 409 {object} types.ErrResponse
+// @Failure 406 {object} types.ErrResponse
+// @Failure 415 {object} types.ErrResponse
 func PutOrder(w http.ResponseWriter, r *http.Request) {
+	list, err := isList(r, "orders")
+	if err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+	if list {
+		putOrders(w, r)
+		return
+	}
+
 	order := &types.Order{}
 	if err := render.Bind(r, order); err != nil {
 		_ = render.Render(w, r, types.ErrInvalidRequest(err))
 		return
 	}
 
-	if err := DBClient.SetOrder(order); err != nil {
+	if err := auditedClient(r.Context()).SetOrder(order); err != nil {
+		var stockErr *types.InsufficientStockError
+		if errors.As(err, &stockErr) {
+			_ = render.Render(w, r, types.ErrConflict(err))
+			return
+		}
 		_ = render.Render(w, r, types.ErrInvalidRequest(err))
 		return
 	}
@@ -121,19 +204,53 @@ func PutOrder(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// DeleteOrder deletes the order from the context
+// @Summary Delete order by id
+// @Description DeleteOrder cancels a single order by deleting it and its items by id. Its reserved stock is released.
+// @Tags Orders
+// @Param id path string true "order id"
+// @Router /orders/{id} [delete]
+// @Success 204
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func DeleteOrder(w http.ResponseWriter, r *http.Request) {
+	order := r.Context().Value(m.OrderCtxKey).(*types.Order)
+
+	if err := auditedClient(r.Context()).DeleteOrder(order.ID); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	render.NoContent(w, r)
+}
+
 // ListOrders returns all orders in the database
 // @Summary List all orders
 // @Description Get all orders stored in the database
 // @Tags Orders
-// @Produce json
+// @Produce json,xml,application/msgpack,text/csv,application/vnd.api+json
 // @Param page_id query string false "id of the page to be retrieved"
+// @Param customer query int false "only list orders of this customer"
+// @Param ids query string false "comma separated ids of the orders to list instead of a page"
+// @Param fields query string false "comma separated fields to return, such as id,total"
+// @Param expand query string false "comma separated relations to embed, such as customer or items.article"
 // @Router /orders [get]
 // @Success 200 {object} types.OrderList
 // @Failure 400 {object} types.ErrResponse
 // @Failure 404 {object} types.ErrResponse
+// @Failure 406 {object} types.ErrResponse
 func ListOrders(w http.ResponseWriter, r *http.Request) {
 	pageID := r.Context().Value(m.PageIDKey)
-	if err := render.Render(w, r, DBClient.GetOrders(pageID.(int))); err != nil {
+	filter := r.Context().Value(m.OrderFilterKey).(*types.OrderFilter)
+	client := m.GetSelectingDBClient(r.Context())
+	var list *types.OrderList
+	if ids := r.Context().Value(m.IDsKey).([]int); ids != nil {
+		list = &types.OrderList{Items: client.GetOrdersByIDs(ids)}
+	} else {
+		list = client.GetOrders(pageID.(int), filter)
+	}
+	expandOrders(m.GetDBClient(r.Context()), selection(r), list.Items...)
+	if err := render.Render(w, r, list); err != nil {
 		_ = render.Render(w, r, types.ErrRender(err))
 		return
 	}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// ListArticlePrices returns the price history of the article from the context
+// @Summary List the prices of an article
+// @Description Get the price history of an article including its scheduled prices, latest first
+// @Tags Prices
+// @Produce json
+// @Param id path string true "article id"
+// @Param page_id query string false "id of the page to be retrieved"
+// @Router /articles/{id}/prices [get]
+// @Success 200 {object} types.ArticlePriceList
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func ListArticlePrices(w http.ResponseWriter, r *http.Request) {
+	article := r.Context().Value(m.ArticleCtxKey).(*types.Article)
+	pageID := r.Context().Value(m.PageIDKey)
+	if err := render.Render(w, r, m.GetDBClient(r.Context()).GetArticlePrices(article.ID, pageID.(int))); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// PostArticlePrice schedules a price change of the article from the context
+// @Summary Schedule a price change of an article
+// @Description PostArticlePrice records a future price of an article, which is applied to the article once it takes effect.
+// @Description A scheduled price replaces another price of the article that takes effect at the same time.
+// @Tags Prices
+// @Accept json
+// @Produce json
+// @Param id path string true "article id"
+// @Param price body types.ArticlePrice true "the price and the time it takes effect"
+// @Router /articles/{id}/prices [post]
+// @Success 200 {object} types.ArticlePrice
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func PostArticlePrice(w http.ResponseWriter, r *http.Request) {
+	article := r.Context().Value(m.ArticleCtxKey).(*types.Article)
+	price := &types.ArticlePrice{}
+	if err := render.Bind(r, price); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+	price.ID = 0
+	price.ArticleID = article.ID
+
+	if err := auditedClient(r.Context()).SchedulePrice(price); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := render.Render(w, r, price); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// QuoteOrder prices an order without writing it to the database
+// @Summary Price an order
+// @Description QuoteOrder calculates the subtotal, discount, tax and total of an order without writing it to the database
+// @Description Articles are priced at the time of the order, or now if it is empty. Taxes depend on the region and the tax category of the articles.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param order body types.Order true "the order to be priced"
+// @Router /orders:quote [post]
+// @Success 200 {object} types.Quote
+// @Failure 400 {object} types.ErrResponse
+func QuoteOrder(w http.ResponseWriter, r *http.Request) {
+	order := &types.Order{}
+	if err := render.Bind(r, order); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	quote, err := m.GetDBClient(r.Context()).QuoteOrder(order)
+	if err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := render.Render(w, r, quote); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// GetCoupon renders the coupon from the context
+// @Summary Get coupon by id
+// @Description GetCoupon returns a single coupon by id
+// @Tags Coupons
+// @Produce json
+// @Param id path string true "coupon id"
+// @Router /coupons/{id} [get]
+// @Success 200 {object} types.Coupon
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func GetCoupon(w http.ResponseWriter, r *http.Request) {
+	coupon := r.Context().Value(m.CouponCtxKey).(*types.Coupon)
+
+	if err := render.Render(w, r, coupon); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// PutCoupon writes a coupon to the database
+// @Summary Add a coupon to the database
+// @Description PutCoupon writes a coupon to the database
+// @Description To write a new coupon, leave the id empty. To update an existing one, use the id of the coupon to be updated
+// @Description The code must not be used by another coupon. The number of uses is only changed by orders.
+// @Tags Coupons
+// @Accept json
+// @Produce json
+// @Param coupon body types.Coupon true "the coupon"
+// @Router /coupons [put]
+// @Success 200 {object} types.Coupon
+// @Failure 400 {object} types.ErrResponse
+// @Failure 409 {object} types.ErrResponse
+func PutCoupon(w http.ResponseWriter, r *http.Request) {
+	coupon := &types.Coupon{}
+	if err := render.Bind(r, coupon); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := auditedClient(r.Context()).SetCoupon(coupon); err != nil {
+		if errors.Is(err, types.ErrCouponCodeTaken) {
+			_ = render.Render(w, r, types.ErrConflict(err))
+			return
+		}
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := render.Render(w, r, coupon); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// DeleteCoupon deletes the coupon from the context
+// @Summary Delete coupon by id
+// @Description DeleteCoupon deletes a single coupon by id. Orders keep the code of the coupon.
+// @Tags Coupons
+// @Param id path string true "coupon id"
+// @Router /coupons/{id} [delete]
+// @Success 204
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func DeleteCoupon(w http.ResponseWriter, r *http.Request) {
+	coupon := r.Context().Value(m.CouponCtxKey).(*types.Coupon)
+
+	if err := auditedClient(r.Context()).DeleteCoupon(coupon.ID); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	render.NoContent(w, r)
+}
+
+// ListCoupons returns all coupons in the database
+// @Summary List all coupons
+// @Description Get all coupons stored in the database
+// @Tags Coupons
+// @Produce json
+// @Param page_id query string false "id of the page to be retrieved"
+// @Router /coupons [get]
+// @Success 200 {object} types.CouponList
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func ListCoupons(w http.ResponseWriter, r *http.Request) {
+	pageID := r.Context().Value(m.PageIDKey)
+	if err := render.Render(w, r, m.GetDBClient(r.Context()).GetCoupons(pageID.(int))); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/search"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// SearchArticles returns the articles matching a search query
+// @Summary Search articles
+// @Description Search the names of all articles. Words of the query match the words of names starting with them,
+// @Description names with typos match by similarity. Results are ordered by relevance and the matching words are highlighted.
+// @Tags Articles
+// @Produce json
+// @Param q query string true "the search query"
+// @Param page_id query string false "id of the page to be retrieved"
+// @Router /articles/search [get]
+// @Success 200 {object} types.ArticleSearchResultList
+// @Failure 400 {object} types.ErrResponse
+func SearchArticles(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if len(search.Terms(query)) == 0 {
+		_ = render.Render(w, r, types.ErrInvalidRequest(errors.New("q must contain at least one word")))
+		return
+	}
+
+	pageID := r.Context().Value(m.PageIDKey)
+	if err := render.Render(w, r, m.GetDBClient(r.Context()).SearchArticles(query, pageID.(int))); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
@@ -0,0 +1,288 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/render"
+
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+const (
+	// lastEventIDHeader is sent by reconnecting EventSource clients with the id of the last received event
+	lastEventIDHeader = "Last-Event-ID"
+	// replayBatchSize is the number of change log entries read per query when a stream is resumed
+	replayBatchSize = 100
+	// reconnectDelay is the time clients wait before reconnecting to a closed stream
+	reconnectDelay = 3 * time.Second
+	// dedupeWindow is the number of event ids below the latest sent one that a stream remembers to skip duplicates.
+	// Events can be committed and thus published out of the order of their ids, but not that far apart.
+	dedupeWindow = 1000
+)
+
+// heartbeatInterval is the time between two heartbeats, which keep idle connections open through proxies
+var heartbeatInterval = 15 * time.Second
+
+// StreamArticles streams changes of articles as server-sent events
+// @Summary Stream article changes
+// @Description StreamArticles streams every created, updated and deleted article as a server-sent event
+// @Description The id of every event is its position in the change log. Send it as Last-Event-ID header or last_event_id query parameter to resume a stream, new streams start with the next change.
+// @Description Consumers that fall too far behind are disconnected and have to resume the stream.
+// @Tags Articles
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "id of the last received event"
+// @Param last_event_id query string false "id of the last received event"
+// @Param types query string false "comma separated actions to receive" Enums(created, updated, deleted)
+// @Param ids query string false "comma separated article ids to receive"
+// @Router /articles/stream [get]
+// @Success 200 {object} types.Event
+// @Failure 400 {object} types.ErrResponse
+// @Failure 503 {object} types.ErrResponse
+func StreamArticles(w http.ResponseWriter, r *http.Request) {
+	streamEvents(w, r, "article")
+}
+
+// StreamOrders streams changes of orders as server-sent events
+// @Summary Stream order changes
+// @Description StreamOrders streams every created, updated and deleted order as a server-sent event
+// @Description The id of every event is its position in the change log. Send it as Last-Event-ID header or last_event_id query parameter to resume a stream, new streams start with the next change.
+// @Description Consumers that fall too far behind are disconnected and have to resume the stream.
+// @Tags Orders
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "id of the last received event"
+// @Param last_event_id query string false "id of the last received event"
+// @Param types query string false "comma separated actions to receive" Enums(created, updated, deleted)
+// @Param ids query string false "comma separated order ids to receive"
+// @Router /orders/stream [get]
+// @Success 200 {object} types.Event
+// @Failure 400 {object} types.ErrResponse
+// @Failure 503 {object} types.ErrResponse
+func StreamOrders(w http.ResponseWriter, r *http.Request) {
+	streamEvents(w, r, "order")
+}
+
+// streamEvents replays the change log after the last event id and then streams live events of the resource
+// from the broker. The subscription is made before the replay, so that no event is missed in between.
+// Streams without a last event id start at the end of the change log.
+func streamEvents(w http.ResponseWriter, r *http.Request, resource string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		_ = render.Render(w, r, types.ErrRender(errors.New("streaming is not supported")))
+		return
+	}
+
+	filter, err := newStreamFilter(r, resource)
+	if err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+	lastID, resumed, err := lastEventID(r)
+	if err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if EventBroker == nil {
+		_ = render.Render(w, r, types.ErrUnavailable(errors.New("no event broker configured")))
+		return
+	}
+	messages, unsubscribe := EventBroker.Subscribe(types.EventAll)
+	defer unsubscribe()
+	dbClient := m.GetDBClient(r.Context())
+	if !resumed {
+		lastID = dbClient.GetLastOutboxEventID()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// disable response buffering of nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", reconnectDelay.Milliseconds()); err != nil {
+		return
+	}
+
+	sent := newSentEvents(lastID)
+	for {
+		outboxEvents := dbClient.GetOutboxEventsAfter(lastID, replayBatchSize)
+		for _, outboxEvent := range outboxEvents {
+			lastID = outboxEvent.ID
+			sent.add(outboxEvent.ID)
+			event, err := outboxEvent.Event()
+			if err != nil || !filter.match(event) {
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		}
+		if len(outboxEvents) < replayBatchSize {
+			break
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case msg, ok := <-messages:
+			// the broker closes the subscription of consumers that are too slow
+			if !ok {
+				return
+			}
+			event, err := types.DecodeEvent(msg.Body)
+			// events that were already replayed from the change log or published twice are skipped
+			if err != nil || !sent.add(event.Sequence) {
+				continue
+			}
+			if !filter.match(event) {
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes the event in the text/event-stream format
+func writeEvent(w http.ResponseWriter, event *types.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, event.Type, data)
+	return err
+}
+
+// lastEventID returns the id of the last event the client received, preferring the header over the query parameter,
+// and whether the client sent one
+func lastEventID(r *http.Request) (int, bool, error) {
+	value := r.Header.Get(lastEventIDHeader)
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 0 {
+		return 0, false, fmt.Errorf("invalid last event id %q", value)
+	}
+	return id, true, nil
+}
+
+// sentEvents remembers the ids of the events a stream already passed on. Since events can be committed and thus
+// published out of the order of their ids, the ids after the start of the stream are remembered one by one, up to
+// dedupeWindow ids below the latest one. Ids at or below the floor count as sent.
+type sentEvents struct {
+	ids    map[int]bool
+	floor  int
+	latest int
+}
+
+// newSentEvents returns the sent events of a stream that starts after the event with the given id
+func newSentEvents(lastID int) *sentEvents {
+	return &sentEvents{ids: map[int]bool{}, floor: lastID, latest: lastID}
+}
+
+// add records the event id and reports whether it wasn't sent before
+func (s *sentEvents) add(id int) bool {
+	if id <= s.floor || s.ids[id] {
+		return false
+	}
+	s.ids[id] = true
+	if id > s.latest {
+		s.latest = id
+	}
+	if len(s.ids) > 2*dedupeWindow {
+		s.floor = s.latest - dedupeWindow
+		for sentID := range s.ids {
+			if sentID <= s.floor {
+				delete(s.ids, sentID)
+			}
+		}
+	}
+	return true
+}
+
+// streamFilter selects the events a stream connection receives
+type streamFilter struct {
+	// types contains the accepted event types, all event types of the resource are accepted if it is empty
+	types map[string]bool
+	// ids contains the accepted resource ids, all resources are accepted if it is empty
+	ids      map[int]bool
+	resource string
+}
+
+// newStreamFilter parses the filter of a stream connection from the types and ids query parameters
+func newStreamFilter(r *http.Request, resource string) (*streamFilter, error) {
+	filter := &streamFilter{
+		types:    map[string]bool{},
+		ids:      map[int]bool{},
+		resource: resource,
+	}
+	for _, action := range splitQuery(r, "types") {
+		switch action {
+		case "created", "updated", "deleted":
+			filter.types[resource+"."+action] = true
+		default:
+			return nil, fmt.Errorf("unknown type %q", action)
+		}
+	}
+	for _, value := range splitQuery(r, "ids") {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q", value)
+		}
+		filter.ids[id] = true
+	}
+	return filter, nil
+}
+
+// match reports whether the event passes the filter
+func (f *streamFilter) match(event *types.Event) bool {
+	if !strings.HasPrefix(event.Type, f.resource+".") {
+		return false
+	}
+	if len(f.types) > 0 && !f.types[event.Type] {
+		return false
+	}
+	if len(f.ids) > 0 {
+		data, _ := event.Data.(json.RawMessage)
+		resource := struct {
+			ID int `json:"id"`
+		}{}
+		if err := json.Unmarshal(data, &resource); err != nil || !f.ids[resource.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitQuery returns the non-empty comma separated values of the query parameter
+func splitQuery(r *http.Request, key string) []string {
+	values := []string{}
+	for _, value := range strings.Split(r.URL.Query().Get(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
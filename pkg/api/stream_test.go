@@ -0,0 +1,136 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonnylangefeld/go-api/pkg/api/mocks"
+	"github.com/jonnylangefeld/go-api/pkg/outbox"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+func testOutboxEvent(t *testing.T, id int, eventType string, data interface{}) *types.OutboxEvent {
+	event := types.NewEvent(eventType, data)
+	event.CreatedAt = time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	event.ID = "e" + strconv.Itoa(id)
+	outboxEvent, err := types.NewOutboxEvent(event)
+	assert.NoError(t, err)
+	outboxEvent.ID = id
+	return outboxEvent
+}
+
+// TestStreamOrders ensures that a resumed stream replays the change log and continues with live events
+func TestStreamOrders(t *testing.T) {
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	dbClient.EXPECT().Available().Return(time.Duration(0), nil).AnyTimes()
+	dbClient.EXPECT().Session(gomock.Any()).Return(dbClient).AnyTimes()
+	dbClient.EXPECT().LastWrite().Return(time.Time{}).AnyTimes()
+	// the event 3 isn't committed yet when the stream is resumed
+	dbClient.EXPECT().GetOutboxEventsAfter(gomock.Eq(1), gomock.Eq(replayBatchSize)).Return([]*types.OutboxEvent{
+		testOutboxEvent(t, 2, types.EventOrderCreated, &types.Order{ID: 1}),
+		testOutboxEvent(t, 4, types.EventOrderCreated, &types.Order{ID: 2}),
+	})
+
+	broker := outbox.NewMemoryBroker()
+	SetBroker(broker)
+	defer SetBroker(nil)
+	ts := httptest.NewServer(GetRouter(nil, dbClient))
+	defer ts.Close()
+
+	readEvent, closeStream := openStream(t, ts.URL+"/orders/stream?ids=1", "1")
+	defer closeStream()
+
+	assert.Equal(t, "retry: 3000\n", readEvent())
+	assert.Equal(t, "id: 2\nevent: order.created\ndata: {\"id\":\"e2\",\"type\":\"order.created\",\"created_at\":\"2020-10-01T12:00:00Z\",\"data\":{\"id\":1,\"lastUpdated\":\"0001-01-01T00:00:00Z\",\"subtotal\":null,\"discount\":null,\"tax\":null,\"total\":null},\"sequence\":2}\n", readEvent())
+
+	// the first live event was already replayed, the second one is committed after the replay, the fourth one
+	// doesn't match the filter and the last one is published twice
+	publishEvents(t, broker,
+		testOutboxEvent(t, 4, types.EventOrderCreated, &types.Order{ID: 2}),
+		testOutboxEvent(t, 3, types.EventOrderUpdated, &types.Order{ID: 1}),
+		testOutboxEvent(t, 5, types.EventOrderUpdated, &types.Order{ID: 2}),
+		testOutboxEvent(t, 6, types.EventOrderDeleted, &types.Order{ID: 1}),
+		testOutboxEvent(t, 6, types.EventOrderDeleted, &types.Order{ID: 1}),
+		testOutboxEvent(t, 7, types.EventOrderCreated, &types.Order{ID: 1}),
+	)
+	assert.Equal(t, "id: 3\nevent: order.updated\ndata: {\"id\":\"e3\",\"type\":\"order.updated\",\"created_at\":\"2020-10-01T12:00:00Z\",\"data\":{\"id\":1,\"lastUpdated\":\"0001-01-01T00:00:00Z\",\"subtotal\":null,\"discount\":null,\"tax\":null,\"total\":null},\"sequence\":3}\n", readEvent())
+	assert.Equal(t, "id: 6\nevent: order.deleted\ndata: {\"id\":\"e6\",\"type\":\"order.deleted\",\"created_at\":\"2020-10-01T12:00:00Z\",\"data\":{\"id\":1,\"lastUpdated\":\"0001-01-01T00:00:00Z\",\"subtotal\":null,\"discount\":null,\"tax\":null,\"total\":null},\"sequence\":6}\n", readEvent())
+	assert.Equal(t, "id: 7\nevent: order.created\ndata: {\"id\":\"e7\",\"type\":\"order.created\",\"created_at\":\"2020-10-01T12:00:00Z\",\"data\":{\"id\":1,\"lastUpdated\":\"0001-01-01T00:00:00Z\",\"subtotal\":null,\"discount\":null,\"tax\":null,\"total\":null},\"sequence\":7}\n", readEvent())
+}
+
+// TestStreamArticles ensures that a new stream starts with the next change instead of replaying the change log
+func TestStreamArticles(t *testing.T) {
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	dbClient.EXPECT().Available().Return(time.Duration(0), nil).AnyTimes()
+	dbClient.EXPECT().Session(gomock.Any()).Return(dbClient).AnyTimes()
+	dbClient.EXPECT().LastWrite().Return(time.Time{}).AnyTimes()
+	dbClient.EXPECT().GetLastOutboxEventID().Return(4)
+	dbClient.EXPECT().GetOutboxEventsAfter(gomock.Eq(4), gomock.Eq(replayBatchSize)).Return([]*types.OutboxEvent{})
+
+	broker := outbox.NewMemoryBroker()
+	SetBroker(broker)
+	defer SetBroker(nil)
+	ts := httptest.NewServer(GetRouter(nil, dbClient))
+	defer ts.Close()
+
+	readEvent, closeStream := openStream(t, ts.URL+"/articles/stream", "")
+	defer closeStream()
+	assert.Equal(t, "retry: 3000\n", readEvent())
+
+	publishEvents(t, broker,
+		testOutboxEvent(t, 4, types.EventArticleCreated, &types.Article{ID: 1}),
+		testOutboxEvent(t, 5, types.EventArticleUpdated, &types.Article{ID: 1}),
+	)
+	assert.Equal(t, "id: 5\nevent: article.updated\ndata: {\"id\":\"e5\",\"type\":\"article.updated\",\"created_at\":\"2020-10-01T12:00:00Z\",\"data\":{\"id\":1,\"name\":\"\",\"price\":null},\"sequence\":5}\n", readEvent())
+}
+
+// openStream opens the event stream at url, resumed after the last event id if it isn't empty, and returns a function
+// reading the next event and a function closing the stream
+func openStream(t *testing.T, url string, lastEventID string) (func() string, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if lastEventID != "" {
+		req.Header.Set(lastEventIDHeader, lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if !assert.NoError(t, err) {
+		cancel()
+		t.FailNow()
+	}
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		event := ""
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil || line == "\n" {
+				return event
+			}
+			event += line
+		}
+	}
+	return readEvent, func() {
+		cancel()
+		_ = resp.Body.Close()
+	}
+}
+
+// publishEvents publishes the events of the outbox events to the broker
+func publishEvents(t *testing.T, broker outbox.Broker, outboxEvents ...*types.OutboxEvent) {
+	for _, outboxEvent := range outboxEvents {
+		event, err := outboxEvent.Event()
+		assert.NoError(t, err)
+		body, _ := json.Marshal(event)
+		assert.NoError(t, broker.Publish(context.Background(), event.Type, body))
+	}
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// ListVariants returns the variants of the article from the context
+// @Summary List the variants of an article
+// @Description Get all variants of an article, such as its sizes or flavours
+// @Tags Variants
+// @Produce json
+// @Param id path string true "article id"
+// @Router /articles/{id}/variants [get]
+// @Success 200 {object} types.VariantList
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func ListVariants(w http.ResponseWriter, r *http.Request) {
+	article := r.Context().Value(m.ArticleCtxKey).(*types.Article)
+	if err := render.Render(w, r, &types.VariantList{Items: m.GetDBClient(r.Context()).GetVariants(article.ID)}); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// PutVariant writes a variant of the article from the context to the database
+// @Summary Add a variant of an article to the database
+// @Description PutVariant writes a variant of an article to the database
+// @Description To write a new variant, leave the id empty. To update an existing one, use the id of the variant to be updated
+// @Description SKUs are stored in upper case and must not be used by another variant. The price of the article applies if the price is empty.
+// @Description The stock of a variant is only changed by stock adjustments and orders.
+// @Tags Variants
+// @Accept json
+// @Produce json
+// @Param id path string true "article id"
+// @Param variant body types.Variant true "the variant"
+// @Router /articles/{id}/variants [put]
+// @Success 200 {object} types.Variant
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+// @Failure 409 {object} types.ErrResponse
+func PutVariant(w http.ResponseWriter, r *http.Request) {
+	article := r.Context().Value(m.ArticleCtxKey).(*types.Article)
+	variant := &types.Variant{}
+	if err := render.Bind(r, variant); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+	variant.ArticleID = article.ID
+
+	if err := auditedClient(r.Context()).SetVariant(variant); err != nil {
+		if errors.Is(err, types.ErrSKUTaken) {
+			_ = render.Render(w, r, types.ErrConflict(err))
+			return
+		}
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := render.Render(w, r, variant); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// GetVariant renders the variant from the context
+// @Summary Get a variant of an article
+// @Description GetVariant returns a single variant of an article by id
+// @Tags Variants
+// @Produce json
+// @Param id path string true "article id"
+// @Param variantID path string true "variant id"
+// @Router /articles/{id}/variants/{variantID} [get]
+// @Success 200 {object} types.Variant
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func GetVariant(w http.ResponseWriter, r *http.Request) {
+	variant := r.Context().Value(m.VariantCtxKey).(*types.Variant)
+
+	if err := render.Render(w, r, variant); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// DeleteVariant deletes the variant from the context
+// @Summary Delete a variant of an article
+// @Description DeleteVariant deletes a single variant of an article by id. Orders keep referencing it.
+// @Tags Variants
+// @Param id path string true "article id"
+// @Param variantID path string true "variant id"
+// @Router /articles/{id}/variants/{variantID} [delete]
+// @Success 204
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	variant := r.Context().Value(m.VariantCtxKey).(*types.Variant)
+
+	if err := auditedClient(r.Context()).DeleteVariant(variant.ArticleID, variant.ID); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	render.NoContent(w, r)
+}
+
+// GetVariantStock renders the stock of the variant from the context
+// @Summary Get the stock of a variant of an article
+// @Description GetVariantStock returns the available and reserved quantity of a variant
+// @Tags Inventory
+// @Produce json
+// @Param id path string true "article id"
+// @Param variantID path string true "variant id"
+// @Router /articles/{id}/variants/{variantID}/stock [get]
+// @Success 200 {object} types.Stock
+// @Failure 400 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func GetVariantStock(w http.ResponseWriter, r *http.Request) {
+	variant := r.Context().Value(m.VariantCtxKey).(*types.Variant)
+
+	if err := render.Render(w, r, variant.StockLevel()); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// GetVariantBySKU renders the variant with the SKU together with its article
+// @Summary Look up a variant by its SKU
+// @Description GetVariantBySKU returns the variant with the SKU in any case, including the article it is a variant of
+// @Tags Variants
+// @Produce json
+// @Param sku path string true "stock keeping unit"
+// @Router /skus/{sku} [get]
+// @Success 200 {object} types.Variant
+// @Failure 404 {object} types.ErrResponse
+func GetVariantBySKU(w http.ResponseWriter, r *http.Request) {
+	variant := m.GetDBClient(r.Context()).GetVariantBySKU(chi.URLParam(r, "sku"))
+	if variant == nil {
+		_ = render.Render(w, r, types.ErrNotFound())
+		return
+	}
+
+	if err := render.Render(w, r, variant); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
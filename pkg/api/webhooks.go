@@ -0,0 +1,155 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+	"github.com/jonnylangefeld/go-api/pkg/webhook"
+)
+
+// GetWebhook renders the webhook from the context
+// @Summary Get webhook by id
+// @Description GetWebhook returns a single webhook by id. The secret is never returned.
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "webhook id"
+// @Router /webhooks/{id} [get]
+// @Success 200 {object} types.Webhook
+// @Failure 400 {object} types.ErrResponse
+// @Failure 401 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func GetWebhook(w http.ResponseWriter, r *http.Request) {
+	wh := r.Context().Value(m.WebhookCtxKey).(*types.Webhook)
+
+	if err := render.Render(w, r, wh); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// PutWebhook writes a webhook to the database
+// @Summary Subscribe a webhook to events
+// @Description PutWebhook writes a webhook to the database
+// @Description To write a new webhook, leave the id empty. To update an existing one, use the id of the webhook to be updated
+// @Description Every delivery is signed with the secret, see the X-Webhook-Signature header
+// @Tags Webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param webhook body types.Webhook true "the webhook"
+// @Router /webhooks [put]
+// @Success 200 {object} types.Webhook
+// @Failure 400 {object} types.ErrResponse
+// @Failure 401 {object} types.ErrResponse
+func PutWebhook(w http.ResponseWriter, r *http.Request) {
+	wh := &types.Webhook{}
+	if err := render.Bind(r, wh); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := auditedClient(r.Context()).SetWebhook(wh); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := render.Render(w, r, wh); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// DeleteWebhook deletes the webhook from the context
+// @Summary Delete webhook by id
+// @Description DeleteWebhook deletes a single webhook and its deliveries by id
+// @Tags Webhooks
+// @Security BearerAuth
+// @Param id path string true "webhook id"
+// @Router /webhooks/{id} [delete]
+// @Success 204
+// @Failure 400 {object} types.ErrResponse
+// @Failure 401 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	wh := r.Context().Value(m.WebhookCtxKey).(*types.Webhook)
+
+	if err := auditedClient(r.Context()).DeleteWebhook(wh.ID); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	render.NoContent(w, r)
+}
+
+// ListWebhooks returns all webhooks in the database
+// @Summary List all webhooks
+// @Description Get all webhooks stored in the database
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param page_id query string false "id of the page to be retrieved"
+// @Router /webhooks [get]
+// @Success 200 {object} types.WebhookList
+// @Failure 400 {object} types.ErrResponse
+// @Failure 401 {object} types.ErrResponse
+func ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	pageID := r.Context().Value(m.PageIDKey)
+	if err := render.Render(w, r, m.GetDBClient(r.Context()).GetWebhooks(pageID.(int))); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// ListWebhookDeliveries returns all deliveries of the webhook from the context
+// @Summary List all deliveries of a webhook
+// @Description Get all deliveries of a webhook, newest first
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "webhook id"
+// @Param page_id query string false "id of the page to be retrieved"
+// @Router /webhooks/{id}/deliveries [get]
+// @Success 200 {object} types.WebhookDeliveryList
+// @Failure 400 {object} types.ErrResponse
+// @Failure 401 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	wh := r.Context().Value(m.WebhookCtxKey).(*types.Webhook)
+	pageID := r.Context().Value(m.PageIDKey)
+	if err := render.Render(w, r, m.GetDBClient(r.Context()).GetWebhookDeliveries(wh.ID, pageID.(int))); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
+
+// RedeliverWebhookDelivery schedules the delivery from the context to be sent again
+// @Summary Redeliver a webhook delivery
+// @Description RedeliverWebhookDelivery resets a delivery to pending, including dead deliveries, so that it is sent again
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "webhook id"
+// @Param deliveryID path string true "delivery id"
+// @Router /webhooks/{id}/deliveries/{deliveryID}/redeliver [post]
+// @Success 200 {object} types.WebhookDelivery
+// @Failure 400 {object} types.ErrResponse
+// @Failure 401 {object} types.ErrResponse
+// @Failure 404 {object} types.ErrResponse
+func RedeliverWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	delivery := r.Context().Value(m.WebhookDeliveryCtxKey).(*types.WebhookDelivery)
+
+	webhook.Redeliver(delivery)
+	if err := m.GetDBClient(r.Context()).SetWebhookDelivery(delivery); err != nil {
+		_ = render.Render(w, r, types.ErrInvalidRequest(err))
+		return
+	}
+
+	if err := render.Render(w, r, delivery); err != nil {
+		_ = render.Render(w, r, types.ErrRender(err))
+		return
+	}
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/render"
+
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+	"github.com/jonnylangefeld/go-api/pkg/websocket"
+)
+
+// WebSocket upgrades the connection and hands it to the realtime hub
+// @Summary Subscribe to changes over a WebSocket
+// @Description WebSocket upgrades the connection to the WebSocket protocol. The token is checked during the handshake.
+// @Description Clients send realtime.ClientMessage objects to subscribe to the orders or articles topic, optionally restricted to a single resource or a price range of articles.
+// @Description The server answers with realtime.ServerMessage objects and pings the client periodically.
+// @Tags Realtime
+// @Security BearerAuth
+// @Param access_token query string false "api token, if it can't be sent as bearer token"
+// @Router /ws [get]
+// @Success 101 {object} realtime.ServerMessage
+// @Failure 400 {object} types.ErrResponse
+// @Failure 401 {object} types.ErrResponse
+// @Failure 503 {object} types.ErrResponse
+func WebSocket(w http.ResponseWriter, r *http.Request) {
+	if RealtimeHub == nil {
+		_ = render.Render(w, r, types.ErrUnavailable(errors.New("no realtime hub configured")))
+		return
+	}
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		return
+	}
+	RealtimeHub.Serve(conn, r.Context().Value(m.ActorCtxKey).(string))
+}
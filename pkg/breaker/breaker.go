@@ -0,0 +1,109 @@
+// Package breaker stops calls to a dependency that is down, so that requests fail fast instead of piling up
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned while the circuit is open
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a circuit breaker
+type State int
+
+const (
+	// Closed lets all calls through
+	Closed State = iota
+	// Open rejects all calls until the cooldown passed
+	Open
+	// HalfOpen lets a single probe through to decide whether to close or to open the circuit again
+	HalfOpen
+)
+
+// Breaker opens the circuit after a number of consecutive failures. Once the cooldown passed, the next caller
+// probes the dependency, which closes the circuit if it succeeds and opens it again if it fails.
+type Breaker struct {
+	// Threshold is the number of consecutive failures that open the circuit
+	Threshold int
+	// Cooldown is the time the circuit stays open before it is probed
+	Cooldown time.Duration
+
+	probe func() error
+	now   func() time.Time
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openUntil time.Time
+}
+
+// New returns a circuit breaker that probes the dependency with probe
+func New(threshold int, cooldown time.Duration, probe func() error) *Breaker {
+	return &Breaker{
+		Threshold: threshold,
+		Cooldown:  cooldown,
+		probe:     probe,
+		now:       time.Now,
+	}
+}
+
+// Allow returns nil if calls may be made and ErrOpen otherwise, together with the time until the next probe.
+// The caller that finds the cooldown passed runs the probe.
+func (b *Breaker) Allow() (time.Duration, error) {
+	b.mu.Lock()
+	switch b.state {
+	case Closed:
+		b.mu.Unlock()
+		return 0, nil
+	case HalfOpen:
+		b.mu.Unlock()
+		return b.Cooldown, ErrOpen
+	}
+	if wait := b.openUntil.Sub(b.now()); wait > 0 {
+		b.mu.Unlock()
+		return wait, ErrOpen
+	}
+	b.state = HalfOpen
+	b.mu.Unlock()
+
+	if err := b.probe(); err != nil {
+		b.mu.Lock()
+		b.open()
+		b.mu.Unlock()
+		return b.Cooldown, ErrOpen
+	}
+	b.Success()
+	return 0, nil
+}
+
+// Success records a successful call, which resets the consecutive failures
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = Closed
+	b.failures = 0
+}
+
+// Failure records a failed call, which opens the circuit once the threshold is reached
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == Closed && b.failures >= b.Threshold {
+		b.open()
+	}
+}
+
+// State returns the current state of the circuit
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) open() {
+	b.state = Open
+	b.openUntil = b.now().Add(b.Cooldown)
+}
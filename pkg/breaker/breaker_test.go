@@ -0,0 +1,48 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	probeErr := errors.New("connection refused")
+	b := New(2, 10*time.Second, func() error {
+		return probeErr
+	})
+	b.now = func() time.Time {
+		return now
+	}
+
+	// a success in between resets the consecutive failures
+	b.Failure()
+	b.Success()
+	b.Failure()
+	_, err := b.Allow()
+	assert.NoError(t, err)
+
+	b.Failure()
+	assert.Equal(t, Open, b.State())
+	wait, err := b.Allow()
+	assert.Equal(t, ErrOpen, err)
+	assert.Equal(t, 10*time.Second, wait)
+
+	// a failed probe opens the circuit again
+	now = now.Add(10 * time.Second)
+	_, err = b.Allow()
+	assert.Equal(t, ErrOpen, err)
+	assert.Equal(t, Open, b.State())
+	wait, _ = b.Allow()
+	assert.Equal(t, 10*time.Second, wait)
+
+	// a successful probe closes it
+	now = now.Add(10 * time.Second)
+	probeErr = nil
+	_, err = b.Allow()
+	assert.NoError(t, err)
+	assert.Equal(t, Closed, b.State())
+}
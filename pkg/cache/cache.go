@@ -0,0 +1,425 @@
+// Package cache caches database lookups in front of db.ClientInterface
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/jonnylangefeld/go-api/pkg/db"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// ErrMiss is returned by remote caches for keys they don't hold
+var ErrMiss = errors.New("cache miss")
+
+// Remote is a cache shared between instances of the api, such as redis or memcached.
+// Values are opaque bytes that expire after their ttl.
+type Remote interface {
+	// Get returns the value of the key or ErrMiss
+	Get(key string) ([]byte, error)
+	// Set stores the value of the key for the ttl, a ttl of 0 keeps it until it is deleted
+	Set(key string, value []byte, ttl time.Duration) error
+	// Delete removes the keys, missing keys aren't an error
+	Delete(keys ...string) error
+}
+
+// Client is a read-through cache of articles and orders implementing db.ClientInterface.
+// Lookups by id are answered from an in-process LRU cache, then from the remote cache if there is one, and only
+// then from the database. Concurrent lookups of a missing entry share a single database query.
+// Writes through the client invalidate the entries they change, in process and in the remote cache. Other instances
+// of the api only notice changes once the entries of their in-process caches expire, so the ttl bounds how stale
+// their reads can be.
+type Client struct {
+	db.ClientInterface
+	// TTL is the time entries are cached for
+	TTL time.Duration
+	// Remote is an optional cache shared with other instances
+	Remote Remote
+
+	*state
+}
+
+// epochKey is the key of the epoch of the remote cache, which is part of the keys of all its entries, so that
+// replacing it invalidates the entries of all instances at once
+const epochKey = "go-api:epoch"
+
+// state holds the entries and statistics of a cache, which are shared with the audited clients derived from it
+type state struct {
+	// writes counts invalidations, loads that overlap with one aren't cached because they may be stale
+	writes int64
+
+	hits, misses, coalesced, remoteErrors int64
+
+	local *lru
+	group group
+	now   func() time.Time
+}
+
+// New returns a cache of at most size entries in front of the database client
+func New(client db.ClientInterface, size int, ttl time.Duration) *Client {
+	return &Client{
+		ClientInterface: client,
+		TTL:             ttl,
+		state: &state{
+			local: newLRU(size),
+			now:   time.Now,
+		},
+	}
+}
+
+// Audited implements db.ClientInterface. The audited client shares the entries of the cache, so that its writes
+// invalidate them.
+func (c *Client) Audited(actor, requestID string) db.ClientInterface {
+	audited := *c
+	audited.ClientInterface = c.ClientInterface.Audited(actor, requestID)
+	return &audited
+}
+
+// Session implements db.ClientInterface. The client of the session shares the entries of the cache, so that its
+// writes invalidate them.
+func (c *Client) Session(lastWrite time.Time) db.ClientInterface {
+	session := *c
+	session.ClientInterface = c.ClientInterface.Session(lastWrite)
+	return &session
+}
+
+// Select implements db.ClientInterface. Reads of selected fields bypass the cache, because the cache only holds
+// whole resources.
+func (c *Client) Select(fields []string) db.ClientInterface {
+	return c.ClientInterface.Select(fields)
+}
+
+// Stats returns the statistics of the cache
+func (c *Client) Stats() *types.CacheStats {
+	stats := &types.CacheStats{
+		Hits:         atomic.LoadInt64(&c.hits),
+		Misses:       atomic.LoadInt64(&c.misses),
+		Coalesced:    atomic.LoadInt64(&c.coalesced),
+		RemoteErrors: atomic.LoadInt64(&c.remoteErrors),
+	}
+	stats.Entries, stats.Evictions = c.local.len()
+	if lookups := stats.Hits + stats.Misses + stats.Coalesced; lookups > 0 {
+		stats.HitRatio = float64(stats.Hits+stats.Coalesced) / float64(lookups)
+	}
+	return stats
+}
+
+// Transaction implements db.ClientInterface. Transactions bypass the cache, because their changes aren't visible
+// to anyone else until they are committed, and purge it once they are committed.
+func (c *Client) Transaction(fn func(client db.ClientInterface) error) error {
+	err := c.ClientInterface.Transaction(fn)
+	if err == nil {
+		c.purge()
+	}
+	return err
+}
+
+// GetArticleByID implements db.ClientInterface
+func (c *Client) GetArticleByID(id int) *types.Article {
+	article := &types.Article{}
+	if !c.cached(c.key("article", id), article, func() (interface{}, bool) {
+		article := c.ClientInterface.GetArticleByID(id)
+		return article, article.ID != 0
+	}) {
+		return c.ClientInterface.GetArticleByID(id)
+	}
+	return article
+}
+
+// GetOrderByID implements db.ClientInterface
+func (c *Client) GetOrderByID(id int) *types.Order {
+	order := &types.Order{}
+	if !c.cached(c.key("order", id), order, func() (interface{}, bool) {
+		order := c.ClientInterface.GetOrderByID(id)
+		return order, order.ID != 0
+	}) {
+		return c.ClientInterface.GetOrderByID(id)
+	}
+	return order
+}
+
+// SetArticle implements db.ClientInterface
+func (c *Client) SetArticle(article *types.Article) error {
+	err := c.ClientInterface.SetArticle(article)
+	c.invalidateArticles(article.ID)
+	return err
+}
+
+// SetArticles implements db.ClientInterface
+func (c *Client) SetArticles(articles []*types.Article) error {
+	err := c.ClientInterface.SetArticles(articles)
+	ids := make([]int, 0, len(articles))
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+	}
+	c.invalidateArticles(ids...)
+	return err
+}
+
+// DeleteArticle implements db.ClientInterface
+func (c *Client) DeleteArticle(id int) error {
+	err := c.ClientInterface.DeleteArticle(id)
+	c.invalidateArticles(id)
+	return err
+}
+
+// AdjustStock implements db.ClientInterface
+func (c *Client) AdjustStock(adjustment *types.StockAdjustment) error {
+	err := c.ClientInterface.AdjustStock(adjustment)
+	c.invalidateArticles(adjustment.ArticleID)
+	return err
+}
+
+// SchedulePrice implements db.ClientInterface
+func (c *Client) SchedulePrice(price *types.ArticlePrice) error {
+	err := c.ClientInterface.SchedulePrice(price)
+	c.invalidateArticles(price.ArticleID)
+	return err
+}
+
+// ApplyScheduledPrices implements db.ClientInterface
+func (c *Client) ApplyScheduledPrices(now time.Time) (int, error) {
+	changed, err := c.ClientInterface.ApplyScheduledPrices(now)
+	if changed > 0 {
+		c.purge()
+	}
+	return changed, err
+}
+
+// AddArticleImage implements db.ClientInterface
+func (c *Client) AddArticleImage(image *types.ArticleImage) error {
+	err := c.ClientInterface.AddArticleImage(image)
+	c.invalidateArticles(image.ArticleID)
+	return err
+}
+
+// DeleteArticleImage implements db.ClientInterface
+func (c *Client) DeleteArticleImage(articleID, id int) error {
+	err := c.ClientInterface.DeleteArticleImage(articleID, id)
+	c.invalidateArticles(articleID)
+	return err
+}
+
+// SetVariant implements db.ClientInterface
+func (c *Client) SetVariant(variant *types.Variant) error {
+	err := c.ClientInterface.SetVariant(variant)
+	c.invalidateArticles(variant.ArticleID)
+	return err
+}
+
+// DeleteVariant implements db.ClientInterface
+func (c *Client) DeleteVariant(articleID, id int) error {
+	err := c.ClientInterface.DeleteVariant(articleID, id)
+	c.invalidateArticles(articleID)
+	return err
+}
+
+// DeleteCategory implements db.ClientInterface
+func (c *Client) DeleteCategory(id int) error {
+	err := c.ClientInterface.DeleteCategory(id)
+	c.purge()
+	return err
+}
+
+// SetTag implements db.ClientInterface
+func (c *Client) SetTag(tag *types.Tag) error {
+	err := c.ClientInterface.SetTag(tag)
+	c.purge()
+	return err
+}
+
+// DeleteTag implements db.ClientInterface
+func (c *Client) DeleteTag(id int) error {
+	err := c.ClientInterface.DeleteTag(id)
+	c.purge()
+	return err
+}
+
+// SetOrder implements db.ClientInterface. Orders change the stock of their articles, so the articles of the
+// previous and the new items are invalidated too. The previous items are read through the cache, which usually
+// holds the order already.
+func (c *Client) SetOrder(order *types.Order) error {
+	var previous *types.Order
+	if order.ID != 0 {
+		previous = c.GetOrderByID(order.ID)
+	}
+	err := c.ClientInterface.SetOrder(order)
+	c.invalidateOrder(previous)
+	c.invalidateOrder(order)
+	return err
+}
+
+// SetOrders implements db.ClientInterface
+func (c *Client) SetOrders(orders []*types.Order) error {
+	ids := make([]int, 0, len(orders))
+	for _, order := range orders {
+		if order.ID != 0 {
+			ids = append(ids, order.ID)
+		}
+	}
+	previous := c.ClientInterface.GetOrdersByIDs(ids)
+	err := c.ClientInterface.SetOrders(orders)
+	for _, order := range previous {
+		c.invalidateOrder(order)
+	}
+	for _, order := range orders {
+		c.invalidateOrder(order)
+	}
+	return err
+}
+
+// DeleteOrder implements db.ClientInterface
+func (c *Client) DeleteOrder(id int) error {
+	order := c.GetOrderByID(id)
+	err := c.ClientInterface.DeleteOrder(id)
+	c.invalidateOrder(order)
+	return err
+}
+
+// cached decodes the entry of the key into value, loading and storing it first if it isn't cached. Load reports
+// whether the entry exists, missing entries aren't stored, so that they are found as soon as they are created.
+// It returns false if the entry can't be encoded, in which case the caller has to query the database itself.
+func (c *Client) cached(key string, value interface{}, load func() (interface{}, bool)) bool {
+	if data, ok := c.local.get(key, c.now()); ok && decode(data, value) == nil {
+		atomic.AddInt64(&c.hits, 1)
+		return true
+	}
+
+	writes := atomic.LoadInt64(&c.writes)
+	data, err, shared := c.group.do(key, func() ([]byte, error) {
+		remoteKey := c.remoteKey(key)
+		if remoteKey != "" {
+			data, err := c.Remote.Get(remoteKey)
+			if err == nil {
+				atomic.AddInt64(&c.hits, 1)
+				c.store(key, "", data, writes)
+				return data, nil
+			}
+			if !errors.Is(err, ErrMiss) {
+				atomic.AddInt64(&c.remoteErrors, 1)
+			}
+		}
+
+		atomic.AddInt64(&c.misses, 1)
+		loaded, found := load()
+		data, err := encode(loaded)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			c.store(key, remoteKey, data, writes)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return false
+	}
+	if shared {
+		atomic.AddInt64(&c.coalesced, 1)
+	}
+	return decode(data, value) == nil
+}
+
+// store caches the loaded data unless an invalidation happened since the load started. The data is stored in the
+// remote cache too unless remoteKey is empty.
+func (c *Client) store(key, remoteKey string, data []byte, writes int64) {
+	if atomic.LoadInt64(&c.writes) != writes {
+		return
+	}
+	c.local.set(key, data, c.now(), c.TTL)
+	if remoteKey != "" {
+		if err := c.Remote.Set(remoteKey, data, c.TTL); err != nil {
+			atomic.AddInt64(&c.remoteErrors, 1)
+		}
+	}
+}
+
+// invalidateArticles removes the articles from the cache
+func (c *Client) invalidateArticles(ids ...int) {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, c.key("article", id))
+	}
+	c.invalidate(keys...)
+}
+
+// invalidateOrder removes the order and its articles from the cache
+func (c *Client) invalidateOrder(order *types.Order) {
+	if order == nil || order.ID == 0 {
+		return
+	}
+	keys := []string{c.key("order", order.ID)}
+	for _, item := range order.Items {
+		keys = append(keys, c.key("article", item.ArticleID))
+	}
+	c.invalidate(keys...)
+}
+
+func (c *Client) invalidate(keys ...string) {
+	atomic.AddInt64(&c.writes, 1)
+	c.local.delete(keys...)
+	if c.Remote == nil {
+		return
+	}
+	remoteKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if remoteKey := c.remoteKey(key); remoteKey != "" {
+			remoteKeys = append(remoteKeys, remoteKey)
+		}
+	}
+	if len(remoteKeys) == 0 {
+		return
+	}
+	if err := c.Remote.Delete(remoteKeys...); err != nil {
+		atomic.AddInt64(&c.remoteErrors, 1)
+	}
+}
+
+// purge invalidates all entries, for changes that affect an unknown number of articles. The entries of the remote
+// cache are invalidated for all instances by replacing its epoch, which outlives restarts of the instances.
+func (c *Client) purge() {
+	atomic.AddInt64(&c.writes, 1)
+	c.local.purge()
+	if c.Remote != nil {
+		epoch := strconv.FormatInt(c.now().UnixNano(), 36)
+		if err := c.Remote.Set(epochKey, []byte(epoch), 0); err != nil {
+			atomic.AddInt64(&c.remoteErrors, 1)
+		}
+	}
+}
+
+func (c *Client) key(kind string, id int) string {
+	return fmt.Sprintf("%s:%d", kind, id)
+}
+
+// remoteKey returns the key of the entry in the remote cache, which contains the current epoch of the remote cache.
+// It returns an empty key if there is no remote cache or its epoch can't be read.
+func (c *Client) remoteKey(key string) string {
+	if c.Remote == nil {
+		return ""
+	}
+	epoch, err := c.Remote.Get(epochKey)
+	if errors.Is(err, ErrMiss) {
+		epoch = []byte("0")
+	} else if err != nil {
+		atomic.AddInt64(&c.remoteErrors, 1)
+		return ""
+	}
+	return fmt.Sprintf("go-api:%s:%s", epoch, key)
+}
+
+// encode serializes entries with gob rather than json, because json omits fields such as the stock of articles
+func encode(value interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := gob.NewEncoder(buf).Encode(value)
+	return buf.Bytes(), err
+}
+
+func decode(data []byte, value interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
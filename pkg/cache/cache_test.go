@@ -0,0 +1,244 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonnylangefeld/go-api/pkg/api/mocks"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+func TestClient_GetArticleByID(t *testing.T) {
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	c := New(dbClient, 10, time.Minute)
+	stock := 3
+	article := &types.Article{ID: 1, Name: "Skittles", Price: types.NewMoney(199, "USD"), Stock: &stock}
+
+	// the second lookup is answered from the cache, including the fields hidden from json
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(article).Times(1)
+	assert.Equal(t, article, c.GetArticleByID(1))
+	assert.Equal(t, article, c.GetArticleByID(1))
+
+	// writes invalidate the article
+	dbClient.EXPECT().SetArticle(gomock.Any()).Return(nil)
+	assert.NoError(t, c.SetArticle(&types.Article{ID: 1, Name: "Sour Skittles"}))
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&types.Article{ID: 1, Name: "Sour Skittles"}).Times(1)
+	assert.Equal(t, "Sour Skittles", c.GetArticleByID(1).Name)
+	assert.Equal(t, "Sour Skittles", c.GetArticleByID(1).Name)
+
+	assert.Equal(t, &types.CacheStats{Hits: 2, Misses: 2, HitRatio: 0.5, Entries: 1}, c.Stats())
+}
+
+func TestClient_Audited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dbClient := mocks.NewMockClientInterface(ctrl)
+	auditedClient := mocks.NewMockClientInterface(ctrl)
+	c := New(dbClient, 10, time.Minute)
+
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&types.Article{ID: 1, Name: "Skittles"}).Times(1)
+	c.GetArticleByID(1)
+
+	// audited clients write through the audited database client and share the entries of the cache
+	dbClient.EXPECT().Audited(gomock.Eq("backoffice"), gomock.Eq("request")).Return(auditedClient)
+	auditedClient.EXPECT().SetArticle(gomock.Any()).Return(nil)
+	assert.NoError(t, c.Audited("backoffice", "request").SetArticle(&types.Article{ID: 1, Name: "Sour Skittles"}))
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&types.Article{ID: 1, Name: "Sour Skittles"}).Times(1)
+	assert.Equal(t, "Sour Skittles", c.GetArticleByID(1).Name)
+}
+
+func TestClient_SetOrder(t *testing.T) {
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	c := New(dbClient, 10, time.Minute)
+
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&types.Article{ID: 1}).Times(2)
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(2)).Return(&types.Article{ID: 2}).Times(2)
+	dbClient.EXPECT().GetOrderByID(gomock.Eq(1)).Return(&types.Order{ID: 1, Items: []*types.OrderItem{{ArticleID: 1, Quantity: 1}}}).Times(2)
+	c.GetArticleByID(1)
+	c.GetArticleByID(2)
+	c.GetOrderByID(1)
+
+	// orders reserve stock, so the articles of the previous and the new items are invalidated. The previous items
+	// are read from the cache.
+	dbClient.EXPECT().SetOrder(gomock.Any()).Return(nil)
+	assert.NoError(t, c.SetOrder(&types.Order{ID: 1, Items: []*types.OrderItem{{ArticleID: 2, Quantity: 1}}}))
+	c.GetArticleByID(1)
+	c.GetArticleByID(2)
+	c.GetOrderByID(1)
+	c.GetOrderByID(1)
+}
+
+func TestClient_DeleteVariant(t *testing.T) {
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	c := New(dbClient, 10, time.Minute)
+
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&types.Article{ID: 1}).Times(2)
+	c.GetArticleByID(1)
+
+	// the article of the variant and of the image is invalidated without reading them first
+	dbClient.EXPECT().DeleteVariant(gomock.Eq(1), gomock.Eq(2)).Return(nil)
+	assert.NoError(t, c.DeleteVariant(1, 2))
+	c.GetArticleByID(1)
+	dbClient.EXPECT().DeleteArticleImage(gomock.Eq(1), gomock.Eq(3)).Return(nil)
+	assert.NoError(t, c.DeleteArticleImage(1, 3))
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&types.Article{ID: 1})
+	c.GetArticleByID(1)
+}
+
+func TestClient_Missing(t *testing.T) {
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	c := New(dbClient, 10, time.Minute)
+
+	// missing articles aren't cached, so that they are found once they are created by another instance
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&types.Article{}).Times(2)
+	assert.Equal(t, 0, c.GetArticleByID(1).ID)
+	assert.Equal(t, 0, c.GetArticleByID(1).ID)
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&types.Article{ID: 1})
+	assert.Equal(t, 1, c.GetArticleByID(1).ID)
+	assert.Equal(t, 1, c.GetArticleByID(1).ID)
+	assert.Equal(t, 1, c.Stats().Entries)
+}
+
+func TestClient_SetOrders(t *testing.T) {
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	c := New(dbClient, 10, time.Minute)
+
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&types.Article{ID: 1}).Times(2)
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(2)).Return(&types.Article{ID: 2}).Times(2)
+	c.GetArticleByID(1)
+	c.GetArticleByID(2)
+
+	// the articles of the previous items of all orders and of their new items are invalidated
+	dbClient.EXPECT().GetOrdersByIDs(gomock.Eq([]int{1})).Return([]*types.Order{{ID: 1, Items: []*types.OrderItem{{ArticleID: 1, Quantity: 1}}}})
+	dbClient.EXPECT().SetOrders(gomock.Any()).Return(nil)
+	assert.NoError(t, c.SetOrders([]*types.Order{
+		{ID: 1, Items: []*types.OrderItem{{ArticleID: 2, Quantity: 1}}},
+		{Items: []*types.OrderItem{{ArticleID: 2, Quantity: 1}}},
+	}))
+	c.GetArticleByID(1)
+	c.GetArticleByID(2)
+}
+
+func TestClient_Stampede(t *testing.T) {
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	c := New(dbClient, 10, time.Minute)
+
+	// concurrent lookups of a missing article share a single query
+	release := make(chan struct{})
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).DoAndReturn(func(int) *types.Article {
+		<-release
+		return &types.Article{ID: 1}
+	}).Times(1)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, 1, c.GetArticleByID(1).ID)
+		}()
+	}
+	for c.group.waiting() < 10 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	stats := c.Stats()
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, int64(9), stats.Coalesced)
+}
+
+func TestClient_Remote(t *testing.T) {
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	remote := &memoryRemote{values: map[string][]byte{}}
+	first, second := New(dbClient, 10, time.Minute), New(dbClient, 10, time.Minute)
+	first.Remote, second.Remote = remote, remote
+
+	// entries loaded by one instance are shared with the others
+	dbClient.EXPECT().GetOrderByID(gomock.Eq(1)).Return(&types.Order{ID: 1, Region: "US-CA"}).Times(1)
+	assert.Equal(t, "US-CA", first.GetOrderByID(1).Region)
+	assert.Equal(t, "US-CA", second.GetOrderByID(1).Region)
+	assert.Equal(t, int64(1), second.Stats().Hits)
+
+	dbClient.EXPECT().DeleteOrder(gomock.Eq(1)).Return(nil)
+	assert.NoError(t, first.DeleteOrder(1))
+	assert.Empty(t, remote.values)
+}
+
+func TestClient_RemotePurge(t *testing.T) {
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	remote := &memoryRemote{values: map[string][]byte{}}
+	first, second := New(dbClient, 10, time.Minute), New(dbClient, 10, time.Minute)
+	first.Remote, second.Remote = remote, remote
+
+	dbClient.EXPECT().GetOrderByID(gomock.Eq(1)).Return(&types.Order{ID: 1, Region: "US-CA"})
+	assert.Equal(t, "US-CA", first.GetOrderByID(1).Region)
+
+	// a purge by one instance invalidates the remote entries of all instances, also of instances started later
+	dbClient.EXPECT().SetTag(gomock.Any()).Return(nil)
+	assert.NoError(t, second.SetTag(&types.Tag{Name: "vegan"}))
+	restarted := New(dbClient, 10, time.Minute)
+	restarted.Remote = remote
+	dbClient.EXPECT().GetOrderByID(gomock.Eq(1)).Return(&types.Order{ID: 1, Region: "US-NY"})
+	assert.Equal(t, "US-NY", restarted.GetOrderByID(1).Region)
+	assert.Equal(t, "US-NY", second.GetOrderByID(1).Region)
+}
+
+func TestLRU(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	l := newLRU(2)
+	l.set("a", []byte("a"), now, time.Minute)
+	l.set("b", []byte("b"), now, time.Minute)
+	_, ok := l.get("a", now)
+	assert.True(t, ok)
+
+	// the least recently used entry is evicted
+	l.set("c", []byte("c"), now, time.Minute)
+	_, ok = l.get("b", now)
+	assert.False(t, ok)
+	entries, evictions := l.len()
+	assert.Equal(t, 2, entries)
+	assert.Equal(t, int64(1), evictions)
+
+	// entries expire after their ttl
+	_, ok = l.get("a", now.Add(time.Minute))
+	assert.False(t, ok)
+	value, ok := l.get("c", now.Add(time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, "c", string(value))
+}
+
+// memoryRemote is an in-memory remote cache ignoring ttls
+type memoryRemote struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (m *memoryRemote) Get(key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.values[key]
+	if !ok {
+		return nil, ErrMiss
+	}
+	return value, nil
+}
+
+func (m *memoryRemote) Set(key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+func (m *memoryRemote) Delete(keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.values, key)
+	}
+	return nil
+}
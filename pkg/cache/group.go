@@ -0,0 +1,56 @@
+package cache
+
+import "sync"
+
+// group coalesces concurrent loads of the same key, so that a missing entry is only loaded once
+// no matter how many requests ask for it at the same time
+type group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	wg    sync.WaitGroup
+	value []byte
+	err   error
+	// dups is the number of callers waiting for the result
+	dups int
+}
+
+// do calls fn unless a call for the key is already in flight, in which case it waits for that call and returns
+// its result. shared reports whether the result came from another call.
+func (g *group) do(key string, fn func() ([]byte, error)) (value []byte, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*call{}
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.value, c.err, true
+	}
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	c.value, c.err = fn()
+	c.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	return c.value, c.err, false
+}
+
+// waiting returns the number of callers of calls in flight
+func (g *group) waiting() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	n := 0
+	for _, c := range g.calls {
+		n += c.dups + 1
+	}
+	return n
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru is an in-process cache holding at most size entries. Entries expire after their ttl and the least
+// recently used entry is evicted when a new one doesn't fit.
+type lru struct {
+	size int
+
+	mu        sync.Mutex
+	entries   map[string]*list.Element
+	order     *list.List
+	evictions int64
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func newLRU(size int) *lru {
+	return &lru{size: size, entries: map[string]*list.Element{}, order: list.New()}
+}
+
+// get returns the value of the key unless it is missing or expired
+func (l *lru) get(key string, now time.Time) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if !now.Before(entry.expires) {
+		l.remove(element)
+		return nil, false
+	}
+	l.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// set stores the value of the key until now+ttl
+func (l *lru) set(key string, value []byte, now time.Time, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value, entry.expires = value, now.Add(ttl)
+		l.order.MoveToFront(element)
+		return
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value, expires: now.Add(ttl)})
+	for l.order.Len() > l.size {
+		l.remove(l.order.Back())
+		l.evictions++
+	}
+}
+
+// delete removes the keys
+func (l *lru) delete(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := l.entries[key]; ok {
+			l.remove(element)
+		}
+	}
+}
+
+// purge removes all entries
+func (l *lru) purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = map[string]*list.Element{}
+	l.order.Init()
+}
+
+// len returns the number of entries and evictions
+func (l *lru) len() (int, int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len(), l.evictions
+}
+
+func (l *lru) remove(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.entries, element.Value.(*lruEntry).key)
+}
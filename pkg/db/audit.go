@@ -0,0 +1,142 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+const (
+	// auditActorKey refers to the gorm setting that stores the actor recorded in the audit log
+	auditActorKey = "go-api:audit_actor"
+	// auditRequestIDKey refers to the gorm setting that stores the request id recorded in the audit log
+	auditRequestIDKey = "go-api:audit_request_id"
+)
+
+// Audited returns a client that records the actor and the request id in the audit log of all changes it writes.
+// Changes written by the client itself are recorded with the system actor.
+func (c *Client) Audited(actor, requestID string) ClientInterface {
+	return &Client{
+		Client:        c.Client.Set(auditActorKey, actor).Set(auditRequestIDKey, requestID),
+		Pricing:       c.Pricing,
+		Options:       c.Options,
+		PinDuration:   c.PinDuration,
+		replicas:      c.replicas,
+		session:       c.session,
+		breaker:       c.breaker,
+		origin:        c.root(),
+		inTransaction: c.inTransaction,
+	}
+}
+
+// root returns the client that audited clients were derived from, which holds the state they share
+func (c *Client) root() *Client {
+	if c.origin != nil {
+		return c.origin
+	}
+	return c
+}
+
+// loadPrevious loads the resource with the given id into model as part of the transaction, before it is changed.
+// It returns nil if the resource doesn't exist yet.
+func loadPrevious(tx *gorm.DB, model interface{}, id int) (interface{}, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	if err := tx.Where("id = ?", id).First(model).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return model, nil
+}
+
+// writeAudit records the change of a resource in the audit log as part of the transaction, so that it is only
+// recorded if the change is committed. Before is nil for created and after is nil for deleted resources.
+// Updates that didn't change anything aren't recorded.
+func writeAudit(tx *gorm.DB, resource string, id int, before, after interface{}) error {
+	action := types.AuditUpdated
+	switch {
+	case before == nil:
+		action = types.AuditCreated
+	case after == nil:
+		action = types.AuditDeleted
+	}
+	changes, err := types.NewAuditChanges(before, after)
+	if err != nil {
+		return err
+	}
+	if action == types.AuditUpdated && len(changes) == 0 {
+		return nil
+	}
+
+	entry := &types.AuditEntry{
+		Resource:   resource,
+		ResourceID: id,
+		Action:     action,
+		Actor:      types.AuditSystemActor,
+		Changes:    changes,
+	}
+	if actor, ok := tx.Get(auditActorKey); ok {
+		entry.Actor = actor.(string)
+	}
+	if requestID, ok := tx.Get(auditRequestIDKey); ok {
+		entry.RequestID = requestID.(string)
+	}
+	return tx.Create(entry).Error
+}
+
+// GetAuditEntries returns the entries of the audit log that pass the filter, which may be nil, latest first
+func (c *Client) GetAuditEntries(pageID int, filter *types.AuditFilter) *types.AuditEntryList {
+	entries := &types.AuditEntryList{}
+	query := c.reader().Model(&types.AuditEntry{})
+	if pageID > 0 {
+		query = query.Where("id <= ?", pageID)
+	}
+	if filter != nil {
+		if filter.Resource != "" {
+			query = query.Where("resource = ?", filter.Resource)
+		}
+		if filter.ResourceID != 0 {
+			query = query.Where("resource_id = ?", filter.ResourceID)
+		}
+		if filter.Actor != "" {
+			query = query.Where("actor = ?", filter.Actor)
+		}
+		if filter.From != nil {
+			query = query.Where("created_at >= ?", *filter.From)
+		}
+		if filter.To != nil {
+			query = query.Where("created_at < ?", *filter.To)
+		}
+	}
+	query.Order("id DESC").Limit(pageSize + 1).Find(&entries.Items)
+	if len(entries.Items) == pageSize+1 {
+		entries.NextPageID = entries.Items[len(entries.Items)-1].ID
+		entries.Items = entries.Items[:pageSize]
+	}
+	return entries
+}
+
+// migrateAudit makes the audit log append-only by rejecting updates and deletes of its entries
+func (c *Client) migrateAudit() error {
+	if c.Client.Dialect().GetName() != "postgres" {
+		return nil
+	}
+	for _, statement := range []string{
+		`CREATE OR REPLACE FUNCTION audit_entries_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit entries are append-only';
+		END;
+		$$ LANGUAGE plpgsql`,
+		"DROP TRIGGER IF EXISTS audit_entries_append_only ON audit_entries",
+		`CREATE TRIGGER audit_entries_append_only BEFORE UPDATE OR DELETE ON audit_entries
+		FOR EACH ROW EXECUTE FUNCTION audit_entries_append_only()`,
+	} {
+		if err := c.Client.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// SetArticles writes all articles to the database in a single transaction with a multi-row upsert, which behaves
+// like calling SetArticle for each of them. Each article gets an outbox event and an entry in the audit log.
+// An article must not be given more than once.
+func (c *Client) SetArticles(articles []*types.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+	if err := unique(len(articles), func(i int) int { return articles[i].ID }); err != nil {
+		return fmt.Errorf("article %w", err)
+	}
+
+	return c.transaction(func(tx *gorm.DB) error {
+		previous := map[int]*types.Article{}
+		existing := []*types.Article{}
+		if err := tx.Where("id IN (?)", idsOf(len(articles), func(i int) int { return articles[i].ID })).
+			Find(&existing).Error; err != nil {
+			return err
+		}
+		if err := loadArticleDetails(tx, existing...); err != nil {
+			return err
+		}
+		for _, article := range existing {
+			previous[article.ID] = article
+		}
+
+		if err := allocateIDs(tx, "articles", len(articles), func(i int) *int { return &articles[i].ID }); err != nil {
+			return err
+		}
+		// Existing articles only change their non-empty fields, like updates of a single article
+		rows := make([][]interface{}, 0, len(articles))
+		for _, article := range articles {
+			rows = append(rows, []interface{}{article.ID, article.Name, article.Price.Amount, article.Price.Currency,
+				article.TaxCategory, article.Stock, article.Reserved})
+		}
+		values, vars := valuesList(rows)
+		if err := tx.Exec("INSERT INTO articles (id, name, price_amount, price_currency, tax_category, stock, reserved) "+
+			"VALUES "+values+" ON CONFLICT (id) DO UPDATE SET "+
+			"name = COALESCE(NULLIF(EXCLUDED.name, ''), articles.name), "+
+			"price_amount = COALESCE(NULLIF(EXCLUDED.price_amount, 0), articles.price_amount), "+
+			"price_currency = COALESCE(NULLIF(EXCLUDED.price_currency, ''), articles.price_currency), "+
+			"tax_category = COALESCE(NULLIF(EXCLUDED.tax_category, ''), articles.tax_category), "+
+			"stock = COALESCE(EXCLUDED.stock, articles.stock), "+
+			"reserved = COALESCE(NULLIF(EXCLUDED.reserved, 0), articles.reserved)", vars...).Error; err != nil {
+			return err
+		}
+
+		for _, article := range articles {
+			if err := setTaxonomy(tx, article); err != nil {
+				return err
+			}
+		}
+
+		stored := []*types.Article{}
+		if err := tx.Where("id IN (?)", idsOf(len(articles), func(i int) int { return articles[i].ID })).
+			Find(&stored).Error; err != nil {
+			return err
+		}
+		if err := loadArticleDetails(tx, stored...); err != nil {
+			return err
+		}
+		byID := map[int]*types.Article{}
+		for _, article := range stored {
+			byID[article.ID] = article
+		}
+
+		for _, article := range articles {
+			current := byID[article.ID]
+			article.CategoryIDs, article.Tags, article.Variants, article.Images =
+				current.CategoryIDs, current.Tags, current.Variants, current.Images
+
+			eventType := types.EventArticleCreated
+			var before interface{}
+			price := types.Money{}
+			if p, ok := previous[article.ID]; ok {
+				eventType = types.EventArticleUpdated
+				before = p
+				price = p.Price
+			}
+			if !current.Price.IsZero() && current.Price != price {
+				if err := recordPrice(tx, &types.ArticlePrice{
+					ArticleID:     current.ID,
+					Price:         current.Price,
+					EffectiveFrom: gorm.NowFunc(),
+					Applied:       true,
+				}); err != nil {
+					return err
+				}
+			}
+			if err := writeAudit(tx, "article", current.ID, before, current); err != nil {
+				return err
+			}
+			if err := writeOutbox(tx, eventType, current); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetOrdersByIDs queries all orders with the given ids and their items in a single query per table.
+// Orders that don't exist are omitted from the result.
+func (c *Client) GetOrdersByIDs(ids []int) []*types.Order {
+	orders := []*types.Order{}
+	if len(ids) == 0 {
+		return orders
+	}
+
+	c.preloadItems(c.selectFields(c.reader(), &types.Order{})).Where("id IN (?)", ids).Order("id").Find(&orders)
+
+	return orders
+}
+
+// SetOrders writes all orders and their items to the database in a single transaction with a multi-row upsert per
+// table, which behaves like calling SetOrder for each of them. The items of each order are replaced by the given ones,
+// which can't include items of other orders. The stock is reserved, the coupons are redeemed and the orders are
+// priced one order after another. An order must not be given more than once.
+func (c *Client) SetOrders(orders []*types.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	if err := unique(len(orders), func(i int) int { return orders[i].ID }); err != nil {
+		return fmt.Errorf("order %w", err)
+	}
+
+	return c.transaction(func(tx *gorm.DB) error {
+		customerIDs := []int{}
+		items := []*types.OrderItem{}
+		for _, order := range orders {
+			if order.CustomerID != nil {
+				customerIDs = append(customerIDs, *order.CustomerID)
+			}
+			items = append(items, order.Items...)
+		}
+		if len(customerIDs) > 0 {
+			customers := []int{}
+			if err := tx.Model(&types.Customer{}).Where("id IN (?)", customerIDs).Pluck("id", &customers).Error; err != nil {
+				return err
+			}
+			found := map[int]bool{}
+			for _, id := range customers {
+				found[id] = true
+			}
+			for _, id := range customerIDs {
+				if !found[id] {
+					return fmt.Errorf("customer %d doesn't exist", id)
+				}
+			}
+		}
+		if _, err := resolveVariants(tx, items); err != nil {
+			return err
+		}
+
+		previous := map[int]*types.Order{}
+		existing := []*types.Order{}
+		if err := tx.Preload("Items").Where("id IN (?)", idsOf(len(orders), func(i int) int { return orders[i].ID })).
+			Order("id").Find(&existing).Error; err != nil {
+			return err
+		}
+		for _, order := range existing {
+			previous[order.ID] = order
+			if err := releaseStock(tx, order.ID, order.Items); err != nil {
+				return err
+			}
+		}
+		for _, order := range orders {
+			p, ok := previous[order.ID]
+			if !ok {
+				p = &types.Order{}
+			}
+			if err := replaceItems(tx, p, order.Items); err != nil {
+				return err
+			}
+			// orders are priced at the time they were placed, which is taken from the server and kept by updates
+			order.DateTime = p.DateTime
+			if p.ID == 0 {
+				order.DateTime = gorm.NowFunc()
+			}
+		}
+
+		if err := allocateIDs(tx, "orders", len(orders), func(i int) *int { return &orders[i].ID }); err != nil {
+			return err
+		}
+		rows := make([][]interface{}, 0, len(orders))
+		for _, order := range orders {
+			rows = append(rows, []interface{}{order.ID, order.DateTime, order.CustomerID, order.Region, order.CouponCode})
+		}
+		values, vars := valuesList(rows)
+		// Existing orders only change their non-empty fields, like updates of a single order, and keep the time
+		// they were placed. The totals are calculated when the orders are priced.
+		if err := tx.Exec("INSERT INTO orders (id, date_time, customer_id, region, coupon_code) "+
+			"VALUES "+values+" ON CONFLICT (id) DO UPDATE SET "+
+			"customer_id = COALESCE(EXCLUDED.customer_id, orders.customer_id), "+
+			"region = COALESCE(NULLIF(EXCLUDED.region, ''), orders.region), "+
+			"coupon_code = COALESCE(NULLIF(EXCLUDED.coupon_code, ''), orders.coupon_code)", vars...).Error; err != nil {
+			return err
+		}
+
+		if len(items) > 0 {
+			for _, order := range orders {
+				for _, item := range order.Items {
+					item.OrderID = order.ID
+				}
+			}
+			if err := allocateIDs(tx, "order_items", len(items), func(i int) *int { return &items[i].ID }); err != nil {
+				return err
+			}
+			rows := make([][]interface{}, 0, len(items))
+			for _, item := range items {
+				rows = append(rows, []interface{}{item.ID, item.OrderID, item.ArticleID, item.VariantID, item.Quantity,
+					item.UnitPrice.Amount, item.UnitPrice.Currency})
+			}
+			values, vars := valuesList(rows)
+			// items with an id were checked to be items of their order, so their order doesn't change
+			if err := tx.Exec("INSERT INTO order_items "+
+				"(id, order_id, article_id, variant_id, quantity, unit_price_amount, unit_price_currency) "+
+				"VALUES "+values+" ON CONFLICT (id) DO UPDATE SET "+
+				"article_id = EXCLUDED.article_id, variant_id = EXCLUDED.variant_id, quantity = EXCLUDED.quantity",
+				vars...).Error; err != nil {
+				return err
+			}
+		}
+
+		stored := []*types.Order{}
+		if err := tx.Preload("Items").Where("id IN (?)", idsOf(len(orders), func(i int) int { return orders[i].ID })).
+			Find(&stored).Error; err != nil {
+			return err
+		}
+		byID := map[int]*types.Order{}
+		for _, order := range stored {
+			byID[order.ID] = order
+		}
+
+		for _, order := range orders {
+			current := byID[order.ID]
+			eventType := types.EventOrderCreated
+			var before interface{}
+			coupon := ""
+			if p, ok := previous[order.ID]; ok {
+				eventType = types.EventOrderUpdated
+				before = p
+				coupon = p.CouponCode
+			}
+			if err := reserveStock(tx, current.ID, current.Items); err != nil {
+				return err
+			}
+			if err := redeemCoupon(tx, coupon, current.CouponCode); err != nil {
+				return err
+			}
+			if err := c.priceOrder(tx, current); err != nil {
+				return err
+			}
+			order.Items = current.Items
+			order.CouponCode = current.CouponCode
+			order.Subtotal = current.Subtotal
+			order.Discount = current.Discount
+			order.Tax = current.Tax
+			order.Total = current.Total
+			if err := writeAudit(tx, "order", current.ID, before, current); err != nil {
+				return err
+			}
+			if err := writeOutbox(tx, eventType, current); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// unique returns an error if two of the n rows have the same id. Rows without id are new and always unique.
+func unique(n int, id func(i int) int) error {
+	seen := map[int]bool{}
+	for i := 0; i < n; i++ {
+		if id(i) == 0 {
+			continue
+		}
+		if seen[id(i)] {
+			return fmt.Errorf("%d is given more than once", id(i))
+		}
+		seen[id(i)] = true
+	}
+	return nil
+}
+
+// idsOf returns the ids of the n rows
+func idsOf(n int, id func(i int) int) []int {
+	ids := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		ids = append(ids, id(i))
+	}
+	return ids
+}
+
+// allocateIDs assigns ids from the serial sequence of the table to the rows of the n rows that don't have one yet,
+// so that new rows can be written by the same multi-row insert as existing ones
+func allocateIDs(tx *gorm.DB, table string, n int, id func(i int) *int) error {
+	missing := 0
+	for i := 0; i < n; i++ {
+		if *id(i) == 0 {
+			missing++
+		}
+	}
+	if missing == 0 {
+		return nil
+	}
+
+	rows, err := tx.Raw("SELECT nextval(pg_get_serial_sequence(?, 'id')) FROM generate_series(1, ?)", table, missing).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for i := 0; i < n; i++ {
+		if *id(i) != 0 {
+			continue
+		}
+		if !rows.Next() {
+			return fmt.Errorf("couldn't allocate ids of %s", table)
+		}
+		if err := rows.Scan(id(i)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// valuesList returns the VALUES list of a multi-row insert of the rows together with its variables
+func valuesList(rows [][]interface{}) (string, []interface{}) {
+	tuples := make([]string, 0, len(rows))
+	vars := []interface{}{}
+	for _, row := range rows {
+		tuples = append(tuples, "("+strings.TrimSuffix(strings.Repeat("?, ", len(row)), ", ")+")")
+		vars = append(vars, row...)
+	}
+	return strings.Join(tuples, ", "), vars
+}
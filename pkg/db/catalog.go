@@ -0,0 +1,249 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// categoryTree selects the ids of a category and all of its descendants
+const categoryTree = `WITH RECURSIVE tree(id) AS (
+	SELECT id FROM categories WHERE id = ?
+	UNION SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id
+) SELECT id FROM tree`
+
+// GetCategoryByID queries a category from the database
+func (c *Client) GetCategoryByID(id int) *types.Category {
+	category := &types.Category{}
+	if err := c.reader().Where("id = ?", id).First(category).Error; err != nil {
+		return nil
+	}
+	return category
+}
+
+// SetCategory writes a category to the database.
+// The parent category must exist and must not be the category itself or one of its descendants.
+func (c *Client) SetCategory(category *types.Category) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		if category.ParentID != nil {
+			if !exists(tx, &types.Category{}, *category.ParentID) {
+				return fmt.Errorf("parent category %d doesn't exist", *category.ParentID)
+			}
+			if category.ID != 0 {
+				descendants := 0
+				if err := tx.Raw("SELECT count(*) FROM ("+categoryTree+") AS tree WHERE id = ?",
+					category.ID, *category.ParentID).Row().Scan(&descendants); err != nil {
+					return err
+				}
+				if descendants > 0 {
+					return fmt.Errorf("category %d can't be moved below its own subcategory %d", category.ID, *category.ParentID)
+				}
+			}
+		}
+		before, err := loadPrevious(tx, &types.Category{}, category.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(category).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, "category", category.ID, before, category)
+	})
+}
+
+// DeleteCategory deletes a category and its links to articles from the database.
+// It returns types.ErrCategoryHasChildren if the category still has subcategories.
+func (c *Client) DeleteCategory(id int) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		children := 0
+		if err := tx.Model(&types.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return types.ErrCategoryHasChildren
+		}
+		before, err := loadPrevious(tx, &types.Category{}, id)
+		if err != nil || before == nil {
+			return err
+		}
+		if err := tx.Where("category_id = ?", id).Delete(&types.ArticleCategory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", id).Delete(&types.Category{}).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, "category", id, before, nil)
+	})
+}
+
+// GetCategories returns all categories from the database
+func (c *Client) GetCategories(pageID int) *types.CategoryList {
+	categories := &types.CategoryList{}
+	c.reader().Where("id >= ?", pageID).Order("id").Limit(pageSize + 1).Find(&categories.Items)
+	if len(categories.Items) == pageSize+1 {
+		categories.NextPageID = categories.Items[len(categories.Items)-1].ID
+		categories.Items = categories.Items[:pageSize]
+	}
+	return categories
+}
+
+// GetTagByID queries a tag from the database
+func (c *Client) GetTagByID(id int) *types.Tag {
+	tag := &types.Tag{}
+	if err := c.reader().Where("id = ?", id).First(tag).Error; err != nil {
+		return nil
+	}
+	return tag
+}
+
+// SetTag writes a tag to the database.
+// It returns types.ErrTagTaken if another tag already has the name.
+func (c *Client) SetTag(tag *types.Tag) error {
+	err := c.transaction(func(tx *gorm.DB) error {
+		taken := 0
+		if err := tx.Model(&types.Tag{}).Where("name = ? AND id <> ?", tag.Name, tag.ID).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return types.ErrTagTaken
+		}
+		before, err := loadPrevious(tx, &types.Tag{}, tag.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(tag).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, "tag", tag.ID, before, tag)
+	})
+
+	// concurrent writes of the same name are caught by the unique index
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return types.ErrTagTaken
+	}
+	return err
+}
+
+// DeleteTag deletes a tag and its links to articles from the database
+func (c *Client) DeleteTag(id int) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		before, err := loadPrevious(tx, &types.Tag{}, id)
+		if err != nil || before == nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", id).Delete(&types.ArticleTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", id).Delete(&types.Tag{}).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, "tag", id, before, nil)
+	})
+}
+
+// GetTags returns all tags from the database
+func (c *Client) GetTags(pageID int) *types.TagList {
+	tags := &types.TagList{}
+	c.reader().Where("id >= ?", pageID).Order("id").Limit(pageSize + 1).Find(&tags.Items)
+	if len(tags.Items) == pageSize+1 {
+		tags.NextPageID = tags.Items[len(tags.Items)-1].ID
+		tags.Items = tags.Items[:pageSize]
+	}
+	return tags
+}
+
+// filterTaxonomy restricts the query to articles of the category of the filter or its descendants and to
+// articles with the tag of the filter
+func filterTaxonomy(query *gorm.DB, filter *types.ArticleFilter) *gorm.DB {
+	if filter.CategoryID != nil {
+		query = query.Where("id IN (SELECT article_id FROM article_categories WHERE category_id IN ("+categoryTree+"))",
+			*filter.CategoryID)
+	}
+	if filter.Tag != "" {
+		query = query.Where("id IN (SELECT article_tags.article_id FROM article_tags "+
+			"JOIN tags ON tags.id = article_tags.tag_id WHERE tags.name = ?)", types.NormalizeTag(filter.Tag))
+	}
+	return query
+}
+
+// setTaxonomy replaces the category and tag links of the article as part of the transaction.
+// Links are kept if the categories or tags of the article are nil. Tags that don't exist yet are created.
+func setTaxonomy(tx *gorm.DB, article *types.Article) error {
+	if article.CategoryIDs != nil {
+		unique := map[int]bool{}
+		for _, id := range article.CategoryIDs {
+			unique[id] = true
+		}
+		found := 0
+		if err := tx.Model(&types.Category{}).Where("id IN (?)", article.CategoryIDs).Count(&found).Error; err != nil {
+			return err
+		}
+		if found != len(unique) {
+			return fmt.Errorf("all categories of article %d must exist", article.ID)
+		}
+		if err := tx.Where("article_id = ?", article.ID).Delete(&types.ArticleCategory{}).Error; err != nil {
+			return err
+		}
+		for id := range unique {
+			if err := tx.Create(&types.ArticleCategory{ArticleID: article.ID, CategoryID: id}).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	if article.Tags != nil {
+		if err := tx.Where("article_id = ?", article.ID).Delete(&types.ArticleTag{}).Error; err != nil {
+			return err
+		}
+		for _, name := range types.NormalizeTags(article.Tags) {
+			tag := &types.Tag{}
+			if err := tx.Where(types.Tag{Name: name}).FirstOrCreate(tag).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&types.ArticleTag{ArticleID: article.ID, TagID: tag.ID}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadTaxonomy fills the category ids and tags of the articles with a query per kind of link
+func loadTaxonomy(tx *gorm.DB, articles ...*types.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(articles))
+	byID := map[int]*types.Article{}
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+		byID[article.ID] = article
+	}
+
+	categories := []*types.ArticleCategory{}
+	if err := tx.Where("article_id IN (?)", ids).Order("category_id").Find(&categories).Error; err != nil {
+		return err
+	}
+	for _, link := range categories {
+		article := byID[link.ArticleID]
+		article.CategoryIDs = append(article.CategoryIDs, link.CategoryID)
+	}
+
+	tags := []struct {
+		ArticleID int
+		Name      string
+	}{}
+	if err := tx.Table("article_tags").Select("article_tags.article_id, tags.name").
+		Joins("JOIN tags ON tags.id = article_tags.tag_id").
+		Where("article_tags.article_id IN (?)", ids).Order("tags.name").Scan(&tags).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		article := byID[tag.ArticleID]
+		article.Tags = append(article.Tags, tag.Name)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// uniqueViolation is the postgres error code of unique constraint violations
+const uniqueViolation = "23505"
+
+// byID orders preloaded associations by their id
+func byID(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+// GetCustomerByID queries a customer and its addresses from the database
+func (c *Client) GetCustomerByID(id int) *types.Customer {
+	customer := &types.Customer{}
+	if err := c.reader().Preload("Addresses", byID).Where("id = ?", id).First(customer).Error; err != nil {
+		return nil
+	}
+	return customer
+}
+
+// SetCustomer writes a customer to the database and replaces its addresses.
+// It returns types.ErrEmailTaken if another customer already uses the email.
+func (c *Client) SetCustomer(customer *types.Customer) error {
+	err := c.transaction(func(tx *gorm.DB) error {
+		taken := 0
+		if err := tx.Model(&types.Customer{}).Where("email = ? AND id <> ?", customer.Email, customer.ID).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return types.ErrEmailTaken
+		}
+
+		before, err := loadPrevious(tx.Preload("Addresses", byID), &types.Customer{}, customer.ID)
+		if err != nil {
+			return err
+		}
+		addresses := customer.Addresses
+		if err := tx.Set("gorm:association_autocreate", false).Set("gorm:association_autoupdate", false).
+			Save(customer).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("customer_id = ?", customer.ID).Delete(&types.Address{}).Error; err != nil {
+			return err
+		}
+		for _, address := range addresses {
+			address.ID = 0
+			address.CustomerID = customer.ID
+			if err := tx.Create(address).Error; err != nil {
+				return err
+			}
+		}
+		customer.Addresses = addresses
+		return writeAudit(tx, "customer", customer.ID, before, customer)
+	})
+
+	// concurrent writes of the same email are caught by the unique index
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return types.ErrEmailTaken
+	}
+	return err
+}
+
+// DeleteCustomer deletes a customer and its addresses from the database.
+// It returns types.ErrCustomerHasOrders if the customer still owns orders.
+func (c *Client) DeleteCustomer(id int) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		orders := 0
+		if err := tx.Model(&types.Order{}).Where("customer_id = ?", id).Count(&orders).Error; err != nil {
+			return err
+		}
+		if orders > 0 {
+			return types.ErrCustomerHasOrders
+		}
+		before, err := loadPrevious(tx.Preload("Addresses", byID), &types.Customer{}, id)
+		if err != nil || before == nil {
+			return err
+		}
+		if err := tx.Where("customer_id = ?", id).Delete(&types.Address{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", id).Delete(&types.Customer{}).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, "customer", id, before, nil)
+	})
+}
+
+// GetCustomers returns all customers from the database
+func (c *Client) GetCustomers(pageID int) *types.CustomerList {
+	customers := &types.CustomerList{}
+	c.reader().Preload("Addresses", byID).Where("id >= ?", pageID).Order("id").Limit(pageSize + 1).Find(&customers.Items)
+	if len(customers.Items) == pageSize+1 {
+		customers.NextPageID = customers.Items[len(customers.Items)-1].ID
+		customers.Items = customers.Items[:pageSize]
+	}
+	return customers
+}
+
+// GetCustomerOrders returns all orders of a customer from the database
+func (c *Client) GetCustomerOrders(customerID int, pageID int) *types.OrderList {
+	orders := &types.OrderList{}
+	query := c.reader().Model(&types.Order{}).Where("customer_id = ?", customerID)
+	query.Preload("Items").Where("id >= ?", pageID).Order("id").Limit(pageSize + 1).Find(&orders.Items)
+	if len(orders.Items) == pageSize+1 {
+		orders.NextPageID = orders.Items[len(orders.Items)-1].ID
+		orders.Items = orders.Items[:pageSize]
+	}
+	orders.PrevPageID = prevPageID(query, pageID)
+	return orders
+}
@@ -3,10 +3,15 @@
 package db
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/jinzhu/gorm"
 	// postgres blank import for gorm
 	_ "github.com/jinzhu/gorm/dialects/postgres"
 
+	"github.com/jonnylangefeld/go-api/pkg/breaker"
+	"github.com/jonnylangefeld/go-api/pkg/pricing"
 	"github.com/jonnylangefeld/go-api/pkg/types"
 )
 
@@ -17,18 +22,102 @@ const (
 // ClientInterface resembles a db interface to interact with an underlying db
 type ClientInterface interface {
 	Ping() error
+	Available() (time.Duration, error)
 	Connect(connectionString string) error
 	GetArticleByID(id int) *types.Article
+	GetArticlesByIDs(ids []int) []*types.Article
 	SetArticle(article *types.Article) error
-	GetArticles(pageID int) *types.ArticleList
+	SetArticles(articles []*types.Article) error
+	DeleteArticle(id int) error
+	GetArticleImages(articleID int) []*types.ArticleImage
+	GetArticleImageByID(id int) *types.ArticleImage
+	AddArticleImage(image *types.ArticleImage) error
+	DeleteArticleImage(articleID, id int) error
+	GetVariants(articleID int) []*types.Variant
+	GetVariantByID(id int) *types.Variant
+	GetVariantBySKU(sku string) *types.Variant
+	SetVariant(variant *types.Variant) error
+	DeleteVariant(articleID, id int) error
+	GetArticles(pageID int, filter *types.ArticleFilter) *types.ArticleList
+	StreamArticles(pageID int, filter *types.ArticleFilter, fn func(article *types.Article) error) error
+	SearchArticles(query string, pageID int) *types.ArticleSearchResultList
 	GetOrderByID(id int) *types.Order
+	GetOrdersByIDs(ids []int) []*types.Order
 	SetOrder(order *types.Order) error
-	GetOrders(pageID int) *types.OrderList
+	SetOrders(orders []*types.Order) error
+	DeleteOrder(id int) error
+	GetOrders(pageID int, filter *types.OrderFilter) *types.OrderList
+	StreamOrders(pageID int, filter *types.OrderFilter, fn func(order *types.Order) error) error
+	GetWebhookByID(id int) *types.Webhook
+	SetWebhook(webhook *types.Webhook) error
+	DeleteWebhook(id int) error
+	GetWebhooks(pageID int) *types.WebhookList
+	GetWebhooksForEvent(eventType string) []*types.Webhook
+	GetWebhookDeliveryByID(id int) *types.WebhookDelivery
+	SetWebhookDelivery(delivery *types.WebhookDelivery) error
+	GetWebhookDeliveries(webhookID int, pageID int) *types.WebhookDeliveryList
+	GetDueWebhookDeliveries(now time.Time, limit int) []*types.WebhookDelivery
+	LockOutbox(fn func(client ClientInterface) error) (bool, error)
+	GetUnpublishedOutboxEvents(limit int) []*types.OutboxEvent
+	SetOutboxEvent(event *types.OutboxEvent) error
+	GetOutboxEventsAfter(id int, limit int) []*types.OutboxEvent
+	GetLastOutboxEventID() int
+	AdjustStock(adjustment *types.StockAdjustment) error
+	GetStockAdjustments(articleID int, pageID int) *types.StockAdjustmentList
+	GetArticlePrices(articleID int, pageID int) *types.ArticlePriceList
+	SchedulePrice(price *types.ArticlePrice) error
+	ApplyScheduledPrices(now time.Time) (int, error)
+	GetCustomerByID(id int) *types.Customer
+	SetCustomer(customer *types.Customer) error
+	DeleteCustomer(id int) error
+	GetCustomers(pageID int) *types.CustomerList
+	GetCustomerOrders(customerID int, pageID int) *types.OrderList
+	QuoteOrder(order *types.Order) (*types.Quote, error)
+	GetCouponByID(id int) *types.Coupon
+	SetCoupon(coupon *types.Coupon) error
+	DeleteCoupon(id int) error
+	GetCoupons(pageID int) *types.CouponList
+	GetCategoryByID(id int) *types.Category
+	SetCategory(category *types.Category) error
+	DeleteCategory(id int) error
+	GetCategories(pageID int) *types.CategoryList
+	GetTagByID(id int) *types.Tag
+	SetTag(tag *types.Tag) error
+	DeleteTag(id int) error
+	GetTags(pageID int) *types.TagList
+	Audited(actor, requestID string) ClientInterface
+	Select(fields []string) ClientInterface
+	Transaction(fn func(client ClientInterface) error) error
+	Session(lastWrite time.Time) ClientInterface
+	LastWrite() time.Time
+	GetAuditEntries(pageID int, filter *types.AuditFilter) *types.AuditEntryList
 }
 
 // Client is a custom db client
 type Client struct {
+	// Client is the primary database, which serves all writes
 	Client *gorm.DB
+	// Pricing calculates the totals of orders, orders aren't taxed if it is empty
+	Pricing *pricing.Engine
+	// Options tune the connections to the database, DefaultOptions apply if it is empty
+	Options *Options
+	// PinDuration is the time reads go to the primary after a write, which has to exceed the replication lag
+	// of the replicas. DefaultPinDuration applies if it is empty.
+	PinDuration time.Duration
+
+	replicas []*replica
+	next     uint32
+	// lastWrite is the time of the last write of the clients without a session
+	lastWrite int64
+	// session holds the time of the last write of a client returned by Session
+	session *session
+	breaker *breaker.Breaker
+	// origin is the client an audited or a transaction client was derived from
+	origin *Client
+	// inTransaction is set for clients whose database is a transaction
+	inTransaction bool
+	// fields are the json names of the fields read by a client returned by Select
+	fields []string
 }
 
 // Ping allows the db to be pinged.
@@ -36,84 +125,420 @@ func (c *Client) Ping() error {
 	return c.Client.DB().Ping()
 }
 
-// Connect establishes a connection to the database and auto migrates the database schema
+// Connect establishes a connection to the database and auto migrates the database schema.
+// It keeps retrying until the connect timeout of the options passed, so that the database may start after the api.
 func (c *Client) Connect(connectionString string) error {
 	var err error
 	// Create the database connection
-	c.Client, err = gorm.Open(
-		"postgres",
-		connectionString,
-	)
+	c.Client, err = c.open(connectionString)
 
 	// End the program with an error if it could not connect to the database
 	if err != nil {
 		return err
 	}
-	c.Client.LogMode(false)
-	c.autoMigrate()
-	return nil
+	c.watch()
+	return c.autoMigrate()
 }
 
-// autoMigrate creates the default database schema
-func (c *Client) autoMigrate() {
+// autoMigrate creates the default database schema and migrates existing data
+func (c *Client) autoMigrate() error {
 	c.Client.AutoMigrate(&types.Article{})
 	c.Client.AutoMigrate(&types.Order{})
+	c.Client.AutoMigrate(&types.OrderItem{})
+	c.Client.AutoMigrate(&types.Webhook{})
+	c.Client.AutoMigrate(&types.WebhookDelivery{})
+	c.Client.AutoMigrate(&types.OutboxEvent{})
+	c.Client.AutoMigrate(&types.StockAdjustment{})
+	c.Client.AutoMigrate(&types.ArticlePrice{})
+	c.Client.AutoMigrate(&types.Customer{})
+	c.Client.AutoMigrate(&types.Address{})
+	c.Client.AutoMigrate(&types.Coupon{})
+	c.Client.AutoMigrate(&types.Category{})
+	c.Client.AutoMigrate(&types.Tag{})
+	c.Client.AutoMigrate(&types.ArticleCategory{})
+	c.Client.AutoMigrate(&types.ArticleTag{})
+	c.Client.AutoMigrate(&types.ArticleImage{})
+	c.Client.AutoMigrate(&types.Variant{})
+	c.Client.AutoMigrate(&types.AuditEntry{})
+	c.Client.Model(&types.Address{}).AddForeignKey("customer_id", "customers(id)", "CASCADE", "CASCADE")
+	c.Client.Model(&types.Order{}).AddForeignKey("customer_id", "customers(id)", "RESTRICT", "CASCADE")
+	c.Client.Model(&types.Category{}).AddForeignKey("parent_id", "categories(id)", "RESTRICT", "CASCADE")
+	c.Client.Model(&types.ArticleCategory{}).AddForeignKey("article_id", "articles(id)", "CASCADE", "CASCADE")
+	c.Client.Model(&types.ArticleCategory{}).AddForeignKey("category_id", "categories(id)", "CASCADE", "CASCADE")
+	c.Client.Model(&types.ArticleTag{}).AddForeignKey("article_id", "articles(id)", "CASCADE", "CASCADE")
+	c.Client.Model(&types.ArticleTag{}).AddForeignKey("tag_id", "tags(id)", "CASCADE", "CASCADE")
+	c.Client.Model(&types.ArticleImage{}).AddForeignKey("article_id", "articles(id)", "CASCADE", "CASCADE")
+	c.Client.Model(&types.Variant{}).AddForeignKey("article_id", "articles(id)", "CASCADE", "CASCADE")
+	if err := c.migrateMoney(); err != nil {
+		return err
+	}
+	if err := c.migrateAudit(); err != nil {
+		return err
+	}
+	return c.migrateSearch()
 }
 
-// GetArticleByID queries an article from the database
+// GetArticleByID queries an article and its categories, tags, variants and images from the database
 func (c *Client) GetArticleByID(id int) *types.Article {
+	conn := c.reader()
 	article := &types.Article{}
 
-	c.Client.Where("id = ?", id).First(&article).Scan(article)
+	c.selectFields(conn, article).Where("id = ?", id).First(&article).Scan(article)
+	if article.ID != 0 {
+		_ = c.loadSelectedDetails(conn, article)
+	}
 
 	return article
 }
 
-// SetArticle writes an article to the database
-func (c *Client) SetArticle(article *types.Article) error {
-	// Upsert by trying to create and updating on conflict
-	if err := c.Client.Create(&article).Error; err != nil {
-		return c.Client.Model(&article).Where("id = ?", article.ID).Update(&article).Error
+// GetArticlesByIDs queries all articles with the given ids in a single query.
+// Articles that don't exist are omitted from the result.
+func (c *Client) GetArticlesByIDs(ids []int) []*types.Article {
+	articles := []*types.Article{}
+	if len(ids) == 0 {
+		return articles
 	}
-	return nil
+	conn := c.reader()
+
+	c.selectFields(conn, &types.Article{}).Where("id IN (?)", ids).Order("id").Find(&articles)
+	_ = c.loadSelectedDetails(conn, articles...)
+
+	return articles
+}
+
+// SetArticle writes an article to the database together with an outbox event of the change.
+// A changed price is recorded in the price history of the article. The categories and tags of the article
+// replace its current ones unless they are nil.
+func (c *Client) SetArticle(article *types.Article) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		// Upsert by updating existing articles and creating new ones
+		eventType := types.EventArticleCreated
+		previous := &types.Article{}
+		if article.ID != 0 {
+			if err := tx.Where("id = ?", article.ID).First(previous).Error; err != nil && !gorm.IsRecordNotFoundError(err) {
+				return err
+			}
+		}
+		var before interface{}
+		if previous.ID != 0 {
+			eventType = types.EventArticleUpdated
+			if err := loadArticleDetails(tx, previous); err != nil {
+				return err
+			}
+			before = previous
+			if err := tx.Model(&article).Where("id = ?", article.ID).Update(&article).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Create(&article).Error; err != nil {
+			return err
+		}
+
+		if err := setTaxonomy(tx, article); err != nil {
+			return err
+		}
+
+		stored := &types.Article{}
+		if err := tx.Where("id = ?", article.ID).First(stored).Error; err != nil {
+			return err
+		}
+		if err := loadArticleDetails(tx, stored); err != nil {
+			return err
+		}
+		article.CategoryIDs, article.Tags, article.Variants, article.Images =
+			stored.CategoryIDs, stored.Tags, stored.Variants, stored.Images
+		if !stored.Price.IsZero() && stored.Price != previous.Price {
+			if err := recordPrice(tx, &types.ArticlePrice{
+				ArticleID:     stored.ID,
+				Price:         stored.Price,
+				EffectiveFrom: gorm.NowFunc(),
+				Applied:       true,
+			}); err != nil {
+				return err
+			}
+		}
+		if err := writeAudit(tx, "article", stored.ID, before, stored); err != nil {
+			return err
+		}
+		return writeOutbox(tx, eventType, stored)
+	})
 }
 
-// GetArticles returns all articles from the database
-func (c *Client) GetArticles(pageID int) *types.ArticleList {
+// DeleteArticle deletes an article from the database together with an outbox event of the change.
+// The blobs of the images of the article have to be deleted from the storage by the caller.
+func (c *Client) DeleteArticle(id int) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		article := &types.Article{}
+		if err := tx.Where("id = ?", id).First(article).Error; err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return nil
+			}
+			return err
+		}
+		if err := loadArticleDetails(tx, article); err != nil {
+			return err
+		}
+		if err := tx.Where("article_id = ?", id).Delete(&types.ArticleCategory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id = ?", id).Delete(&types.ArticleTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id = ?", id).Delete(&types.ArticleImage{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id = ?", id).Delete(&types.Variant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(article).Error; err != nil {
+			return err
+		}
+		if err := writeAudit(tx, "article", id, article, nil); err != nil {
+			return err
+		}
+		return writeOutbox(tx, types.EventArticleDeleted, article)
+	})
+}
+
+// GetArticles returns all articles from the database that pass the filter, which may be nil
+func (c *Client) GetArticles(pageID int, filter *types.ArticleFilter) *types.ArticleList {
+	conn := c.reader()
 	articles := &types.ArticleList{}
-	c.Client.Where("id >= ?", pageID).Order("id").Limit(pageSize + 1).Find(&articles.Items)
+	query := filterArticles(conn.Model(&types.Article{}), filter)
+	c.selectFields(query, &types.Article{}).Where("id >= ?", pageID).Order("id").Limit(pageSize + 1).Find(&articles.Items)
 	if len(articles.Items) == pageSize+1 {
 		articles.NextPageID = articles.Items[len(articles.Items)-1].ID
 		articles.Items = articles.Items[:pageSize]
 	}
+	articles.PrevPageID = prevPageID(query, pageID)
+	_ = c.loadSelectedDetails(conn, articles.Items...)
 	return articles
 }
 
+// filterArticles restricts the query to the articles that pass the filter, which may be nil
+func filterArticles(query *gorm.DB, filter *types.ArticleFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+	if filter.LowStock != nil {
+		query = query.Where("stock <= ?", *filter.LowStock)
+	}
+	return filterTaxonomy(query, filter)
+}
+
+// prevPageID returns the id to query the page before the one starting at pageID from the rows of the query,
+// which is 0 if the page is the first one
+func prevPageID(query *gorm.DB, pageID int) int {
+	if pageID == 0 {
+		return 0
+	}
+	ids := []int{}
+	query.Where("id < ?", pageID).Order("id DESC").Limit(pageSize).Pluck("id", &ids)
+	if len(ids) == 0 {
+		return 0
+	}
+	return ids[len(ids)-1]
+}
+
+// StreamArticles iterates over all articles starting at pageID that pass the filter, which may be nil, using a
+// database cursor and calls fn for each of them. Iteration stops at the first error returned by fn.
+func (c *Client) StreamArticles(pageID int, filter *types.ArticleFilter, fn func(article *types.Article) error) error {
+	conn := c.reader()
+	rows, err := filterArticles(conn.Model(&types.Article{}), filter).Where("id >= ?", pageID).Order("id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		article := &types.Article{}
+		if err := conn.ScanRows(rows, article); err != nil {
+			return err
+		}
+		if err := fn(article); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 // GetOrderByID queries an order from the database
 func (c *Client) GetOrderByID(id int) *types.Order {
 	order := &types.Order{}
 
-	c.Client.Where("id = ?", id).First(&order).Scan(order)
+	c.preloadItems(c.selectFields(c.reader(), order)).Where("id = ?", id).First(&order)
 
 	return order
 }
 
-// SetOrder writes an order to the database
+// SetOrder writes an order to the database together with an outbox event of the change.
+// Items referencing a variant are ordered as that variant of its article.
+// The stock of the ordered articles is reserved, updated orders release the stock of their previous items first.
+// The items of updated orders are replaced by the given ones, unless they are nil.
+// The coupon of the order is redeemed and the order is priced with the article prices at the time of the order,
+// which is the time it was created. The time sent by clients is ignored.
 func (c *Client) SetOrder(order *types.Order) error {
-	// Upsert by trying to create and updating on conflict
-	if err := c.Client.Create(&order).Error; err != nil {
-		return c.Client.Model(&order).Where("id = ?", order.ID).Update(&order).Error
+	return c.transaction(func(tx *gorm.DB) error {
+		if order.CustomerID != nil && !exists(tx, &types.Customer{}, *order.CustomerID) {
+			return fmt.Errorf("customer %d doesn't exist", *order.CustomerID)
+		}
+		if _, err := resolveVariants(tx, order.Items); err != nil {
+			return err
+		}
+
+		// Upsert by updating existing orders and creating new ones
+		eventType := types.EventOrderCreated
+		previous := &types.Order{}
+		if order.ID != 0 {
+			if err := tx.Preload("Items").Where("id = ?", order.ID).First(previous).Error; err != nil && !gorm.IsRecordNotFoundError(err) {
+				return err
+			}
+		}
+		if err := replaceItems(tx, previous, order.Items); err != nil {
+			return err
+		}
+		// orders are priced at the time they were placed, which is taken from the server and kept by updates
+		order.DateTime = previous.DateTime
+		if previous.ID == 0 {
+			order.DateTime = gorm.NowFunc()
+		}
+		var before interface{}
+		if previous.ID != 0 {
+			eventType = types.EventOrderUpdated
+			before = previous
+			if err := releaseStock(tx, order.ID, previous.Items); err != nil {
+				return err
+			}
+			if err := tx.Model(&order).Where("id = ?", order.ID).Update(&order).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+
+		stored := &types.Order{}
+		if err := tx.Preload("Items").Where("id = ?", order.ID).First(stored).Error; err != nil {
+			return err
+		}
+		if err := reserveStock(tx, order.ID, stored.Items); err != nil {
+			return err
+		}
+		if err := redeemCoupon(tx, previous.CouponCode, stored.CouponCode); err != nil {
+			return err
+		}
+		if err := c.priceOrder(tx, stored); err != nil {
+			return err
+		}
+		order.Items = stored.Items
+		order.CouponCode = stored.CouponCode
+		order.Subtotal = stored.Subtotal
+		order.Discount = stored.Discount
+		order.Tax = stored.Tax
+		order.Total = stored.Total
+		if err := writeAudit(tx, "order", stored.ID, before, stored); err != nil {
+			return err
+		}
+		return writeOutbox(tx, eventType, stored)
+	})
+}
+
+// replaceItems deletes the items of the previous order that aren't among the given items as part of the transaction,
+// so that the order is left with exactly the given items once they are saved. The items are kept if the given items
+// are nil. Given items with an id have to be items of the previous order, which is empty for new orders.
+func replaceItems(tx *gorm.DB, previous *types.Order, items []*types.OrderItem) error {
+	owned := map[int]bool{}
+	for _, item := range previous.Items {
+		owned[item.ID] = true
+	}
+	keep := []int{}
+	for _, item := range items {
+		if item.ID == 0 {
+			continue
+		}
+		if !owned[item.ID] {
+			return fmt.Errorf("item %d isn't an item of the order", item.ID)
+		}
+		keep = append(keep, item.ID)
+	}
+	if items == nil || previous.ID == 0 {
+		return nil
 	}
-	return nil
+	query := tx.Where("order_id = ?", previous.ID)
+	if len(keep) > 0 {
+		query = query.Where("id NOT IN (?)", keep)
+	}
+	return query.Delete(&types.OrderItem{}).Error
+}
+
+// DeleteOrder cancels an order by deleting it and its items from the database, together with an outbox event
+// of the change. The reserved stock of its articles and the use of its coupon are released.
+func (c *Client) DeleteOrder(id int) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		order := &types.Order{}
+		if err := tx.Preload("Items").Where("id = ?", id).First(order).Error; err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return nil
+			}
+			return err
+		}
+		if err := releaseStock(tx, order.ID, order.Items); err != nil {
+			return err
+		}
+		if err := redeemCoupon(tx, order.CouponCode, ""); err != nil {
+			return err
+		}
+		if err := tx.Where("order_id = ?", id).Delete(&types.OrderItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(order).Error; err != nil {
+			return err
+		}
+		if err := writeAudit(tx, "order", id, order, nil); err != nil {
+			return err
+		}
+		return writeOutbox(tx, types.EventOrderDeleted, order)
+	})
 }
 
-// GetOrders returns all orders from the database
-func (c *Client) GetOrders(pageID int) *types.OrderList {
+// GetOrders returns all orders from the database that pass the filter, which may be nil
+func (c *Client) GetOrders(pageID int, filter *types.OrderFilter) *types.OrderList {
 	orders := &types.OrderList{}
-	c.Client.Find(&orders.Items).Where("id >= ?", pageID).Order("id").Limit(pageSize + 1)
+	query := filterOrders(c.reader().Model(&types.Order{}), filter)
+	c.preloadItems(c.selectFields(query, &types.Order{})).Where("id >= ?", pageID).Order("id").Limit(pageSize + 1).
+		Find(&orders.Items)
 	if len(orders.Items) == pageSize+1 {
 		orders.NextPageID = orders.Items[len(orders.Items)-1].ID
-		orders.Items = orders.Items[:pageSize+1]
+		orders.Items = orders.Items[:pageSize]
 	}
+	orders.PrevPageID = prevPageID(query, pageID)
 	return orders
 }
+
+// filterOrders restricts the query to the orders that pass the filter, which may be nil
+func filterOrders(query *gorm.DB, filter *types.OrderFilter) *gorm.DB {
+	if filter != nil && filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	return query
+}
+
+// StreamOrders iterates over all orders starting at pageID that pass the filter, which may be nil, using a
+// database cursor and calls fn for each of them. Iteration stops at the first error returned by fn.
+func (c *Client) StreamOrders(pageID int, filter *types.OrderFilter, fn func(order *types.Order) error) error {
+	conn := c.reader()
+	rows, err := filterOrders(conn.Model(&types.Order{}), filter).Where("id >= ?", pageID).Order("id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		order := &types.Order{}
+		if err := conn.ScanRows(rows, order); err != nil {
+			return err
+		}
+		if err := fn(order); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -1,14 +1,21 @@
 package db
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
 	"github.com/ory/dockertest/v3"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/jonnylangefeld/go-api/pkg/pricing"
 	"github.com/jonnylangefeld/go-api/pkg/types"
 )
 
@@ -16,7 +23,7 @@ var (
 	testClient  = &Client{}
 	testArticle = types.Article{
 		Name:  "Skittles",
-		Price: 1.99,
+		Price: types.NewMoney(199, "USD"),
 	}
 )
 
@@ -54,7 +61,7 @@ func TestMain(m *testing.M) {
 }
 
 func TestClient_Articles(t *testing.T) {
-	testClient.Client.DropTable(&types.Article{})
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{})
 	testClient.autoMigrate()
 	first := testArticle
 	err := testClient.SetArticle(&first)
@@ -67,31 +74,745 @@ func TestClient_Articles(t *testing.T) {
 	assert.Equal(t, 2, second.ID)
 
 	update := first
-	update.Price = 2.99
+	update.Price = types.NewMoney(299, "USD")
 	err = testClient.SetArticle(&update)
 	assert.NoError(t, err)
 
 	got := testClient.GetArticleByID(1)
 	assert.Equal(t, testArticle.Name, got.Name, "")
-	assert.Equal(t, 2.99, got.Price, "")
+	assert.Equal(t, types.NewMoney(299, "USD"), got.Price, "")
 
 	got = testClient.GetArticleByID(2)
 	assert.Equal(t, testArticle.Name, got.Name, "")
-	assert.Equal(t, 1.99, got.Price, "")
+	assert.Equal(t, types.NewMoney(199, "USD"), got.Price, "")
 }
 
 func TestClient_PaginateArticles(t *testing.T) {
-	testClient.Client.DropTable(&types.Article{})
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{})
 	testClient.autoMigrate()
 	for i := 0; i < pageSize+2; i++ {
 		article := testArticle
 		_ = testClient.SetArticle(&article)
 	}
-	got := testClient.GetArticles(0)
+	got := testClient.GetArticles(0, nil)
 	assert.Equal(t, 10, len(got.Items))
 	assert.Equal(t, 11, got.NextPageID)
+	assert.Equal(t, 0, got.PrevPageID)
 
-	got = testClient.GetArticles(11)
+	got = testClient.GetArticles(11, nil)
 	assert.Equal(t, 2, len(got.Items))
 	assert.Equal(t, 0, got.NextPageID)
+	assert.Equal(t, 1, got.PrevPageID)
+
+	// the previous page of a page that doesn't start at a multiple of the page size starts after the first article
+	assert.Equal(t, 3, testClient.GetArticles(13, nil).PrevPageID)
+}
+
+func TestClient_StreamArticles(t *testing.T) {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{})
+	testClient.autoMigrate()
+	for i := 0; i < pageSize+2; i++ {
+		article := testArticle
+		_ = testClient.SetArticle(&article)
+	}
+
+	var got []int
+	err := testClient.StreamArticles(3, nil, func(article *types.Article) error {
+		got = append(got, article.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, got)
+
+	lowStock := 0
+	got = nil
+	err = testClient.StreamArticles(0, &types.ArticleFilter{LowStock: &lowStock}, func(article *types.Article) error {
+		got = append(got, article.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	wantErr := fmt.Errorf("stop")
+	err = testClient.StreamArticles(0, nil, func(article *types.Article) error {
+		return wantErr
+	})
+	assert.Equal(t, wantErr, err)
+}
+
+func TestClient_Orders(t *testing.T) {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{}, &types.Order{}, &types.OrderItem{})
+	testClient.autoMigrate()
+	for i := 0; i < 2; i++ {
+		article := testArticle
+		_ = testClient.SetArticle(&article)
+	}
+
+	order := &types.Order{
+		Items: []*types.OrderItem{
+			{ArticleID: 1, Quantity: 2},
+			{ArticleID: 2, Quantity: 1},
+		},
+	}
+	err := testClient.SetOrder(order)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, order.ID)
+
+	got := testClient.GetOrderByID(1)
+	assert.Equal(t, 2, len(got.Items))
+	assert.Equal(t, 1, got.Items[0].ArticleID)
+	assert.Equal(t, 2, got.Items[0].Quantity)
+
+	articles := testClient.GetArticlesByIDs([]int{2, 1, 3})
+	assert.Equal(t, 2, len(articles))
+	assert.Equal(t, 1, articles[0].ID)
+	assert.Equal(t, 2, articles[1].ID)
+}
+
+func TestClient_Outbox(t *testing.T) {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{}, &types.OutboxEvent{})
+	testClient.autoMigrate()
+
+	article := testArticle
+	assert.NoError(t, testClient.SetArticle(&article))
+	update := article
+	update.Price = types.NewMoney(299, "USD")
+	assert.NoError(t, testClient.SetArticle(&update))
+	assert.NoError(t, testClient.DeleteArticle(article.ID))
+
+	events := testClient.GetUnpublishedOutboxEvents(10)
+	assert.Equal(t, 3, len(events))
+	assert.Equal(t, types.EventArticleCreated, events[0].Type)
+	assert.Equal(t, types.EventArticleUpdated, events[1].Type)
+	assert.Equal(t, types.EventArticleDeleted, events[2].Type)
+
+	event, err := events[1].Event()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":1,"name":"Skittles","price":{"amount":"2.99","currency":"USD"}}`, string(event.Data.(json.RawMessage)))
+
+	now := time.Now()
+	events[0].PublishedTo = []string{"log"}
+	events[0].PublishedAt = &now
+	assert.NoError(t, testClient.SetOutboxEvent(events[0]))
+	assert.Equal(t, 2, len(testClient.GetUnpublishedOutboxEvents(10)))
+
+	// published events stay in the change log
+	after := testClient.GetOutboxEventsAfter(1, 1)
+	assert.Equal(t, 1, len(after))
+	assert.Equal(t, 2, after[0].ID)
+	assert.Equal(t, 3, testClient.GetLastOutboxEventID())
+
+	// only one relay at a time holds the lock of the outbox
+	locked, err := testClient.LockOutbox(func(client ClientInterface) error {
+		assert.Equal(t, 2, len(client.GetUnpublishedOutboxEvents(10)))
+		other, err := testClient.LockOutbox(func(client ClientInterface) error {
+			t.Error("the outbox was locked twice")
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.False(t, other)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, locked)
+}
+
+func TestClient_Inventory(t *testing.T) {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{}, &types.Order{}, &types.OrderItem{}, &types.StockAdjustment{})
+	testClient.autoMigrate()
+	article := testArticle
+	assert.NoError(t, testClient.SetArticle(&article))
+	assert.False(t, testClient.GetArticleByID(article.ID).StockLevel().Tracked)
+
+	assert.NoError(t, testClient.AdjustStock(&types.StockAdjustment{ArticleID: article.ID, Delta: 3, Reason: types.StockRestock}))
+	err := testClient.AdjustStock(&types.StockAdjustment{ArticleID: article.ID, Delta: -4, Reason: types.StockDamage})
+	assert.Equal(t, &types.InsufficientStockError{ArticleID: article.ID, Requested: 4, Available: 3}, err)
+
+	// the second order exceeds the remaining stock and is rolled back
+	order := &types.Order{Items: []*types.OrderItem{{ArticleID: article.ID, Quantity: 2}}}
+	assert.NoError(t, testClient.SetOrder(order))
+	err = testClient.SetOrder(&types.Order{Items: []*types.OrderItem{{ArticleID: article.ID, Quantity: 2}}})
+	assert.Equal(t, &types.InsufficientStockError{ArticleID: article.ID, Requested: 2, Available: 1}, err)
+
+	lowStock := 1
+	assert.Equal(t, 1, len(testClient.GetArticles(0, &types.ArticleFilter{LowStock: &lowStock}).Items))
+
+	stock := testClient.GetArticleByID(article.ID).StockLevel()
+	assert.Equal(t, 1, *stock.Available)
+	assert.Equal(t, 2, stock.Reserved)
+
+	assert.NoError(t, testClient.DeleteOrder(order.ID))
+	stock = testClient.GetArticleByID(article.ID).StockLevel()
+	assert.Equal(t, 3, *stock.Available)
+	assert.Equal(t, 0, stock.Reserved)
+
+	ledger := testClient.GetStockAdjustments(article.ID, 0).Items
+	assert.Equal(t, 3, len(ledger))
+	assert.Equal(t, types.StockRelease, ledger[0].Reason)
+	assert.Equal(t, types.StockReservation, ledger[1].Reason)
+	assert.Equal(t, types.StockRestock, ledger[2].Reason)
+}
+
+func TestClient_UpdateOrderItems(t *testing.T) {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{}, &types.Order{}, &types.OrderItem{}, &types.StockAdjustment{})
+	testClient.autoMigrate()
+	article := testArticle
+	assert.NoError(t, testClient.SetArticle(&article))
+	assert.NoError(t, testClient.AdjustStock(&types.StockAdjustment{ArticleID: article.ID, Delta: 5, Reason: types.StockRestock}))
+
+	order := &types.Order{Items: []*types.OrderItem{{ArticleID: article.ID, Quantity: 2}, {ArticleID: article.ID, Quantity: 1}}}
+	assert.NoError(t, testClient.SetOrder(order))
+	other := &types.Order{Items: []*types.OrderItem{{ArticleID: article.ID, Quantity: 1}}}
+	assert.NoError(t, testClient.SetOrder(other))
+
+	// the items of an updated order are replaced and only the new ones are reserved
+	update := &types.Order{ID: order.ID, Items: []*types.OrderItem{{ID: order.Items[0].ID, ArticleID: article.ID, Quantity: 3}}}
+	assert.NoError(t, testClient.SetOrder(update))
+	got := testClient.GetOrderByID(order.ID)
+	assert.Equal(t, 1, len(got.Items))
+	assert.Equal(t, 3, got.Items[0].Quantity)
+	stock := testClient.GetArticleByID(article.ID).StockLevel()
+	assert.Equal(t, 1, *stock.Available)
+	assert.Equal(t, 4, stock.Reserved)
+
+	// items without id are added, items of other orders can't be taken over
+	update = &types.Order{ID: order.ID, Items: []*types.OrderItem{{ArticleID: article.ID, Quantity: 1}}}
+	assert.NoError(t, testClient.SetOrder(update))
+	got = testClient.GetOrderByID(order.ID)
+	assert.Equal(t, 1, len(got.Items))
+	assert.NotEqual(t, order.Items[0].ID, got.Items[0].ID)
+	update = &types.Order{ID: order.ID, Items: []*types.OrderItem{{ID: other.Items[0].ID, ArticleID: article.ID, Quantity: 1}}}
+	assert.Error(t, testClient.SetOrder(update))
+	assert.Equal(t, 1, len(testClient.GetOrderByID(other.ID).Items))
+
+	// orders without items keep them
+	assert.NoError(t, testClient.SetOrder(&types.Order{ID: order.ID, Region: "DE"}))
+	assert.Equal(t, 1, len(testClient.GetOrderByID(order.ID).Items))
+	stock = testClient.GetArticleByID(article.ID).StockLevel()
+	assert.Equal(t, 3, *stock.Available)
+	assert.Equal(t, 2, stock.Reserved)
+}
+
+func TestClient_Prices(t *testing.T) {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{}, &types.Order{}, &types.OrderItem{}, &types.ArticlePrice{})
+	testClient.autoMigrate()
+	article := testArticle
+	assert.NoError(t, testClient.SetArticle(&article))
+	created := time.Now()
+
+	// a changed price ends the previous one
+	update := testArticle
+	update.ID = article.ID
+	update.Price = types.NewMoney(249, "USD")
+	assert.NoError(t, testClient.SetArticle(&update))
+	history := testClient.GetArticlePrices(article.ID, 0).Items
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, types.NewMoney(249, "USD"), history[0].Price)
+	assert.Nil(t, history[0].EffectiveTo)
+	assert.Equal(t, history[0].EffectiveFrom, *history[1].EffectiveTo)
+
+	// orders are priced at the time they are created, the time sent by clients is ignored
+	order := &types.Order{DateTime: created, Items: []*types.OrderItem{{ArticleID: article.ID, Quantity: 2}}}
+	assert.NoError(t, testClient.SetOrder(order))
+	assert.Equal(t, types.NewMoney(498, "USD"), order.Total)
+	assert.True(t, order.DateTime.After(created))
+
+	// scheduled prices are applied once they take effect, updated orders keep the time they were created
+	scheduled := &types.ArticlePrice{ArticleID: article.ID, Price: types.NewMoney(299, "USD"), EffectiveFrom: time.Now().Add(time.Hour)}
+	assert.NoError(t, testClient.SchedulePrice(scheduled))
+	future := &types.Order{DateTime: time.Now().Add(2 * time.Hour), Items: []*types.OrderItem{{ArticleID: article.ID, Quantity: 1}}}
+	assert.NoError(t, testClient.SetOrder(future))
+	assert.Equal(t, types.NewMoney(249, "USD"), future.Total)
+	quote, err := testClient.QuoteOrder(&types.Order{DateTime: time.Now().Add(2 * time.Hour), Items: future.Items})
+	assert.NoError(t, err)
+	assert.Equal(t, types.NewMoney(249, "USD"), quote.Total)
+	future.DateTime = time.Now().Add(2 * time.Hour)
+	assert.NoError(t, testClient.SetOrder(future))
+	assert.True(t, future.DateTime.Before(time.Now()))
+	assert.Equal(t, types.NewMoney(249, "USD"), future.Total)
+
+	changed, err := testClient.ApplyScheduledPrices(time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, changed)
+	changed, err = testClient.ApplyScheduledPrices(time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, types.NewMoney(299, "USD"), testClient.GetArticleByID(article.ID).Price)
+	assert.True(t, testClient.GetArticlePrices(article.ID, 0).Items[0].Applied)
+}
+
+func TestClient_Customers(t *testing.T) {
+	testClient.Client.DropTable(&types.Order{}, &types.OrderItem{}, &types.Address{}, &types.Customer{})
+	testClient.autoMigrate()
+	customer := &types.Customer{
+		Name:      "Jane Doe",
+		Email:     "jane@example.com",
+		Addresses: []*types.Address{{Kind: types.AddressShipping, Line1: "1 Main St", City: "San Francisco", Country: "US"}},
+	}
+	assert.NoError(t, testClient.SetCustomer(customer))
+	assert.Equal(t, 1, len(testClient.GetCustomerByID(customer.ID).Addresses))
+
+	// the addresses of an updated customer are replaced
+	customer.Addresses = []*types.Address{{Kind: types.AddressBilling, Line1: "2 Main St", City: "Oakland", Country: "US"}}
+	assert.NoError(t, testClient.SetCustomer(customer))
+	got := testClient.GetCustomerByID(customer.ID)
+	assert.Equal(t, 1, len(got.Addresses))
+	assert.Equal(t, types.AddressBilling, got.Addresses[0].Kind)
+
+	assert.Equal(t, types.ErrEmailTaken, testClient.SetCustomer(&types.Customer{Name: "Jane Smith", Email: "jane@example.com"}))
+
+	order := &types.Order{CustomerID: &customer.ID}
+	assert.NoError(t, testClient.SetOrder(order))
+	// orders without items are priced at zero in the default currency
+	assert.Equal(t, types.NewMoney(0, types.DefaultCurrency), order.Total)
+	assert.Error(t, testClient.SetOrder(&types.Order{CustomerID: new(int)}))
+	assert.Equal(t, 1, len(testClient.GetCustomerOrders(customer.ID, 0).Items))
+	assert.Equal(t, 1, len(testClient.GetOrders(0, &types.OrderFilter{CustomerID: &customer.ID}).Items))
+	var streamed []int
+	assert.NoError(t, testClient.StreamOrders(0, &types.OrderFilter{CustomerID: &customer.ID}, func(order *types.Order) error {
+		streamed = append(streamed, order.ID)
+		return nil
+	}))
+	assert.Equal(t, []int{order.ID}, streamed)
+
+	assert.Equal(t, types.ErrCustomerHasOrders, testClient.DeleteCustomer(customer.ID))
+	assert.NoError(t, testClient.DeleteOrder(order.ID))
+	assert.NoError(t, testClient.DeleteCustomer(customer.ID))
+	assert.Nil(t, testClient.GetCustomerByID(customer.ID))
+}
+
+func TestClient_Pricing(t *testing.T) {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{}, &types.Order{}, &types.OrderItem{}, &types.ArticlePrice{}, &types.Coupon{})
+	testClient.autoMigrate()
+	testClient.Pricing = pricing.NewEngine(pricing.RateTable{"US-CA": 1000})
+	defer func() {
+		testClient.Pricing = nil
+	}()
+	article := testArticle
+	assert.NoError(t, testClient.SetArticle(&article))
+
+	maxUses := 1
+	coupon := &types.Coupon{Code: "SAVE10", Kind: types.CouponPercentage, PercentOff: 10, MaxUses: &maxUses}
+	assert.NoError(t, testClient.SetCoupon(coupon))
+	assert.Equal(t, types.ErrCouponCodeTaken, testClient.SetCoupon(&types.Coupon{Code: "SAVE10", Kind: types.CouponFixed, AmountOff: types.NewMoney(100, "USD")}))
+
+	cart := &types.Order{Region: "US-CA", CouponCode: "SAVE10", Items: []*types.OrderItem{{ArticleID: article.ID, Quantity: 10}}}
+	quote, err := testClient.QuoteOrder(cart)
+	assert.NoError(t, err)
+	assert.Equal(t, types.NewMoney(1990, "USD"), quote.Subtotal)
+	assert.Equal(t, types.NewMoney(199, "USD"), quote.Discount)
+	assert.Equal(t, types.NewMoney(179, "USD"), quote.Tax)
+	assert.Equal(t, types.NewMoney(1970, "USD"), quote.Total)
+	assert.Equal(t, 0, testClient.GetCouponByID(coupon.ID).Uses)
+
+	// orders redeem their coupon until its usage limit is reached
+	assert.NoError(t, testClient.SetOrder(cart))
+	assert.Equal(t, quote.Total, cart.Total)
+	assert.Equal(t, 1, testClient.GetCouponByID(coupon.ID).Uses)
+	err = testClient.SetOrder(&types.Order{CouponCode: "SAVE10", Items: []*types.OrderItem{{ArticleID: article.ID, Quantity: 1}}})
+	assert.Equal(t, &types.CouponError{Code: "SAVE10", Reason: "its usage limit is reached"}, err)
+
+	assert.NoError(t, testClient.DeleteOrder(cart.ID))
+	assert.Equal(t, 0, testClient.GetCouponByID(coupon.ID).Uses)
+}
+
+func TestClient_Catalog(t *testing.T) {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{}, &types.Category{}, &types.Tag{})
+	testClient.autoMigrate()
+
+	candy := &types.Category{Name: "Candy"}
+	assert.NoError(t, testClient.SetCategory(candy))
+	chewy := &types.Category{Name: "Chewy Candy", ParentID: &candy.ID}
+	assert.NoError(t, testClient.SetCategory(chewy))
+	unknown := 100
+	assert.Error(t, testClient.SetCategory(&types.Category{Name: "Hard Candy", ParentID: &unknown}))
+	// a category can't be moved below its own subcategory
+	candy.ParentID = &chewy.ID
+	assert.Error(t, testClient.SetCategory(candy))
+	candy.ParentID = nil
+
+	skittles := testArticle
+	skittles.CategoryIDs = []int{candy.ID}
+	skittles.Tags = []string{"sweet"}
+	assert.NoError(t, testClient.SetArticle(&skittles))
+	jellyBeans := types.Article{Name: "Jelly Beans", Price: types.NewMoney(299, "USD"), CategoryIDs: []int{chewy.ID}, Tags: []string{"sweet", "vegan"}}
+	assert.NoError(t, testClient.SetArticle(&jellyBeans))
+	assert.Error(t, testClient.SetArticle(&types.Article{Name: "Lollipop", CategoryIDs: []int{unknown}}))
+
+	assert.Equal(t, []int{chewy.ID}, testClient.GetArticleByID(jellyBeans.ID).CategoryIDs)
+	assert.Equal(t, []string{"sweet", "vegan"}, testClient.GetArticleByID(jellyBeans.ID).Tags)
+
+	// categories include the articles of their descendants
+	assert.Len(t, testClient.GetArticles(0, &types.ArticleFilter{CategoryID: &candy.ID}).Items, 2)
+	assert.Len(t, testClient.GetArticles(0, &types.ArticleFilter{CategoryID: &chewy.ID}).Items, 1)
+	assert.Len(t, testClient.GetArticles(0, &types.ArticleFilter{Tag: "vegan"}).Items, 1)
+	assert.Len(t, testClient.GetTags(0).Items, 2)
+
+	// nil categories and tags keep the current links
+	jellyBeans.CategoryIDs, jellyBeans.Tags = nil, nil
+	jellyBeans.Name = "Jelly Belly"
+	assert.NoError(t, testClient.SetArticle(&jellyBeans))
+	assert.Equal(t, []string{"sweet", "vegan"}, jellyBeans.Tags)
+
+	assert.Equal(t, types.ErrCategoryHasChildren, testClient.DeleteCategory(candy.ID))
+	assert.NoError(t, testClient.DeleteCategory(chewy.ID))
+	assert.Len(t, testClient.GetArticles(0, &types.ArticleFilter{CategoryID: &candy.ID}).Items, 1)
+
+	vegan := testClient.GetTags(0).Items[1]
+	assert.Equal(t, types.ErrTagTaken, testClient.SetTag(&types.Tag{Name: "sweet"}))
+	assert.NoError(t, testClient.DeleteTag(vegan.ID))
+	assert.Nil(t, testClient.GetArticleByID(jellyBeans.ID).Tags)
+}
+
+func TestClient_SearchArticles(t *testing.T) {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{})
+	testClient.autoMigrate()
+	for _, name := range []string{"Skittles", "Skittles Sour", "Jelly Beans", "Sour Patch Kids"} {
+		article := types.Article{Name: name, Price: types.NewMoney(199, "USD")}
+		assert.NoError(t, testClient.SetArticle(&article))
+	}
+
+	results := testClient.SearchArticles("skit", 0)
+	assert.Len(t, results.Items, 2)
+	assert.Equal(t, "Skittles", results.Items[0].Article.Name)
+	assert.Equal(t, "<mark>Skittles</mark> Sour", results.Items[1].Highlight)
+
+	// names with typos are found by similarity
+	results = testClient.SearchArticles("jely beans", 0)
+	assert.Len(t, results.Items, 1)
+	assert.Equal(t, "Jelly Beans", results.Items[0].Article.Name)
+
+	assert.Empty(t, testClient.SearchArticles("licorice", 0).Items)
+}
+
+func TestClient_ArticleImages(t *testing.T) {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{})
+	testClient.autoMigrate()
+	article := testArticle
+	assert.NoError(t, testClient.SetArticle(&article))
+
+	image := &types.ArticleImage{ArticleID: article.ID, ContentType: "image/png", Size: 100, Width: 10, Height: 10,
+		Key: "articles/1/a.png", ThumbnailKey: "articles/1/a-thumbnail.png", ThumbnailContentType: "image/png"}
+	assert.NoError(t, testClient.AddArticleImage(image))
+	assert.Equal(t, fmt.Sprintf("/articles/%d/images/%d", article.ID, image.ID), image.URL)
+	assert.Equal(t, image.Key, testClient.GetArticleImageByID(image.ID).Key)
+	assert.Len(t, testClient.GetArticleImages(article.ID), 1)
+	assert.Equal(t, image.URL, testClient.GetArticleByID(article.ID).Images[0].URL)
+
+	assert.NoError(t, testClient.DeleteArticleImage(article.ID+1, image.ID))
+	assert.NotNil(t, testClient.GetArticleImageByID(image.ID))
+	assert.NoError(t, testClient.DeleteArticleImage(article.ID, image.ID))
+	assert.Nil(t, testClient.GetArticleImageByID(image.ID))
+	assert.Empty(t, testClient.GetArticleByID(article.ID).Images)
+}
+
+func TestClient_Variants(t *testing.T) {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{}, &types.Order{}, &types.OrderItem{}, &types.StockAdjustment{})
+	testClient.autoMigrate()
+	article := testArticle
+	assert.NoError(t, testClient.SetArticle(&article))
+
+	variant := &types.Variant{ArticleID: article.ID, SKU: "SKITTLES-SOUR", Attributes: types.Attributes{"flavour": "sour"},
+		Price: types.NewMoney(249, "USD")}
+	assert.NoError(t, testClient.SetVariant(variant))
+	assert.Equal(t, types.ErrSKUTaken, testClient.SetVariant(&types.Variant{ArticleID: article.ID, SKU: "SKITTLES-SOUR"}))
+	assert.Equal(t, variant.Attributes, testClient.GetVariantByID(variant.ID).Attributes)
+	assert.Equal(t, article.Name, testClient.GetVariantBySKU("skittles-sour").Article.Name)
+	assert.Nil(t, testClient.GetVariantBySKU("unknown"))
+	assert.Len(t, testClient.GetArticleByID(article.ID).Variants, 1)
+
+	// variants have their own stock and price
+	assert.NoError(t, testClient.AdjustStock(&types.StockAdjustment{ArticleID: article.ID, VariantID: variant.ID, Delta: 2, Reason: types.StockRestock}))
+	order := &types.Order{Items: []*types.OrderItem{{VariantID: variant.ID, Quantity: 2}}}
+	assert.NoError(t, testClient.SetOrder(order))
+	assert.Equal(t, article.ID, order.Items[0].ArticleID)
+	assert.Equal(t, types.NewMoney(249, "USD"), order.Items[0].UnitPrice)
+	err := testClient.SetOrder(&types.Order{Items: []*types.OrderItem{{VariantID: variant.ID, Quantity: 1}}})
+	assert.Equal(t, &types.InsufficientStockError{ArticleID: article.ID, VariantID: variant.ID, Requested: 1, Available: 0}, err)
+	assert.False(t, testClient.GetArticleByID(article.ID).StockLevel().Tracked)
+
+	stock := testClient.GetVariantByID(variant.ID).StockLevel()
+	assert.Equal(t, 0, *stock.Available)
+	assert.Equal(t, 2, stock.Reserved)
+
+	assert.NoError(t, testClient.DeleteVariant(article.ID+1, variant.ID))
+	assert.NotNil(t, testClient.GetVariantByID(variant.ID))
+	assert.NoError(t, testClient.DeleteVariant(article.ID, variant.ID))
+	assert.Nil(t, testClient.GetVariantByID(variant.ID))
+}
+
+func TestClient_WebhookDeliveries(t *testing.T) {
+	webhook := &types.Webhook{URL: "https://example.com/hooks", Events: []string{types.EventAll}, Secret: "s3cr3t"}
+	assert.NoError(t, testClient.SetWebhook(webhook))
+	now := time.Now().Truncate(time.Second)
+	delivery := &types.WebhookDelivery{WebhookID: webhook.ID, EventType: types.EventOrderCreated, Payload: "{}", Status: types.DeliveryPending, NextAttemptAt: now}
+	assert.NoError(t, testClient.SetWebhookDelivery(delivery))
+
+	// due deliveries are claimed, so that concurrent polls don't return them again until the lease expired
+	due := testClient.GetDueWebhookDeliveries(now, 10)
+	assert.Len(t, due, 1)
+	assert.Equal(t, delivery.ID, due[0].ID)
+	assert.Empty(t, testClient.GetDueWebhookDeliveries(now, 10))
+	assert.Len(t, testClient.GetDueWebhookDeliveries(now.Add(WebhookDeliveryLease), 10), 1)
+}
+
+func TestClient_Replicas(t *testing.T) {
+	replicaDB := testClient.Client.New()
+	client := &Client{Client: testClient.Client, PinDuration: time.Hour, replicas: []*replica{{db: replicaDB, healthy: 1}}}
+	assert.True(t, replicaDB == client.reader())
+
+	// reads follow writes to the primary until the pin duration passed
+	assert.NoError(t, client.SetArticle(&types.Article{Name: "Skittles"}))
+	assert.True(t, testClient.Client == client.reader())
+	client.PinDuration = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	assert.True(t, replicaDB == client.reader())
+
+	// replicas that don't respond don't serve reads
+	client.replicas[0].healthy = 0
+	assert.True(t, testClient.Client == client.reader())
+	client.checkReplicas()
+	assert.True(t, replicaDB == client.reader())
+
+	// reads only follow the writes of the same session
+	client.PinDuration = time.Hour
+	writing := client.Session(time.Time{}).(*Client)
+	reading := client.Session(time.Time{}).(*Client)
+	assert.NoError(t, writing.SetArticle(&types.Article{Name: "Mars"}))
+	assert.True(t, testClient.Client == writing.reader())
+	assert.True(t, replicaDB == reading.reader())
+	assert.True(t, replicaDB == client.reader())
+
+	// sessions continue from the time of their last write
+	assert.False(t, writing.LastWrite().IsZero())
+	assert.True(t, testClient.Client == client.Session(writing.LastWrite()).(*Client).reader())
+}
+
+func TestClient_AddReplica(t *testing.T) {
+	client := &Client{Client: testClient.Client}
+	assert.Error(t, client.AddReplica("host=localhost port=1 user=postgres dbname=postgres sslmode=disable"))
+
+	// replicas that are down on startup are added, but don't serve reads until they respond
+	assert.Len(t, client.replicas, 1)
+	assert.Equal(t, int32(0), client.replicas[0].healthy)
+	assert.True(t, testClient.Client == client.reader())
+	client.checkReplicas()
+	assert.True(t, testClient.Client == client.reader())
+}
+
+func TestClient_Options(t *testing.T) {
+	options, err := ParseOptions("max_open_conns=50, query_timeout=5s")
+	assert.NoError(t, err)
+	assert.Equal(t, 50, options.MaxOpenConns)
+	assert.Equal(t, 5*time.Second, options.QueryTimeout)
+	assert.Equal(t, DefaultOptions.BreakerThreshold, options.BreakerThreshold)
+	_, err = ParseOptions("max_open_conns")
+	assert.Error(t, err)
+	_, err = ParseOptions("pool_size=5")
+	assert.Error(t, err)
+
+	assert.Equal(t, "host=localhost statement_timeout=5000", withStatementTimeout("host=localhost", 5*time.Second))
+	assert.Equal(t, "postgres://localhost/api?sslmode=disable&statement_timeout=5000",
+		withStatementTimeout("postgres://localhost/api?sslmode=disable", 5*time.Second))
+
+	// only errors reaching the database open the breaker
+	assert.True(t, unavailable(driver.ErrBadConn))
+	assert.True(t, unavailable(&pq.Error{Code: "08006"}))
+	assert.False(t, unavailable(&pq.Error{Code: "23505"}))
+	assert.False(t, unavailable(gorm.ErrRecordNotFound))
+	_, err = testClient.Available()
+	assert.NoError(t, err)
+}
+
+func TestClient_Audit(t *testing.T) {
+	testClient.Client.DropTable(&types.AuditEntry{}, &types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{})
+	assert.NoError(t, testClient.autoMigrate())
+	client := testClient.Audited("backoffice", "host/abcdef-000001")
+
+	article := testArticle
+	assert.NoError(t, client.SetArticle(&article))
+	article.Price = types.NewMoney(299, "USD")
+	assert.NoError(t, client.SetArticle(&article))
+	// updates that don't change anything aren't recorded
+	assert.NoError(t, client.SetArticle(&article))
+	assert.NoError(t, testClient.DeleteArticle(article.ID))
+
+	got := testClient.GetAuditEntries(0, &types.AuditFilter{Resource: "article", ResourceID: article.ID})
+	assert.Len(t, got.Items, 3)
+	assert.Equal(t, types.AuditDeleted, got.Items[0].Action)
+	assert.Equal(t, types.AuditSystemActor, got.Items[0].Actor)
+	assert.Equal(t, types.AuditUpdated, got.Items[1].Action)
+	assert.Equal(t, "backoffice", got.Items[1].Actor)
+	assert.Equal(t, "host/abcdef-000001", got.Items[1].RequestID)
+	changes, err := json.Marshal(got.Items[1].Changes)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"price":{"before":{"amount":"1.99","currency":"USD"},"after":{"amount":"2.99","currency":"USD"}}}`, string(changes))
+	assert.Equal(t, types.AuditCreated, got.Items[2].Action)
+
+	got = testClient.GetAuditEntries(0, &types.AuditFilter{Actor: "backoffice"})
+	assert.Len(t, got.Items, 2)
+	future := time.Now().Add(time.Hour)
+	got = testClient.GetAuditEntries(0, &types.AuditFilter{From: &future})
+	assert.Len(t, got.Items, 0)
+
+	// the audit log is append-only
+	assert.Error(t, testClient.Client.Delete(&types.AuditEntry{}).Error)
+}
+
+func TestClient_Transaction(t *testing.T) {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{})
+	testClient.autoMigrate()
+
+	// changes are rolled back if fn fails, but are visible to fn until then
+	err := testClient.Transaction(func(client ClientInterface) error {
+		article := testArticle
+		assert.NoError(t, client.SetArticle(&article))
+		assert.Equal(t, testArticle.Name, client.GetArticleByID(article.ID).Name)
+		return errors.New("rollback")
+	})
+	assert.EqualError(t, err, "rollback")
+	assert.Len(t, testClient.GetArticles(0, nil).Items, 0)
+
+	err = testClient.Transaction(func(client ClientInterface) error {
+		article := testArticle
+		return client.Audited("backoffice", "").SetArticle(&article)
+	})
+	assert.NoError(t, err)
+	assert.Len(t, testClient.GetArticles(0, nil).Items, 1)
+}
+
+func TestClient_Bulk(t *testing.T) {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{}, &types.Order{}, &types.OrderItem{})
+	testClient.autoMigrate()
+
+	first := testArticle
+	assert.NoError(t, testClient.SetArticle(&first))
+
+	// existing articles only change their non-empty fields, new ones get the next ids
+	articles := []*types.Article{
+		{ID: first.ID, Price: types.NewMoney(249, "USD")},
+		{Name: "Jelly Beans", Price: types.NewMoney(299, "USD"), Tags: []string{"vegan"}},
+	}
+	assert.NoError(t, testClient.SetArticles(articles))
+	assert.Equal(t, 2, articles[1].ID)
+	assert.Equal(t, []string{"vegan"}, articles[1].Tags)
+	stored := testClient.GetArticlesByIDs([]int{2, 1})
+	assert.Len(t, stored, 2)
+	assert.Equal(t, "Skittles", stored[0].Name)
+	assert.Equal(t, types.NewMoney(249, "USD"), stored[0].Price)
+	assert.Len(t, testClient.GetArticlePrices(1, 0).Items, 2)
+	assert.EqualError(t, testClient.SetArticles([]*types.Article{{ID: 1}, {ID: 1}}), "article 1 is given more than once")
+
+	orders := []*types.Order{
+		{Items: []*types.OrderItem{{ArticleID: 1, Quantity: 2}}},
+		{Items: []*types.OrderItem{{ArticleID: 2, Quantity: 1}}},
+	}
+	assert.NoError(t, testClient.SetOrders(orders))
+	assert.Equal(t, 1, orders[0].ID)
+	assert.Equal(t, 2, orders[1].ID)
+	assert.Equal(t, types.NewMoney(498, "USD"), orders[0].Total)
+
+	got := testClient.GetOrdersByIDs([]int{2, 1, 3})
+	assert.Len(t, got, 2)
+	assert.Equal(t, 2, got[0].Items[0].Quantity)
+	assert.Equal(t, types.NewMoney(299, "USD"), got[1].Total)
+
+	// the items of updated orders are replaced, items of other orders can't be taken over and the time the
+	// orders were placed is kept
+	placed := got[0].DateTime
+	update := []*types.Order{{ID: 1, DateTime: placed.Add(-time.Hour), Items: []*types.OrderItem{{ArticleID: 2, Quantity: 1}}}}
+	assert.NoError(t, testClient.SetOrders(update))
+	got = testClient.GetOrdersByIDs([]int{1})
+	assert.Len(t, got[0].Items, 1)
+	assert.Equal(t, 2, got[0].Items[0].ArticleID)
+	assert.True(t, placed.Equal(got[0].DateTime))
+	assert.Equal(t, types.NewMoney(299, "USD"), got[0].Total)
+	steal := []*types.Order{{ID: 1, Items: []*types.OrderItem{{ID: orders[1].Items[0].ID, ArticleID: 2, Quantity: 1}}}}
+	assert.EqualError(t, testClient.SetOrders(steal), fmt.Sprintf("item %d isn't an item of the order", orders[1].Items[0].ID))
+	assert.Len(t, testClient.GetOrderByID(2).Items, 1)
+}
+
+func TestClient_Select(t *testing.T) {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{}, &types.Order{}, &types.OrderItem{})
+	testClient.autoMigrate()
+
+	article := types.Article{Name: "Skittles", Price: types.NewMoney(199, "USD"), TaxCategory: "food", Tags: []string{"vegan"}}
+	assert.NoError(t, testClient.SetArticle(&article))
+	order := types.Order{Items: []*types.OrderItem{{ArticleID: article.ID, Quantity: 2}}}
+	assert.NoError(t, testClient.SetOrder(&order))
+
+	// only the selected columns and details are read, the id always is
+	client := testClient.Select([]string{"name"})
+	assert.Equal(t, &types.Article{ID: article.ID, Name: "Skittles"}, client.GetArticleByID(article.ID))
+	assert.Equal(t, []*types.Article{{ID: article.ID, Name: "Skittles"}}, client.GetArticles(0, nil).Items)
+	got := testClient.Select([]string{"price", "tags"}).GetArticlesByIDs([]int{article.ID})
+	assert.Len(t, got, 1)
+	assert.Equal(t, "", got[0].Name)
+	assert.Equal(t, types.NewMoney(199, "USD"), got[0].Price)
+	assert.Equal(t, []string{"vegan"}, got[0].Tags)
+	assert.Nil(t, got[0].Variants)
+
+	assert.Nil(t, client.GetOrderByID(order.ID).Items)
+	assert.Len(t, testClient.Select([]string{"items"}).GetOrders(0, nil).Items[0].Items, 1)
+	assert.Equal(t, types.NewMoney(398, "USD"), testClient.Select([]string{"total"}).GetOrdersByIDs([]int{order.ID})[0].Total)
+}
+
+func benchmarkArticles(b *testing.B, n int) []*types.Article {
+	testClient.Client.DropTable(&types.ArticleCategory{}, &types.ArticleTag{}, &types.ArticleImage{}, &types.Variant{}, &types.Article{})
+	testClient.autoMigrate()
+	articles := make([]*types.Article, 0, n)
+	for i := 0; i < n; i++ {
+		article := testArticle
+		articles = append(articles, &article)
+	}
+	b.ResetTimer()
+	return articles
+}
+
+func BenchmarkClient_SetArticle(b *testing.B) {
+	articles := benchmarkArticles(b, 100)
+	for i := 0; i < b.N; i++ {
+		for _, article := range articles {
+			if err := testClient.SetArticle(article); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkClient_SetArticles(b *testing.B) {
+	articles := benchmarkArticles(b, 100)
+	for i := 0; i < b.N; i++ {
+		if err := testClient.SetArticles(articles); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkClient_GetArticleByID(b *testing.B) {
+	articles := benchmarkArticles(b, 100)
+	if err := testClient.SetArticles(articles); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, article := range articles {
+			testClient.GetArticleByID(article.ID)
+		}
+	}
+}
+
+func BenchmarkClient_GetArticlesByIDs(b *testing.B) {
+	articles := benchmarkArticles(b, 100)
+	if err := testClient.SetArticles(articles); err != nil {
+		b.Fatal(err)
+	}
+	ids := make([]int, 0, len(articles))
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		testClient.GetArticlesByIDs(ids)
+	}
 }
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// Select returns a client whose queries of articles and orders only read the given fields, which are json names
+// like in the responses of the api. The id is always read. Details that aren't stored in the table of the resource,
+// such as the tags of articles or the items of orders, are only loaded if they are selected.
+// All fields are read if fields is nil.
+func (c *Client) Select(fields []string) ClientInterface {
+	return &Client{
+		Client:        c.Client,
+		Pricing:       c.Pricing,
+		Options:       c.Options,
+		PinDuration:   c.PinDuration,
+		replicas:      c.replicas,
+		session:       c.session,
+		breaker:       c.breaker,
+		origin:        c.root(),
+		inTransaction: c.inTransaction,
+		fields:        fields,
+	}
+}
+
+// selects reports whether the client reads the field with the given json name
+func (c *Client) selects(field string) bool {
+	if c.fields == nil {
+		return true
+	}
+	for _, f := range c.fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// selectFields restricts the query of the model to the columns of the selected fields
+func (c *Client) selectFields(query *gorm.DB, model interface{}) *gorm.DB {
+	if c.fields == nil {
+		return query
+	}
+	t := reflect.TypeOf(model).Elem()
+	columns := []string{}
+	for _, field := range query.NewScope(model).GetModelStruct().StructFields {
+		if field.IsIgnored || !field.IsNormal {
+			continue
+		}
+		// embedded fields like prices are stored in several columns, which belong to the field of the model
+		sf, _ := t.FieldByName(field.Names[0])
+		if field.IsPrimaryKey || c.selects(strings.Split(sf.Tag.Get("json"), ",")[0]) {
+			columns = append(columns, field.DBName)
+		}
+	}
+	return query.Select(columns)
+}
+
+// preloadItems preloads the items of orders if they are selected
+func (c *Client) preloadItems(query *gorm.DB) *gorm.DB {
+	if !c.selects("items") {
+		return query
+	}
+	return query.Preload("Items")
+}
+
+// loadSelectedDetails loads the selected details of the articles
+func (c *Client) loadSelectedDetails(tx *gorm.DB, articles ...*types.Article) error {
+	if c.fields == nil {
+		return loadArticleDetails(tx, articles...)
+	}
+	if c.selects("category_ids") || c.selects("tags") {
+		if err := loadTaxonomy(tx, articles...); err != nil {
+			return err
+		}
+	}
+	if c.selects("variants") {
+		if err := loadVariants(tx, articles...); err != nil {
+			return err
+		}
+	}
+	if c.selects("images") {
+		return loadImages(tx, articles...)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// GetArticleImages returns all images of an article from the database, oldest first
+func (c *Client) GetArticleImages(articleID int) []*types.ArticleImage {
+	images := []*types.ArticleImage{}
+	c.reader().Where("article_id = ?", articleID).Order("id").Find(&images)
+	for _, image := range images {
+		image.SetURLs()
+	}
+	return images
+}
+
+// GetArticleImageByID queries an article image from the database
+func (c *Client) GetArticleImageByID(id int) *types.ArticleImage {
+	image := &types.ArticleImage{}
+	if err := c.reader().Where("id = ?", id).First(image).Error; err != nil {
+		return nil
+	}
+	image.SetURLs()
+	return image
+}
+
+// AddArticleImage writes the metadata of an uploaded article image to the database
+func (c *Client) AddArticleImage(image *types.ArticleImage) error {
+	image.ID = 0
+	return c.transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(image).Error; err != nil {
+			return err
+		}
+		image.SetURLs()
+		return writeAudit(tx, "article_image", image.ID, nil, image)
+	})
+}
+
+// DeleteArticleImage deletes the metadata of an image of the article from the database
+func (c *Client) DeleteArticleImage(articleID, id int) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		image := &types.ArticleImage{}
+		before, err := loadPrevious(tx, image, id)
+		if err != nil || before == nil || image.ArticleID != articleID {
+			return err
+		}
+		if err := tx.Where("id = ?", id).Delete(&types.ArticleImage{}).Error; err != nil {
+			return err
+		}
+		image.SetURLs()
+		return writeAudit(tx, "article_image", id, image, nil)
+	})
+}
+
+// loadArticleDetails fills the categories, tags, variants and images of the articles
+func loadArticleDetails(tx *gorm.DB, articles ...*types.Article) error {
+	if err := loadTaxonomy(tx, articles...); err != nil {
+		return err
+	}
+	if err := loadVariants(tx, articles...); err != nil {
+		return err
+	}
+	return loadImages(tx, articles...)
+}
+
+// loadImages fills the images of the articles with a single query
+func loadImages(tx *gorm.DB, articles ...*types.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(articles))
+	byID := map[int]*types.Article{}
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+		byID[article.ID] = article
+	}
+
+	images := []*types.ArticleImage{}
+	if err := tx.Where("article_id IN (?)", ids).Order("id").Find(&images).Error; err != nil {
+		return err
+	}
+	for _, image := range images {
+		image.SetURLs()
+		article := byID[image.ArticleID]
+		article.Images = append(article.Images, image)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// AdjustStock changes the available stock of an article or of one of its variants and records the adjustment
+// in the inventory ledger. The first adjustment of an article or variant starts tracking its stock.
+// The stock can't become negative.
+func (c *Client) AdjustStock(adjustment *types.StockAdjustment) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		key := stockKey{ArticleID: adjustment.ArticleID, VariantID: adjustment.VariantID}
+		if key.VariantID != 0 {
+			count := 0
+			if err := tx.Model(&types.Variant{}).Where("id = ? AND article_id = ?", key.VariantID, key.ArticleID).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return fmt.Errorf("variant %d of article %d doesn't exist", key.VariantID, key.ArticleID)
+			}
+		}
+		model, id := key.row()
+		res := tx.Model(model).
+			Where("id = ? AND COALESCE(stock, 0) + ? >= 0", id, adjustment.Delta).
+			UpdateColumn("stock", gorm.Expr("COALESCE(stock, 0) + ?", adjustment.Delta))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return stockError(tx, key, -adjustment.Delta)
+		}
+		if err := tx.Create(adjustment).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, "stock_adjustment", adjustment.ID, nil, adjustment)
+	})
+}
+
+// GetStockAdjustments returns the inventory ledger of an article from the database, newest first
+func (c *Client) GetStockAdjustments(articleID int, pageID int) *types.StockAdjustmentList {
+	adjustments := &types.StockAdjustmentList{}
+	query := c.reader().Where("article_id = ?", articleID)
+	if pageID > 0 {
+		query = query.Where("id <= ?", pageID)
+	}
+	query.Order("id DESC").Limit(pageSize + 1).Find(&adjustments.Items)
+	if len(adjustments.Items) == pageSize+1 {
+		adjustments.NextPageID = adjustments.Items[len(adjustments.Items)-1].ID
+		adjustments.Items = adjustments.Items[:pageSize]
+	}
+	return adjustments
+}
+
+// stockKey identifies the stock of an article or of one of its variants
+type stockKey struct {
+	ArticleID int
+	VariantID int
+}
+
+// row returns the model and id of the row that holds the stock
+func (k stockKey) row() (interface{}, int) {
+	if k.VariantID != 0 {
+		return &types.Variant{}, k.VariantID
+	}
+	return &types.Article{}, k.ArticleID
+}
+
+// reserveStock atomically reserves the stock of the order items as part of the transaction.
+// Each article or variant is only updated if it has enough stock left, so concurrent orders can't oversell it.
+func reserveStock(tx *gorm.DB, orderID int, items []*types.OrderItem) error {
+	quantities, keys := itemQuantities(items)
+	for _, key := range keys {
+		quantity := quantities[key]
+		model, id := key.row()
+		res := tx.Model(model).
+			Where("id = ? AND (stock IS NULL OR stock >= ?)", id, quantity).
+			UpdateColumns(map[string]interface{}{
+				"stock":    gorm.Expr("stock - ?", quantity),
+				"reserved": gorm.Expr("reserved + ?", quantity),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return stockError(tx, key, quantity)
+		}
+		if err := tx.Create(&types.StockAdjustment{
+			ArticleID: key.ArticleID,
+			VariantID: key.VariantID,
+			Delta:     -quantity,
+			Reason:    types.StockReservation,
+			OrderID:   orderID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseStock returns the reserved stock of the order items as part of the transaction
+func releaseStock(tx *gorm.DB, orderID int, items []*types.OrderItem) error {
+	quantities, keys := itemQuantities(items)
+	for _, key := range keys {
+		quantity := quantities[key]
+		model, id := key.row()
+		if err := tx.Model(model).
+			Where("id = ?", id).
+			UpdateColumns(map[string]interface{}{
+				"stock":    gorm.Expr("stock + ?", quantity),
+				"reserved": gorm.Expr("GREATEST(reserved - ?, 0)", quantity),
+			}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&types.StockAdjustment{
+			ArticleID: key.ArticleID,
+			VariantID: key.VariantID,
+			Delta:     quantity,
+			Reason:    types.StockRelease,
+			OrderID:   orderID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemQuantities sums up the quantities of the items per article and variant. The keys are sorted,
+// so that concurrent transactions lock the rows in the same order and can't deadlock.
+func itemQuantities(items []*types.OrderItem) (map[stockKey]int, []stockKey) {
+	quantities := map[stockKey]int{}
+	keys := []stockKey{}
+	for _, item := range items {
+		key := stockKey{ArticleID: item.ArticleID, VariantID: item.VariantID}
+		if _, ok := quantities[key]; !ok {
+			keys = append(keys, key)
+		}
+		quantities[key] += item.Quantity
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].ArticleID != keys[j].ArticleID {
+			return keys[i].ArticleID < keys[j].ArticleID
+		}
+		return keys[i].VariantID < keys[j].VariantID
+	})
+	return quantities, keys
+}
+
+// stockError explains why the stock of an article or variant couldn't be changed by the requested quantity
+func stockError(tx *gorm.DB, key stockKey, requested int) error {
+	var stock *int
+	if key.VariantID != 0 {
+		variant := &types.Variant{}
+		if err := tx.Where("id = ?", key.VariantID).First(variant).Error; err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return fmt.Errorf("variant %d doesn't exist", key.VariantID)
+			}
+			return err
+		}
+		stock = variant.Stock
+	} else {
+		article := &types.Article{}
+		if err := tx.Where("id = ?", key.ArticleID).First(article).Error; err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return fmt.Errorf("article %d doesn't exist", key.ArticleID)
+			}
+			return err
+		}
+		stock = article.Stock
+	}
+	available := 0
+	if stock != nil {
+		available = *stock
+	}
+	return &types.InsufficientStockError{ArticleID: key.ArticleID, VariantID: key.VariantID, Requested: requested, Available: available}
+}
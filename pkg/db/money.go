@@ -0,0 +1,38 @@
+package db
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// migrateMoney converts the decimal prices of articles written before the introduction of the money type
+// into minor units of the default currency. It refuses to run if a price has more decimals than the
+// currency has minor units, because those can't be converted without loss.
+func (c *Client) migrateMoney() error {
+	if !c.Client.Dialect().HasColumn("articles", "price") {
+		return nil
+	}
+	exp, ok := types.CurrencyExponent(types.DefaultCurrency)
+	if !ok {
+		return fmt.Errorf("unsupported default currency %q", types.DefaultCurrency)
+	}
+
+	return c.Client.Transaction(func(tx *gorm.DB) error {
+		lossy := 0
+		if err := tx.Raw("SELECT count(*) FROM articles WHERE price <> ROUND(price, ?)", exp).Row().Scan(&lossy); err != nil {
+			return err
+		}
+		if lossy > 0 {
+			return fmt.Errorf("can't convert %d article prices to %s without loss, round them to %d decimals first", lossy, types.DefaultCurrency, exp)
+		}
+		if err := tx.Exec("UPDATE articles SET price_amount = (price * ?)::bigint, price_currency = ?",
+			int64(math.Pow10(exp)), types.DefaultCurrency).Error; err != nil {
+			return err
+		}
+		return tx.Exec("ALTER TABLE articles DROP COLUMN price").Error
+	})
+}
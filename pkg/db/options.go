@@ -0,0 +1,206 @@
+package db
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+
+	"github.com/jonnylangefeld/go-api/pkg/breaker"
+)
+
+// ErrUnavailable is returned by Available while the database is considered down
+var ErrUnavailable = errors.New("database is unavailable")
+
+// Options tune the connections to the database
+type Options struct {
+	// MaxOpenConns limits the open connections per database, 0 means unlimited
+	MaxOpenConns int
+	// MaxIdleConns limits the idle connections kept open per database
+	MaxIdleConns int
+	// ConnMaxLifetime closes connections after they were open for this long, 0 keeps them forever
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime closes connections after they were idle for this long, 0 keeps them forever
+	ConnMaxIdleTime time.Duration
+	// QueryTimeout cancels statements running longer than this, 0 means no timeout
+	QueryTimeout time.Duration
+	// ConnectTimeout is the time Connect keeps retrying to reach the database on startup
+	ConnectTimeout time.Duration
+	// BreakerThreshold is the number of consecutive failed queries after which the database is considered down
+	BreakerThreshold int
+	// BreakerCooldown is the time the database is considered down before it is probed again
+	BreakerCooldown time.Duration
+}
+
+// DefaultOptions are the options of clients that don't set their own
+var DefaultOptions = Options{
+	MaxOpenConns:     20,
+	MaxIdleConns:     10,
+	ConnMaxLifetime:  30 * time.Minute,
+	ConnMaxIdleTime:  5 * time.Minute,
+	QueryTimeout:     10 * time.Second,
+	ConnectTimeout:   time.Minute,
+	BreakerThreshold: 5,
+	BreakerCooldown:  10 * time.Second,
+}
+
+// ParseOptions parses a comma separated list of name=value options, such as max_open_conns=50,query_timeout=5s,
+// on top of the default options. Durations use the format of time.ParseDuration.
+func ParseOptions(s string) (Options, error) {
+	options := DefaultOptions
+	ints := map[string]*int{
+		"max_open_conns":    &options.MaxOpenConns,
+		"max_idle_conns":    &options.MaxIdleConns,
+		"breaker_threshold": &options.BreakerThreshold,
+	}
+	durations := map[string]*time.Duration{
+		"conn_max_lifetime":  &options.ConnMaxLifetime,
+		"conn_max_idle_time": &options.ConnMaxIdleTime,
+		"query_timeout":      &options.QueryTimeout,
+		"connect_timeout":    &options.ConnectTimeout,
+		"breaker_cooldown":   &options.BreakerCooldown,
+	}
+	for _, option := range strings.Split(s, ",") {
+		if option = strings.TrimSpace(option); option == "" {
+			continue
+		}
+		parts := strings.SplitN(option, "=", 2)
+		if len(parts) != 2 {
+			return options, fmt.Errorf("invalid database option %q, expected name=value", option)
+		}
+		name, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		var err error
+		if i, ok := ints[name]; ok {
+			*i, err = strconv.Atoi(value)
+		} else if d, ok := durations[name]; ok {
+			*d, err = time.ParseDuration(value)
+		} else {
+			return options, fmt.Errorf("unknown database option %q", name)
+		}
+		if err != nil {
+			return options, fmt.Errorf("invalid database option %q: %w", option, err)
+		}
+	}
+	return options, nil
+}
+
+// options returns the options of the client, which are the default options if it has none
+func (c *Client) options() Options {
+	if c.Options == nil {
+		return DefaultOptions
+	}
+	return *c.Options
+}
+
+// open connects to the database with the options of the client, retrying with exponential backoff until the
+// connect timeout passed
+func (c *Client) open(connectionString string) (*gorm.DB, error) {
+	options := c.options()
+	connectionString = withStatementTimeout(connectionString, options.QueryTimeout)
+	deadline := time.Now().Add(options.ConnectTimeout)
+	delay := 100 * time.Millisecond
+	for {
+		conn, err := gorm.Open("postgres", connectionString)
+		if err == nil {
+			c.configure(conn)
+			return conn, nil
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return nil, err
+		}
+		time.Sleep(delay)
+		if delay *= 2; delay > 5*time.Second {
+			delay = 5 * time.Second
+		}
+	}
+}
+
+// configure applies the connection pool options of the client to the connection
+func (c *Client) configure(conn *gorm.DB) {
+	options := c.options()
+	conn.LogMode(false)
+	conn.DB().SetMaxOpenConns(options.MaxOpenConns)
+	conn.DB().SetMaxIdleConns(options.MaxIdleConns)
+	conn.DB().SetConnMaxLifetime(options.ConnMaxLifetime)
+	conn.DB().SetConnMaxIdleTime(options.ConnMaxIdleTime)
+}
+
+// withStatementTimeout adds the timeout as statement_timeout run-time parameter to the connection string,
+// which postgres applies to every statement of the connections. Connection strings that already set it are kept.
+func withStatementTimeout(connectionString string, timeout time.Duration) string {
+	if timeout <= 0 || strings.Contains(connectionString, "statement_timeout") {
+		return connectionString
+	}
+	ms := strconv.FormatInt(timeout.Milliseconds(), 10)
+	if u, err := url.Parse(connectionString); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		query := u.Query()
+		query.Set("statement_timeout", ms)
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+	return strings.TrimSpace(connectionString + " statement_timeout=" + ms)
+}
+
+// Available returns ErrUnavailable and the time until the database is probed again while the database is
+// considered down, because the last queries failed to reach it
+func (c *Client) Available() (time.Duration, error) {
+	if c.breaker == nil {
+		return 0, nil
+	}
+	wait, err := c.breaker.Allow()
+	if err != nil {
+		return wait, ErrUnavailable
+	}
+	return 0, nil
+}
+
+// watch records the outcome of every query of the primary in the circuit breaker of the client.
+// Database/sql replaces broken connections on its own, so the database is used again as soon as it is back.
+func (c *Client) watch() {
+	options := c.options()
+	c.breaker = breaker.New(options.BreakerThreshold, options.BreakerCooldown, c.Ping)
+	record := func(scope *gorm.Scope) {
+		if unavailable(scope.DB().Error) {
+			c.breaker.Failure()
+		} else {
+			c.breaker.Success()
+		}
+	}
+	callbacks := c.Client.Callback()
+	callbacks.Create().After("gorm:create").Register("go-api:breaker", record)
+	callbacks.Update().After("gorm:update").Register("go-api:breaker", record)
+	callbacks.Delete().After("gorm:delete").Register("go-api:breaker", record)
+	callbacks.Query().After("gorm:query").Register("go-api:breaker", record)
+	callbacks.RowQuery().After("gorm:row_query").Register("go-api:breaker", record)
+}
+
+// unavailable reports whether the error means that the database couldn't be reached, rather than that the
+// query itself failed
+func unavailable(err error) bool {
+	if err == nil || gorm.IsRecordNotFoundError(err) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// connection exceptions, shutdowns and statements canceled by the statement timeout
+		return pqErr.Code.Class() == "08" || pqErr.Code == "57P01" || pqErr.Code == "57P03" || pqErr.Code == "57014"
+	}
+	return false
+}
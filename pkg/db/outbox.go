@@ -0,0 +1,85 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// outboxLockKey is the key of the postgres advisory lock held by the relay publishing the outbox
+const outboxLockKey = 7301
+
+// writeOutbox stores an event of the given type in the outbox as part of the transaction,
+// so that it is only published if the change it describes is committed
+func writeOutbox(tx *gorm.DB, eventType string, data interface{}) error {
+	event, err := types.NewOutboxEvent(types.NewEvent(eventType, data))
+	if err != nil {
+		return err
+	}
+	return tx.Create(event).Error
+}
+
+// exists reports whether a row of the model with the given id exists
+func exists(tx *gorm.DB, model interface{}, id int) bool {
+	if id == 0 {
+		return false
+	}
+	count := 0
+	tx.Model(model).Where("id = ?", id).Count(&count)
+	return count > 0
+}
+
+// LockOutbox runs fn with a client whose reads and writes happen in a transaction holding the lock of the outbox
+// relay and reports whether it got the lock. fn isn't run while another relay holds the lock, so that only one
+// relay per database publishes the events at a time, once and in order. The lock is released with the transaction.
+func (c *Client) LockOutbox(fn func(client ClientInterface) error) (bool, error) {
+	locked := false
+	err := c.Client.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", outboxLockKey).Row().Scan(&locked); err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+		return fn(&Client{
+			Client:        tx,
+			Pricing:       c.Pricing,
+			Options:       c.Options,
+			PinDuration:   c.PinDuration,
+			breaker:       c.breaker,
+			origin:        c.root(),
+			inTransaction: true,
+		})
+	})
+	return locked, err
+}
+
+// GetUnpublishedOutboxEvents returns up to limit outbox events that weren't published to all sinks yet, oldest first
+func (c *Client) GetUnpublishedOutboxEvents(limit int) []*types.OutboxEvent {
+	events := []*types.OutboxEvent{}
+	c.Client.Where("published_at IS NULL").Order("id").Limit(limit).Find(&events)
+	return events
+}
+
+// SetOutboxEvent writes an outbox event to the database
+func (c *Client) SetOutboxEvent(event *types.OutboxEvent) error {
+	return c.Client.Save(event).Error
+}
+
+// GetOutboxEventsAfter returns up to limit outbox events following the event with the given id, oldest first.
+// The outbox doubles as the change log streams are resumed from.
+func (c *Client) GetOutboxEventsAfter(id int, limit int) []*types.OutboxEvent {
+	events := []*types.OutboxEvent{}
+	c.Client.Where("id > ?", id).Order("id").Limit(limit).Find(&events)
+	return events
+}
+
+// GetLastOutboxEventID returns the id of the latest outbox event, or 0 if the outbox is empty
+func (c *Client) GetLastOutboxEventID() int {
+	ids := []int{}
+	c.Client.Model(&types.OutboxEvent{}).Order("id DESC").Limit(1).Pluck("id", &ids)
+	if len(ids) == 0 {
+		return 0
+	}
+	return ids[0]
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// GetArticlePrices returns the price history of an article including its scheduled prices from the database,
+// latest first
+func (c *Client) GetArticlePrices(articleID int, pageID int) *types.ArticlePriceList {
+	prices := &types.ArticlePriceList{}
+	query := c.reader().Where("article_id = ?", articleID)
+	if pageID > 0 {
+		query = query.Where("(effective_from, id) <= (SELECT effective_from, id FROM article_prices WHERE id = ?)", pageID)
+	}
+	query.Order("effective_from DESC, id DESC").Limit(pageSize + 1).Find(&prices.Items)
+	if len(prices.Items) == pageSize+1 {
+		prices.NextPageID = prices.Items[len(prices.Items)-1].ID
+		prices.Items = prices.Items[:pageSize]
+	}
+	return prices
+}
+
+// SchedulePrice records a future price of an article, which is applied to the article once it takes effect.
+// A scheduled price replaces another price of the article that takes effect at the same time.
+func (c *Client) SchedulePrice(price *types.ArticlePrice) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		price.Applied = false
+		var before interface{}
+		same := &types.ArticlePrice{}
+		err := tx.Where("article_id = ? AND effective_from = ?", price.ArticleID, price.EffectiveFrom).First(same).Error
+		switch {
+		case err == nil:
+			before = same
+		case !gorm.IsRecordNotFoundError(err):
+			return err
+		}
+		if err := recordPrice(tx, price); err != nil {
+			return err
+		}
+		return writeAudit(tx, "article_price", price.ID, before, price)
+	})
+}
+
+// ApplyScheduledPrices applies the scheduled prices that took effect until now to their articles, together
+// with an outbox event of every change. Prices that were superseded before they were applied are only
+// marked as applied. It returns the number of changed articles.
+func (c *Client) ApplyScheduledPrices(now time.Time) (int, error) {
+	changed := 0
+	err := c.Client.Transaction(func(tx *gorm.DB) error {
+		changed = 0
+		due := []*types.ArticlePrice{}
+		if err := tx.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").
+			Where("applied = ? AND effective_from <= ?", false, now).
+			Order("effective_from, id").Find(&due).Error; err != nil {
+			return err
+		}
+
+		for _, price := range due {
+			if err := tx.Model(price).UpdateColumn("applied", true).Error; err != nil {
+				return err
+			}
+			if price.EffectiveTo != nil && !price.EffectiveTo.After(now) {
+				continue
+			}
+
+			article := &types.Article{}
+			if err := tx.Where("id = ?", price.ArticleID).First(article).Error; err != nil {
+				if gorm.IsRecordNotFoundError(err) {
+					continue
+				}
+				return err
+			}
+			if article.Price == price.Price {
+				continue
+			}
+			before := *article
+			if err := tx.Model(article).UpdateColumns(map[string]interface{}{
+				"price_amount":   price.Price.Amount,
+				"price_currency": price.Price.Currency,
+			}).Error; err != nil {
+				return err
+			}
+			article.Price = price.Price
+			if err := writeAudit(tx, "article", article.ID, &before, article); err != nil {
+				return err
+			}
+			if err := writeOutbox(tx, types.EventArticleUpdated, article); err != nil {
+				return err
+			}
+			changed++
+		}
+		return nil
+	})
+	// only pin reads to the primary if something changed, because this runs periodically
+	if changed > 0 {
+		c.pin()
+	}
+	return changed, err
+}
+
+// recordPrice inserts the price into the timeline of its article as part of the transaction.
+// The previous price ends when it takes effect and it ends when the next price takes effect.
+func recordPrice(tx *gorm.DB, price *types.ArticlePrice) error {
+	same := &types.ArticlePrice{}
+	err := tx.Where("article_id = ? AND effective_from = ?", price.ArticleID, price.EffectiveFrom).First(same).Error
+	switch {
+	case err == nil:
+		price.ID = same.ID
+		price.EffectiveTo = same.EffectiveTo
+		price.CreatedAt = same.CreatedAt
+		return tx.Save(price).Error
+	case !gorm.IsRecordNotFoundError(err):
+		return err
+	}
+
+	next := &types.ArticlePrice{}
+	err = tx.Where("article_id = ? AND effective_from > ?", price.ArticleID, price.EffectiveFrom).
+		Order("effective_from").First(next).Error
+	switch {
+	case err == nil:
+		price.EffectiveTo = &next.EffectiveFrom
+	case gorm.IsRecordNotFoundError(err):
+		price.EffectiveTo = nil
+	default:
+		return err
+	}
+
+	if err := tx.Model(&types.ArticlePrice{}).
+		Where("article_id = ? AND effective_from < ? AND (effective_to IS NULL OR effective_to > ?)",
+			price.ArticleID, price.EffectiveFrom, price.EffectiveFrom).
+		UpdateColumn("effective_to", price.EffectiveFrom).Error; err != nil {
+		return err
+	}
+	price.ID = 0
+	return tx.Create(price).Error
+}
+
+// articlesAt returns the articles with the given ids with the prices that were effective at the given time.
+// Articles without a price at that time, such as articles that were created before prices were recorded,
+// keep their current price.
+func articlesAt(tx *gorm.DB, articleIDs []int, at time.Time) (map[int]*types.Article, error) {
+	articles := map[int]*types.Article{}
+	if len(articleIDs) == 0 {
+		return articles, nil
+	}
+
+	found := []*types.Article{}
+	if err := tx.Where("id IN (?)", articleIDs).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	for _, article := range found {
+		articles[article.ID] = article
+	}
+
+	effective := []*types.ArticlePrice{}
+	if err := tx.Where("article_id IN (?) AND effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)",
+		articleIDs, at, at).Find(&effective).Error; err != nil {
+		return nil, err
+	}
+	for _, price := range effective {
+		if article, ok := articles[price.ArticleID]; ok {
+			article.Price = price.Price
+		}
+	}
+	return articles, nil
+}
@@ -0,0 +1,228 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+
+	"github.com/jonnylangefeld/go-api/pkg/pricing"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// QuoteOrder prices an order with the article prices that were effective at the time of the order
+// without writing anything to the database. Variants with their own price are priced with it.
+// Like when orders are written, the time of a stored order is kept and new orders are priced now.
+func (c *Client) QuoteOrder(order *types.Order) (*types.Quote, error) {
+	conn := c.reader()
+	quoted := *order
+	quoted.DateTime = time.Time{}
+	if order.ID != 0 {
+		stored := &types.Order{}
+		if err := conn.Select("date_time").Where("id = ?", order.ID).First(stored).Error; err == nil {
+			quoted.DateTime = stored.DateTime
+		}
+	}
+	return c.quote(conn, &quoted, true)
+}
+
+// GetCouponByID queries a coupon from the database
+func (c *Client) GetCouponByID(id int) *types.Coupon {
+	coupon := &types.Coupon{}
+	if err := c.reader().Where("id = ?", id).First(coupon).Error; err != nil {
+		return nil
+	}
+	return coupon
+}
+
+// SetCoupon writes a coupon to the database. The number of uses is only changed by orders.
+// It returns types.ErrCouponCodeTaken if another coupon already uses the code.
+func (c *Client) SetCoupon(coupon *types.Coupon) error {
+	err := c.transaction(func(tx *gorm.DB) error {
+		taken := 0
+		if err := tx.Model(&types.Coupon{}).Where("code = ? AND id <> ?", coupon.Code, coupon.ID).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return types.ErrCouponCodeTaken
+		}
+
+		coupon.Uses = 0
+		stored := &types.Coupon{}
+		before, err := loadPrevious(tx, stored, coupon.ID)
+		if err != nil {
+			return err
+		}
+		if before != nil {
+			coupon.Uses = stored.Uses
+		}
+		if err := tx.Save(coupon).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, "coupon", coupon.ID, before, coupon)
+	})
+
+	// concurrent writes of the same code are caught by the unique index
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return types.ErrCouponCodeTaken
+	}
+	return err
+}
+
+// DeleteCoupon deletes a coupon from the database. Orders keep the code of the coupon.
+func (c *Client) DeleteCoupon(id int) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		before, err := loadPrevious(tx, &types.Coupon{}, id)
+		if err != nil || before == nil {
+			return err
+		}
+		if err := tx.Where("id = ?", id).Delete(&types.Coupon{}).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, "coupon", id, before, nil)
+	})
+}
+
+// GetCoupons returns all coupons from the database
+func (c *Client) GetCoupons(pageID int) *types.CouponList {
+	coupons := &types.CouponList{}
+	c.reader().Where("id >= ?", pageID).Order("id").Limit(pageSize + 1).Find(&coupons.Items)
+	if len(coupons.Items) == pageSize+1 {
+		coupons.NextPageID = coupons.Items[len(coupons.Items)-1].ID
+		coupons.Items = coupons.Items[:pageSize]
+	}
+	return coupons
+}
+
+// engine returns the pricing engine of the client, which doesn't tax orders unless it was configured
+func (c *Client) engine() *pricing.Engine {
+	if c.Pricing == nil {
+		return pricing.NewEngine()
+	}
+	return c.Pricing
+}
+
+// quote prices the order with the pricing engine at the time of the order, or now if it doesn't have one yet.
+// The usage limit of the coupon is only checked if checkUsage is set, because orders check it when they redeem
+// the coupon.
+func (c *Client) quote(tx *gorm.DB, order *types.Order, checkUsage bool) (*types.Quote, error) {
+	at := order.DateTime
+	if at.IsZero() {
+		at = gorm.NowFunc()
+	}
+
+	variants, err := resolveVariants(tx, order.Items)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(order.Items))
+	for _, item := range order.Items {
+		ids = append(ids, item.ArticleID)
+	}
+	articles, err := articlesAt(tx, ids, at)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]pricing.Item, 0, len(order.Items))
+	for _, item := range order.Items {
+		article, ok := articles[item.ArticleID]
+		if !ok {
+			return nil, fmt.Errorf("article %d doesn't exist", item.ArticleID)
+		}
+		unitPrice := article.Price
+		if variant, ok := variants[item.VariantID]; ok && !variant.Price.IsZero() {
+			unitPrice = variant.Price
+		}
+		items = append(items, pricing.Item{
+			ArticleID:   item.ArticleID,
+			VariantID:   item.VariantID,
+			Quantity:    item.Quantity,
+			UnitPrice:   unitPrice,
+			TaxCategory: article.TaxCategory,
+		})
+	}
+
+	var coupon *types.Coupon
+	if order.CouponCode != "" {
+		coupon = &types.Coupon{}
+		if err := tx.Where("code = ?", order.CouponCode).First(coupon).Error; err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return nil, &types.CouponError{Code: order.CouponCode, Reason: "it doesn't exist"}
+			}
+			return nil, err
+		}
+		if checkUsage && coupon.MaxUses != nil && coupon.Uses >= *coupon.MaxUses {
+			return nil, &types.CouponError{Code: coupon.Code, Reason: "its usage limit is reached"}
+		}
+	}
+
+	return c.engine().Quote(items, order.Region, coupon, at)
+}
+
+// priceOrder prices the stored order with the pricing engine and writes the unit prices of its items and its
+// totals as part of the transaction. The coupon of the order has to be redeemed already.
+func (c *Client) priceOrder(tx *gorm.DB, order *types.Order) error {
+	quote, err := c.quote(tx, order, false)
+	if err != nil {
+		return err
+	}
+
+	for i, item := range order.Items {
+		item.UnitPrice = quote.Items[i].UnitPrice
+		if err := tx.Model(item).UpdateColumns(map[string]interface{}{
+			"unit_price_amount":   item.UnitPrice.Amount,
+			"unit_price_currency": item.UnitPrice.Currency,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	order.Subtotal = quote.Subtotal
+	order.Discount = quote.Discount
+	order.Tax = quote.Tax
+	order.Total = quote.Total
+	return tx.Model(order).UpdateColumns(map[string]interface{}{
+		"subtotal_amount":   order.Subtotal.Amount,
+		"subtotal_currency": order.Subtotal.Currency,
+		"discount_amount":   order.Discount.Amount,
+		"discount_currency": order.Discount.Currency,
+		"tax_amount":        order.Tax.Amount,
+		"tax_currency":      order.Tax.Currency,
+		"total_amount":      order.Total.Amount,
+		"total_currency":    order.Total.Currency,
+	}).Error
+}
+
+// redeemCoupon counts a use of the current coupon of an order and releases the use of its previous coupon
+// as part of the transaction. A coupon is only redeemed if its usage limit isn't reached yet, so concurrent
+// orders can't exceed it.
+func redeemCoupon(tx *gorm.DB, previous, current string) error {
+	if previous == current {
+		return nil
+	}
+	if previous != "" {
+		if err := tx.Model(&types.Coupon{}).Where("code = ? AND uses > 0", previous).
+			UpdateColumn("uses", gorm.Expr("uses - 1")).Error; err != nil {
+			return err
+		}
+	}
+	if current == "" {
+		return nil
+	}
+
+	res := tx.Model(&types.Coupon{}).Where("code = ? AND (max_uses IS NULL OR uses < max_uses)", current).
+		UpdateColumn("uses", gorm.Expr("uses + 1"))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		count := 0
+		tx.Model(&types.Coupon{}).Where("code = ?", current).Count(&count)
+		if count == 0 {
+			return &types.CouponError{Code: current, Reason: "it doesn't exist"}
+		}
+		return &types.CouponError{Code: current, Reason: "its usage limit is reached"}
+	}
+	return nil
+}
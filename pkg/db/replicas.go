@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// DefaultPinDuration is the time reads go to the primary after a write unless Client.PinDuration is set
+const DefaultPinDuration = 2 * time.Second
+
+// replica is a read-only copy of the primary database
+type replica struct {
+	db *gorm.DB
+	// healthy is 1 while the replica responds to pings
+	healthy int32
+}
+
+// AddReplica connects to a read replica of the primary database. Lookups and lists are spread over the
+// healthy replicas, everything else keeps using the primary in Client.Client. A replica that doesn't respond is
+// added as unhealthy and serves reads once MonitorReplicas finds it responding, the returned error tells why.
+func (c *Client) AddReplica(connectionString string) error {
+	sqlDB, err := sql.Open("postgres", withStatementTimeout(connectionString, c.options().QueryTimeout))
+	if err != nil {
+		return err
+	}
+	// the connection is kept even if the ping of gorm fails, because it wasn't opened by gorm
+	replicaDB, err := gorm.Open("postgres", sqlDB)
+	c.configure(replicaDB)
+	r := &replica{db: replicaDB}
+	if err == nil {
+		r.healthy = 1
+	}
+	c.replicas = append(c.replicas, r)
+	return err
+}
+
+// MonitorReplicas pings the replicas every interval until the context is canceled.
+// Replicas that don't respond don't serve reads until they respond again.
+func (c *Client) MonitorReplicas(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.checkReplicas()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkReplicas pings all replicas and records whether they are healthy
+func (c *Client) checkReplicas() {
+	for _, r := range c.replicas {
+		healthy := int32(1)
+		if err := r.db.DB().Ping(); err != nil {
+			healthy = 0
+		}
+		atomic.StoreInt32(&r.healthy, healthy)
+	}
+}
+
+// reader returns the database to read from. Reads go to the healthy replicas in turn, or to the primary if there
+// are none or if the client or its session wrote recently, so that reads following a write see it despite the replication lag.
+func (c *Client) reader() *gorm.DB {
+	if len(c.replicas) == 0 || c.pinned() {
+		return c.Client
+	}
+	start := atomic.AddUint32(&c.root().next, 1)
+	for i := range c.replicas {
+		r := c.replicas[(int(start)+i)%len(c.replicas)]
+		if atomic.LoadInt32(&r.healthy) == 1 {
+			return r.db
+		}
+	}
+	return c.Client
+}
+
+// primary returns the primary database for writes outside of transactions and pins reads to it
+func (c *Client) primary() *gorm.DB {
+	c.pin()
+	return c.Client
+}
+
+// transaction runs fn in a transaction of the primary database and pins reads to it until the pin duration
+// passed after the commit. Clients that are already in a transaction run fn as part of it.
+func (c *Client) transaction(fn func(tx *gorm.DB) error) error {
+	if c.inTransaction {
+		return fn(c.Client)
+	}
+	c.pin()
+	defer c.pin()
+	return c.Client.Transaction(fn)
+}
+
+// session is a sequence of requests of one client, whose reads follow its own writes only
+type session struct {
+	lastWrite int64
+}
+
+// Session returns a client whose reads only go to the primary after writes of the session, so that a client sees
+// its own writes without sending the reads of all other clients to the primary. The session continues from the
+// given time of its last write, which is zero for new sessions.
+func (c *Client) Session(lastWrite time.Time) ClientInterface {
+	s := &session{}
+	if !lastWrite.IsZero() {
+		s.lastWrite = lastWrite.UnixNano()
+	}
+	return &Client{
+		Client:        c.Client,
+		Pricing:       c.Pricing,
+		Options:       c.Options,
+		PinDuration:   c.PinDuration,
+		replicas:      c.replicas,
+		session:       s,
+		breaker:       c.breaker,
+		origin:        c.root(),
+		inTransaction: c.inTransaction,
+		fields:        c.fields,
+	}
+}
+
+// LastWrite returns the time of the last write of the session of the client, or zero if it didn't write yet
+func (c *Client) LastWrite() time.Time {
+	lastWrite := atomic.LoadInt64(c.pinnedSince())
+	if lastWrite == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, lastWrite)
+}
+
+// pinnedSince returns the time of the last write that pins the reads of the client, which is shared by all clients
+// without a session
+func (c *Client) pinnedSince() *int64 {
+	if c.session != nil {
+		return &c.session.lastWrite
+	}
+	return &c.root().lastWrite
+}
+
+// pin sends the reads of the client to the primary for the pin duration
+func (c *Client) pin() {
+	atomic.StoreInt64(c.pinnedSince(), time.Now().UnixNano())
+}
+
+// pinned reports whether reads have to go to the primary because of a recent write
+func (c *Client) pinned() bool {
+	pinDuration := c.PinDuration
+	if pinDuration == 0 {
+		pinDuration = DefaultPinDuration
+	}
+	return time.Since(time.Unix(0, atomic.LoadInt64(c.pinnedSince()))) < pinDuration
+}
+
+// Transaction runs fn with a client whose reads and writes all happen in a single transaction of the primary
+// database, which is committed if fn returns nil and rolled back otherwise
+func (c *Client) Transaction(fn func(client ClientInterface) error) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		return fn(&Client{
+			Client:        tx,
+			Pricing:       c.Pricing,
+			Options:       c.Options,
+			PinDuration:   c.PinDuration,
+			session:       c.session,
+			breaker:       c.breaker,
+			origin:        c.root(),
+			inTransaction: true,
+		})
+	})
+}
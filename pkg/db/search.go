@@ -0,0 +1,127 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/jonnylangefeld/go-api/pkg/search"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// searchHit is a ranked and highlighted article of a search
+type searchHit struct {
+	search.Result
+	Highlight string
+}
+
+// SearchArticles returns the articles whose names match the query, ranked by relevance. Words of the query
+// match words of the name starting with them, names with typos match by trigram similarity.
+// The page id is the number of results on the previous pages.
+func (c *Client) SearchArticles(query string, pageID int) *types.ArticleSearchResultList {
+	results := &types.ArticleSearchResultList{Items: []*types.ArticleSearchResult{}}
+	terms := search.Terms(query)
+	if len(terms) == 0 {
+		return results
+	}
+
+	var hits []searchHit
+	var err error
+	conn := c.reader()
+	if conn.Dialect().GetName() == "postgres" {
+		hits, err = searchPostgres(conn, terms, pageID)
+	} else {
+		hits, err = searchNaive(conn, terms, pageID)
+	}
+	if err != nil {
+		return results
+	}
+	if len(hits) == pageSize+1 {
+		results.NextPageID = pageID + pageSize
+		hits = hits[:pageSize]
+	}
+
+	ids := make([]int, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	articles := map[int]*types.Article{}
+	for _, article := range c.GetArticlesByIDs(ids) {
+		articles[article.ID] = article
+	}
+	for _, hit := range hits {
+		if article, ok := articles[hit.ID]; ok {
+			results.Items = append(results.Items, &types.ArticleSearchResult{Article: article, Rank: hit.Rank, Highlight: hit.Highlight})
+		}
+	}
+	return results
+}
+
+// searchPostgres ranks the articles with the full-text search column and the trigram similarity of their names
+func searchPostgres(conn *gorm.DB, terms []string, offset int) ([]searchHit, error) {
+	text := strings.Join(terms, " ")
+	rows, err := conn.Raw(`SELECT id, ts_rank(search, query) + similarity(name, ?) AS rank,
+		ts_headline('simple', name, query, ?) AS highlight
+		FROM articles, to_tsquery('simple', ?) AS query
+		WHERE search @@ query OR similarity(name, ?) >= ?
+		ORDER BY rank DESC, id LIMIT ? OFFSET ?`,
+		text, "StartSel="+search.StartSel+", StopSel="+search.StopSel+", HighlightAll=true", search.TSQuery(terms),
+		text, search.SimilarityThreshold, pageSize+1, offset).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []searchHit{}
+	for rows.Next() {
+		hit := searchHit{}
+		if err := rows.Scan(&hit.ID, &hit.Rank, &hit.Highlight); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+// searchNaive ranks all articles in memory for databases without full-text search
+func searchNaive(conn *gorm.DB, terms []string, offset int) ([]searchHit, error) {
+	articles := []*types.Article{}
+	if err := conn.Order("id").Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	results := []search.Result{}
+	names := map[int]string{}
+	for _, article := range articles {
+		if rank, ok := search.Match(article.Name, terms); ok {
+			results = append(results, search.Result{ID: article.ID, Rank: rank})
+			names[article.ID] = article.Name
+		}
+	}
+	search.Sort(results)
+
+	hits := []searchHit{}
+	for i := offset; i < len(results) && len(hits) < pageSize+1; i++ {
+		hits = append(hits, searchHit{Result: results[i], Highlight: search.Highlight(names[results[i].ID], terms)})
+	}
+	return hits, nil
+}
+
+// migrateSearch maintains the full-text search column of the article names and the indexes of the search.
+// Other databases than postgres are searched without them.
+func (c *Client) migrateSearch() error {
+	if c.Client.Dialect().GetName() != "postgres" {
+		return nil
+	}
+	for _, statement := range []string{
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+		"ALTER TABLE articles ADD COLUMN IF NOT EXISTS search tsvector GENERATED ALWAYS AS (to_tsvector('simple', name)) STORED",
+		"CREATE INDEX IF NOT EXISTS articles_search_idx ON articles USING GIN (search)",
+		"CREATE INDEX IF NOT EXISTS articles_name_trgm_idx ON articles USING GIN (name gin_trgm_ops)",
+	} {
+		if err := c.Client.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// GetVariants returns all variants of an article from the database
+func (c *Client) GetVariants(articleID int) []*types.Variant {
+	variants := []*types.Variant{}
+	c.reader().Where("article_id = ?", articleID).Order("id").Find(&variants)
+	return variants
+}
+
+// GetVariantByID queries a variant from the database
+func (c *Client) GetVariantByID(id int) *types.Variant {
+	variant := &types.Variant{}
+	if err := c.reader().Where("id = ?", id).First(variant).Error; err != nil {
+		return nil
+	}
+	return variant
+}
+
+// GetVariantBySKU queries a variant and its article from the database. The SKU is matched in any case.
+func (c *Client) GetVariantBySKU(sku string) *types.Variant {
+	conn := c.reader()
+	variant := &types.Variant{}
+	if err := conn.Where("sku = ?", types.NormalizeSKU(sku)).First(variant).Error; err != nil {
+		return nil
+	}
+	article := &types.Article{}
+	if err := conn.Where("id = ?", variant.ArticleID).First(article).Error; err != nil {
+		return nil
+	}
+	_ = loadArticleDetails(conn, article)
+	variant.Article = article
+	return variant
+}
+
+// SetVariant writes a variant to the database together with an outbox event of the change of its article.
+// The stock of the variant is only changed by stock adjustments and orders.
+// It returns types.ErrSKUTaken if another variant already uses the SKU.
+func (c *Client) SetVariant(variant *types.Variant) error {
+	err := c.transaction(func(tx *gorm.DB) error {
+		if !exists(tx, &types.Article{}, variant.ArticleID) {
+			return fmt.Errorf("article %d doesn't exist", variant.ArticleID)
+		}
+		taken := 0
+		if err := tx.Model(&types.Variant{}).Where("sku = ? AND id <> ?", variant.SKU, variant.ID).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return types.ErrSKUTaken
+		}
+
+		variant.Stock, variant.Reserved = nil, 0
+		stored := &types.Variant{}
+		before, err := loadPrevious(tx, stored, variant.ID)
+		if err != nil {
+			return err
+		}
+		if before != nil {
+			if stored.ArticleID != variant.ArticleID {
+				return fmt.Errorf("variant %d belongs to article %d", variant.ID, stored.ArticleID)
+			}
+			variant.Stock, variant.Reserved = stored.Stock, stored.Reserved
+		}
+		if err := tx.Save(variant).Error; err != nil {
+			return err
+		}
+		if err := writeAudit(tx, "variant", variant.ID, before, variant); err != nil {
+			return err
+		}
+		return writeArticleUpdated(tx, variant.ArticleID)
+	})
+
+	// concurrent writes of the same SKU are caught by the unique index
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return types.ErrSKUTaken
+	}
+	return err
+}
+
+// DeleteVariant deletes a variant of the article from the database together with an outbox event of the change of
+// the article. Orders keep referencing the deleted variant.
+func (c *Client) DeleteVariant(articleID, id int) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		variant := &types.Variant{}
+		if err := tx.Where("id = ? AND article_id = ?", id, articleID).First(variant).Error; err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				return nil
+			}
+			return err
+		}
+		if err := tx.Delete(variant).Error; err != nil {
+			return err
+		}
+		if err := writeAudit(tx, "variant", id, variant, nil); err != nil {
+			return err
+		}
+		return writeArticleUpdated(tx, variant.ArticleID)
+	})
+}
+
+// writeArticleUpdated writes an outbox event with the current state of the article as part of the transaction
+func writeArticleUpdated(tx *gorm.DB, articleID int) error {
+	article := &types.Article{}
+	if err := tx.Where("id = ?", articleID).First(article).Error; err != nil {
+		return err
+	}
+	if err := loadArticleDetails(tx, article); err != nil {
+		return err
+	}
+	return writeOutbox(tx, types.EventArticleUpdated, article)
+}
+
+// resolveVariants queries the variants referenced by the order items and fills in the article ids of the items
+// from their variants. It fails if a variant doesn't exist or doesn't belong to the article of its item.
+func resolveVariants(tx *gorm.DB, items []*types.OrderItem) (map[int]*types.Variant, error) {
+	ids := []int{}
+	for _, item := range items {
+		if item.VariantID != 0 {
+			ids = append(ids, item.VariantID)
+		}
+	}
+	byID := map[int]*types.Variant{}
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	variants := []*types.Variant{}
+	if err := tx.Where("id IN (?)", ids).Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	for _, variant := range variants {
+		byID[variant.ID] = variant
+	}
+	for _, item := range items {
+		if item.VariantID == 0 {
+			continue
+		}
+		variant, ok := byID[item.VariantID]
+		if !ok {
+			return nil, fmt.Errorf("variant %d doesn't exist", item.VariantID)
+		}
+		if item.ArticleID != 0 && item.ArticleID != variant.ArticleID {
+			return nil, fmt.Errorf("variant %d doesn't belong to article %d", variant.ID, item.ArticleID)
+		}
+		item.ArticleID = variant.ArticleID
+	}
+	return byID, nil
+}
+
+// loadVariants fills the variants of the articles with a single query
+func loadVariants(tx *gorm.DB, articles ...*types.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(articles))
+	byID := map[int]*types.Article{}
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+		byID[article.ID] = article
+	}
+
+	variants := []*types.Variant{}
+	if err := tx.Where("article_id IN (?)", ids).Order("id").Find(&variants).Error; err != nil {
+		return err
+	}
+	for _, variant := range variants {
+		article := byID[variant.ArticleID]
+		article.Variants = append(article.Variants, variant)
+	}
+	return nil
+}
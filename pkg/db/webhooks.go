@@ -0,0 +1,138 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// WebhookDeliveryLease is the time a delivery returned by GetDueWebhookDeliveries isn't returned again, which has
+// to exceed the time the dispatcher takes to send a batch of deliveries
+const WebhookDeliveryLease = 15 * time.Minute
+
+// GetWebhookByID queries a webhook from the database
+func (c *Client) GetWebhookByID(id int) *types.Webhook {
+	webhook := &types.Webhook{}
+	if err := c.reader().Where("id = ?", id).First(webhook).Error; err != nil {
+		return nil
+	}
+	return webhook
+}
+
+// SetWebhook writes a webhook to the database
+func (c *Client) SetWebhook(webhook *types.Webhook) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		before, err := loadPrevious(tx, &types.Webhook{}, webhook.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(webhook).Error; err != nil {
+			return err
+		}
+		if before != nil {
+			before = withoutSecret(before.(*types.Webhook))
+		}
+		return writeAudit(tx, "webhook", webhook.ID, before, withoutSecret(webhook))
+	})
+}
+
+// DeleteWebhook deletes a webhook and all its deliveries from the database
+func (c *Client) DeleteWebhook(id int) error {
+	return c.transaction(func(tx *gorm.DB) error {
+		webhook := &types.Webhook{}
+		before, err := loadPrevious(tx, webhook, id)
+		if err != nil || before == nil {
+			return err
+		}
+		if err := tx.Where("webhook_id = ?", id).Delete(&types.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", id).Delete(&types.Webhook{}).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, "webhook", id, withoutSecret(webhook), nil)
+	})
+}
+
+// withoutSecret returns a copy of the webhook without its secret, which must not end up in the audit log
+func withoutSecret(webhook *types.Webhook) *types.Webhook {
+	copied := *webhook
+	copied.Secret = ""
+	return &copied
+}
+
+// GetWebhooks returns all webhooks from the database
+func (c *Client) GetWebhooks(pageID int) *types.WebhookList {
+	webhooks := &types.WebhookList{}
+	c.reader().Where("id >= ?", pageID).Order("id").Limit(pageSize + 1).Find(&webhooks.Items)
+	if len(webhooks.Items) == pageSize+1 {
+		webhooks.NextPageID = webhooks.Items[len(webhooks.Items)-1].ID
+		webhooks.Items = webhooks.Items[:pageSize]
+	}
+	return webhooks
+}
+
+// GetWebhooksForEvent returns all webhooks that are subscribed to the event type
+func (c *Client) GetWebhooksForEvent(eventType string) []*types.Webhook {
+	webhooks := []*types.Webhook{}
+	c.Client.Where("? = ANY(events) OR ? = ANY(events)", eventType, types.EventAll).Order("id").Find(&webhooks)
+	return webhooks
+}
+
+// GetWebhookDeliveryByID queries a webhook delivery from the database
+func (c *Client) GetWebhookDeliveryByID(id int) *types.WebhookDelivery {
+	delivery := &types.WebhookDelivery{}
+	if err := c.Client.Where("id = ?", id).First(delivery).Error; err != nil {
+		return nil
+	}
+	return delivery
+}
+
+// SetWebhookDelivery writes a webhook delivery to the database
+func (c *Client) SetWebhookDelivery(delivery *types.WebhookDelivery) error {
+	return c.Client.Save(delivery).Error
+}
+
+// GetWebhookDeliveries returns all deliveries of a webhook from the database, newest first
+func (c *Client) GetWebhookDeliveries(webhookID int, pageID int) *types.WebhookDeliveryList {
+	deliveries := &types.WebhookDeliveryList{}
+	query := c.reader().Where("webhook_id = ?", webhookID)
+	if pageID > 0 {
+		query = query.Where("id <= ?", pageID)
+	}
+	query.Order("id DESC").Limit(pageSize + 1).Find(&deliveries.Items)
+	if len(deliveries.Items) == pageSize+1 {
+		deliveries.NextPageID = deliveries.Items[len(deliveries.Items)-1].ID
+		deliveries.Items = deliveries.Items[:pageSize]
+	}
+	return deliveries
+}
+
+// GetDueWebhookDeliveries returns up to limit pending deliveries whose next attempt is due. The deliveries are
+// claimed by moving their next attempt WebhookDeliveryLease into the future, so that other instances of the api
+// polling concurrently skip them instead of sending them twice. The dispatcher sets the next attempt once it sent them.
+func (c *Client) GetDueWebhookDeliveries(now time.Time, limit int) []*types.WebhookDelivery {
+	deliveries := []*types.WebhookDelivery{}
+	err := c.Client.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").
+			Where("status = ? AND next_attempt_at <= ?", types.DeliveryPending, now).
+			Order("next_attempt_at").Limit(limit).Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		ids := make([]int, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&types.WebhookDelivery{}).Where("id IN (?)", ids).
+			UpdateColumn("next_attempt_at", now.Add(WebhookDeliveryLease)).Error
+	})
+	if err != nil {
+		return []*types.WebhookDelivery{}
+	}
+	return deliveries
+}
@@ -0,0 +1,661 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Params are the parameters of a single graphql request
+type Params struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	// QueryOnly rejects mutations, e.g. for requests that were sent via GET
+	QueryOnly bool `json:"-"`
+}
+
+// Result is the response to a graphql request
+type Result struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Error is a graphql error as returned in the errors list of a result
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Location points to a position in the request document
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Do parses and executes a graphql request against the schema
+func Do(ctx context.Context, schema *Schema, p Params) *Result {
+	doc, err := parse(p.Query)
+	if err != nil {
+		return &Result{Errors: []*Error{toError(err)}}
+	}
+
+	op, err := selectOperation(doc, p.OperationName)
+	if err != nil {
+		return &Result{Errors: []*Error{toError(err)}}
+	}
+	if err := checkLimits(schema, doc, op); err != nil {
+		return &Result{Errors: []*Error{toError(err)}}
+	}
+
+	var root *Object
+	switch op.kind {
+	case "query":
+		root = schema.Query
+	case "mutation":
+		if p.QueryOnly {
+			return &Result{Errors: []*Error{{Message: "Can only perform a mutation operation from a POST request."}}}
+		}
+		root = schema.Mutation
+	}
+	if root == nil {
+		return &Result{Errors: []*Error{{Message: fmt.Sprintf("Schema is not configured for %ss.", op.kind), Locations: []Location{op.loc}}}}
+	}
+
+	e := &executor{
+		ctx:    ctx,
+		schema: schema,
+		doc:    doc,
+	}
+	if e.variables, err = e.coerceVariables(op, p.Variables); err != nil {
+		return &Result{Errors: []*Error{toError(err)}}
+	}
+
+	data := e.executeSelections(root, nil, op.selections, nil, op.kind == "mutation")
+	e.drain()
+	return &Result{Data: data, Errors: e.errors}
+}
+
+func selectOperation(doc *document, name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, &Error{Message: "Must provide operation name if query contains multiple operations."}
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("Unknown operation named %q.", name)}
+}
+
+func toError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	return &Error{Message: err.Error()}
+}
+
+// executor holds the state of a single execution
+type executor struct {
+	ctx       context.Context
+	schema    *Schema
+	doc       *document
+	variables map[string]interface{}
+	errors    []*Error
+	pending   []*pendingValue
+}
+
+// pendingValue is a field whose resolver returned a thunk that has not been resolved yet
+type pendingValue struct {
+	thunk     Thunk
+	fieldType Type
+	fields    []*field
+	path      []interface{}
+	set       func(v interface{})
+}
+
+// drain resolves all pending thunks. Thunks are resolved level by level, so that all
+// thunks of one level are queued before the first one is resolved, allowing loaders to batch them.
+func (e *executor) drain() {
+	for len(e.pending) > 0 {
+		pending := e.pending
+		e.pending = nil
+		for _, p := range pending {
+			v, err := p.thunk()
+			if err != nil {
+				e.fieldError(err, p.fields[0], p.path)
+				p.set(nil)
+				continue
+			}
+			e.completeValue(p.fieldType, p.fields, v, p.path, p.set)
+		}
+	}
+}
+
+func (e *executor) fieldError(err error, f *field, path []interface{}) {
+	e.errors = append(e.errors, &Error{
+		Message:   err.Error(),
+		Locations: []Location{f.loc},
+		Path:      path,
+	})
+}
+
+// executeSelections resolves the selections on the given object. If serial is true,
+// all pending thunks are resolved after each field, as required for mutations.
+func (e *executor) executeSelections(obj *Object, source interface{}, selections []selection, path []interface{}, serial bool) *orderedMap {
+	result := &orderedMap{}
+	keys, fields := e.collectFields(obj, selections, map[string]bool{})
+
+	for _, key := range keys {
+		key := key
+		fieldPath := appendPath(path, key)
+		f := fields[key][0]
+		result.set(key, nil)
+
+		def := e.fieldDefinition(obj, f.name)
+		if def == nil {
+			e.fieldError(fmt.Errorf("Cannot query field %q on type %q.", f.name, obj.Name), f, fieldPath)
+			continue
+		}
+
+		args, err := e.argumentValues(def.Args, f.arguments)
+		if err != nil {
+			e.fieldError(err, f, fieldPath)
+			continue
+		}
+
+		params := ResolveParams{Context: e.ctx, Source: source, Args: args}
+		var v interface{}
+		switch {
+		case f.name == "__typename":
+			v = obj.Name
+		case def.Resolve != nil:
+			v, err = def.Resolve(params)
+		default:
+			v, err = defaultResolve(source, def.Name)
+		}
+		if err != nil {
+			e.fieldError(err, f, fieldPath)
+			continue
+		}
+
+		e.completeValue(def.Type, fields[key], v, fieldPath, func(v interface{}) {
+			result.set(key, v)
+		})
+		if serial {
+			e.drain()
+		}
+	}
+	return result
+}
+
+// fieldDefinition looks up a field on the object, including the introspection meta fields
+func (e *executor) fieldDefinition(obj *Object, name string) *Field {
+	switch {
+	case name == "__typename":
+		return typenameField
+	case name == "__schema" && obj == e.schema.Query:
+		return &Field{Name: name, Type: NewNonNull(schemaType), Resolve: func(p ResolveParams) (interface{}, error) {
+			return e.schema, nil
+		}}
+	case name == "__type" && obj == e.schema.Query:
+		return &Field{Name: name, Type: typeType, Args: []*Argument{{Name: "name", Type: NewNonNull(String)}}, Resolve: func(p ResolveParams) (interface{}, error) {
+			return e.schema.Type(p.Args["name"].(string)), nil
+		}}
+	}
+	return obj.Field(name)
+}
+
+// completeValue converts a resolved value to its response representation and passes it to set
+func (e *executor) completeValue(t Type, fields []*field, v interface{}, path []interface{}, set func(v interface{})) {
+	if thunk, ok := v.(Thunk); ok {
+		e.pending = append(e.pending, &pendingValue{thunk: thunk, fieldType: t, fields: fields, path: path, set: set})
+		return
+	}
+
+	if nonNull, ok := t.(*NonNull); ok {
+		if isNil(v) {
+			e.fieldError(errors.New("Cannot return null for non-nullable field."), fields[0], path)
+			set(nil)
+			return
+		}
+		t = nonNull.OfType
+	}
+	if isNil(v) {
+		set(nil)
+		return
+	}
+
+	switch t := t.(type) {
+	case *List:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.fieldError(fmt.Errorf("Expected a list, but got %T.", v), fields[0], path)
+			set(nil)
+			return
+		}
+		items := make([]interface{}, rv.Len())
+		set(items)
+		for i := range items {
+			i := i
+			e.completeValue(t.OfType, fields, rv.Index(i).Interface(), appendPath(path, i), func(v interface{}) {
+				items[i] = v
+			})
+		}
+	case *Scalar:
+		serialized, err := t.Serialize(v)
+		if err != nil {
+			e.fieldError(err, fields[0], path)
+		}
+		set(serialized)
+	case *Enum:
+		name, err := serializeString(v)
+		if err != nil || !t.has(name.(string)) {
+			e.fieldError(fmt.Errorf("Enum %q cannot represent value: %v", t.Name, v), fields[0], path)
+			set(nil)
+			return
+		}
+		set(name)
+	case *Object:
+		var selections []selection
+		for _, f := range fields {
+			selections = append(selections, f.selections...)
+		}
+		set(e.executeSelections(t, v, selections, path, false))
+	default:
+		e.fieldError(fmt.Errorf("Cannot complete value of type %s.", t), fields[0], path)
+		set(nil)
+	}
+}
+
+// collectFields groups the selected fields by response key in the order of their first appearance
+func (e *executor) collectFields(obj *Object, selections []selection, visited map[string]bool) ([]string, map[string][]*field) {
+	var keys []string
+	fields := map[string][]*field{}
+	add := func(key string, f ...*field) {
+		if _, ok := fields[key]; !ok {
+			keys = append(keys, key)
+		}
+		fields[key] = append(fields[key], f...)
+	}
+
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			if !e.included(sel.directives) {
+				continue
+			}
+			add(sel.responseKey(), sel)
+		case *fragmentSpread:
+			frag, ok := e.doc.fragments[sel.name]
+			if visited[sel.name] || !ok || !e.included(sel.directives) || frag.typeCondition != obj.Name {
+				continue
+			}
+			visited[sel.name] = true
+			subKeys, subFields := e.collectFields(obj, frag.selections, visited)
+			for _, key := range subKeys {
+				add(key, subFields[key]...)
+			}
+		case *inlineFragment:
+			if !e.included(sel.directives) || (sel.typeCondition != "" && sel.typeCondition != obj.Name) {
+				continue
+			}
+			subKeys, subFields := e.collectFields(obj, sel.selections, visited)
+			for _, key := range subKeys {
+				add(key, subFields[key]...)
+			}
+		}
+	}
+	return keys, fields
+}
+
+// included evaluates the @skip and @include directives
+func (e *executor) included(directives []*directive) bool {
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			continue
+		}
+		args, err := e.argumentValues(skipDirective.Args, d.arguments)
+		if err != nil {
+			continue
+		}
+		if cond, _ := args["if"].(bool); cond == (d.name == "skip") {
+			return false
+		}
+	}
+	return true
+}
+
+// argumentValues coerces the arguments of a field according to their definitions
+func (e *executor) argumentValues(defs []*Argument, args []*argument) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, arg := range args {
+		found := false
+		for _, def := range defs {
+			found = found || def.Name == arg.name
+		}
+		if !found {
+			return nil, fmt.Errorf("Unknown argument %q.", arg.name)
+		}
+	}
+
+	for _, def := range defs {
+		var literal *value
+		for _, arg := range args {
+			if arg.name == def.Name {
+				literal = arg.value
+			}
+		}
+		if literal != nil && literal.kind == valueVariable {
+			if _, ok := e.variables[literal.raw]; !ok {
+				literal = nil
+			}
+		}
+
+		if literal == nil {
+			switch {
+			case def.DefaultValue != nil:
+				values[def.Name] = def.DefaultValue
+			case isNonNull(def.Type):
+				return nil, fmt.Errorf("Argument %q of required type %q was not provided.", def.Name, def.Type)
+			}
+			continue
+		}
+
+		v, err := coerceValue(def.Type, e.valueFromLiteral(literal))
+		if err != nil {
+			return nil, fmt.Errorf("Argument %q has invalid value: %s", def.Name, err)
+		}
+		values[def.Name] = v
+	}
+	return values, nil
+}
+
+// coerceVariables coerces the provided variables according to the variable definitions of the operation
+func (e *executor) coerceVariables(op *operation, provided map[string]interface{}) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, def := range op.variables {
+		t, err := e.typeFromRef(def.typ)
+		if err != nil {
+			return nil, err
+		}
+
+		v, ok := provided[def.name]
+		if !ok {
+			switch {
+			case def.defaultValue != nil:
+				v = e.valueFromLiteral(def.defaultValue)
+			case isNonNull(t):
+				return nil, &Error{Message: fmt.Sprintf("Variable \"$%s\" of required type %q was not provided.", def.name, t)}
+			default:
+				continue
+			}
+		}
+
+		if values[def.name], err = coerceValue(t, v); err != nil {
+			return nil, &Error{Message: fmt.Sprintf("Variable \"$%s\" got invalid value: %s", def.name, err)}
+		}
+	}
+	return values, nil
+}
+
+func (e *executor) typeFromRef(ref *typeRef) (Type, error) {
+	var t Type
+	if ref.elem != nil {
+		elem, err := e.typeFromRef(ref.elem)
+		if err != nil {
+			return nil, err
+		}
+		t = NewList(elem)
+	} else {
+		t = e.schema.Type(ref.name)
+		switch t.(type) {
+		case *Scalar, *Enum, *InputObject:
+		default:
+			return nil, &Error{Message: fmt.Sprintf("Unknown input type %q.", ref.name)}
+		}
+	}
+	if ref.nonNull {
+		t = NewNonNull(t)
+	}
+	return t, nil
+}
+
+// valueFromLiteral converts a literal of the document to a go value, resolving variables
+func (e *executor) valueFromLiteral(v *value) interface{} {
+	switch v.kind {
+	case valueVariable:
+		return e.variables[v.raw]
+	case valueInt:
+		i, err := strconv.ParseInt(v.raw, 10, 64)
+		if err != nil {
+			return v.raw
+		}
+		return i
+	case valueFloat:
+		f, _ := strconv.ParseFloat(v.raw, 64)
+		return f
+	case valueString, valueEnum:
+		return v.raw
+	case valueBoolean:
+		return v.raw == "true"
+	case valueList:
+		list := make([]interface{}, len(v.list))
+		for i, item := range v.list {
+			list[i] = e.valueFromLiteral(item)
+		}
+		return list
+	case valueObject:
+		obj := map[string]interface{}{}
+		for _, f := range v.fields {
+			obj[f.name] = e.valueFromLiteral(f.value)
+		}
+		return obj
+	}
+	return nil
+}
+
+// coerceValue converts an input value to the go representation of the given input type
+func coerceValue(t Type, v interface{}) (interface{}, error) {
+	if nonNull, ok := t.(*NonNull); ok {
+		if v == nil {
+			return nil, fmt.Errorf("expected non-nullable type %q not to be null", t)
+		}
+		t = nonNull.OfType
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		items, ok := v.([]interface{})
+		if !ok {
+			item, err := coerceValue(t.OfType, v)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			coerced, err := coerceValue(t.OfType, item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = coerced
+		}
+		return list, nil
+	case *Scalar:
+		return t.ParseValue(v)
+	case *Enum:
+		if s, ok := v.(string); ok && t.has(s) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("value %v does not exist in %q enum", v, t.Name)
+	case *InputObject:
+		fields, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected type %q to be an object", t.Name)
+		}
+		obj := map[string]interface{}{}
+		for name := range fields {
+			found := false
+			for _, def := range t.Fields {
+				found = found || def.Name == name
+			}
+			if !found {
+				return nil, fmt.Errorf("field %q is not defined by type %q", name, t.Name)
+			}
+		}
+		for _, def := range t.Fields {
+			fv, ok := fields[def.Name]
+			if !ok {
+				switch {
+				case def.DefaultValue != nil:
+					obj[def.Name] = def.DefaultValue
+				case isNonNull(def.Type):
+					return nil, fmt.Errorf("field %q of required type %q was not provided", def.Name, def.Type)
+				}
+				continue
+			}
+			coerced, err := coerceValue(def.Type, fv)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %s", def.Name, err)
+			}
+			obj[def.Name] = coerced
+		}
+		return obj, nil
+	}
+	return nil, fmt.Errorf("%q is not an input type", t)
+}
+
+// defaultResolve looks up a field by name in a map or by json name in a struct
+func defaultResolve(source interface{}, name string) (interface{}, error) {
+	if m, ok := source.(map[string]interface{}); ok {
+		return m[name], nil
+	}
+
+	rv := reflect.ValueOf(source)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	if index, ok := structFieldIndex(rv.Type(), name); ok {
+		return rv.FieldByIndex(index).Interface(), nil
+	}
+	return nil, nil
+}
+
+// structFieldIndex returns the index of the struct field with the given json name.
+// Like encoding/json, it falls back to a case insensitive match.
+func structFieldIndex(t reflect.Type, name string) ([]int, bool) {
+	var fallback []int
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		switch n := jsonName(sf); {
+		case n == name:
+			return sf.Index, true
+		case fallback == nil && strings.EqualFold(n, name):
+			fallback = sf.Index
+		}
+	}
+	return fallback, fallback != nil
+}
+
+// jsonName returns the name of a struct field as used by encoding/json, or "-" if it is ignored
+func jsonName(sf reflect.StructField) string {
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "-"
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name
+	}
+	return sf.Name
+}
+
+func isNonNull(t Type) bool {
+	_, ok := t.(*NonNull)
+	return ok
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func:
+		return rv.IsNil()
+	}
+	return false
+}
+
+func appendPath(path []interface{}, elem interface{}) []interface{} {
+	p := make([]interface{}, len(path), len(path)+1)
+	copy(p, path)
+	return append(p, elem)
+}
+
+// orderedMap is a json object that keeps the order of its keys, as required for graphql responses
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) set(key string, v interface{}) {
+	if m.values == nil {
+		m.values = map[string]interface{}{}
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = v
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
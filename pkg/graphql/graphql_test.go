@@ -0,0 +1,190 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAuthor struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type testBook struct {
+	ID       int     `json:"id"`
+	Title    string  `json:"title"`
+	Price    float64 `json:"price"`
+	AuthorID int     `json:"-"`
+}
+
+var (
+	testAuthors = map[int]interface{}{
+		1: &testAuthor{ID: 1, Name: "Ursula"},
+		2: &testAuthor{ID: 2, Name: "Terry"},
+	}
+	testBooks = []*testBook{
+		{ID: 1, Title: "The Dispossessed", Price: 9.99, AuthorID: 1},
+		{ID: 2, Title: "Mort", Price: 7.5, AuthorID: 2},
+		{ID: 3, Title: "Lathe of Heaven", Price: 8, AuthorID: 1},
+	}
+)
+
+// newTestSchema returns a schema of books and their authors and a pointer to the number of author batch loads
+func newTestSchema(t *testing.T) (*Schema, *int) {
+	batches := 0
+	loader := NewLoader(func(ctx context.Context, keys []int) (map[int]interface{}, error) {
+		batches++
+		return testAuthors, nil
+	})
+
+	mapper := NewMapper()
+	book := mapper.Object(testBook{})
+	book.AddField(&Field{
+		Name: "author",
+		Type: mapper.Object(testAuthor{}),
+		Resolve: func(p ResolveParams) (interface{}, error) {
+			return loader.Load(p.Context, p.Source.(*testBook).AuthorID), nil
+		},
+	})
+
+	query := &Object{
+		Name: "Query",
+		Fields: []*Field{
+			{
+				Name: "books",
+				Type: NewNonNull(NewList(NewNonNull(book))),
+				Args: []*Argument{{Name: "first", Type: Int, DefaultValue: 10}},
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					first := p.Args["first"].(int)
+					if first > len(testBooks) {
+						first = len(testBooks)
+					}
+					return testBooks[:first], nil
+				},
+			},
+		},
+	}
+	mutation := &Object{
+		Name: "Mutation",
+		Fields: []*Field{
+			{
+				Name: "setBook",
+				Type: NewNonNull(book),
+				Args: []*Argument{{Name: "input", Type: NewNonNull(mapper.InputObject(testBook{}))}},
+				Resolve: func(p ResolveParams) (interface{}, error) {
+					b := &testBook{}
+					err := Decode(p.Args["input"], b)
+					return b, err
+				},
+			},
+		},
+	}
+
+	schema, err := NewSchema(query, mutation)
+	assert.NoError(t, err)
+	return schema, &batches
+}
+
+func TestDo(t *testing.T) {
+	testcases := map[string]struct {
+		params        Params
+		maxDepth      int
+		maxComplexity int
+		want          string
+		wantBatches   int
+	}{
+		"query with alias and nested batched field": {
+			params: Params{Query: `{ books { id t: title author { name } } }`},
+			want: `{"data":{"books":[` +
+				`{"id":1,"t":"The Dispossessed","author":{"name":"Ursula"}},` +
+				`{"id":2,"t":"Mort","author":{"name":"Terry"}},` +
+				`{"id":3,"t":"Lathe of Heaven","author":{"name":"Ursula"}}]}}`,
+			wantBatches: 1,
+		},
+		"variables, fragments and directives": {
+			params: Params{
+				Query: `query Books($first: Int, $withPrice: Boolean!) {
+					books(first: $first) { ...bookFields price @include(if: $withPrice) author @skip(if: true) { id } }
+				}
+				fragment bookFields on testBook { __typename title }`,
+				Variables: map[string]interface{}{"first": float64(1), "withPrice": true},
+			},
+			want: `{"data":{"books":[{"__typename":"testBook","title":"The Dispossessed","price":9.99}]}}`,
+		},
+		"mutation with input object": {
+			params: Params{Query: `mutation { setBook(input: {id: 4, title: "Small Gods"}) { id title } }`},
+			want:   `{"data":{"setBook":{"id":4,"title":"Small Gods"}}}`,
+		},
+		"mutation via GET": {
+			params: Params{Query: `mutation { setBook(input: {id: 4}) { id } }`, QueryOnly: true},
+			want:   `{"errors":[{"message":"Can only perform a mutation operation from a POST request."}]}`,
+		},
+		"unknown field": {
+			params: Params{Query: `{ books(first: 1) { isbn } }`},
+			want: `{"data":{"books":[{"isbn":null}]},"errors":[{"message":"Cannot query field \"isbn\" on type \"testBook\".",` +
+				`"locations":[{"line":1,"column":21}],"path":["books",0,"isbn"]}]}`,
+		},
+		"missing required variable": {
+			params: Params{Query: `query ($first: Int!) { books(first: $first) { id } }`},
+			want:   `{"errors":[{"message":"Variable \"$first\" of required type \"Int!\" was not provided."}]}`,
+		},
+		"syntax error": {
+			params: Params{Query: `{ books { id }`},
+			want:   `{"errors":[{"message":"Syntax Error: Unexpected \u003cEOF\u003e.","locations":[{"line":1,"column":15}]}]}`,
+		},
+		"too deep": {
+			params:   Params{Query: `{ books { ...bookAuthor } } fragment bookAuthor on testBook { author { name } }`},
+			maxDepth: 2,
+			want:     `{"errors":[{"message":"Operation has a depth of 3, which exceeds the maximum depth of 2.","locations":[{"line":1,"column":1}]}]}`,
+		},
+		"too complex": {
+			params: Params{Query: `query Books { books { ...bookFields } more: books { ...bookFields } }
+				fragment bookFields on testBook { id title author { name } }`},
+			maxDepth:      3,
+			maxComplexity: 9,
+			want:          `{"errors":[{"message":"Operation has a complexity of 10, which exceeds the maximum complexity of 9.","locations":[{"line":1,"column":1}]}]}`,
+		},
+		"within limits": {
+			params:        Params{Query: `{ books(first: 1) { title author { name } } }`},
+			maxDepth:      3,
+			maxComplexity: 4,
+			want:          `{"data":{"books":[{"title":"The Dispossessed","author":{"name":"Ursula"}}]}}`,
+			wantBatches:   1,
+		},
+		"introspection": {
+			params: Params{Query: `{ __schema { queryType { name } mutationType { name } } __type(name: "testBook") { kind fields { name type { kind ofType { name } } } } }`},
+			want: `{"data":{"__schema":{"queryType":{"name":"Query"},"mutationType":{"name":"Mutation"}},"__type":{"kind":"OBJECT","fields":[` +
+				`{"name":"id","type":{"kind":"NON_NULL","ofType":{"name":"Int"}}},` +
+				`{"name":"title","type":{"kind":"NON_NULL","ofType":{"name":"String"}}},` +
+				`{"name":"price","type":{"kind":"NON_NULL","ofType":{"name":"Float"}}},` +
+				`{"name":"author","type":{"kind":"OBJECT","ofType":null}}]}}}`,
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			schema, batches := newTestSchema(t)
+			schema.MaxDepth, schema.MaxComplexity = test.maxDepth, test.maxComplexity
+			got, err := json.Marshal(Do(context.Background(), schema, test.params))
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, string(got))
+			assert.Equal(t, test.wantBatches, *batches)
+		})
+	}
+}
+
+func TestPlayground(t *testing.T) {
+	w := httptest.NewRecorder()
+	Playground("/graphql").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphiql", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `fetch("/graphql"`)
+
+	// all assets are part of the page
+	assert.NotContains(t, w.Body.String(), "http://")
+	assert.NotContains(t, w.Body.String(), "https://")
+}
@@ -0,0 +1,144 @@
+package graphql
+
+import (
+	"encoding/json"
+	"html/template"
+	"io/ioutil"
+	"mime"
+	"net/http"
+)
+
+// Handler returns an http handler that executes graphql requests sent as GET query parameters
+// or as POST body of type application/json or application/graphql
+func Handler(schema *Schema) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params, err := requestParams(r)
+		if err != nil {
+			writeResult(w, http.StatusBadRequest, &Result{Errors: []*Error{{Message: err.Error()}}})
+			return
+		}
+		writeResult(w, http.StatusOK, Do(r.Context(), schema, params))
+	})
+}
+
+// requestParams reads the graphql parameters of the request
+func requestParams(r *http.Request) (Params, error) {
+	params := Params{}
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		params.Query = query.Get("query")
+		params.OperationName = query.Get("operationName")
+		params.QueryOnly = true
+		if v := query.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &params.Variables); err != nil {
+				return params, err
+			}
+		}
+		return params, nil
+	}
+
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if contentType == "application/graphql" {
+		body, err := ioutil.ReadAll(r.Body)
+		params.Query = string(body)
+		return params, err
+	}
+	err := json.NewDecoder(r.Body).Decode(&params)
+	return params, err
+}
+
+func writeResult(w http.ResponseWriter, status int, result *Result) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// Playground returns an http handler serving a page to write graphql queries and send them to endpoint.
+// The page is self-contained, so that it doesn't load scripts from third parties. That's why it isn't GraphiQL,
+// whose scripts and styles are only distributed through npm and CDNs as bundles of several hundred kilobytes.
+func Playground(endpoint string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = playgroundTemplate.Execute(w, endpoint)
+	}
+}
+
+var playgroundTemplate = template.Must(template.New("playground").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>GraphQL Playground</title>
+  <style>
+    body { display: flex; flex-direction: column; height: 100vh; margin: 0; font-family: sans-serif; }
+    header { display: flex; align-items: center; gap: 1em; padding: 0.5em 1em; background: #f3f3f3; border-bottom: 1px solid #ddd; }
+    main { display: flex; flex: 1; min-height: 0; }
+    section { display: flex; flex-direction: column; flex: 1; min-width: 0; }
+    label { padding: 0.25em 1em; font-size: 0.8em; color: #666; background: #fafafa; border-bottom: 1px solid #eee; }
+    textarea, pre { flex: 1; margin: 0; padding: 1em; border: 0; border-right: 1px solid #ddd; font: 14px monospace; resize: none; overflow: auto; }
+    #variables { flex: 0 0 8em; border-top: 1px solid #ddd; }
+  </style>
+</head>
+<body>
+  <header>
+    <strong>GraphQL Playground</strong>
+    <button id="run" title="Ctrl+Enter">Run</button>
+  </header>
+  <main>
+    <section>
+      <label for="query">Query</label>
+      <textarea id="query" spellcheck="false">{
+  articles(first: 5) {
+    edges {
+      node {
+        id
+        name
+      }
+    }
+  }
+}</textarea>
+      <label for="variables">Variables</label>
+      <textarea id="variables" spellcheck="false">{}</textarea>
+    </section>
+    <section>
+      <label for="result">Result</label>
+      <pre id="result"></pre>
+    </section>
+  </main>
+  <script>
+    var query = document.getElementById('query');
+    var variables = document.getElementById('variables');
+    var result = document.getElementById('result');
+
+    function run() {
+      var params = {query: query.value};
+      try {
+        params.variables = variables.value.trim() ? JSON.parse(variables.value) : null;
+      } catch (e) {
+        result.textContent = 'Invalid variables: ' + e.message;
+        return;
+      }
+      result.textContent = 'Loading...';
+      fetch({{.}}, {
+        method: 'post',
+        headers: {'Accept': 'application/json', 'Content-Type': 'application/json'},
+        body: JSON.stringify(params),
+        credentials: 'same-origin',
+      }).then(function (response) {
+        return response.json();
+      }).then(function (json) {
+        result.textContent = JSON.stringify(json, null, 2);
+      }).catch(function (e) {
+        result.textContent = e.message;
+      });
+    }
+
+    document.getElementById('run').addEventListener('click', run);
+    document.addEventListener('keydown', function (e) {
+      if ((e.ctrlKey || e.metaKey) && e.key === 'Enter') {
+        run();
+      }
+    });
+  </script>
+</body>
+</html>
+`))
@@ -0,0 +1,222 @@
+package graphql
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// directiveDefinition describes a directive supported by the executor
+type directiveDefinition struct {
+	Name        string
+	Description string
+	Locations   []string
+	Args        []*Argument
+}
+
+var (
+	skipDirective = &directiveDefinition{
+		Name:        "skip",
+		Description: "Directs the executor to skip this field or fragment when the `if` argument is true.",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:        []*Argument{{Name: "if", Description: "Skipped when true.", Type: NewNonNull(Boolean)}},
+	}
+	includeDirective = &directiveDefinition{
+		Name:        "include",
+		Description: "Directs the executor to include this field or fragment only when the `if` argument is true.",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:        []*Argument{{Name: "if", Description: "Included when true.", Type: NewNonNull(Boolean)}},
+	}
+
+	typenameField = &Field{
+		Name:        "__typename",
+		Description: "The name of the current object type.",
+		Type:        NewNonNull(String),
+	}
+
+	typeKindEnum = &Enum{
+		Name:        "__TypeKind",
+		Description: "An enum describing what kind of type a given `__Type` is.",
+		Values:      []string{"SCALAR", "OBJECT", "INTERFACE", "UNION", "ENUM", "INPUT_OBJECT", "LIST", "NON_NULL"},
+	}
+	directiveLocationEnum = &Enum{
+		Name:        "__DirectiveLocation",
+		Description: "A Directive can be adjacent to many parts of the GraphQL language.",
+		Values: []string{"QUERY", "MUTATION", "SUBSCRIPTION", "FIELD", "FRAGMENT_DEFINITION", "FRAGMENT_SPREAD",
+			"INLINE_FRAGMENT", "VARIABLE_DEFINITION", "SCHEMA", "SCALAR", "OBJECT", "FIELD_DEFINITION",
+			"ARGUMENT_DEFINITION", "INTERFACE", "UNION", "ENUM", "ENUM_VALUE", "INPUT_OBJECT", "INPUT_FIELD_DEFINITION"},
+	}
+
+	schemaType     = &Object{Name: "__Schema", Description: "A GraphQL Schema defines the capabilities of a GraphQL server."}
+	typeType       = &Object{Name: "__Type", Description: "The fundamental unit of any GraphQL Schema is the type."}
+	fieldType      = &Object{Name: "__Field", Description: "Object and Interface types are described by a list of Fields."}
+	inputValueType = &Object{Name: "__InputValue", Description: "Arguments provided to Fields or Directives and the input fields of an InputObject."}
+	enumValueType  = &Object{Name: "__EnumValue", Description: "One possible value for a given Enum."}
+	directiveType  = &Object{Name: "__Directive", Description: "A Directive provides a way to describe alternate runtime execution in GraphQL."}
+)
+
+// init wires up the introspection types, which reference each other
+func init() {
+	includeDeprecated := []*Argument{{Name: "includeDeprecated", Type: Boolean, DefaultValue: false}}
+	typeList := NewList(NewNonNull(typeType))
+
+	schemaType.AddField(
+		&Field{Name: "description", Type: String, Resolve: null},
+		&Field{Name: "types", Type: NewNonNull(typeList), Resolve: func(p ResolveParams) (interface{}, error) {
+			s := p.Source.(*Schema)
+			names := append([]string{}, s.names...)
+			sort.Strings(names)
+			types := make([]Type, len(names))
+			for i, name := range names {
+				types[i] = s.types[name]
+			}
+			return types, nil
+		}},
+		&Field{Name: "queryType", Type: NewNonNull(typeType), Resolve: func(p ResolveParams) (interface{}, error) {
+			return p.Source.(*Schema).Query, nil
+		}},
+		&Field{Name: "mutationType", Type: typeType, Resolve: func(p ResolveParams) (interface{}, error) {
+			if m := p.Source.(*Schema).Mutation; m != nil {
+				return m, nil
+			}
+			return nil, nil
+		}},
+		&Field{Name: "subscriptionType", Type: typeType, Resolve: null},
+		&Field{Name: "directives", Type: NewNonNull(NewList(NewNonNull(directiveType))), Resolve: func(p ResolveParams) (interface{}, error) {
+			return []*directiveDefinition{includeDirective, skipDirective}, nil
+		}},
+	)
+
+	typeType.AddField(
+		&Field{Name: "kind", Type: NewNonNull(typeKindEnum), Resolve: func(p ResolveParams) (interface{}, error) {
+			switch p.Source.(type) {
+			case *Scalar:
+				return "SCALAR", nil
+			case *Object:
+				return "OBJECT", nil
+			case *Enum:
+				return "ENUM", nil
+			case *InputObject:
+				return "INPUT_OBJECT", nil
+			case *List:
+				return "LIST", nil
+			}
+			return "NON_NULL", nil
+		}},
+		&Field{Name: "name", Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			switch t := p.Source.(type) {
+			case *List, *NonNull:
+				return nil, nil
+			case Type:
+				return t.String(), nil
+			}
+			return nil, nil
+		}},
+		&Field{Name: "description", Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			switch t := p.Source.(type) {
+			case *Scalar:
+				return t.Description, nil
+			case *Object:
+				return t.Description, nil
+			case *Enum:
+				return t.Description, nil
+			case *InputObject:
+				return t.Description, nil
+			}
+			return nil, nil
+		}},
+		&Field{Name: "specifiedByURL", Type: String, Resolve: null},
+		&Field{Name: "fields", Type: NewList(NewNonNull(fieldType)), Args: includeDeprecated, Resolve: func(p ResolveParams) (interface{}, error) {
+			if o, ok := p.Source.(*Object); ok {
+				return o.Fields, nil
+			}
+			return nil, nil
+		}},
+		&Field{Name: "interfaces", Type: typeList, Resolve: func(p ResolveParams) (interface{}, error) {
+			if _, ok := p.Source.(*Object); ok {
+				return []Type{}, nil
+			}
+			return nil, nil
+		}},
+		&Field{Name: "possibleTypes", Type: typeList, Resolve: null},
+		&Field{Name: "enumValues", Type: NewList(NewNonNull(enumValueType)), Args: includeDeprecated, Resolve: func(p ResolveParams) (interface{}, error) {
+			if e, ok := p.Source.(*Enum); ok {
+				return e.Values, nil
+			}
+			return nil, nil
+		}},
+		&Field{Name: "inputFields", Type: NewList(NewNonNull(inputValueType)), Resolve: func(p ResolveParams) (interface{}, error) {
+			if i, ok := p.Source.(*InputObject); ok {
+				return i.Fields, nil
+			}
+			return nil, nil
+		}},
+		&Field{Name: "ofType", Type: typeType, Resolve: func(p ResolveParams) (interface{}, error) {
+			switch t := p.Source.(type) {
+			case *List:
+				return t.OfType, nil
+			case *NonNull:
+				return t.OfType, nil
+			}
+			return nil, nil
+		}},
+	)
+
+	fieldType.AddField(
+		&Field{Name: "name", Type: NewNonNull(String)},
+		&Field{Name: "description", Type: String},
+		&Field{Name: "args", Type: NewNonNull(NewList(NewNonNull(inputValueType))), Resolve: func(p ResolveParams) (interface{}, error) {
+			if args := p.Source.(*Field).Args; args != nil {
+				return args, nil
+			}
+			return []*Argument{}, nil
+		}},
+		&Field{Name: "type", Type: NewNonNull(typeType)},
+		&Field{Name: "isDeprecated", Type: NewNonNull(Boolean), Resolve: constant(false)},
+		&Field{Name: "deprecationReason", Type: String, Resolve: null},
+	)
+
+	inputValueType.AddField(
+		&Field{Name: "name", Type: NewNonNull(String)},
+		&Field{Name: "description", Type: String},
+		&Field{Name: "type", Type: NewNonNull(typeType)},
+		&Field{Name: "defaultValue", Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			v := p.Source.(*Argument).DefaultValue
+			if v == nil {
+				return nil, nil
+			}
+			b, err := json.Marshal(v)
+			return string(b), err
+		}},
+		&Field{Name: "isDeprecated", Type: NewNonNull(Boolean), Resolve: constant(false)},
+		&Field{Name: "deprecationReason", Type: String, Resolve: null},
+	)
+
+	enumValueType.AddField(
+		&Field{Name: "name", Type: NewNonNull(String), Resolve: func(p ResolveParams) (interface{}, error) {
+			return p.Source, nil
+		}},
+		&Field{Name: "description", Type: String, Resolve: null},
+		&Field{Name: "isDeprecated", Type: NewNonNull(Boolean), Resolve: constant(false)},
+		&Field{Name: "deprecationReason", Type: String, Resolve: null},
+	)
+
+	directiveType.AddField(
+		&Field{Name: "name", Type: NewNonNull(String)},
+		&Field{Name: "description", Type: String},
+		&Field{Name: "isRepeatable", Type: NewNonNull(Boolean), Resolve: constant(false)},
+		&Field{Name: "locations", Type: NewNonNull(NewList(NewNonNull(directiveLocationEnum)))},
+		&Field{Name: "args", Type: NewNonNull(NewList(NewNonNull(inputValueType)))},
+	)
+}
+
+// null resolves a field to null
+func null(ResolveParams) (interface{}, error) {
+	return nil, nil
+}
+
+// constant resolves a field to a fixed value
+func constant(v interface{}) ResolveFunc {
+	return func(ResolveParams) (interface{}, error) {
+		return v, nil
+	}
+}
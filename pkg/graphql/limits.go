@@ -0,0 +1,81 @@
+package graphql
+
+import (
+	"fmt"
+)
+
+// checkLimits rejects operations whose fields are nested deeper than the maximum depth of the schema or that
+// select more fields than its maximum complexity, before any of their fields is resolved
+func checkLimits(schema *Schema, doc *document, op *operation) error {
+	if schema.MaxDepth <= 0 && schema.MaxComplexity <= 0 {
+		return nil
+	}
+	m := &measurer{doc: doc, fragments: map[string]measure{}, visiting: map[string]bool{}}
+	size := m.selections(op.selections)
+	if schema.MaxDepth > 0 && size.depth > schema.MaxDepth {
+		return &Error{
+			Message:   fmt.Sprintf("Operation has a depth of %d, which exceeds the maximum depth of %d.", size.depth, schema.MaxDepth),
+			Locations: []Location{op.loc},
+		}
+	}
+	if schema.MaxComplexity > 0 && size.complexity > schema.MaxComplexity {
+		return &Error{
+			Message:   fmt.Sprintf("Operation has a complexity of %d, which exceeds the maximum complexity of %d.", size.complexity, schema.MaxComplexity),
+			Locations: []Location{op.loc},
+		}
+	}
+	return nil
+}
+
+// measure is the depth of the most nested field and the number of fields of a selection set
+type measure struct {
+	depth      int
+	complexity int
+}
+
+// add merges the measure of a selection on the same level into m
+func (m *measure) add(other measure) {
+	if other.depth > m.depth {
+		m.depth = other.depth
+	}
+	m.complexity += other.complexity
+}
+
+// measurer measures the selection sets of a document. The measures of fragments are kept, so that fragments
+// spread many times are only measured once, and fragments spreading themselves don't count.
+type measurer struct {
+	doc       *document
+	fragments map[string]measure
+	visiting  map[string]bool
+}
+
+func (m *measurer) selections(selections []selection) measure {
+	size := measure{}
+	for _, s := range selections {
+		switch s := s.(type) {
+		case *field:
+			nested := m.selections(s.selections)
+			size.add(measure{depth: nested.depth + 1, complexity: nested.complexity + 1})
+		case *inlineFragment:
+			size.add(m.selections(s.selections))
+		case *fragmentSpread:
+			size.add(m.fragment(s.name))
+		}
+	}
+	return size
+}
+
+func (m *measurer) fragment(name string) measure {
+	if size, ok := m.fragments[name]; ok {
+		return size
+	}
+	f := m.doc.fragments[name]
+	if f == nil || m.visiting[name] {
+		return measure{}
+	}
+	m.visiting[name] = true
+	size := m.selections(f.selections)
+	delete(m.visiting, name)
+	m.fragments[name] = size
+	return size
+}
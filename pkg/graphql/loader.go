@@ -0,0 +1,69 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchFunc loads the values for all keys at once. Keys without a value may be omitted from the result.
+type BatchFunc func(ctx context.Context, keys []int) (map[int]interface{}, error)
+
+// Loader collects the keys requested by resolvers and loads them with a single call of its BatchFunc once
+// the first of the returned thunks is resolved. Results are cached for the lifetime of the loader,
+// which should therefore be created per request.
+type Loader struct {
+	batch BatchFunc
+
+	mu      sync.Mutex
+	queue   []int
+	results map[int]*loaderResult
+}
+
+type loaderResult struct {
+	done  bool
+	value interface{}
+	err   error
+}
+
+// NewLoader returns a loader that uses batch to load the queued keys
+func NewLoader(batch BatchFunc) *Loader {
+	return &Loader{
+		batch:   batch,
+		results: map[int]*loaderResult{},
+	}
+}
+
+// Load queues the key and returns a thunk resolving to its value
+func (l *Loader) Load(ctx context.Context, key int) Thunk {
+	l.mu.Lock()
+	if _, ok := l.results[key]; !ok {
+		l.results[key] = &loaderResult{}
+		l.queue = append(l.queue, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		result := l.results[key]
+		if !result.done {
+			l.dispatch(ctx)
+		}
+		return result.value, result.err
+	}
+}
+
+// dispatch loads all queued keys. It must be called with the lock held.
+func (l *Loader) dispatch(ctx context.Context) {
+	keys := l.queue
+	l.queue = nil
+
+	values, err := l.batch(ctx, keys)
+	for _, key := range keys {
+		result := l.results[key]
+		result.done = true
+		result.value = values[key]
+		result.err = err
+	}
+}
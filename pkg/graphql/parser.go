@@ -0,0 +1,531 @@
+package graphql
+
+import (
+	"fmt"
+)
+
+// document is the parsed representation of a graphql request document
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+// operation is a single query or mutation of a document
+type operation struct {
+	kind       string
+	name       string
+	variables  []*variableDefinition
+	directives []*directive
+	selections []selection
+	loc        Location
+}
+
+// variableDefinition declares a variable of an operation
+type variableDefinition struct {
+	name         string
+	typ          *typeRef
+	defaultValue *value
+}
+
+// typeRef references a type by name, possibly wrapped in lists and non-null markers
+type typeRef struct {
+	name    string
+	elem    *typeRef
+	nonNull bool
+}
+
+// selection is one of *field, *fragmentSpread or *inlineFragment
+type selection interface{}
+
+// field selects a single field of an object
+type field struct {
+	alias      string
+	name       string
+	arguments  []*argument
+	directives []*directive
+	selections []selection
+	loc        Location
+}
+
+// responseKey returns the key under which the field is returned
+func (f *field) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+// fragmentSpread includes a named fragment
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	loc        Location
+}
+
+// inlineFragment includes a selection set if the type condition matches
+type inlineFragment struct {
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+}
+
+// fragment is a named, reusable selection set
+type fragment struct {
+	name          string
+	typeCondition string
+	directives    []*directive
+	selections    []selection
+}
+
+// argument is a named value passed to a field or directive
+type argument struct {
+	name  string
+	value *value
+}
+
+// directive annotates a selection
+type directive struct {
+	name      string
+	arguments []*argument
+}
+
+type valueKind int
+
+const (
+	valueVariable valueKind = iota
+	valueInt
+	valueFloat
+	valueString
+	valueBoolean
+	valueNull
+	valueEnum
+	valueList
+	valueObject
+)
+
+// value is a literal or variable reference in a document
+type value struct {
+	kind   valueKind
+	raw    string
+	list   []*value
+	fields []*objectField
+}
+
+// objectField is a single field of an input object literal
+type objectField struct {
+	name  string
+	value *value
+}
+
+// parser builds a document from the tokens of the lexer
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+// parse parses a graphql request document
+func parse(src string) (*document, error) {
+	p := &parser{lex: &lexer{src: src}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{fragments: map[string]*fragment{}}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek(tokenPunctuator, "{"):
+			op, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case p.peek(tokenName, "query"), p.peek(tokenName, "mutation"), p.peek(tokenName, "subscription"):
+			op, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case p.peek(tokenName, "fragment"):
+			frag, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[frag.name]; ok {
+				return nil, &Error{Message: fmt.Sprintf("There can be only one fragment named %q.", frag.name)}
+			}
+			doc.fragments[frag.name] = frag
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, &Error{Message: "Document does not contain any operation."}
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) peek(kind tokenKind, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return p.lex.errorf(p.tok.pos, "Unexpected <EOF>.")
+	}
+	return p.lex.errorf(p.tok.pos, "Unexpected %q.", p.tok.value)
+}
+
+// skip advances if the current token matches and reports whether it did
+func (p *parser) skip(kind tokenKind, value string) (bool, error) {
+	if !p.peek(kind, value) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(kind tokenKind, value string) error {
+	if !p.peek(kind, value) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) parseOperation() (*operation, error) {
+	op := &operation{kind: "query", loc: p.lex.location(p.tok.pos)}
+	if p.tok.kind == tokenPunctuator {
+		selections, err := p.parseSelectionSet()
+		op.selections = selections
+		return op, err
+	}
+
+	op.kind = p.tok.value
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName {
+		op.name = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	if ok, err := p.skip(tokenPunctuator, "("); err != nil {
+		return nil, err
+	} else if ok {
+		for !p.peek(tokenPunctuator, ")") {
+			def, err := p.parseVariableDefinition()
+			if err != nil {
+				return nil, err
+			}
+			op.variables = append(op.variables, def)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if op.directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	op.selections, err = p.parseSelectionSet()
+	return op, err
+}
+
+func (p *parser) parseVariableDefinition() (*variableDefinition, error) {
+	if err := p.expect(tokenPunctuator, "$"); err != nil {
+		return nil, err
+	}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokenPunctuator, ":"); err != nil {
+		return nil, err
+	}
+	typ, err := p.parseTypeRef()
+	if err != nil {
+		return nil, err
+	}
+
+	def := &variableDefinition{name: name, typ: typ}
+	if ok, err := p.skip(tokenPunctuator, "="); err != nil {
+		return nil, err
+	} else if ok {
+		if def.defaultValue, err = p.parseValue(true); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+func (p *parser) parseTypeRef() (*typeRef, error) {
+	ref := &typeRef{}
+	if ok, err := p.skip(tokenPunctuator, "["); err != nil {
+		return nil, err
+	} else if ok {
+		if ref.elem, err = p.parseTypeRef(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenPunctuator, "]"); err != nil {
+			return nil, err
+		}
+	} else if ref.name, err = p.expectName(); err != nil {
+		return nil, err
+	}
+
+	ok, err := p.skip(tokenPunctuator, "!")
+	ref.nonNull = ok
+	return ref, err
+}
+
+func (p *parser) parseSelectionSet() ([]selection, error) {
+	if err := p.expect(tokenPunctuator, "{"); err != nil {
+		return nil, err
+	}
+
+	var selections []selection
+	for !p.peek(tokenPunctuator, "}") {
+		if p.tok.kind == tokenEOF {
+			return nil, p.unexpected()
+		}
+		sel, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, sel)
+	}
+	if len(selections) == 0 {
+		return nil, p.unexpected()
+	}
+	return selections, p.advance()
+}
+
+func (p *parser) parseSelection() (selection, error) {
+	if !p.peek(tokenPunctuator, "...") {
+		return p.parseField()
+	}
+
+	loc := p.lex.location(p.tok.pos)
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		spread := &fragmentSpread{name: p.tok.value, loc: loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		spread.directives, err = p.parseDirectives()
+		return spread, err
+	}
+
+	inline := &inlineFragment{}
+	if ok, err := p.skip(tokenName, "on"); err != nil {
+		return nil, err
+	} else if ok {
+		if inline.typeCondition, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if inline.directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	inline.selections, err = p.parseSelectionSet()
+	return inline, err
+}
+
+func (p *parser) parseField() (*field, error) {
+	f := &field{loc: p.lex.location(p.tok.pos)}
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	f.name = name
+
+	if ok, err := p.skip(tokenPunctuator, ":"); err != nil {
+		return nil, err
+	} else if ok {
+		f.alias = name
+		if f.name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.arguments, err = p.parseArguments(); err != nil {
+		return nil, err
+	}
+	if f.directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	if p.peek(tokenPunctuator, "{") {
+		if f.selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (p *parser) parseArguments() ([]*argument, error) {
+	if ok, err := p.skip(tokenPunctuator, "("); err != nil || !ok {
+		return nil, err
+	}
+
+	var args []*argument
+	for !p.peek(tokenPunctuator, ")") {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenPunctuator, ":"); err != nil {
+			return nil, err
+		}
+		v, err := p.parseValue(false)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, &argument{name: name, value: v})
+	}
+	return args, p.advance()
+}
+
+func (p *parser) parseDirectives() ([]*directive, error) {
+	var directives []*directive
+	for p.peek(tokenPunctuator, "@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, &directive{name: name, arguments: args})
+	}
+	return directives, nil
+}
+
+func (p *parser) parseFragment() (*fragment, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.peek(tokenName, "on") {
+		return nil, p.unexpected()
+	}
+
+	frag := &fragment{}
+	var err error
+	if frag.name, err = p.expectName(); err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokenName, "on"); err != nil {
+		return nil, err
+	}
+	if frag.typeCondition, err = p.expectName(); err != nil {
+		return nil, err
+	}
+	if frag.directives, err = p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	frag.selections, err = p.parseSelectionSet()
+	return frag, err
+}
+
+// parseValue parses a value literal. Variables are only allowed if isConst is false.
+func (p *parser) parseValue(isConst bool) (*value, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenPunctuator:
+		switch tok.value {
+		case "$":
+			if isConst {
+				return nil, p.unexpected()
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			name, err := p.expectName()
+			return &value{kind: valueVariable, raw: name}, err
+		case "[":
+			return p.parseList(isConst)
+		case "{":
+			return p.parseObject(isConst)
+		}
+	case tokenInt:
+		return &value{kind: valueInt, raw: tok.value}, p.advance()
+	case tokenFloat:
+		return &value{kind: valueFloat, raw: tok.value}, p.advance()
+	case tokenString:
+		return &value{kind: valueString, raw: tok.value}, p.advance()
+	case tokenName:
+		switch tok.value {
+		case "true", "false":
+			return &value{kind: valueBoolean, raw: tok.value}, p.advance()
+		case "null":
+			return &value{kind: valueNull}, p.advance()
+		}
+		return &value{kind: valueEnum, raw: tok.value}, p.advance()
+	}
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseList(isConst bool) (*value, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	list := &value{kind: valueList}
+	for !p.peek(tokenPunctuator, "]") {
+		item, err := p.parseValue(isConst)
+		if err != nil {
+			return nil, err
+		}
+		list.list = append(list.list, item)
+	}
+	return list, p.advance()
+}
+
+func (p *parser) parseObject(isConst bool) (*value, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	obj := &value{kind: valueObject}
+	for !p.peek(tokenPunctuator, "}") {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenPunctuator, ":"); err != nil {
+			return nil, err
+		}
+		v, err := p.parseValue(isConst)
+		if err != nil {
+			return nil, err
+		}
+		obj.fields = append(obj.fields, &objectField{name: name, value: v})
+	}
+	return obj, p.advance()
+}
@@ -0,0 +1,187 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// DateTime is a scalar for time.Time values, serialized as RFC 3339 string
+var DateTime = &Scalar{
+	Name:        "DateTime",
+	Description: "The `DateTime` scalar type represents a point in time as RFC 3339 string.",
+	Serialize: func(v interface{}) (interface{}, error) {
+		t, ok := v.(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("DateTime cannot represent value: %v", v)
+		}
+		return t.Format(time.RFC3339Nano), nil
+	},
+	ParseValue: func(v interface{}) (interface{}, error) {
+		switch t := v.(type) {
+		case time.Time:
+			return t, nil
+		case string:
+			return time.Parse(time.RFC3339Nano, t)
+		}
+		return nil, fmt.Errorf("DateTime cannot represent value: %v", v)
+	},
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Mapper generates object and input object types from go structs, using the json names of the struct fields.
+// Types are cached per go type, so structs referencing each other share the same graphql types.
+// Fields tagged with graphql:"-" are left out.
+type Mapper struct {
+	objects map[reflect.Type]*Object
+	inputs  map[reflect.Type]*InputObject
+	custom  map[reflect.Type]customType
+}
+
+// customType contains the graphql types registered for a go type
+type customType struct {
+	output Type
+	input  Type
+}
+
+// NewMapper returns an empty Mapper
+func NewMapper() *Mapper {
+	return &Mapper{
+		objects: map[reflect.Type]*Object{},
+		inputs:  map[reflect.Type]*InputObject{},
+		custom:  map[reflect.Type]customType{},
+	}
+}
+
+// Register maps the go type of v to the given output and input types instead of deriving them from its fields.
+// This is meant for types with a custom json encoding. The types are used as they are, including their nullability.
+func (m *Mapper) Register(v interface{}, output Type, input Type) {
+	m.custom[structType(v)] = customType{output: output, input: input}
+}
+
+// Object returns the object type for the struct v, named like the go type
+func (m *Mapper) Object(v interface{}) *Object {
+	return m.object(structType(v))
+}
+
+// InputObject returns the input object type for the struct v, named like the go type with an Input suffix.
+// All fields of an input object are optional.
+func (m *Mapper) InputObject(v interface{}) *InputObject {
+	return m.input(structType(v))
+}
+
+func (m *Mapper) object(t reflect.Type) *Object {
+	if o, ok := m.objects[t]; ok {
+		return o
+	}
+	o := &Object{Name: t.Name()}
+	m.objects[t] = o
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := jsonName(sf)
+		if sf.PkgPath != "" || name == "-" || sf.Tag.Get("graphql") == "-" {
+			continue
+		}
+		if ft := m.typeOf(sf.Type, false); ft != nil {
+			o.Fields = append(o.Fields, &Field{Name: name, Type: ft})
+		}
+	}
+	return o
+}
+
+func (m *Mapper) input(t reflect.Type) *InputObject {
+	if i, ok := m.inputs[t]; ok {
+		return i
+	}
+	i := &InputObject{Name: t.Name() + "Input"}
+	m.inputs[t] = i
+
+	for n := 0; n < t.NumField(); n++ {
+		sf := t.Field(n)
+		name := jsonName(sf)
+		if sf.PkgPath != "" || name == "-" || sf.Tag.Get("graphql") == "-" {
+			continue
+		}
+		if ft := m.typeOf(sf.Type, true); ft != nil {
+			i.Fields = append(i.Fields, &Argument{Name: name, Type: ft})
+		}
+	}
+	return i
+}
+
+// typeOf maps a go type to a graphql type. It returns nil for go types that can't be mapped.
+func (m *Mapper) typeOf(t reflect.Type, input bool) Type {
+	nullable := input
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	if c, ok := m.custom[t]; ok {
+		if input {
+			return c.input
+		}
+		return c.output
+	}
+
+	var gt Type
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		gt = Int
+	case reflect.Float32, reflect.Float64:
+		gt = Float
+	case reflect.String:
+		gt = String
+	case reflect.Bool:
+		gt = Boolean
+	case reflect.Slice:
+		elem := m.typeOf(t.Elem(), false)
+		if elem == nil {
+			return nil
+		}
+		if input {
+			elem = m.typeOf(t.Elem(), true)
+		}
+		return NewList(elem)
+	case reflect.Struct:
+		switch {
+		case t == timeType:
+			gt = DateTime
+		case input:
+			gt = m.input(t)
+		default:
+			gt = m.object(t)
+		}
+	default:
+		return nil
+	}
+
+	if nullable {
+		return gt
+	}
+	return NewNonNull(gt)
+}
+
+// Decode converts a coerced input object argument into the struct v, using the json names of its fields
+func Decode(input interface{}, v interface{}) error {
+	b, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func structType(v interface{}) reflect.Type {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("graphql: expected struct, got %s", t))
+	}
+	return t
+}
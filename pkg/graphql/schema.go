@@ -0,0 +1,369 @@
+// Package graphql implements a small graphql server: a parser, an executor with support for batched
+// resolution through thunks, introspection and an http handler with a GraphiQL playground.
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Type is implemented by all graphql types. String returns the type as it is written in a schema, e.g. [Int!]
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type that serializes to a single json value
+type Scalar struct {
+	Name        string
+	Description string
+	// Serialize converts a resolved go value to its json representation
+	Serialize func(v interface{}) (interface{}, error)
+	// ParseValue converts an input value to the go value passed to resolvers
+	ParseValue func(v interface{}) (interface{}, error)
+}
+
+// String implements the Type interface
+func (s *Scalar) String() string {
+	return s.Name
+}
+
+// Enum is a leaf type restricted to a fixed set of values
+type Enum struct {
+	Name        string
+	Description string
+	Values      []string
+}
+
+// String implements the Type interface
+func (e *Enum) String() string {
+	return e.Name
+}
+
+func (e *Enum) has(v string) bool {
+	for _, value := range e.Values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Object is a type with a set of fields that can be selected
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+// String implements the Type interface
+func (o *Object) String() string {
+	return o.Name
+}
+
+// Field returns the field with the given name or nil if it doesn't exist
+func (o *Object) Field(name string) *Field {
+	for _, f := range o.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// AddField adds fields to the object, replacing existing fields with the same name
+func (o *Object) AddField(fields ...*Field) {
+	for _, f := range fields {
+		replaced := false
+		for i, existing := range o.Fields {
+			if existing.Name == f.Name {
+				o.Fields[i] = f
+				replaced = true
+			}
+		}
+		if !replaced {
+			o.Fields = append(o.Fields, f)
+		}
+	}
+}
+
+// InputObject is a composite type that can be passed as argument
+type InputObject struct {
+	Name        string
+	Description string
+	Fields      []*Argument
+}
+
+// String implements the Type interface
+func (i *InputObject) String() string {
+	return i.Name
+}
+
+// List wraps a type to represent a list of it
+type List struct {
+	OfType Type
+}
+
+// NewList returns a list of the given type
+func NewList(t Type) *List {
+	return &List{OfType: t}
+}
+
+// String implements the Type interface
+func (l *List) String() string {
+	return "[" + l.OfType.String() + "]"
+}
+
+// NonNull wraps a type to mark that it can never be null
+type NonNull struct {
+	OfType Type
+}
+
+// NewNonNull returns a non-null version of the given type
+func NewNonNull(t Type) *NonNull {
+	return &NonNull{OfType: t}
+}
+
+// String implements the Type interface
+func (n *NonNull) String() string {
+	return n.OfType.String() + "!"
+}
+
+// Field is a field of an object
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Argument
+	// Resolve returns the value of the field. If it is nil, the field is looked up in the source by its json name.
+	Resolve ResolveFunc
+}
+
+// Argument is an argument of a field or a field of an input object
+type Argument struct {
+	Name         string
+	Description  string
+	Type         Type
+	DefaultValue interface{}
+}
+
+// ResolveFunc resolves the value of a field. It may return a Thunk to defer the resolution,
+// which allows batching the lookups of sibling fields, see Loader.
+type ResolveFunc func(p ResolveParams) (interface{}, error)
+
+// ResolveParams are passed to every ResolveFunc
+type ResolveParams struct {
+	Context context.Context
+	// Source is the resolved value of the parent object
+	Source interface{}
+	// Args contains the coerced arguments of the field
+	Args map[string]interface{}
+}
+
+// Thunk is a deferred value. Thunks are resolved after all fields on the same level have been resolved.
+type Thunk func() (interface{}, error)
+
+// Schema is an executable graphql schema
+type Schema struct {
+	Query    *Object
+	Mutation *Object
+	// MaxDepth limits how deep the fields of an operation are nested, 0 means unlimited
+	MaxDepth int
+	// MaxComplexity limits the number of fields an operation selects, including those of its fragments,
+	// 0 means unlimited
+	MaxComplexity int
+
+	types map[string]Type
+	names []string
+}
+
+// NewSchema builds a schema from its root types and collects all types that are reachable from them
+func NewSchema(query, mutation *Object) (*Schema, error) {
+	s := &Schema{
+		Query:    query,
+		Mutation: mutation,
+		types:    map[string]Type{},
+	}
+
+	roots := []Type{query, schemaType, Int, Float, String, Boolean, ID}
+	if mutation != nil {
+		roots = append(roots, mutation)
+	}
+	for _, t := range roots {
+		if err := s.collect(t); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Type returns the named type of the schema or nil if it doesn't exist
+func (s *Schema) Type(name string) Type {
+	return s.types[name]
+}
+
+// collect adds the named type t and all types referenced by it to the schema
+func (s *Schema) collect(t Type) error {
+	named := namedType(t)
+	name := named.String()
+	if existing, ok := s.types[name]; ok {
+		if existing != named {
+			return fmt.Errorf("schema contains more than one type named %q", name)
+		}
+		return nil
+	}
+	s.types[name] = named
+	s.names = append(s.names, name)
+
+	switch n := named.(type) {
+	case *Object:
+		for _, f := range n.Fields {
+			if err := s.collect(f.Type); err != nil {
+				return err
+			}
+			for _, arg := range f.Args {
+				if err := s.collect(arg.Type); err != nil {
+					return err
+				}
+			}
+		}
+	case *InputObject:
+		for _, f := range n.Fields {
+			if err := s.collect(f.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// namedType unwraps lists and non-null types
+func namedType(t Type) Type {
+	for {
+		switch w := t.(type) {
+		case *List:
+			t = w.OfType
+		case *NonNull:
+			t = w.OfType
+		default:
+			return t
+		}
+	}
+}
+
+var (
+	// Int is the builtin signed 32 bit integer type
+	Int = &Scalar{
+		Name:        "Int",
+		Description: "The `Int` scalar type represents non-fractional signed whole numeric values.",
+		Serialize:   coerceInt,
+		ParseValue:  coerceInt,
+	}
+	// Float is the builtin double precision floating point type
+	Float = &Scalar{
+		Name:        "Float",
+		Description: "The `Float` scalar type represents signed double-precision fractional values.",
+		Serialize:   coerceFloat,
+		ParseValue:  coerceFloat,
+	}
+	// String is the builtin UTF-8 string type
+	String = &Scalar{
+		Name:        "String",
+		Description: "The `String` scalar type represents textual data, represented as UTF-8 character sequences.",
+		Serialize:   serializeString,
+		ParseValue:  parseString,
+	}
+	// Boolean is the builtin true or false type
+	Boolean = &Scalar{
+		Name:        "Boolean",
+		Description: "The `Boolean` scalar type represents `true` or `false`.",
+		Serialize:   parseBoolean,
+		ParseValue:  parseBoolean,
+	}
+	// ID is the builtin unique identifier type, serialized as string
+	ID = &Scalar{
+		Name:        "ID",
+		Description: "The `ID` scalar type represents a unique identifier.",
+		Serialize:   serializeString,
+		ParseValue:  parseID,
+	}
+)
+
+func coerceInt(v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.Int() < math.MinInt32 || rv.Int() > math.MaxInt32 {
+			return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %v", v)
+		}
+		return int(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt32 {
+			return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %v", v)
+		}
+		return int(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f != math.Trunc(f) || f < math.MinInt32 || f > math.MaxInt32 {
+			return nil, fmt.Errorf("Int cannot represent non-integer value: %v", v)
+		}
+		return int(f), nil
+	}
+	return nil, fmt.Errorf("Int cannot represent non-integer value: %v", v)
+}
+
+func coerceFloat(v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	}
+	return nil, fmt.Errorf("Float cannot represent non numeric value: %v", v)
+}
+
+func serializeString(v interface{}) (interface{}, error) {
+	switch s := v.(type) {
+	case string:
+		return s, nil
+	case fmt.Stringer:
+		return s.String(), nil
+	case int:
+		return strconv.Itoa(s), nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.String {
+		return rv.String(), nil
+	}
+	return nil, fmt.Errorf("String cannot represent value: %v", v)
+}
+
+func parseString(v interface{}) (interface{}, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("String cannot represent a non string value: %v", v)
+}
+
+func parseID(v interface{}) (interface{}, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	i, err := coerceInt(v)
+	if err != nil {
+		return nil, fmt.Errorf("ID cannot represent value: %v", v)
+	}
+	return strconv.Itoa(i.(int)), nil
+}
+
+func parseBoolean(v interface{}) (interface{}, error) {
+	if b, ok := v.(bool); ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("Boolean cannot represent a non boolean value: %v", v)
+}
@@ -0,0 +1,73 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/go-chi/chi/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/jonnylangefeld/go-api/pkg/db"
+	"github.com/jonnylangefeld/go-api/pkg/grpc/goapiv1"
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// articlesServer implements the Articles service on top of the database client in the context
+type articlesServer struct {
+	goapiv1.UnimplementedArticlesServer
+}
+
+// GetArticle returns a single article by id
+func (s *articlesServer) GetArticle(ctx context.Context, req *goapiv1.GetByIDRequest) (*goapiv1.Article, error) {
+	article := m.GetDBClient(ctx).GetArticleByID(int(req.GetId()))
+	if article == nil || article.ID == 0 {
+		return nil, status.Errorf(codes.NotFound, "article %d not found", req.GetId())
+	}
+	return toArticle(article), nil
+}
+
+// ListArticles returns a page of all articles
+func (s *articlesServer) ListArticles(ctx context.Context, req *goapiv1.ListRequest) (*goapiv1.ArticleList, error) {
+	list := m.GetDBClient(ctx).GetArticles(int(req.GetPageId()), nil)
+	resp := &goapiv1.ArticleList{NextPageId: int64(list.NextPageID)}
+	for _, article := range list.Items {
+		resp.Items = append(resp.Items, toArticle(article))
+	}
+	return resp, nil
+}
+
+// SetArticle writes an article, a new one if its id is empty
+func (s *articlesServer) SetArticle(ctx context.Context, req *goapiv1.Article) (*goapiv1.Article, error) {
+	article := fromArticle(req)
+	if err := article.Bind(nil); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := auditedClient(ctx).SetArticle(article); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return toArticle(article), nil
+}
+
+// DeleteArticle deletes a single article by id
+func (s *articlesServer) DeleteArticle(ctx context.Context, req *goapiv1.GetByIDRequest) (*emptypb.Empty, error) {
+	article := m.GetDBClient(ctx).GetArticleByID(int(req.GetId()))
+	if article == nil || article.ID == 0 {
+		return nil, status.Errorf(codes.NotFound, "article %d not found", req.GetId())
+	}
+	if err := auditedClient(ctx).DeleteArticle(article.ID); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// auditedClient returns the database client recording the actor and the id of the call in the context in the
+// audit log of its writes
+func auditedClient(ctx context.Context) db.ClientInterface {
+	actor, ok := ctx.Value(m.ActorCtxKey).(string)
+	if !ok {
+		actor = types.AuditAnonymousActor
+	}
+	return m.GetDBClient(ctx).Audited(actor, middleware.GetReqID(ctx))
+}
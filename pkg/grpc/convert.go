@@ -0,0 +1,128 @@
+package grpc
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/jonnylangefeld/go-api/pkg/grpc/goapiv1"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// toMoney converts an amount to its message, missing amounts have none
+func toMoney(money types.Money) *goapiv1.Money {
+	if money == (types.Money{}) {
+		return nil
+	}
+	return &goapiv1.Money{Amount: money.Amount, Currency: money.Currency}
+}
+
+func fromMoney(money *goapiv1.Money) types.Money {
+	return types.NewMoney(money.GetAmount(), money.GetCurrency())
+}
+
+func toArticle(article *types.Article) *goapiv1.Article {
+	msg := &goapiv1.Article{
+		Id:          int64(article.ID),
+		Name:        article.Name,
+		Price:       toMoney(article.Price),
+		TaxCategory: article.TaxCategory,
+		Tags:        article.Tags,
+	}
+	for _, id := range article.CategoryIDs {
+		msg.CategoryIds = append(msg.CategoryIds, int64(id))
+	}
+	for _, variant := range article.Variants {
+		msg.Variants = append(msg.Variants, toVariant(variant))
+	}
+	return msg
+}
+
+func fromArticle(msg *goapiv1.Article) *types.Article {
+	article := &types.Article{
+		ID:          int(msg.GetId()),
+		Name:        msg.GetName(),
+		Price:       fromMoney(msg.GetPrice()),
+		TaxCategory: msg.GetTaxCategory(),
+		Tags:        msg.GetTags(),
+	}
+	for _, id := range msg.GetCategoryIds() {
+		article.CategoryIDs = append(article.CategoryIDs, int(id))
+	}
+	for _, variant := range msg.GetVariants() {
+		article.Variants = append(article.Variants, fromVariant(variant))
+	}
+	return article
+}
+
+func toVariant(variant *types.Variant) *goapiv1.Variant {
+	return &goapiv1.Variant{
+		Id:         int64(variant.ID),
+		ArticleId:  int64(variant.ArticleID),
+		Sku:        variant.SKU,
+		Attributes: variant.Attributes,
+		Price:      toMoney(variant.Price),
+	}
+}
+
+func fromVariant(msg *goapiv1.Variant) *types.Variant {
+	return &types.Variant{
+		ID:         int(msg.GetId()),
+		ArticleID:  int(msg.GetArticleId()),
+		SKU:        msg.GetSku(),
+		Attributes: msg.GetAttributes(),
+		Price:      fromMoney(msg.GetPrice()),
+	}
+}
+
+func toOrder(order *types.Order) *goapiv1.Order {
+	msg := &goapiv1.Order{
+		Id:         int64(order.ID),
+		Region:     order.Region,
+		CouponCode: order.CouponCode,
+		Subtotal:   toMoney(order.Subtotal),
+		Discount:   toMoney(order.Discount),
+		Tax:        toMoney(order.Tax),
+		Total:      toMoney(order.Total),
+	}
+	if !order.DateTime.IsZero() {
+		msg.DateTime = timestamppb.New(order.DateTime)
+	}
+	if order.CustomerID != nil {
+		msg.CustomerId = int64(*order.CustomerID)
+	}
+	for _, item := range order.Items {
+		msg.Items = append(msg.Items, &goapiv1.OrderItem{
+			Id:        int64(item.ID),
+			ArticleId: int64(item.ArticleID),
+			VariantId: int64(item.VariantID),
+			Quantity:  int64(item.Quantity),
+			UnitPrice: toMoney(item.UnitPrice),
+		})
+	}
+	return msg
+}
+
+// fromOrder converts an order message to an order. The amounts aren't converted, because they are calculated when
+// the order is written.
+func fromOrder(msg *goapiv1.Order) *types.Order {
+	order := &types.Order{
+		ID:         int(msg.GetId()),
+		Region:     msg.GetRegion(),
+		CouponCode: msg.GetCouponCode(),
+	}
+	if msg.GetDateTime() != nil {
+		order.DateTime = msg.GetDateTime().AsTime()
+	}
+	if msg.GetCustomerId() != 0 {
+		customerID := int(msg.GetCustomerId())
+		order.CustomerID = &customerID
+	}
+	for _, item := range msg.GetItems() {
+		order.Items = append(order.Items, &types.OrderItem{
+			ID:        int(item.GetId()),
+			ArticleID: int(item.GetArticleId()),
+			VariantID: int(item.GetVariantId()),
+			Quantity:  int(item.GetQuantity()),
+		})
+	}
+	return order
+}
@@ -0,0 +1,110 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/jonnylangefeld/go-api/pkg/db"
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+)
+
+const (
+	// requestIDKey is the metadata key of the id of a call, the counterpart of the X-Request-Id header
+	requestIDKey = "x-request-id"
+	// lastWriteKey is the metadata key of the time of the last write of a client in nanoseconds since the epoch,
+	// the counterpart of the last_write cookie
+	lastWriteKey = "x-last-write"
+	// apiPrefix is the prefix of the methods of the services defined in proto/api.proto
+	apiPrefix = "/goapi.v1."
+)
+
+// requestID interceptor is used to store the id of the call from the x-request-id metadata in the context, or a
+// new one if there is none. It is stored under the key of the request id middleware, so that the audit log records it.
+func requestID(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	id := incoming(ctx, requestIDKey)
+	if id == "" {
+		id = fmt.Sprintf("grpc-%06d", middleware.NextRequestID())
+	}
+	return handler(context.WithValue(ctx, middleware.RequestIDKey, id), req)
+}
+
+// logger returns an interceptor that logs each call, along with what was called, what the status of the response
+// was and how long it took to return
+func logger(l *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		t1 := time.Now()
+		resp, err := handler(ctx, req)
+		l.Info("served call",
+			zap.String("proto", "grpc"),
+			zap.String("method", info.FullMethod),
+			zap.Duration("lat", time.Since(t1)),
+			zap.String("status", status.Code(err).String()),
+			zap.String("reqId", middleware.GetReqID(ctx)))
+		return resp, err
+	}
+}
+
+// identify interceptor is used to store the actor of calls with a known bearer token in the authorization metadata
+// in the context. Like the Identify middleware, it lets calls without a known token pass.
+func identify(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if header := incoming(ctx, "authorization"); strings.HasPrefix(header, "Bearer ") {
+		if actor := m.Actor(strings.TrimPrefix(header, "Bearer ")); actor != "" {
+			ctx = context.WithValue(ctx, m.ActorCtxKey, actor)
+		}
+	}
+	return handler(ctx, req)
+}
+
+// database returns an interceptor that stores the database client in the context. Like the DatabaseAvailable
+// middleware, it fails calls fast while the database is down. Calls of the health service don't use the database and
+// always pass.
+func database(dbClient db.ClientInterface) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, apiPrefix) {
+			return handler(ctx, req)
+		}
+		if _, err := dbClient.Available(); err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		return handler(context.WithValue(ctx, m.DBClientCtxKey, dbClient), req)
+	}
+}
+
+// readYourWrites interceptor is used to read from the primary database after the writes of the same client only,
+// like the ReadYourWrites middleware. The time of the last write of the client is kept in the x-last-write
+// metadata, which is sent in the header of the responses to writes.
+func readYourWrites(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !strings.HasPrefix(info.FullMethod, apiPrefix) {
+		return handler(ctx, req)
+	}
+	var lastWrite time.Time
+	// writes in the future would pin the reads of the client to the primary for good
+	if nanos, err := strconv.ParseInt(incoming(ctx, lastWriteKey), 10, 64); err == nil && nanos <= time.Now().UnixNano() {
+		lastWrite = time.Unix(0, nanos)
+	}
+
+	client := m.GetDBClient(ctx).Session(lastWrite)
+	resp, err := handler(context.WithValue(ctx, m.DBClientCtxKey, client), req)
+	if written := client.LastWrite(); written.After(lastWrite) {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(lastWriteKey, strconv.FormatInt(written.UnixNano(), 10)))
+	}
+	return resp, err
+}
+
+// incoming returns the first value of the key in the metadata of the call
+func incoming(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/jonnylangefeld/go-api/pkg/grpc/goapiv1"
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// ordersServer implements the Orders service on top of the database client in the context
+type ordersServer struct {
+	goapiv1.UnimplementedOrdersServer
+}
+
+// GetOrder returns a single order by id
+func (s *ordersServer) GetOrder(ctx context.Context, req *goapiv1.GetByIDRequest) (*goapiv1.Order, error) {
+	order := m.GetDBClient(ctx).GetOrderByID(int(req.GetId()))
+	if order == nil || order.ID == 0 {
+		return nil, status.Errorf(codes.NotFound, "order %d not found", req.GetId())
+	}
+	return toOrder(order), nil
+}
+
+// ListOrders returns a page of all orders
+func (s *ordersServer) ListOrders(ctx context.Context, req *goapiv1.ListRequest) (*goapiv1.OrderList, error) {
+	list := m.GetDBClient(ctx).GetOrders(int(req.GetPageId()), nil)
+	resp := &goapiv1.OrderList{NextPageId: int64(list.NextPageID)}
+	for _, order := range list.Items {
+		resp.Items = append(resp.Items, toOrder(order))
+	}
+	return resp, nil
+}
+
+// SetOrder writes an order, a new one if its id is empty. Orders of more than the stock of their articles fail
+// with FailedPrecondition.
+func (s *ordersServer) SetOrder(ctx context.Context, req *goapiv1.Order) (*goapiv1.Order, error) {
+	order := fromOrder(req)
+	if err := order.Bind(nil); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := auditedClient(ctx).SetOrder(order); err != nil {
+		var stockErr *types.InsufficientStockError
+		if errors.As(err, &stockErr) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return toOrder(order), nil
+}
+
+// DeleteOrder deletes a single order by id together with its items
+func (s *ordersServer) DeleteOrder(ctx context.Context, req *goapiv1.GetByIDRequest) (*emptypb.Empty, error) {
+	order := m.GetDBClient(ctx).GetOrderByID(int(req.GetId()))
+	if order == nil || order.ID == 0 {
+		return nil, status.Errorf(codes.NotFound, "order %d not found", req.GetId())
+	}
+	if err := auditedClient(ctx).DeleteOrder(order.ID); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,30 @@
+// Package grpc serves the articles and orders api defined in proto/api.proto over gRPC
+package grpc
+
+import (
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/jonnylangefeld/go-api/pkg/db"
+	"github.com/jonnylangefeld/go-api/pkg/grpc/goapiv1"
+)
+
+// NewServer returns a gRPC server of the articles and orders services on top of the database client, together with
+// the health and reflection services. Calls are logged with log if it isn't nil.
+func NewServer(log *zap.Logger, dbClient db.ClientInterface) *grpc.Server {
+	interceptors := []grpc.UnaryServerInterceptor{requestID}
+	if log != nil {
+		interceptors = append(interceptors, logger(log))
+	}
+	interceptors = append(interceptors, identify, database(dbClient), readYourWrites)
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+
+	goapiv1.RegisterArticlesServer(s, &articlesServer{})
+	goapiv1.RegisterOrdersServer(s, &ordersServer{})
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	reflection.Register(s)
+	return s
+}
@@ -0,0 +1,233 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/jonnylangefeld/go-api/pkg/api/mocks"
+	"github.com/jonnylangefeld/go-api/pkg/grpc/goapiv1"
+	m "github.com/jonnylangefeld/go-api/pkg/middelware"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+var testArticle1 = types.Article{
+	ID:    1,
+	Name:  "Skittles",
+	Price: types.NewMoney(199, "USD"),
+}
+
+// dial serves the server on an in-memory listener and returns a connection to it
+func dial(t *testing.T, s *grpc.Server) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func getDBClientMock(t *testing.T) *mocks.MockClientInterface {
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	dbClient.EXPECT().Available().Return(time.Duration(0), nil).AnyTimes()
+	dbClient.EXPECT().Audited(gomock.Any(), gomock.Any()).Return(dbClient).AnyTimes()
+	dbClient.EXPECT().Session(gomock.Any()).Return(dbClient).AnyTimes()
+	dbClient.EXPECT().LastWrite().Return(time.Time{}).AnyTimes()
+	return dbClient
+}
+
+func TestArticles(t *testing.T) {
+	dbClient := getDBClientMock(t)
+	client := goapiv1.NewArticlesClient(dial(t, NewServer(nil, dbClient)))
+	ctx := context.Background()
+
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&testArticle1)
+	article, err := client.GetArticle(ctx, &goapiv1.GetByIDRequest{Id: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), article.GetId())
+	assert.Equal(t, "Skittles", article.GetName())
+	assert.Equal(t, int64(199), article.GetPrice().GetAmount())
+	assert.Equal(t, "USD", article.GetPrice().GetCurrency())
+
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(2)).Return(&types.Article{})
+	_, err = client.GetArticle(ctx, &goapiv1.GetByIDRequest{Id: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	dbClient.EXPECT().GetArticles(gomock.Eq(1), gomock.Nil()).Return(&types.ArticleList{
+		Items:      []*types.Article{&testArticle1},
+		NextPageID: 2,
+	})
+	list, err := client.ListArticles(ctx, &goapiv1.ListRequest{PageId: 1})
+	assert.NoError(t, err)
+	assert.Len(t, list.GetItems(), 1)
+	assert.Equal(t, int64(2), list.GetNextPageId())
+
+	dbClient.EXPECT().SetArticle(gomock.Any()).DoAndReturn(func(article *types.Article) error {
+		assert.Equal(t, "Jelly Beans", article.Name)
+		assert.Equal(t, types.NewMoney(299, "USD"), article.Price)
+		assert.Equal(t, []string{"vegan"}, article.Tags)
+		article.ID = 3
+		return nil
+	})
+	article, err = client.SetArticle(ctx, &goapiv1.Article{
+		Name:  "Jelly Beans",
+		Price: &goapiv1.Money{Amount: 299, Currency: "USD"},
+		Tags:  []string{" Vegan "},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), article.GetId())
+
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&testArticle1)
+	dbClient.EXPECT().DeleteArticle(gomock.Eq(1)).Return(nil)
+	_, err = client.DeleteArticle(ctx, &goapiv1.GetByIDRequest{Id: 1})
+	assert.NoError(t, err)
+
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(2)).Return(&types.Article{})
+	_, err = client.DeleteArticle(ctx, &goapiv1.GetByIDRequest{Id: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestOrders(t *testing.T) {
+	dbClient := getDBClientMock(t)
+	client := goapiv1.NewOrdersClient(dial(t, NewServer(nil, dbClient)))
+	ctx := context.Background()
+
+	dbClient.EXPECT().GetOrderByID(gomock.Eq(1)).Return(nil)
+	_, err := client.GetOrder(ctx, &goapiv1.GetByIDRequest{Id: 1})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.SetOrder(ctx, &goapiv1.Order{Items: []*goapiv1.OrderItem{{ArticleId: 1}}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	dbClient.EXPECT().SetOrder(gomock.Any()).Return(&types.InsufficientStockError{ArticleID: 1, Requested: 2})
+	_, err = client.SetOrder(ctx, &goapiv1.Order{Items: []*goapiv1.OrderItem{{ArticleId: 1, Quantity: 2}}})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	dbClient.EXPECT().SetOrder(gomock.Any()).DoAndReturn(func(order *types.Order) error {
+		assert.Len(t, order.Items, 1)
+		assert.Equal(t, 7, *order.CustomerID)
+		assert.Equal(t, "SAVE10", order.CouponCode)
+		order.ID = 1
+		order.Total = types.NewMoney(398, "USD")
+		return nil
+	})
+	order, err := client.SetOrder(ctx, &goapiv1.Order{
+		CustomerId: 7,
+		CouponCode: " save10",
+		Items:      []*goapiv1.OrderItem{{ArticleId: 1, Quantity: 2}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), order.GetId())
+	assert.Equal(t, int64(2), order.GetItems()[0].GetQuantity())
+	assert.Equal(t, int64(398), order.GetTotal().GetAmount())
+	assert.Equal(t, int64(7), order.GetCustomerId())
+	assert.Nil(t, order.GetDiscount())
+
+	dbClient.EXPECT().GetOrders(gomock.Eq(0), gomock.Nil()).Return(&types.OrderList{Items: []*types.Order{{ID: 1}}})
+	list, err := client.ListOrders(ctx, &goapiv1.ListRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, list.GetItems(), 1)
+
+	dbClient.EXPECT().GetOrderByID(gomock.Eq(1)).Return(&types.Order{ID: 1})
+	dbClient.EXPECT().DeleteOrder(gomock.Eq(1)).Return(nil)
+	_, err = client.DeleteOrder(ctx, &goapiv1.GetByIDRequest{Id: 1})
+	assert.NoError(t, err)
+}
+
+// TestInterceptors ensures that calls are logged and audited with their request id and actor and fail while the
+// database is down, and that the health service is served regardless
+func TestInterceptors(t *testing.T) {
+	m.SetTokens(map[string]string{"s3cr3t": "backoffice"})
+	defer m.SetTokens(nil)
+
+	buf := &bytes.Buffer{}
+	log := zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zap.InfoLevel))
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	conn := dial(t, NewServer(log, dbClient))
+
+	dbClient.EXPECT().Available().Return(time.Duration(0), nil)
+	dbClient.EXPECT().Session(gomock.Any()).Return(dbClient)
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&testArticle1)
+	dbClient.EXPECT().LastWrite().Return(time.Time{})
+	ctx := metadata.AppendToOutgoingContext(context.Background(), requestIDKey, "abc")
+	_, err := goapiv1.NewArticlesClient(conn).GetArticle(ctx, &goapiv1.GetByIDRequest{Id: 1})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), `"method":"/goapi.v1.Articles/GetArticle"`)
+	assert.Contains(t, buf.String(), `"status":"OK"`)
+	assert.Contains(t, buf.String(), `"reqId":"abc"`)
+
+	dbClient.EXPECT().Available().Return(time.Duration(0), nil)
+	dbClient.EXPECT().Session(gomock.Any()).Return(dbClient)
+	dbClient.EXPECT().Audited(gomock.Eq("backoffice"), gomock.Eq("abc")).Return(dbClient)
+	dbClient.EXPECT().SetArticle(gomock.Any()).Return(nil)
+	dbClient.EXPECT().LastWrite().Return(time.Time{})
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer s3cr3t")
+	_, err = goapiv1.NewArticlesClient(conn).SetArticle(ctx, &goapiv1.Article{Name: "Skittles"})
+	assert.NoError(t, err)
+
+	dbClient.EXPECT().Available().Return(time.Second, errors.New("connection refused"))
+	_, err = goapiv1.NewArticlesClient(conn).GetArticle(ctx, &goapiv1.GetByIDRequest{Id: 1})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	buf.Reset()
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	assert.Contains(t, buf.String(), `"reqId":"grpc-`)
+}
+
+// TestReadYourWrites ensures that writes send the time of the last write of the client and that later calls continue
+// the session of the client with it
+func TestReadYourWrites(t *testing.T) {
+	lastWrite := time.Unix(0, time.Now().Add(-time.Second).UnixNano())
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	dbClient.EXPECT().Available().Return(time.Duration(0), nil).AnyTimes()
+	dbClient.EXPECT().Audited(gomock.Any(), gomock.Any()).Return(dbClient).AnyTimes()
+	client := goapiv1.NewArticlesClient(dial(t, NewServer(nil, dbClient)))
+
+	dbClient.EXPECT().Session(gomock.Eq(time.Time{})).Return(dbClient)
+	dbClient.EXPECT().SetArticle(gomock.Any()).Return(nil)
+	dbClient.EXPECT().LastWrite().Return(lastWrite)
+	var header metadata.MD
+	_, err := client.SetArticle(context.Background(), &goapiv1.Article{Name: "Skittles"}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{strconv.FormatInt(lastWrite.UnixNano(), 10)}, header.Get(lastWriteKey))
+
+	// reads of the client continue the session without sending the header again
+	dbClient.EXPECT().Session(gomock.Eq(lastWrite)).Return(dbClient)
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&testArticle1)
+	dbClient.EXPECT().LastWrite().Return(lastWrite)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), lastWriteKey, header.Get(lastWriteKey)[0])
+	header = nil
+	_, err = client.GetArticle(ctx, &goapiv1.GetByIDRequest{Id: 1}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Empty(t, header.Get(lastWriteKey))
+
+	// writes in the future are ignored
+	dbClient.EXPECT().Session(gomock.Eq(time.Time{})).Return(dbClient)
+	dbClient.EXPECT().GetArticleByID(gomock.Eq(1)).Return(&testArticle1)
+	dbClient.EXPECT().LastWrite().Return(time.Time{})
+	ctx = metadata.AppendToOutgoingContext(context.Background(), lastWriteKey, strconv.FormatInt(time.Now().Add(time.Hour).UnixNano(), 10))
+	_, err = client.GetArticle(ctx, &goapiv1.GetByIDRequest{Id: 1})
+	assert.NoError(t, err)
+}
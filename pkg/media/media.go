@@ -0,0 +1,117 @@
+// Package media inspects uploaded images and generates their thumbnails
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	// gif decoder registration for image.Decode
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	// MaxPixels limits the size of decoded images, so that small files can't expand to huge images
+	MaxPixels = 40000000
+	// ThumbnailSize is the maximum width and height of thumbnails
+	ThumbnailSize = 200
+)
+
+// ErrUnsupportedType is returned for files that aren't JPEG, PNG or GIF images
+var ErrUnsupportedType = errors.New("only jpeg, png and gif images are supported")
+
+// extensions are the file extensions of the supported content types
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Info describes an image
+type Info struct {
+	// ContentType is sniffed from the content, regardless of the name or declared type of the upload
+	ContentType string
+	// Extension is the file extension of the content type
+	Extension string
+	// Width and Height are the dimensions in pixels
+	Width  int
+	Height int
+}
+
+// Inspect sniffs the content type of the data and reads the dimensions of the image.
+// It returns ErrUnsupportedType if the data isn't a supported image.
+func Inspect(data []byte) (*Info, error) {
+	contentType := http.DetectContentType(data)
+	extension, ok := extensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedType
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read image: %w", err)
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, fmt.Errorf("image must have at most %d pixels", MaxPixels)
+	}
+	return &Info{ContentType: contentType, Extension: extension, Width: config.Width, Height: config.Height}, nil
+}
+
+// Thumbnail scales the image down to fit into a square of the size and returns it together with its content type.
+// Thumbnails of JPEG images are JPEG images, thumbnails of other images are PNG images to keep their transparency.
+// Images that already fit are only re-encoded.
+func Thumbnail(data []byte, size int) ([]byte, string, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("couldn't read image: %w", err)
+	}
+	thumbnail := scale(src, size)
+
+	buf := &bytes.Buffer{}
+	if format == "jpeg" {
+		err = jpeg.Encode(buf, thumbnail, &jpeg.Options{Quality: 85})
+		return buf.Bytes(), "image/jpeg", err
+	}
+	err = png.Encode(buf, thumbnail)
+	return buf.Bytes(), "image/png", err
+}
+
+// scale shrinks the image to fit into a square of the size by averaging the pixels each thumbnail pixel covers
+func scale(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	tw, th := w, h
+	if w > size || h > size {
+		if w >= h {
+			tw, th = size, h*size/w
+		} else {
+			tw, th = w*size/h, size
+		}
+	}
+	if tw < 1 {
+		tw = 1
+	}
+	if th < 1 {
+		th = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := bounds.Min.Y+y*h/th, bounds.Min.Y+(y+1)*h/th
+		for x := 0; x < tw; x++ {
+			x0, x1 := bounds.Min.X+x*w/tw, bounds.Min.X+(x+1)*w/tw
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa), n+1
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
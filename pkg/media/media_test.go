@@ -0,0 +1,62 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testPNG returns a png image of the size, whose left half is red and right half is blue
+func testPNG(t *testing.T, width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= width/2 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	buf := &bytes.Buffer{}
+	assert.NoError(t, png.Encode(buf, img))
+	return buf.Bytes()
+}
+
+func TestInspect(t *testing.T) {
+	info, err := Inspect(testPNG(t, 400, 100))
+	assert.NoError(t, err)
+	assert.Equal(t, &Info{ContentType: "image/png", Extension: ".png", Width: 400, Height: 100}, info)
+
+	_, err = Inspect([]byte("<html><body>not an image</body></html>"))
+	assert.Equal(t, ErrUnsupportedType, err)
+
+	// files with the signature of an image must still be readable images
+	_, err = Inspect([]byte("\x89PNG\r\n\x1a\ntruncated"))
+	assert.Error(t, err)
+}
+
+func TestThumbnail(t *testing.T) {
+	data, contentType, err := Thumbnail(testPNG(t, 400, 100), ThumbnailSize)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+
+	thumbnail, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 200, 50), thumbnail.Bounds())
+	r, _, b, _ := thumbnail.At(10, 10).RGBA()
+	assert.Equal(t, [2]uint32{0xffff, 0}, [2]uint32{r, b})
+	r, _, b, _ = thumbnail.At(190, 40).RGBA()
+	assert.Equal(t, [2]uint32{0, 0xffff}, [2]uint32{r, b})
+
+	// small images aren't scaled up
+	data, _, err = Thumbnail(testPNG(t, 20, 10), ThumbnailSize)
+	assert.NoError(t, err)
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, [2]int{20, 10}, [2]int{config.Width, config.Height})
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/render"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+const (
+	// ActorCtxKey refers to the context key that stores the name of the authenticated actor
+	ActorCtxKey CustomKey = "actor"
+	// accessTokenParam is the query parameter for clients that can't set headers, such as browser WebSockets
+	accessTokenParam = "access_token"
+)
+
+// Tokens maps the accepted api tokens to the names of their actors
+var Tokens map[string]string
+
+func SetTokens(t map[string]string) {
+	Tokens = t
+}
+
+// ParseTokens parses a comma separated list of actor:token pairs
+func ParseTokens(s string) (map[string]string, error) {
+	tokens := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid token %q, expected actor:token", pair)
+		}
+		tokens[parts[1]] = parts[0]
+	}
+	return tokens, nil
+}
+
+// Authenticate middleware is used to authenticate the request with a bearer token
+// from the Authorization header or the access_token query parameter. In case
+// the token is missing or unknown, we stop here and return a 401.
+func Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := authenticate(r)
+		if actor == "" {
+			_ = render.Render(w, r, types.ErrUnauthorized())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ActorCtxKey, actor)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Identify middleware is used to store the actor of requests with a known bearer token
+// in the context. Unlike Authenticate, it lets requests without a known token pass.
+func Identify(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := authenticate(r); actor != "" {
+			r = r.WithContext(context.WithValue(r.Context(), ActorCtxKey, actor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate returns the name of the actor of the bearer token from the Authorization header or
+// the access_token query parameter, or an empty string if the token is missing or unknown
+func authenticate(r *http.Request) string {
+	token := r.URL.Query().Get(accessTokenParam)
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		token = strings.TrimPrefix(header, "Bearer ")
+	}
+	return Actor(token)
+}
+
+// Actor returns the name of the actor of the api token, or an empty string if the token is missing or unknown
+func Actor(token string) string {
+	if token == "" {
+		return ""
+	}
+
+	actor := ""
+	for known, name := range Tokens {
+		// compare every token in constant time to not leak how much of a token matched
+		if subtle.ConstantTimeCompare([]byte(token), []byte(known)) == 1 {
+			actor = name
+		}
+	}
+	return actor
+}
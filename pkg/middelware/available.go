@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// DatabaseAvailable middleware rejects requests with a 503 while the database is considered down,
+// so that they fail right away instead of waiting for the database to time out. The Retry-After
+// header tells clients when the database is probed again.
+func DatabaseAvailable(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wait, err := DBClient.Available(); err != nil {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			_ = render.Render(w, r, types.ErrUnavailable(err))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -20,8 +20,27 @@ type (
 const (
 	// ArticleCtxKey refers to the context key that stores the article
 	ArticleCtxKey CustomKey = "article"
+	// ArticleImageCtxKey refers to the context key that stores the article image
+	ArticleImageCtxKey CustomKey = "article_image"
+	// VariantCtxKey refers to the context key that stores the variant
+	VariantCtxKey CustomKey = "variant"
 	// OrderCtxKey refers to the context key that stores the order
 	OrderCtxKey CustomKey = "order"
+	// CustomerCtxKey refers to the context key that stores the customer
+	CustomerCtxKey CustomKey = "customer"
+	// CouponCtxKey refers to the context key that stores the coupon
+	CouponCtxKey CustomKey = "coupon"
+	// CategoryCtxKey refers to the context key that stores the category
+	CategoryCtxKey CustomKey = "category"
+	// TagCtxKey refers to the context key that stores the tag
+	TagCtxKey CustomKey = "tag"
+	// WebhookCtxKey refers to the context key that stores the webhook
+	WebhookCtxKey CustomKey = "webhook"
+	// WebhookDeliveryCtxKey refers to the context key that stores the webhook delivery
+	WebhookDeliveryCtxKey CustomKey = "webhook_delivery"
+	// DBClientCtxKey refers to the context key that stores the database client of requests that don't use DBClient,
+	// such as the operations of a batch running in a transaction
+	DBClientCtxKey CustomKey = "db_client"
 )
 
 var DBClient db.ClientInterface
@@ -30,6 +49,24 @@ func SetDBClient(c db.ClientInterface) {
 	DBClient = c
 }
 
+// GetDBClient returns the database client of the request context, which is DBClient unless the context stores another
+func GetDBClient(ctx context.Context) db.ClientInterface {
+	if client, ok := ctx.Value(DBClientCtxKey).(db.ClientInterface); ok {
+		return client
+	}
+	return DBClient
+}
+
+// GetSelectingDBClient returns the database client of the request context, which only reads the fields selected by
+// the Select middleware
+func GetSelectingDBClient(ctx context.Context) db.ClientInterface {
+	client := GetDBClient(ctx)
+	if selection, ok := ctx.Value(SelectionKey).(*types.Selection); ok && selection.Fields != nil {
+		return client.Select(selection.Fields)
+	}
+	return client
+}
+
 // Article middleware is used to load an Article object from
 // the URL parameters passed through as the request. In case
 // the Article could not be found, we stop here and return a 404.
@@ -43,7 +80,7 @@ func Article(next http.Handler) http.Handler {
 				_ = render.Render(w, r, types.ErrInvalidRequest(err))
 				return
 			}
-			article = DBClient.GetArticleByID(intID)
+			article = GetSelectingDBClient(r.Context()).GetArticleByID(intID)
 		} else {
 			_ = render.Render(w, r, types.ErrNotFound())
 			return
@@ -58,6 +95,64 @@ func Article(next http.Handler) http.Handler {
 	})
 }
 
+// ArticleImage middleware is used to load an ArticleImage object of the
+// article in the context from the URL parameters passed through as the request.
+// In case the ArticleImage could not be found, we stop here and return a 404.
+func ArticleImage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		article := r.Context().Value(ArticleCtxKey).(*types.Article)
+		var image *types.ArticleImage
+
+		if id := chi.URLParam(r, "imageID"); id != "" {
+			intID, err := strconv.Atoi(id)
+			if err != nil {
+				_ = render.Render(w, r, types.ErrInvalidRequest(err))
+				return
+			}
+			image = GetDBClient(r.Context()).GetArticleImageByID(intID)
+		} else {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+		if image == nil || image.ArticleID != article.ID {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ArticleImageCtxKey, image)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Variant middleware is used to load a Variant object of the
+// article in the context from the URL parameters passed through as the request.
+// In case the Variant could not be found, we stop here and return a 404.
+func Variant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		article := r.Context().Value(ArticleCtxKey).(*types.Article)
+		var variant *types.Variant
+
+		if id := chi.URLParam(r, "variantID"); id != "" {
+			intID, err := strconv.Atoi(id)
+			if err != nil {
+				_ = render.Render(w, r, types.ErrInvalidRequest(err))
+				return
+			}
+			variant = GetDBClient(r.Context()).GetVariantByID(intID)
+		} else {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+		if variant == nil || variant.ArticleID != article.ID {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), VariantCtxKey, variant)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // Order middleware is used to load an Order object from
 // the URL parameters passed through as the request. In case
 // the Order could not be found, we stop here and return a 404.
@@ -71,7 +166,7 @@ func Order(next http.Handler) http.Handler {
 				_ = render.Render(w, r, types.ErrInvalidRequest(err))
 				return
 			}
-			order = DBClient.GetOrderByID(intID)
+			order = GetSelectingDBClient(r.Context()).GetOrderByID(intID)
 		} else {
 			_ = render.Render(w, r, types.ErrNotFound())
 			return
@@ -85,3 +180,172 @@ func Order(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// Customer middleware is used to load a Customer object from
+// the URL parameters passed through as the request. In case
+// the Customer could not be found, we stop here and return a 404.
+func Customer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var customer *types.Customer
+
+		if id := chi.URLParam(r, "id"); id != "" {
+			intID, err := strconv.Atoi(id)
+			if err != nil {
+				_ = render.Render(w, r, types.ErrInvalidRequest(err))
+				return
+			}
+			customer = GetDBClient(r.Context()).GetCustomerByID(intID)
+		} else {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+		if customer == nil {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), CustomerCtxKey, customer)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Coupon middleware is used to load a Coupon object from
+// the URL parameters passed through as the request. In case
+// the Coupon could not be found, we stop here and return a 404.
+func Coupon(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var coupon *types.Coupon
+
+		if id := chi.URLParam(r, "id"); id != "" {
+			intID, err := strconv.Atoi(id)
+			if err != nil {
+				_ = render.Render(w, r, types.ErrInvalidRequest(err))
+				return
+			}
+			coupon = GetDBClient(r.Context()).GetCouponByID(intID)
+		} else {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+		if coupon == nil {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), CouponCtxKey, coupon)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Category middleware is used to load a Category object from
+// the URL parameters passed through as the request. In case
+// the Category could not be found, we stop here and return a 404.
+func Category(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var category *types.Category
+
+		if id := chi.URLParam(r, "id"); id != "" {
+			intID, err := strconv.Atoi(id)
+			if err != nil {
+				_ = render.Render(w, r, types.ErrInvalidRequest(err))
+				return
+			}
+			category = GetDBClient(r.Context()).GetCategoryByID(intID)
+		} else {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+		if category == nil {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), CategoryCtxKey, category)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Tag middleware is used to load a Tag object from
+// the URL parameters passed through as the request. In case
+// the Tag could not be found, we stop here and return a 404.
+func Tag(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var tag *types.Tag
+
+		if id := chi.URLParam(r, "id"); id != "" {
+			intID, err := strconv.Atoi(id)
+			if err != nil {
+				_ = render.Render(w, r, types.ErrInvalidRequest(err))
+				return
+			}
+			tag = GetDBClient(r.Context()).GetTagByID(intID)
+		} else {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+		if tag == nil {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), TagCtxKey, tag)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Webhook middleware is used to load a Webhook object from
+// the URL parameters passed through as the request. In case
+// the Webhook could not be found, we stop here and return a 404.
+func Webhook(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var webhook *types.Webhook
+
+		if id := chi.URLParam(r, "id"); id != "" {
+			intID, err := strconv.Atoi(id)
+			if err != nil {
+				_ = render.Render(w, r, types.ErrInvalidRequest(err))
+				return
+			}
+			webhook = GetDBClient(r.Context()).GetWebhookByID(intID)
+		} else {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+		if webhook == nil {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), WebhookCtxKey, webhook)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// WebhookDelivery middleware is used to load a WebhookDelivery object of the
+// webhook in the context from the URL parameters passed through as the request.
+// In case the WebhookDelivery could not be found, we stop here and return a 404.
+func WebhookDelivery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		webhook := r.Context().Value(WebhookCtxKey).(*types.Webhook)
+		var delivery *types.WebhookDelivery
+
+		if id := chi.URLParam(r, "deliveryID"); id != "" {
+			intID, err := strconv.Atoi(id)
+			if err != nil {
+				_ = render.Render(w, r, types.ErrInvalidRequest(err))
+				return
+			}
+			delivery = GetDBClient(r.Context()).GetWebhookDeliveryByID(intID)
+		} else {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+		if delivery == nil || delivery.WebhookID != webhook.ID {
+			_ = render.Render(w, r, types.ErrNotFound())
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), WebhookDeliveryCtxKey, delivery)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+const (
+	// ArticleFilterKey refers to the context key that stores the article filter
+	ArticleFilterKey CustomKey = "article_filter"
+	// OrderFilterKey refers to the context key that stores the order filter
+	OrderFilterKey CustomKey = "order_filter"
+	// AuditFilterKey refers to the context key that stores the audit log filter
+	AuditFilterKey CustomKey = "audit_filter"
+	// IDsKey refers to the context key that stores the ids of the resources to be listed
+	IDsKey CustomKey = "ids"
+	// SelectionKey refers to the context key that stores the selected fields and expanded relations of the response
+	SelectionKey CustomKey = "selection"
+)
+
+// ArticleFilter middleware is used to extract the filter of an article list from the url query
+func ArticleFilter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter := &types.ArticleFilter{}
+		if lowStock := r.URL.Query().Get("low_stock"); lowStock != "" {
+			intLowStock, err := strconv.Atoi(lowStock)
+			if err != nil {
+				_ = render.Render(w, r, types.ErrInvalidRequest(fmt.Errorf("couldn't read low_stock: %w", err)))
+				return
+			}
+			filter.LowStock = &intLowStock
+		}
+		if category := r.URL.Query().Get("category"); category != "" {
+			intCategory, err := strconv.Atoi(category)
+			if err != nil {
+				_ = render.Render(w, r, types.ErrInvalidRequest(fmt.Errorf("couldn't read category: %w", err)))
+				return
+			}
+			filter.CategoryID = &intCategory
+		}
+		filter.Tag = types.NormalizeTag(r.URL.Query().Get("tag"))
+		ctx := context.WithValue(r.Context(), ArticleFilterKey, filter)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// OrderFilter middleware is used to extract the filter of an order list from the url query
+func OrderFilter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter := &types.OrderFilter{}
+		if customer := r.URL.Query().Get("customer"); customer != "" {
+			intCustomer, err := strconv.Atoi(customer)
+			if err != nil {
+				_ = render.Render(w, r, types.ErrInvalidRequest(fmt.Errorf("couldn't read customer: %w", err)))
+				return
+			}
+			filter.CustomerID = &intCustomer
+		}
+		ctx := context.WithValue(r.Context(), OrderFilterKey, filter)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// AuditFilter middleware is used to extract the filter of the audit log from the url query
+func AuditFilter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter := &types.AuditFilter{
+			Resource: query.Get("resource"),
+			Actor:    query.Get("actor"),
+		}
+		if resourceID := query.Get("resource_id"); resourceID != "" {
+			intResourceID, err := strconv.Atoi(resourceID)
+			if err != nil {
+				_ = render.Render(w, r, types.ErrInvalidRequest(fmt.Errorf("couldn't read resource_id: %w", err)))
+				return
+			}
+			filter.ResourceID = intResourceID
+		}
+		for _, bound := range []struct {
+			param string
+			value **time.Time
+		}{{"from", &filter.From}, {"to", &filter.To}} {
+			if s := query.Get(bound.param); s != "" {
+				t, err := time.Parse(time.RFC3339, s)
+				if err != nil {
+					_ = render.Render(w, r, types.ErrInvalidRequest(fmt.Errorf("couldn't read %s: %w", bound.param, err)))
+					return
+				}
+				*bound.value = &t
+			}
+		}
+		ctx := context.WithValue(r.Context(), AuditFilterKey, filter)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// IDs middleware is used to extract a comma separated list of ids from the url query, which selects the resources
+// of a list by id instead of by page. The context stores nil if the query doesn't contain ids.
+func IDs(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ids []int
+		if s := r.URL.Query().Get(string(IDsKey)); s != "" {
+			for _, id := range strings.Split(s, ",") {
+				intID, err := strconv.Atoi(strings.TrimSpace(id))
+				if err != nil {
+					_ = render.Render(w, r, types.ErrInvalidRequest(fmt.Errorf("couldn't read %s: %w", IDsKey, err)))
+					return
+				}
+				ids = append(ids, intID)
+			}
+			if len(ids) > types.MaxBulkSize {
+				_ = render.Render(w, r, types.ErrInvalidRequest(fmt.Errorf("%s can't contain more than %d ids", IDsKey, types.MaxBulkSize)))
+				return
+			}
+		}
+		ctx := context.WithValue(r.Context(), IDsKey, ids)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Select middleware is used to extract the comma separated fields and expanded relations of the resources in the
+// response from the url query, such as fields=id,name and expand=items.article. The resources are loaded with a
+// database client that only reads the selected fields, so it has to run before they are loaded.
+func Select(resource interface{}, relations types.Relations) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			selection, err := types.NewSelection(resource, relations,
+				splitList(r.URL.Query().Get("fields")), splitList(r.URL.Query().Get("expand")))
+			if err != nil {
+				_ = render.Render(w, r, types.ErrInvalidRequest(err))
+				return
+			}
+			ctx := context.WithValue(r.Context(), SelectionKey, selection)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// splitList returns the trimmed values of the comma separated list, or nil if it is empty
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	values := []string{}
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/render"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+const (
+	// MediaTypeKey refers to the context key that stores the negotiated media type of the response
+	MediaTypeKey CustomKey = "media_type"
+
+	// MediaTypeJSON is the media type of json documents
+	MediaTypeJSON = "application/json"
+	// MediaTypeXML is the media type of xml documents
+	MediaTypeXML = "application/xml"
+	// MediaTypeMsgpack is the media type of MessagePack documents
+	MediaTypeMsgpack = "application/msgpack"
+	// MediaTypeCSV is the media type of comma separated values, which are only offered for lists
+	MediaTypeCSV = "text/csv"
+	// MediaTypeJSONAPI is the media type of JSON:API (https://jsonapi.org) documents, which are only offered for lists
+	MediaTypeJSONAPI = "application/vnd.api+json"
+)
+
+// mediaTypeAliases maps alternative names of media types to the names used by this api
+var mediaTypeAliases = map[string]string{
+	"text/xml":              MediaTypeXML,
+	"application/x-msgpack": MediaTypeMsgpack,
+}
+
+// bodyMediaTypes are the media types request bodies may have
+var bodyMediaTypes = []string{MediaTypeJSON, MediaTypeXML, MediaTypeMsgpack}
+
+// Negotiate middleware picks the media type of the response from the offered ones by the Accept header and stores it
+// in the context. The first offer is preferred if the client accepts several equally. Requests that don't accept
+// any of the offers are answered with 406 and requests whose body has a type that can't be decoded with 415.
+func Negotiate(offers ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if hasBody(r) {
+				if !contains(bodyMediaTypes, MediaType(r.Header.Get("Content-Type"))) {
+					_ = render.Render(w, r, types.ErrUnsupportedMediaType(fmt.Errorf(
+						"the content type %q isn't one of %s", r.Header.Get("Content-Type"), strings.Join(bodyMediaTypes, ", "))))
+					return
+				}
+			}
+
+			mediaType, ok := negotiate(r.Header.Get("Accept"), offers)
+			if !ok {
+				_ = render.Render(w, r, types.ErrNotAcceptable(fmt.Errorf(
+					"none of the accepted media types is offered, use one of %s", strings.Join(offers, ", "))))
+				return
+			}
+			w.Header().Add("Vary", "Accept")
+			ctx := context.WithValue(r.Context(), MediaTypeKey, mediaType)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// MediaType returns the media type of the Content-Type header without parameters, with aliases replaced by the
+// names used by this api. It returns an empty string for invalid headers.
+func MediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	if alias, ok := mediaTypeAliases[mediaType]; ok {
+		return alias
+	}
+	return mediaType
+}
+
+// hasBody reports whether the request sends a body that has to be decoded
+func hasBody(r *http.Request) bool {
+	if r.Method != http.MethodPut && r.Method != http.MethodPost && r.Method != http.MethodPatch {
+		return false
+	}
+	return r.ContentLength != 0 && r.Body != nil && r.Body != http.NoBody
+}
+
+// negotiate returns the offer with the highest quality in the Accept header. Ranges match offers in the order
+// type/subtype, type/* and */*, where the most specific range decides the quality of an offer.
+// An empty header accepts all offers.
+func negotiate(accept string, offers []string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], true
+	}
+
+	qualities := map[string]float64{}
+	for _, part := range strings.Split(accept, ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		mediaRange := MediaType(part)
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		qualities[mediaRange] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, offer := range offers {
+		quality, ok := qualities[offer]
+		if !ok {
+			quality, ok = qualities[strings.SplitN(offer, "/", 2)[0]+"/*"]
+		}
+		if !ok {
+			quality = qualities["*/*"]
+		}
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+	return best, best != ""
+}
+
+// contains reports whether the media type is one of the list
+func contains(mediaTypes []string, mediaType string) bool {
+	for _, t := range mediaTypes {
+		if t == mediaType {
+			return true
+		}
+	}
+	return false
+}
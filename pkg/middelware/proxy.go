@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxies are the networks of the proxies whose forwarding headers are honoured
+var TrustedProxies []*net.IPNet
+
+func SetTrustedProxies(p []*net.IPNet) {
+	TrustedProxies = p
+}
+
+// ParseTrustedProxies parses a comma separated list of ip addresses and networks in CIDR notation
+func ParseTrustedProxies(s string) ([]*net.IPNet, error) {
+	proxies := []*net.IPNet{}
+	for _, proxy := range strings.Split(s, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid proxy %q, expected an ip address or network", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %q, expected an ip address or network", proxy)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+// Forwarded middleware is used to restore the host and scheme the client sent the request to from the Forwarded
+// header or the X-Forwarded-Host and X-Forwarded-Proto headers. The headers are only honoured for requests from one
+// of the trusted proxies, as any client could set them otherwise.
+func Forwarded(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !trustedProxy(r.RemoteAddr) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		scheme, host := "", ""
+		if proto := firstValue(r.Header.Get("X-Forwarded-Proto")); proto != "" {
+			scheme = proto
+		}
+		if forwardedHost := firstValue(r.Header.Get("X-Forwarded-Host")); forwardedHost != "" {
+			host = forwardedHost
+		}
+		// only the element added by the proxy closest to the client is used
+		if forwarded := firstValue(r.Header.Get("Forwarded")); forwarded != "" {
+			for _, pair := range strings.Split(forwarded, ";") {
+				kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+				if len(kv) != 2 {
+					continue
+				}
+				value := strings.Trim(kv[1], `"`)
+				switch strings.ToLower(kv[0]) {
+				case "proto":
+					scheme = value
+				case "host":
+					host = value
+				}
+			}
+		}
+
+		if scheme != "" || host != "" {
+			u := *r.URL
+			r = r.Clone(r.Context())
+			r.URL = &u
+			if scheme != "" {
+				r.URL.Scheme = scheme
+			}
+			if host != "" {
+				r.Host = host
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// trustedProxy reports whether the remote address of a request belongs to one of the trusted proxies
+func trustedProxy(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range TrustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstValue returns the first value of a comma separated header
+func firstValue(header string) string {
+	return strings.TrimSpace(strings.SplitN(header, ",", 2)[0])
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jonnylangefeld/go-api/pkg/db"
+)
+
+// LastWriteCookie is the cookie that stores the time of the last write of a client in nanoseconds since the epoch
+const LastWriteCookie = "last_write"
+
+// ReadYourWrites middleware is used to read from the primary database after the writes of the same client only,
+// instead of after the writes of any client. The time of the last write of the client is kept in the
+// LastWriteCookie, which is set on the responses to writes.
+func ReadYourWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var lastWrite time.Time
+		if cookie, err := r.Cookie(LastWriteCookie); err == nil {
+			// writes in the future would pin the reads of the client to the primary for good
+			if nanos, err := strconv.ParseInt(cookie.Value, 10, 64); err == nil && nanos <= time.Now().UnixNano() {
+				lastWrite = time.Unix(0, nanos)
+			}
+		}
+
+		client := GetDBClient(r.Context()).Session(lastWrite)
+		ctx := context.WithValue(r.Context(), DBClientCtxKey, client)
+		next.ServeHTTP(&sessionWriter{ResponseWriter: w, client: client, lastWrite: lastWrite}, r.WithContext(ctx))
+	})
+}
+
+// sessionWriter sets the LastWriteCookie before the header of the response is written if the client wrote
+type sessionWriter struct {
+	http.ResponseWriter
+	client      db.ClientInterface
+	lastWrite   time.Time
+	wroteHeader bool
+}
+
+func (w *sessionWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if lastWrite := w.client.LastWrite(); lastWrite.After(w.lastWrite) {
+			http.SetCookie(w.ResponseWriter, &http.Cookie{
+				Name:     LastWriteCookie,
+				Value:    strconv.FormatInt(lastWrite.UnixNano(), 10),
+				Path:     "/",
+				HttpOnly: true,
+			})
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *sessionWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush allows streaming responses through the writer
+func (w *sessionWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack allows websocket connections through the writer
+func (w *sessionWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer doesn't support hijacking")
+	}
+	return hijacker.Hijack()
+}
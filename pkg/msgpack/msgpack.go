@@ -0,0 +1,354 @@
+// Package msgpack encodes and decodes MessagePack (https://msgpack.org) documents.
+// Values are converted through their json representation, so that MessagePack documents have the same fields
+// as json documents and types only have to implement json marshaling.
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// maxDepth is the maximum nesting of arrays and maps in decoded documents
+const maxDepth = 100
+
+// errShort is returned for documents that end in the middle of a value
+var errShort = errors.New("msgpack: unexpected end of document")
+
+// Marshal returns the MessagePack encoding of the json representation of v
+func Marshal(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return FromJSON(b)
+}
+
+// Unmarshal decodes the MessagePack document into v like json.Unmarshal decodes the json representation of it
+func Unmarshal(data []byte, v interface{}) error {
+	b, err := ToJSON(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// FromJSON converts the json document into a MessagePack document
+func FromJSON(data []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var value interface{}
+	if err := d.Decode(&value); err != nil {
+		return nil, err
+	}
+	e := &encoder{}
+	if err := e.encode(value); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// ToJSON converts the MessagePack document into a json document
+func ToJSON(data []byte) ([]byte, error) {
+	d := &decoder{data: data}
+	value, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("msgpack: %d bytes after the end of the document", len(d.data)-d.pos)
+	}
+	return json.Marshal(value)
+}
+
+// IsArray reports whether the MessagePack document is an array
+func IsArray(data []byte) bool {
+	return len(data) > 0 && (data[0]&0xf0 == 0x90 || data[0] == 0xdc || data[0] == 0xdd)
+}
+
+type encoder struct {
+	buf bytes.Buffer
+}
+
+// encode writes the value decoded by encoding/json with numbers as json.Number
+func (e *encoder) encode(value interface{}) error {
+	switch value := value.(type) {
+	case nil:
+		e.buf.WriteByte(0xc0)
+	case bool:
+		if value {
+			e.buf.WriteByte(0xc3)
+		} else {
+			e.buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		return e.encodeNumber(value)
+	case string:
+		e.encodeString(value)
+	case []interface{}:
+		e.encodeLength(len(value), 0x90, 0xdc, 0xdd)
+		for _, item := range value {
+			if err := e.encode(item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		// keys are sorted, so that equal values have equal encodings
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		e.encodeLength(len(value), 0x80, 0xde, 0xdf)
+		for _, key := range keys {
+			e.encodeString(key)
+			if err := e.encode(value[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", value)
+	}
+	return nil
+}
+
+// encodeNumber writes integers in the smallest format that holds them and all other numbers as float 64
+func (e *encoder) encodeNumber(n json.Number) error {
+	if i, err := n.Int64(); err == nil {
+		e.encodeInt(i)
+		return nil
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		e.buf.WriteByte(0xcf)
+		e.writeUint(u, 8)
+		return nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+	e.buf.WriteByte(0xcb)
+	e.writeUint(math.Float64bits(f), 8)
+	return nil
+}
+
+func (e *encoder) encodeInt(i int64) {
+	switch {
+	case i >= 0 && i <= 0x7f:
+		e.buf.WriteByte(byte(i))
+	case i >= -32 && i < 0:
+		e.buf.WriteByte(byte(i))
+	case i > 0 && i <= math.MaxUint8:
+		e.buf.WriteByte(0xcc)
+		e.writeUint(uint64(i), 1)
+	case i > 0 && i <= math.MaxUint16:
+		e.buf.WriteByte(0xcd)
+		e.writeUint(uint64(i), 2)
+	case i > 0 && i <= math.MaxUint32:
+		e.buf.WriteByte(0xce)
+		e.writeUint(uint64(i), 4)
+	case i > 0:
+		e.buf.WriteByte(0xcf)
+		e.writeUint(uint64(i), 8)
+	case i >= math.MinInt8:
+		e.buf.WriteByte(0xd0)
+		e.writeUint(uint64(i), 1)
+	case i >= math.MinInt16:
+		e.buf.WriteByte(0xd1)
+		e.writeUint(uint64(i), 2)
+	case i >= math.MinInt32:
+		e.buf.WriteByte(0xd2)
+		e.writeUint(uint64(i), 4)
+	default:
+		e.buf.WriteByte(0xd3)
+		e.writeUint(uint64(i), 8)
+	}
+}
+
+func (e *encoder) encodeString(s string) {
+	if len(s) < 32 {
+		e.buf.WriteByte(0xa0 | byte(len(s)))
+	} else {
+		e.encodeLength(len(s), 0, 0xda, 0xdb)
+	}
+	e.buf.WriteString(s)
+}
+
+// encodeLength writes the header of a string, array or map of the given length. Lengths below 16 use the fix format
+// with the given prefix, unless the prefix is 0.
+func (e *encoder) encodeLength(n int, fix, format16, format32 byte) {
+	switch {
+	case fix != 0 && n < 16:
+		e.buf.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(format16)
+		e.writeUint(uint64(n), 2)
+	default:
+		e.buf.WriteByte(format32)
+		e.writeUint(uint64(n), 4)
+	}
+}
+
+// writeUint writes the lowest size bytes of u in big endian order
+func (e *encoder) writeUint(u uint64, size int) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, u)
+	e.buf.Write(b[8-size:])
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+// decode reads the next value into the types encoding/json marshals
+func (d *decoder) decode(depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("msgpack: document is nested deeper than %d levels", maxDepth)
+	}
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	format := b[0]
+
+	switch {
+	case format <= 0x7f:
+		return int64(format), nil
+	case format >= 0xe0:
+		return int64(int8(format)), nil
+	case format&0xe0 == 0xa0:
+		return d.decodeString(int(format & 0x1f))
+	case format&0xf0 == 0x90:
+		return d.decodeArray(int(format&0x0f), depth)
+	case format&0xf0 == 0x80:
+		return d.decodeMap(int(format&0x0f), depth)
+	}
+
+	switch format {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readUint(1 << (format - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		// binary data is base64 encoded like []byte in json
+		return d.read(int(n))
+	case 0xca:
+		u, err := d.readUint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := d.readUint(8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return d.readUint(1 << (format - 0xcc))
+	case 0xd0:
+		u, err := d.readUint(1)
+		return int64(int8(u)), err
+	case 0xd1:
+		u, err := d.readUint(2)
+		return int64(int16(u)), err
+	case 0xd2:
+		u, err := d.readUint(4)
+		return int64(int32(u)), err
+	case 0xd3:
+		u, err := d.readUint(8)
+		return int64(u), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readUint(1 << (format - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(n))
+	case 0xdc, 0xdd:
+		n, err := d.readUint(2 << (format - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(int(n), depth)
+	case 0xde, 0xdf:
+		n, err := d.readUint(2 << (format - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(int(n), depth)
+	}
+	return nil, fmt.Errorf("msgpack: unsupported format 0x%02x", format)
+}
+
+func (d *decoder) decodeString(n int) (interface{}, error) {
+	b, err := d.read(n)
+	return string(b), err
+}
+
+func (d *decoder) decodeArray(n int, depth int) (interface{}, error) {
+	// every item takes at least one byte, which bounds the allocation by the size of the document
+	if n > len(d.data)-d.pos {
+		return nil, errShort
+	}
+	items := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		item, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (d *decoder) decodeMap(n int, depth int) (interface{}, error) {
+	if 2*n > len(d.data)-d.pos {
+		return nil, errShort
+	}
+	fields := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("msgpack: map keys must be strings, not %T", key)
+		}
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = value
+	}
+	return fields, nil
+}
+
+// read returns the next n bytes
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data)-d.pos {
+		return nil, errShort
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// readUint reads a big endian unsigned integer of size bytes
+func (d *decoder) readUint(size int) (uint64, error) {
+	b, err := d.read(size)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
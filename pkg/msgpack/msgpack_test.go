@@ -0,0 +1,103 @@
+package msgpack
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromJSON(t *testing.T) {
+	testcases := map[string]struct {
+		json string
+		want []byte
+	}{
+		"nil":               {json: `null`, want: []byte{0xc0}},
+		"bools":             {json: `[true,false]`, want: []byte{0x92, 0xc3, 0xc2}},
+		"positive fixint":   {json: `127`, want: []byte{0x7f}},
+		"negative fixint":   {json: `-32`, want: []byte{0xe0}},
+		"uint 8":            {json: `200`, want: []byte{0xcc, 0xc8}},
+		"uint 16":           {json: `1000`, want: []byte{0xcd, 0x03, 0xe8}},
+		"int 8":             {json: `-100`, want: []byte{0xd0, 0x9c}},
+		"int 16":            {json: `-1000`, want: []byte{0xd1, 0xfc, 0x18}},
+		"uint 64":           {json: `18446744073709551615`, want: []byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		"float 64":          {json: `1.5`, want: []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		"fixstr":            {json: `"abc"`, want: []byte{0xa3, 'a', 'b', 'c'}},
+		"map sorted by key": {json: `{"b":1,"a":"x"}`, want: []byte{0x82, 0xa1, 'a', 0xa1, 'x', 0xa1, 'b', 0x01}},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			got, err := FromJSON([]byte(tc.json))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	type item struct {
+		ID    int      `json:"id"`
+		Name  string   `json:"name"`
+		Tags  []string `json:"tags"`
+		Price *float64 `json:"price"`
+	}
+	price := 1.99
+	long := strings.Repeat("x", 70000)
+	want := []*item{{ID: 1, Name: "Skittles", Tags: []string{"sweet"}, Price: &price}, {ID: -70000, Name: long}}
+
+	b, err := Marshal(want)
+	assert.NoError(t, err)
+	assert.True(t, IsArray(b))
+	got := []*item{}
+	assert.NoError(t, Unmarshal(b, &got))
+	assert.Equal(t, want, got)
+}
+
+func TestToJSON(t *testing.T) {
+	testcases := map[string]struct {
+		data    []byte
+		want    string
+		wantErr string
+	}{
+		"float 32": {data: []byte{0xca, 0x3f, 0xc0, 0, 0}, want: `1.5`},
+		"binary":   {data: []byte{0xc4, 0x02, 'h', 'i'}, want: `"aGk="`},
+		"str 8":    {data: []byte{0xd9, 0x02, 'h', 'i'}, want: `"hi"`},
+		"truncated": {
+			data:    []byte{0x92, 0x01},
+			wantErr: "msgpack: unexpected end of document",
+		},
+		"oversized array": {
+			data:    []byte{0xdd, 0xff, 0xff, 0xff, 0xff},
+			wantErr: "msgpack: unexpected end of document",
+		},
+		"trailing bytes": {
+			data:    []byte{0x01, 0x02},
+			wantErr: "msgpack: 1 bytes after the end of the document",
+		},
+		"map with integer keys": {
+			data:    []byte{0x81, 0x01, 0x02},
+			wantErr: "msgpack: map keys must be strings, not int64",
+		},
+		"extension": {
+			data:    []byte{0xd4, 0x01, 0x00},
+			wantErr: "msgpack: unsupported format 0xd4",
+		},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ToJSON(tc.data)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.want, string(got))
+		})
+	}
+}
+
+func TestToJSON_Depth(t *testing.T) {
+	data := []byte(strings.Repeat("\x91", maxDepth+2) + "\xc0")
+	_, err := ToJSON(data)
+	assert.EqualError(t, err, "msgpack: document is nested deeper than 100 levels")
+}
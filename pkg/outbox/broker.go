@@ -0,0 +1,98 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// Message is a message received from a broker
+type Message struct {
+	// Topic is the topic the message was published to
+	Topic string
+	// Body is the content of the message
+	Body []byte
+}
+
+// Broker is a message broker that distributes messages to the subscribers of a topic
+type Broker interface {
+	// Publish sends the message body to all subscribers of the topic
+	Publish(ctx context.Context, topic string, body []byte) error
+	// Subscribe returns a channel receiving the messages of the topic, types.EventAll receives all topics.
+	// The returned function cancels the subscription and closes the channel.
+	Subscribe(topic string) (<-chan Message, func())
+}
+
+// MemoryBroker is a broker that distributes messages within the process
+type MemoryBroker struct {
+	// Buffer is the number of messages buffered per subscription
+	Buffer int
+
+	mu          sync.RWMutex
+	subscribers map[string]map[chan Message]bool
+}
+
+// NewMemoryBroker returns an in-memory broker
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		Buffer:      64,
+		subscribers: map[string]map[chan Message]bool{},
+	}
+}
+
+// Publish implements the Broker interface. It never blocks: subscribers whose buffer is full are
+// considered too slow and their subscription is canceled, which closes their channel.
+func (b *MemoryBroker) Publish(ctx context.Context, topic string, body []byte) error {
+	msg := Message{Topic: topic, Body: body}
+	var slow []chan Message
+
+	b.mu.RLock()
+	for _, t := range []string{topic, types.EventAll} {
+		for ch := range b.subscribers[t] {
+			select {
+			case ch <- msg:
+			default:
+				slow = append(slow, ch)
+			}
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, ch := range slow {
+		b.unsubscribe(ch)
+	}
+	return nil
+}
+
+// Subscribe implements the Broker interface
+func (b *MemoryBroker) Subscribe(topic string) (<-chan Message, func()) {
+	ch := make(chan Message, b.Buffer)
+
+	b.mu.Lock()
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = map[chan Message]bool{}
+	}
+	b.subscribers[topic][ch] = true
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.unsubscribe(ch)
+	}
+}
+
+// unsubscribe removes the channel from all topics and closes it, if it wasn't already
+func (b *MemoryBroker) unsubscribe(ch chan Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for topic, subscribers := range b.subscribers {
+		if subscribers[ch] {
+			delete(subscribers, ch)
+			if len(subscribers) == 0 {
+				delete(b.subscribers, topic)
+			}
+			close(ch)
+		}
+	}
+}
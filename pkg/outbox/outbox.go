@@ -0,0 +1,138 @@
+// Package outbox publishes the events written to the transactional outbox to pluggable sinks
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jonnylangefeld/go-api/pkg/db"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// Sink receives the published events. Events are published at least once and in the order they were written,
+// so sinks have to tolerate duplicates, which can be detected by the event id.
+type Sink interface {
+	// Name identifies the sink in the publish markers of the outbox events and must not change between releases
+	Name() string
+	// Publish publishes the event. Events that failed to publish are retried with the next poll.
+	Publish(ctx context.Context, event *types.Event) error
+}
+
+// Relay polls the outbox for unpublished events and publishes them to all sinks.
+// Every sink that received an event is recorded in the event's publish markers, so a failing sink
+// neither blocks the other sinks nor causes them to receive the event again.
+// Relays only publish while they hold the lock of the outbox, so that the relays of several instances of the api
+// don't publish the same events. Sinks that aren't shared between instances, such as a MemoryBroker, only receive
+// the events of the instance holding the lock.
+type Relay struct {
+	// Interval is the time between two polls for unpublished events
+	Interval time.Duration
+	// BatchSize is the maximum number of events published per poll
+	BatchSize int
+
+	db    db.ClientInterface
+	sinks []Sink
+	log   *zap.Logger
+	now   func() time.Time
+}
+
+// NewRelay returns a relay publishing to the given sinks with sensible defaults
+func NewRelay(dbClient db.ClientInterface, log *zap.Logger, sinks ...Sink) *Relay {
+	return &Relay{
+		Interval:  time.Second,
+		BatchSize: 100,
+		db:        dbClient,
+		sinks:     sinks,
+		log:       log,
+		now:       time.Now,
+	}
+}
+
+// Run publishes unpublished events until the context is canceled
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		// keep going without waiting while there are more unpublished events than fit in one batch
+		if full := r.publishPending(ctx) == r.BatchSize; full && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishPending publishes one batch of unpublished events and returns how many events were fully published
+func (r *Relay) publishPending(ctx context.Context) int {
+	published := 0
+	_, err := r.db.LockOutbox(func(client db.ClientInterface) error {
+		// a sink that failed is skipped for the rest of the batch to keep its events in order
+		failed := map[string]bool{}
+		for _, outboxEvent := range client.GetUnpublishedOutboxEvents(r.BatchSize) {
+			if ctx.Err() != nil {
+				published = 0
+				return nil
+			}
+			if r.publish(ctx, client, outboxEvent, failed) {
+				published++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.log.Error("couldn't lock the outbox", zap.Error(err))
+		return 0
+	}
+	return published
+}
+
+// publish publishes the outbox event to all sinks it wasn't published to yet and stores the publish markers.
+// It reports whether the event is now published to all sinks.
+func (r *Relay) publish(ctx context.Context, client db.ClientInterface, outboxEvent *types.OutboxEvent, failed map[string]bool) bool {
+	event, err := outboxEvent.Event()
+	if err != nil {
+		r.log.Error("couldn't decode outbox event", zap.Int("outboxId", outboxEvent.ID), zap.Error(err))
+		return false
+	}
+
+	changed := false
+	for _, sink := range r.sinks {
+		name := sink.Name()
+		if failed[name] || outboxEvent.IsPublishedTo(name) {
+			continue
+		}
+		if err := sink.Publish(ctx, event); err != nil {
+			failed[name] = true
+			r.log.Warn("couldn't publish event", zap.String("sink", name), zap.String("eventId", event.ID), zap.Error(err))
+			continue
+		}
+		outboxEvent.PublishedTo = append(outboxEvent.PublishedTo, name)
+		changed = true
+	}
+
+	done := true
+	for _, sink := range r.sinks {
+		if !outboxEvent.IsPublishedTo(sink.Name()) {
+			done = false
+		}
+	}
+	if done {
+		now := r.now()
+		outboxEvent.PublishedAt = &now
+		changed = true
+	}
+
+	if changed {
+		if err := client.SetOutboxEvent(outboxEvent); err != nil {
+			r.log.Error("couldn't store publish markers", zap.Int("outboxId", outboxEvent.ID), zap.Error(err))
+			return false
+		}
+	}
+	return done
+}
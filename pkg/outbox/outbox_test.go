@@ -0,0 +1,117 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/jonnylangefeld/go-api/pkg/api/mocks"
+	"github.com/jonnylangefeld/go-api/pkg/db"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+var testNow = time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+// testSink records the published events and fails for the event ids in fail
+type testSink struct {
+	name      string
+	fail      map[string]bool
+	published []string
+}
+
+func (s *testSink) Name() string {
+	return s.name
+}
+
+func (s *testSink) Publish(ctx context.Context, event *types.Event) error {
+	if s.fail[event.ID] {
+		return errors.New("unavailable")
+	}
+	s.published = append(s.published, event.ID)
+	return nil
+}
+
+func testOutboxEvent(t *testing.T, id int, eventID string) *types.OutboxEvent {
+	event := &types.Event{ID: eventID, Type: types.EventArticleCreated, CreatedAt: testNow, Data: &types.Article{ID: id}}
+	outboxEvent, err := types.NewOutboxEvent(event)
+	assert.NoError(t, err)
+	outboxEvent.ID = id
+	return outboxEvent
+}
+
+func TestRelay_publishPending(t *testing.T) {
+	first := testOutboxEvent(t, 1, "a")
+	second := testOutboxEvent(t, 2, "b")
+	third := testOutboxEvent(t, 3, "c")
+	// the third event was already published to the log sink in an earlier poll
+	third.PublishedTo = []string{"log"}
+
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	dbClient.EXPECT().LockOutbox(gomock.Any()).DoAndReturn(func(fn func(client db.ClientInterface) error) (bool, error) {
+		return true, fn(dbClient)
+	})
+	dbClient.EXPECT().GetUnpublishedOutboxEvents(gomock.Eq(100)).Return([]*types.OutboxEvent{first, second, third})
+	dbClient.EXPECT().SetOutboxEvent(gomock.Any()).Return(nil).Times(3)
+
+	log := &testSink{name: "log"}
+	broker := &testSink{name: "broker", fail: map[string]bool{"b": true}}
+	r := NewRelay(dbClient, zap.NewNop(), log, broker)
+	r.now = func() time.Time {
+		return testNow
+	}
+
+	assert.Equal(t, 1, r.publishPending(context.Background()))
+	assert.Equal(t, []string{"a", "b"}, log.published)
+	// the broker failed on b, so c is held back to keep the order
+	assert.Equal(t, []string{"a"}, broker.published)
+
+	assert.Equal(t, &testNow, first.PublishedAt)
+	assert.Equal(t, []string{"log", "broker"}, []string(first.PublishedTo))
+	assert.Nil(t, second.PublishedAt)
+	assert.Equal(t, []string{"log"}, []string(second.PublishedTo))
+	assert.Nil(t, third.PublishedAt)
+}
+
+func TestRelay_publishPendingLocked(t *testing.T) {
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	dbClient.EXPECT().LockOutbox(gomock.Any()).Return(false, nil)
+
+	log := &testSink{name: "log"}
+	r := NewRelay(dbClient, zap.NewNop(), log)
+
+	assert.Equal(t, 0, r.publishPending(context.Background()))
+	assert.Empty(t, log.published)
+}
+
+func TestOutboxEvent_Event(t *testing.T) {
+	got, err := testOutboxEvent(t, 1, "a").Event()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", got.ID)
+	assert.Equal(t, types.EventArticleCreated, got.Type)
+	assert.Equal(t, testNow, got.CreatedAt)
+	assert.Equal(t, `{"id":1,"name":"","price":null}`, string(got.Data.(json.RawMessage)))
+}
+
+func TestMemoryBroker(t *testing.T) {
+	b := NewMemoryBroker()
+	b.Buffer = 1
+	created, cancelCreated := b.Subscribe(types.EventArticleCreated)
+	defer cancelCreated()
+	all, cancelAll := b.Subscribe(types.EventAll)
+
+	assert.NoError(t, b.Publish(context.Background(), types.EventArticleCreated, []byte("1")))
+	assert.Equal(t, Message{Topic: types.EventArticleCreated, Body: []byte("1")}, <-created)
+
+	// the subscriber of all topics didn't read the first message and is disconnected
+	assert.NoError(t, b.Publish(context.Background(), types.EventOrderCreated, []byte("2")))
+	assert.Equal(t, Message{Topic: types.EventArticleCreated, Body: []byte("1")}, <-all)
+	_, open := <-all
+	assert.False(t, open)
+	cancelAll()
+}
@@ -0,0 +1,65 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// LogSink writes every event to the log
+type LogSink struct {
+	Log *zap.Logger
+}
+
+// Name implements the Sink interface
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+// Publish implements the Sink interface
+func (s *LogSink) Publish(ctx context.Context, event *types.Event) error {
+	s.Log.Info("event published", zap.String("eventId", event.ID), zap.String("type", event.Type), zap.Time("createdAt", event.CreatedAt))
+	return nil
+}
+
+// Emitter accepts events, such as the webhook dispatcher
+type Emitter interface {
+	Emit(event *types.Event) error
+}
+
+// WebhookSink hands every event to the webhook dispatcher, which delivers it to the subscribed webhooks
+type WebhookSink struct {
+	Emitter Emitter
+}
+
+// Name implements the Sink interface
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Publish implements the Sink interface
+func (s *WebhookSink) Publish(ctx context.Context, event *types.Event) error {
+	return s.Emitter.Emit(event)
+}
+
+// BrokerSink publishes every event as json to a message broker, using the event type as topic
+type BrokerSink struct {
+	Broker Broker
+}
+
+// Name implements the Sink interface
+func (s *BrokerSink) Name() string {
+	return "broker"
+}
+
+// Publish implements the Sink interface
+func (s *BrokerSink) Publish(ctx context.Context, event *types.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.Broker.Publish(ctx, event.Type, body)
+}
@@ -0,0 +1,162 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+// TaxRule decides the tax rate of articles. Rules are pluggable, so that rates can come from configuration,
+// a database or an external tax service.
+type TaxRule interface {
+	// Rate returns the tax rate in basis points, 725 is 7.25%, of an article of the tax category sold to the
+	// region and whether the rule applies to them at all
+	Rate(region, category string) (int64, bool)
+}
+
+// Item is an item of an order to be priced
+type Item struct {
+	// The id of the article
+	ArticleID int
+	// The id of the variant of the article, if the item is a variant
+	VariantID int
+	// The ordered quantity
+	Quantity int
+	// The price of a single unit at the time of the order
+	UnitPrice types.Money
+	// The tax category of the article
+	TaxCategory string
+}
+
+// Engine calculates the subtotal, discount, tax and total of orders
+type Engine struct {
+	// TaxRules are asked in order for the tax rate of every item, the first applying rule wins.
+	// Items without an applying rule aren't taxed.
+	TaxRules []TaxRule
+}
+
+// NewEngine returns an engine that taxes items according to the rules
+func NewEngine(rules ...TaxRule) *Engine {
+	return &Engine{TaxRules: rules}
+}
+
+// Quote prices the items for the region. The discount of the coupon, which may be nil, is spread over the items
+// in proportion to their subtotal and taxes are calculated on the discounted subtotals.
+// The coupon's validity window is checked at the given time, its usage limit has to be checked by the caller.
+func (e *Engine) Quote(items []Item, region string, coupon *types.Coupon, at time.Time) (*types.Quote, error) {
+	quote := &types.Quote{Items: make([]*types.QuoteItem, 0, len(items)), Region: region}
+
+	for _, item := range items {
+		line := &types.QuoteItem{
+			ArticleID: item.ArticleID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Subtotal:  item.UnitPrice.Mul(int64(item.Quantity)),
+		}
+		var err error
+		if quote.Subtotal, err = quote.Subtotal.Add(line.Subtotal); err != nil {
+			return nil, fmt.Errorf("all articles of an order must have the same currency: %w", err)
+		}
+		quote.Items = append(quote.Items, line)
+	}
+	// orders without items are priced in the default currency, so that their totals aren't empty
+	if quote.Subtotal.IsZero() {
+		quote.Subtotal = types.NewMoney(0, types.DefaultCurrency)
+	}
+	currency := quote.Subtotal.Currency
+	quote.Discount = types.NewMoney(0, currency)
+	quote.Tax = types.NewMoney(0, currency)
+
+	if coupon != nil {
+		discount, err := couponDiscount(coupon, quote.Subtotal, at)
+		if err != nil {
+			return nil, err
+		}
+		quote.CouponCode = coupon.Code
+		quote.Discount = discount
+	}
+	spreadDiscount(quote)
+
+	for i, line := range quote.Items {
+		rate := e.taxRate(region, items[i].TaxCategory)
+		discounted, _ := line.Subtotal.Sub(line.Discount)
+		line.TaxRate = formatRate(rate)
+		line.Tax = discounted.Scale(rate, 10000)
+		line.Total, _ = discounted.Add(line.Tax)
+		quote.Tax, _ = quote.Tax.Add(line.Tax)
+	}
+
+	discounted, _ := quote.Subtotal.Sub(quote.Discount)
+	quote.Total, _ = discounted.Add(quote.Tax)
+	return quote, nil
+}
+
+// taxRate returns the rate of the first applying tax rule in basis points
+func (e *Engine) taxRate(region, category string) int64 {
+	for _, rule := range e.TaxRules {
+		if rate, ok := rule.Rate(region, category); ok {
+			return rate
+		}
+	}
+	return 0
+}
+
+// couponDiscount checks that the coupon can be applied at the given time and returns its discount of the
+// subtotal, which never exceeds the subtotal
+func couponDiscount(coupon *types.Coupon, subtotal types.Money, at time.Time) (types.Money, error) {
+	switch {
+	case coupon.ValidFrom != nil && at.Before(*coupon.ValidFrom):
+		return types.Money{}, &types.CouponError{Code: coupon.Code, Reason: "it isn't valid yet"}
+	case coupon.ValidUntil != nil && at.After(*coupon.ValidUntil):
+		return types.Money{}, &types.CouponError{Code: coupon.Code, Reason: "it has expired"}
+	}
+
+	discount := types.NewMoney(0, subtotal.Currency)
+	switch coupon.Kind {
+	case types.CouponPercentage:
+		discount = subtotal.Scale(int64(coupon.PercentOff), 100)
+	case types.CouponFixed:
+		if subtotal.Amount == 0 {
+			break
+		}
+		c, err := coupon.AmountOff.Cmp(subtotal)
+		if err != nil {
+			return types.Money{}, &types.CouponError{Code: coupon.Code, Reason: "it only applies to orders in " + coupon.AmountOff.Currency}
+		}
+		discount = coupon.AmountOff
+		if c > 0 {
+			discount = subtotal
+		}
+	default:
+		return types.Money{}, errors.New("unknown coupon kind " + coupon.Kind)
+	}
+	return discount, nil
+}
+
+// spreadDiscount distributes the discount of the quote over its items in proportion to their subtotal.
+// The last item gets the rounding remainder, so that the item discounts add up to the discount of the quote.
+func spreadDiscount(quote *types.Quote) {
+	remaining := quote.Discount
+	for i, line := range quote.Items {
+		if i == len(quote.Items)-1 || quote.Subtotal.Amount == 0 {
+			line.Discount = remaining
+			remaining = types.NewMoney(0, remaining.Currency)
+			continue
+		}
+		line.Discount = quote.Discount.Scale(line.Subtotal.Amount, quote.Subtotal.Amount)
+		remaining, _ = remaining.Sub(line.Discount)
+	}
+}
+
+// formatRate formats a rate in basis points as a percentage with two decimals
+func formatRate(rate int64) string {
+	sign := ""
+	if rate < 0 {
+		sign = "-"
+		rate = -rate
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, rate/100, rate%100)
+}
@@ -0,0 +1,145 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+func TestEngine_Quote(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	maxUses := 1
+	e := NewEngine(RateTable{"US-CA": 725, "US-CA/food": 0})
+	items := []Item{
+		{ArticleID: 1, Quantity: 2, UnitPrice: types.NewMoney(199, "USD"), TaxCategory: "food"},
+		{ArticleID: 2, Quantity: 1, UnitPrice: types.NewMoney(1000, "USD")},
+	}
+
+	testcases := map[string]struct {
+		items        []Item
+		region       string
+		coupon       *types.Coupon
+		wantSubtotal types.Money
+		wantDiscount types.Money
+		wantTax      types.Money
+		wantTotal    types.Money
+		wantItems    []types.Money
+		wantErr      string
+	}{
+		"untaxed region": {
+			items:        items,
+			region:       "DE",
+			wantSubtotal: types.NewMoney(1398, "USD"),
+			wantDiscount: types.NewMoney(0, "USD"),
+			wantTax:      types.NewMoney(0, "USD"),
+			wantTotal:    types.NewMoney(1398, "USD"),
+			wantItems:    []types.Money{types.NewMoney(398, "USD"), types.NewMoney(1000, "USD")},
+		},
+		"tax per category": {
+			items:        items,
+			region:       "US-CA",
+			wantSubtotal: types.NewMoney(1398, "USD"),
+			wantDiscount: types.NewMoney(0, "USD"),
+			wantTax:      types.NewMoney(72, "USD"),
+			wantTotal:    types.NewMoney(1470, "USD"),
+			wantItems:    []types.Money{types.NewMoney(398, "USD"), types.NewMoney(1072, "USD")},
+		},
+		"percentage coupon": {
+			items:        items,
+			region:       "US-CA",
+			coupon:       &types.Coupon{Code: "SAVE10", Kind: types.CouponPercentage, PercentOff: 10, MaxUses: &maxUses, Uses: 1},
+			wantSubtotal: types.NewMoney(1398, "USD"),
+			wantDiscount: types.NewMoney(140, "USD"),
+			wantTax:      types.NewMoney(65, "USD"),
+			wantTotal:    types.NewMoney(1323, "USD"),
+			wantItems:    []types.Money{types.NewMoney(358, "USD"), types.NewMoney(965, "USD")},
+		},
+		"fixed coupon above the subtotal": {
+			items:        items[:1],
+			coupon:       &types.Coupon{Code: "FIVE", Kind: types.CouponFixed, AmountOff: types.NewMoney(500, "USD")},
+			wantSubtotal: types.NewMoney(398, "USD"),
+			wantDiscount: types.NewMoney(398, "USD"),
+			wantTax:      types.NewMoney(0, "USD"),
+			wantTotal:    types.NewMoney(0, "USD"),
+			wantItems:    []types.Money{types.NewMoney(0, "USD")},
+		},
+		"no items": {
+			coupon:       &types.Coupon{Code: "FIVE", Kind: types.CouponFixed, AmountOff: types.NewMoney(500, "EUR")},
+			wantSubtotal: types.NewMoney(0, "USD"),
+			wantDiscount: types.NewMoney(0, "USD"),
+			wantTax:      types.NewMoney(0, "USD"),
+			wantTotal:    types.NewMoney(0, "USD"),
+		},
+		"fixed coupon in another currency": {
+			items:   items,
+			coupon:  &types.Coupon{Code: "FIVE", Kind: types.CouponFixed, AmountOff: types.NewMoney(500, "EUR")},
+			wantErr: "coupon FIVE can't be applied: it only applies to orders in EUR",
+		},
+		"coupon not valid yet": {
+			items:   items,
+			coupon:  &types.Coupon{Code: "SOON", Kind: types.CouponPercentage, PercentOff: 10, ValidFrom: &later},
+			wantErr: "coupon SOON can't be applied: it isn't valid yet",
+		},
+		"mixed currencies": {
+			items:   append([]Item{{ArticleID: 3, Quantity: 1, UnitPrice: types.NewMoney(100, "EUR")}}, items...),
+			wantErr: "all articles of an order must have the same currency: can't add USD to EUR",
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			quote, err := e.Quote(test.items, test.region, test.coupon, now)
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantSubtotal, quote.Subtotal)
+			assert.Equal(t, test.wantDiscount, quote.Discount)
+			assert.Equal(t, test.wantTax, quote.Tax)
+			assert.Equal(t, test.wantTotal, quote.Total)
+			for i, want := range test.wantItems {
+				assert.Equal(t, want, quote.Items[i].Total)
+			}
+		})
+	}
+}
+
+func TestParseRateTable(t *testing.T) {
+	table, err := ParseRateTable("de=19, DE/food=7,US-CA=7.25,")
+	assert.NoError(t, err)
+	assert.Equal(t, RateTable{"DE": 1900, "DE/food": 700, "US-CA": 725}, table)
+
+	_, err = ParseRateTable("DE=19.125")
+	assert.Error(t, err)
+	_, err = ParseRateTable("DE")
+	assert.Error(t, err)
+}
+
+func TestRateTable_Rate(t *testing.T) {
+	table := RateTable{"US": 500, "US-CA": 725, "US-CA/food": 0}
+
+	testcases := map[string]struct {
+		region   string
+		category string
+		wantRate int64
+		wantOK   bool
+	}{
+		"subdivision":             {region: "US-CA", wantRate: 725, wantOK: true},
+		"category of subdivision": {region: "us-ca", category: "food", wantRate: 0, wantOK: true},
+		"country of subdivision":  {region: "US-NY", category: "food", wantRate: 500, wantOK: true},
+		"unknown region":          {region: "DE"},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			rate, ok := table.Rate(test.region, test.category)
+			assert.Equal(t, test.wantRate, rate)
+			assert.Equal(t, test.wantOK, ok)
+		})
+	}
+}
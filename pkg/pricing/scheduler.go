@@ -0,0 +1,63 @@
+// Package pricing maintains the prices of articles
+package pricing
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PriceApplier applies the scheduled prices that took effect until now and returns the number of changed articles.
+// It is implemented by db.ClientInterface, which can't be referenced here because the db package uses the
+// pricing engine.
+type PriceApplier interface {
+	ApplyScheduledPrices(now time.Time) (int, error)
+}
+
+// Scheduler applies scheduled article prices once they take effect
+type Scheduler struct {
+	// Interval is the time between two checks for due prices, which is the maximum delay of a price change
+	Interval time.Duration
+
+	db  PriceApplier
+	log *zap.Logger
+	now func() time.Time
+}
+
+// NewScheduler returns a scheduler with sensible defaults
+func NewScheduler(dbClient PriceApplier, log *zap.Logger) *Scheduler {
+	return &Scheduler{
+		Interval: 30 * time.Second,
+		db:       dbClient,
+		log:      log,
+		now:      time.Now,
+	}
+}
+
+// Run applies due prices until the context is canceled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.applyDue()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// applyDue applies the prices that took effect until now
+func (s *Scheduler) applyDue() {
+	changed, err := s.db.ApplyScheduledPrices(s.now())
+	if err != nil {
+		s.log.Error("couldn't apply scheduled prices", zap.Error(err))
+		return
+	}
+	if changed > 0 {
+		s.log.Info("applied scheduled prices", zap.Int("articles", changed))
+	}
+}
@@ -0,0 +1,50 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// priceApplierFunc implements PriceApplier with a function, the database client mocks can't be imported here
+// because the database client depends on this package
+type priceApplierFunc func(now time.Time) (int, error)
+
+func (f priceApplierFunc) ApplyScheduledPrices(now time.Time) (int, error) {
+	return f(now)
+}
+
+func TestScheduler_Run(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	var apply func() (int, error)
+	s := NewScheduler(priceApplierFunc(func(at time.Time) (int, error) {
+		assert.Equal(t, now, at)
+		calls++
+		return apply()
+	}), zap.NewNop())
+	s.Interval = time.Hour
+	s.now = func() time.Time {
+		return now
+	}
+
+	// errors are logged and retried with the next check
+	apply = func() (int, error) {
+		return 0, errors.New("connection refused")
+	}
+	s.applyDue()
+	assert.Equal(t, 1, calls)
+
+	// due prices are applied right away on start
+	ctx, cancel := context.WithCancel(context.Background())
+	apply = func() (int, error) {
+		cancel()
+		return 2, nil
+	}
+	s.Run(ctx)
+	assert.Equal(t, 2, calls)
+}
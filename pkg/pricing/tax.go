@@ -0,0 +1,76 @@
+package pricing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RateTable is a TaxRule with fixed rates per region and tax category. Regions are ISO 3166 codes such as DE
+// or US-CA, rates of a country apply to its subdivisions unless the subdivision has a rate of its own.
+// Rates of a tax category take precedence over the rate of the region.
+type RateTable map[string]int64
+
+// ParseRateTable parses tax rates in percent in the format region[/category]=rate separated by commas,
+// for example "DE=19,DE/food=7,US-CA=7.25"
+func ParseRateTable(s string) (RateTable, error) {
+	table := RateTable{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("tax rate %q must have the format region[/category]=rate", entry)
+		}
+		rate, err := parseRate(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("tax rate %q: %w", entry, err)
+		}
+		key := strings.SplitN(parts[0], "/", 2)
+		key[0] = strings.ToUpper(key[0])
+		table[strings.Join(key, "/")] = rate
+	}
+	return table, nil
+}
+
+// Rate implements the TaxRule interface
+func (t RateTable) Rate(region, category string) (int64, bool) {
+	for region = strings.ToUpper(region); region != ""; region = parentRegion(region) {
+		if category != "" {
+			if rate, ok := t[region+"/"+category]; ok {
+				return rate, true
+			}
+		}
+		if rate, ok := t[region]; ok {
+			return rate, true
+		}
+	}
+	return 0, false
+}
+
+// parentRegion returns the country of a subdivision such as US-CA and an empty string for countries
+func parentRegion(region string) string {
+	if i := strings.LastIndexByte(region, '-'); i >= 0 {
+		return region[:i]
+	}
+	return ""
+}
+
+// parseRate parses a percentage with at most two decimals into basis points
+func parseRate(s string) (int64, error) {
+	whole, frac := strings.TrimSpace(s), ""
+	if i := strings.IndexByte(whole, '.'); i >= 0 {
+		whole, frac = whole[:i], whole[i+1:]
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("rate %s must have at most two decimals", s)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+	rate, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || rate < 0 {
+		return 0, fmt.Errorf("rate %s must be a non-negative number", s)
+	}
+	return rate, nil
+}
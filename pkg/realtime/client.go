@@ -0,0 +1,271 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jonnylangefeld/go-api/pkg/types"
+	"github.com/jonnylangefeld/go-api/pkg/websocket"
+)
+
+const (
+	// MessageSubscribe is sent by clients to subscribe to a topic
+	MessageSubscribe = "subscribe"
+	// MessageUnsubscribe is sent by clients to cancel a subscription
+	MessageUnsubscribe = "unsubscribe"
+	// MessageSubscribed confirms a subscription
+	MessageSubscribed = "subscribed"
+	// MessageUnsubscribed confirms the cancellation of a subscription
+	MessageUnsubscribed = "unsubscribed"
+	// MessageEvent contains an event of a subscription
+	MessageEvent = "event"
+	// MessageError reports an invalid client message
+	MessageError = "error"
+)
+
+// maxSubscriptions is the maximum number of subscriptions per client
+const maxSubscriptions = 100
+
+// ClientMessage is a message sent by a client
+type ClientMessage struct {
+	// The type of the message
+	Type string `json:"type" example:"subscribe" enums:"subscribe,unsubscribe"`
+	// The id of the subscription, chosen by the client
+	ID string `json:"id" example:"my-order"`
+	// The topic to subscribe to
+	Topic string `json:"topic,omitempty" example:"orders" enums:"orders,articles"`
+	// Restricts the subscription to the order or article with this id
+	ResourceID int `json:"resource_id,omitempty" example:"1"`
+	// Restricts an articles subscription to articles with at least this price in the same currency
+	MinPrice *types.Money `json:"min_price,omitempty"`
+	// Restricts an articles subscription to articles with at most this price in the same currency
+	MaxPrice *types.Money `json:"max_price,omitempty"`
+} // @name ClientMessage
+
+// ServerMessage is a message sent to a client
+type ServerMessage struct {
+	// The type of the message
+	Type string `json:"type" example:"event" enums:"subscribed,unsubscribed,event,error"`
+	// The id of the subscription the message refers to
+	ID string `json:"id,omitempty" example:"my-order"`
+	// The event of an event message
+	Event *types.Event `json:"event,omitempty"`
+	// The reason of an error message
+	Error string `json:"error,omitempty" example:"unknown topic \"customers\""`
+} // @name ServerMessage
+
+// subscription is a filter on the events of a topic
+type subscription struct {
+	id         string
+	client     *Client
+	topic      string
+	resourceID int
+	minPrice   *types.Money
+	maxPrice   *types.Money
+}
+
+// newSubscription validates the subscribe message and returns its subscription
+func newSubscription(c *Client, msg *ClientMessage) (*subscription, error) {
+	if msg.ID == "" {
+		return nil, errors.New("id must not be empty")
+	}
+	if msg.Topic != TopicOrders && msg.Topic != TopicArticles {
+		return nil, fmt.Errorf("unknown topic %q", msg.Topic)
+	}
+	if (msg.MinPrice != nil || msg.MaxPrice != nil) && msg.Topic != TopicArticles {
+		return nil, errors.New("price ranges are only supported for articles")
+	}
+	if msg.MinPrice != nil && msg.MaxPrice != nil {
+		c, err := msg.MinPrice.Cmp(*msg.MaxPrice)
+		if err != nil {
+			return nil, errors.New("min_price and max_price must have the same currency")
+		}
+		if c > 0 {
+			return nil, errors.New("min_price must not be greater than max_price")
+		}
+	}
+	return &subscription{
+		id:         msg.ID,
+		client:     c,
+		topic:      msg.Topic,
+		resourceID: msg.ResourceID,
+		minPrice:   msg.MinPrice,
+		maxPrice:   msg.MaxPrice,
+	}, nil
+}
+
+// key returns the index key of the subscription
+func (s *subscription) key() string {
+	if s.resourceID != 0 {
+		return s.topic + ":" + strconv.Itoa(s.resourceID)
+	}
+	return s.topic
+}
+
+// match reports whether the changed resource passes the filters of the subscription.
+// Prices in a different currency than the price range never match.
+func (s *subscription) match(r *resource) bool {
+	if s.minPrice != nil {
+		if c, err := r.Price.Cmp(*s.minPrice); err != nil || c < 0 {
+			return false
+		}
+	}
+	if s.maxPrice != nil {
+		if c, err := r.Price.Cmp(*s.maxPrice); err != nil || c > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Client is a WebSocket connection to the hub
+type Client struct {
+	hub   *Hub
+	conn  *websocket.Conn
+	actor string
+	send  chan []byte
+	done  chan struct{}
+	once  sync.Once
+
+	// subscriptions is guarded by the lock of the hub
+	subscriptions map[string]*subscription
+}
+
+// Serve registers a client for the connection of the authenticated actor and handles it until it is closed
+func (h *Hub) Serve(conn *websocket.Conn, actor string) {
+	conn.WriteTimeout = h.WriteTimeout
+	c := &Client{
+		hub:           h,
+		conn:          conn,
+		actor:         actor,
+		send:          make(chan []byte, h.ClientBuffer),
+		done:          make(chan struct{}),
+		subscriptions: map[string]*subscription{},
+	}
+	h.register(c)
+	defer h.unregister(c)
+
+	go c.writePump()
+	c.readPump()
+}
+
+// readPump handles the messages of the client until the connection fails or is closed
+func (c *Client) readPump() {
+	defer c.closeWith(websocket.CloseNormalClosure, "")
+
+	_ = c.conn.SetReadDeadline(time.Now().Add(c.hub.PongTimeout))
+	c.conn.SetPongHandler(func([]byte) {
+		_ = c.conn.SetReadDeadline(time.Now().Add(c.hub.PongTimeout))
+	})
+
+	for {
+		messageType, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if messageType != websocket.TextMessage {
+			c.reply(&ServerMessage{Type: MessageError, Error: "only text messages are supported"})
+			continue
+		}
+		msg := &ClientMessage{}
+		if err := json.Unmarshal(data, msg); err != nil {
+			c.reply(&ServerMessage{Type: MessageError, Error: "invalid message: " + err.Error()})
+			continue
+		}
+		c.handle(msg)
+	}
+}
+
+// handle processes a single message of the client
+func (c *Client) handle(msg *ClientMessage) {
+	switch msg.Type {
+	case MessageSubscribe:
+		sub, err := newSubscription(c, msg)
+		if err != nil {
+			c.reply(&ServerMessage{Type: MessageError, ID: msg.ID, Error: err.Error()})
+			return
+		}
+		if c.subscriptionCount() >= maxSubscriptions {
+			c.reply(&ServerMessage{Type: MessageError, ID: msg.ID, Error: "too many subscriptions"})
+			return
+		}
+		c.hub.subscribe(c, sub)
+		c.reply(&ServerMessage{Type: MessageSubscribed, ID: msg.ID})
+	case MessageUnsubscribe:
+		if !c.hub.unsubscribe(c, msg.ID) {
+			c.reply(&ServerMessage{Type: MessageError, ID: msg.ID, Error: "unknown subscription"})
+			return
+		}
+		c.reply(&ServerMessage{Type: MessageUnsubscribed, ID: msg.ID})
+	default:
+		c.reply(&ServerMessage{Type: MessageError, ID: msg.ID, Error: fmt.Sprintf("unknown message type %q", msg.Type)})
+	}
+}
+
+// subscriptionCount returns the number of subscriptions of the client
+func (c *Client) subscriptionCount() int {
+	c.hub.mu.RLock()
+	defer c.hub.mu.RUnlock()
+	return len(c.subscriptions)
+}
+
+// reply queues a message to the client, disconnecting it if its queue is full
+func (c *Client) reply(msg *ServerMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	if !c.enqueue(data) {
+		c.closeWith(websocket.ClosePolicyViolation, "too slow")
+	}
+}
+
+// enqueue queues the message without blocking and reports whether there was room for it
+func (c *Client) enqueue(msg []byte) bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+	}
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+// writePump writes the queued messages and the pings to the connection
+func (c *Client) writePump() {
+	ticker := time.NewTicker(c.hub.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.send:
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				c.closeWith(websocket.CloseGoingAway, "")
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.closeWith(websocket.CloseGoingAway, "")
+				return
+			}
+		}
+	}
+}
+
+// closeWith closes the connection with the code once, which also ends both pumps
+func (c *Client) closeWith(code int, reason string) {
+	c.once.Do(func() {
+		close(c.done)
+		_ = c.conn.CloseWithCode(code, reason)
+	})
+}
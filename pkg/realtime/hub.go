@@ -0,0 +1,223 @@
+// Package realtime fans out the published events to the subscriptions of WebSocket clients
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jonnylangefeld/go-api/pkg/outbox"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+	"github.com/jonnylangefeld/go-api/pkg/websocket"
+)
+
+const (
+	// TopicOrders receives the changes of all orders or, with an order id, of a single order
+	TopicOrders = "orders"
+	// TopicArticles receives the changes of all articles, of a single article or of the articles in a price range
+	TopicArticles = "articles"
+)
+
+// Hub is the single consumer of the broker that distributes every event to the matching subscriptions.
+// Subscriptions are indexed by topic and resource id, so that an event only visits the subscriptions
+// that are interested in it. Every client has a buffered queue and is disconnected if it can't keep up.
+type Hub struct {
+	// ClientBuffer is the number of messages queued per client before it is disconnected
+	ClientBuffer int
+	// PingInterval is the time between two pings sent to every client
+	PingInterval time.Duration
+	// PongTimeout is the time a client has to answer a ping before it is disconnected
+	PongTimeout time.Duration
+	// WriteTimeout is the maximum duration of a single write to a client
+	WriteTimeout time.Duration
+
+	broker outbox.Broker
+	log    *zap.Logger
+
+	mu sync.RWMutex
+	// index maps the keys of the subscriptions to the subscriptions
+	index   map[string]map[*subscription]bool
+	clients map[*Client]bool
+}
+
+// NewHub returns a hub for the events of the broker with sensible defaults
+func NewHub(broker outbox.Broker, log *zap.Logger) *Hub {
+	return &Hub{
+		ClientBuffer: 256,
+		PingInterval: 30 * time.Second,
+		PongTimeout:  60 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		broker:       broker,
+		log:          log,
+		index:        map[string]map[*subscription]bool{},
+		clients:      map[*Client]bool{},
+	}
+}
+
+// Run distributes the events of the broker until the context is canceled.
+// If the hub falls behind and the broker cancels its subscription, it subscribes again.
+func (h *Hub) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		messages, unsubscribe := h.broker.Subscribe(types.EventAll)
+		h.consume(ctx, messages)
+		unsubscribe()
+	}
+	h.closeAll()
+}
+
+// consume distributes the messages until the channel is closed or the context is canceled
+func (h *Hub) consume(ctx context.Context, messages <-chan outbox.Message) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				h.log.Warn("realtime hub fell behind the broker, events were dropped")
+				return
+			}
+			event, err := types.DecodeEvent(msg.Body)
+			if err != nil {
+				h.log.Error("couldn't decode event", zap.Error(err))
+				continue
+			}
+			h.Dispatch(event)
+		}
+	}
+}
+
+// Dispatch sends the event to all matching subscriptions
+func (h *Hub) Dispatch(event *types.Event) {
+	topic, resource, err := eventResource(event)
+	if err != nil {
+		return
+	}
+
+	var slow []*Client
+	h.mu.RLock()
+	for _, key := range []string{topic, topic + ":" + strconv.Itoa(resource.ID)} {
+		for sub := range h.index[key] {
+			if !sub.match(resource) {
+				continue
+			}
+			msg, err := json.Marshal(&ServerMessage{Type: MessageEvent, ID: sub.id, Event: event})
+			if err != nil {
+				continue
+			}
+			if !sub.client.enqueue(msg) {
+				slow = append(slow, sub.client)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range slow {
+		h.log.Info("disconnecting slow websocket client", zap.String("actor", client.actor))
+		client.closeWith(websocket.ClosePolicyViolation, "too slow")
+	}
+}
+
+// Clients returns the number of connected clients
+func (h *Hub) Clients() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// register adds the client to the hub
+func (h *Hub) register(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clients[c] = true
+}
+
+// unregister removes the client and all its subscriptions from the hub
+func (h *Hub) unregister(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, sub := range c.subscriptions {
+		h.removeLocked(sub)
+	}
+	c.subscriptions = map[string]*subscription{}
+	delete(h.clients, c)
+}
+
+// subscribe adds the subscription of the client, replacing a previous subscription with the same id
+func (h *Hub) subscribe(c *Client, sub *subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if old, ok := c.subscriptions[sub.id]; ok {
+		h.removeLocked(old)
+	}
+	c.subscriptions[sub.id] = sub
+	if h.index[sub.key()] == nil {
+		h.index[sub.key()] = map[*subscription]bool{}
+	}
+	h.index[sub.key()][sub] = true
+}
+
+// unsubscribe removes the subscription with the id of the client and reports whether it existed
+func (h *Hub) unsubscribe(c *Client, id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sub, ok := c.subscriptions[id]
+	if !ok {
+		return false
+	}
+	h.removeLocked(sub)
+	delete(c.subscriptions, id)
+	return true
+}
+
+// removeLocked removes the subscription from the index, the caller must hold the lock
+func (h *Hub) removeLocked(sub *subscription) {
+	key := sub.key()
+	delete(h.index[key], sub)
+	if len(h.index[key]) == 0 {
+		delete(h.index, key)
+	}
+}
+
+// closeAll disconnects all clients
+func (h *Hub) closeAll() {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.RUnlock()
+	for _, c := range clients {
+		c.closeWith(websocket.CloseGoingAway, "shutting down")
+	}
+}
+
+// resource contains the fields of a changed resource that subscriptions filter on
+type resource struct {
+	ID    int         `json:"id"`
+	Price types.Money `json:"price"`
+}
+
+// eventResource returns the topic and the filter fields of the resource the event is about
+func eventResource(event *types.Event) (string, *resource, error) {
+	topic := ""
+	switch event.Type {
+	case types.EventOrderCreated, types.EventOrderUpdated, types.EventOrderDeleted:
+		topic = TopicOrders
+	case types.EventArticleCreated, types.EventArticleUpdated, types.EventArticleDeleted:
+		topic = TopicArticles
+	default:
+		return "", nil, fmt.Errorf("unknown event type %q", event.Type)
+	}
+
+	r := &resource{}
+	data, _ := event.Data.(json.RawMessage)
+	if err := json.Unmarshal(data, r); err != nil {
+		return "", nil, err
+	}
+	return topic, r, nil
+}
@@ -0,0 +1,67 @@
+package realtime
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/jonnylangefeld/go-api/pkg/outbox"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+	"github.com/jonnylangefeld/go-api/pkg/websocket"
+)
+
+func testEvent(t *testing.T, eventType string, data interface{}) *types.Event {
+	body, err := json.Marshal(&types.Event{ID: "1", Type: eventType, CreatedAt: time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC), Data: data})
+	assert.NoError(t, err)
+	event, err := types.DecodeEvent(body)
+	assert.NoError(t, err)
+	return event
+}
+
+func TestHub(t *testing.T) {
+	h := NewHub(outbox.NewMemoryBroker(), zap.NewNop())
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		h.Serve(conn, "test")
+	}))
+	defer ts.Close()
+
+	conn, _, err := websocket.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	exchange := func(msg string) string {
+		t.Helper()
+		if msg != "" {
+			assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(msg)))
+		}
+		_, data, err := conn.ReadMessage()
+		assert.NoError(t, err)
+		return string(data)
+	}
+
+	assert.Equal(t, `{"type":"subscribed","id":"order"}`, exchange(`{"type":"subscribe","id":"order","topic":"orders","resource_id":1}`))
+	assert.Equal(t, `{"type":"subscribed","id":"cheap"}`, exchange(`{"type":"subscribe","id":"cheap","topic":"articles","max_price":2}`))
+	assert.Equal(t, `{"type":"error","id":"x","error":"unknown topic \"customers\""}`, exchange(`{"type":"subscribe","id":"x","topic":"customers"}`))
+	assert.Equal(t, 1, h.Clients())
+
+	// only the events matching a subscription are sent
+	h.Dispatch(testEvent(t, types.EventOrderCreated, &types.Order{ID: 2}))
+	h.Dispatch(testEvent(t, types.EventArticleUpdated, &types.Article{ID: 2, Name: "Jelly Beans", Price: types.NewMoney(299, "USD")}))
+	h.Dispatch(testEvent(t, types.EventArticleUpdated, &types.Article{ID: 1, Name: "Skittles", Price: types.NewMoney(199, "USD")}))
+	assert.Equal(t, `{"type":"event","id":"cheap","event":{"id":"1","type":"article.updated","created_at":"2020-10-01T12:00:00Z","data":{"id":1,"name":"Skittles","price":{"amount":"1.99","currency":"USD"}}}}`, exchange(""))
+	h.Dispatch(testEvent(t, types.EventOrderDeleted, &types.Order{ID: 1}))
+	assert.Equal(t, `{"type":"event","id":"order","event":{"id":"1","type":"order.deleted","created_at":"2020-10-01T12:00:00Z","data":{"id":1,"lastUpdated":"0001-01-01T00:00:00Z","subtotal":null,"discount":null,"tax":null,"total":null}}}`, exchange(""))
+
+	assert.Equal(t, `{"type":"unsubscribed","id":"order"}`, exchange(`{"type":"unsubscribe","id":"order"}`))
+	assert.Equal(t, `{"type":"error","id":"order","error":"unknown subscription"}`, exchange(`{"type":"unsubscribe","id":"order"}`))
+}
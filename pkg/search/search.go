@@ -0,0 +1,149 @@
+// Package search ranks and highlights texts matching a search query. It mirrors the postgres full-text search
+// with the simple configuration, prefix matching and pg_trgm similarity for databases that lack them.
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// StartSel marks the start of a highlighted word
+	StartSel = "<mark>"
+	// StopSel marks the end of a highlighted word
+	StopSel = "</mark>"
+	// SimilarityThreshold is the trigram similarity from which texts match despite typos, like pg_trgm's default
+	SimilarityThreshold = 0.3
+)
+
+// Terms splits the query into lower case words
+func Terms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// TSQuery returns a postgres tsquery matching texts that contain words starting with every term
+func TSQuery(terms []string) string {
+	prefixes := make([]string, 0, len(terms))
+	for _, term := range terms {
+		prefixes = append(prefixes, term+":*")
+	}
+	return strings.Join(prefixes, " & ")
+}
+
+// Match reports whether the text contains a word starting with every term and ranks the match by the share of
+// words of the text matching a term plus the similarity of the text and the terms.
+// Texts that don't contain all terms still match if they are similar enough to the terms.
+func Match(text string, terms []string) (float64, bool) {
+	if len(terms) == 0 {
+		return 0, false
+	}
+	words := Terms(text)
+	similarity := Similarity(text, strings.Join(terms, " "))
+
+	matched := 0
+	for _, word := range words {
+		if matchesTerm(word, terms) {
+			matched++
+		}
+	}
+	for _, term := range terms {
+		if !containsPrefix(words, term) {
+			return similarity, similarity >= SimilarityThreshold
+		}
+	}
+	return float64(matched)/float64(len(words)) + similarity, true
+}
+
+// Highlight surrounds the words of the text that start with one of the terms with StartSel and StopSel
+func Highlight(text string, terms []string) string {
+	b := strings.Builder{}
+	word := []rune{}
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		if matchesTerm(strings.ToLower(string(word)), terms) {
+			b.WriteString(StartSel + string(word) + StopSel)
+		} else {
+			b.WriteString(string(word))
+		}
+		word = word[:0]
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word = append(word, r)
+			continue
+		}
+		flush()
+		b.WriteRune(r)
+	}
+	flush()
+	return b.String()
+}
+
+// Similarity returns the share of trigrams the texts have in common the way pg_trgm's similarity does
+func Similarity(a, b string) float64 {
+	trigramsA, trigramsB := trigrams(a), trigrams(b)
+	if len(trigramsA) == 0 || len(trigramsB) == 0 {
+		return 0
+	}
+	shared := 0
+	for trigram := range trigramsA {
+		if trigramsB[trigram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(trigramsA)+len(trigramsB)-shared)
+}
+
+// Result is a ranked match of a search
+type Result struct {
+	// ID identifies the matching text
+	ID int
+	// Rank orders the results, higher ranks first
+	Rank float64
+}
+
+// Sort orders the results by descending rank and ascending id
+func Sort(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].ID < results[j].ID
+	})
+}
+
+// trigrams returns the set of trigrams of the words of the text, which are padded with two spaces in front
+// and one space at the end
+func trigrams(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range Terms(text) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+func matchesTerm(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPrefix(words []string, term string) bool {
+	for _, word := range words {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"jelly", "beans", "2"}, Terms(" Jelly-Beans & 2!"))
+	assert.Equal(t, "jel:* & bea:*", TSQuery([]string{"jel", "bea"}))
+}
+
+func TestMatch(t *testing.T) {
+	testcases := map[string]struct {
+		text      string
+		query     string
+		wantMatch bool
+	}{
+		"whole word": {
+			text:      "Skittles",
+			query:     "skittles",
+			wantMatch: true,
+		},
+		"prefix": {
+			text:      "Jelly Beans",
+			query:     "jel bea",
+			wantMatch: true,
+		},
+		"missing term": {
+			text:      "Jelly Beans",
+			query:     "jelly licorice drops",
+			wantMatch: false,
+		},
+		"typo": {
+			text:      "Skittles",
+			query:     "skitles",
+			wantMatch: true,
+		},
+		"unrelated": {
+			text:      "Skittles",
+			query:     "beans",
+			wantMatch: false,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			rank, ok := Match(tc.text, Terms(tc.query))
+			assert.Equal(t, tc.wantMatch, ok)
+			if ok {
+				assert.Greater(t, rank, 0.0)
+			}
+		})
+	}
+}
+
+func TestMatch_Rank(t *testing.T) {
+	exact, _ := Match("Skittles", Terms("skittles"))
+	partial, _ := Match("Skittles Sour", Terms("skittles"))
+	typo, _ := Match("Skittles", Terms("skitles"))
+	assert.Greater(t, exact, partial)
+	assert.Greater(t, partial, typo)
+}
+
+func TestHighlight(t *testing.T) {
+	assert.Equal(t, "<mark>Jelly</mark> <mark>Beans</mark>, sour", Highlight("Jelly Beans, sour", Terms("bean jel")))
+	assert.Equal(t, "Skittles", Highlight("Skittles", Terms("skitles")))
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity("Skittles", "skittles"))
+	assert.Equal(t, 0.0, Similarity("Skittles", ""))
+	// the example of the pg_trgm documentation
+	assert.InDelta(t, 0.363636, Similarity("word", "two words"), 0.000001)
+}
+
+func TestSort(t *testing.T) {
+	results := []Result{{ID: 3, Rank: 0.5}, {ID: 2, Rank: 1}, {ID: 1, Rank: 0.5}}
+	Sort(results)
+	assert.Equal(t, []Result{{ID: 2, Rank: 1}, {ID: 1, Rank: 0.5}, {ID: 3, Rank: 0.5}}, results)
+}
@@ -0,0 +1,72 @@
+// Package storage stores blobs such as article images in pluggable backends
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// ErrNotFound is returned when a blob doesn't exist
+var ErrNotFound = errors.New("blob not found")
+
+// Store is a blob storage. Keys are slash separated paths such as articles/1/image.jpg.
+type Store interface {
+	// Put writes the blob, replacing an existing blob with the same key
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get returns a reader of the blob, which has to be closed, or ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob, deleting a missing blob is no error
+	Delete(ctx context.Context, key string) error
+}
+
+// Open returns the store configured by the url. Directories of the local filesystem are configured as
+// file:///var/lib/blobs or file://relative/dir, S3 compatible storage as
+// s3://access-key:secret-key@bucket?region=us-east-1&endpoint=http://localhost:9000.
+// The endpoint defaults to the AWS endpoint of the region.
+func Open(rawURL string) (Store, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "file":
+		dir := u.Host + u.Path
+		if dir == "" {
+			return nil, errors.New("file storage needs a directory")
+		}
+		return NewLocal(dir), nil
+	case "s3":
+		if u.Host == "" {
+			return nil, errors.New("s3 storage needs a bucket")
+		}
+		region := u.Query().Get("region")
+		if region == "" {
+			region = "us-east-1"
+		}
+		endpoint := u.Query().Get("endpoint")
+		if endpoint == "" {
+			endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+		}
+		secretKey, _ := u.User.Password()
+		return NewS3(endpoint, region, u.Host, u.User.Username(), secretKey), nil
+	default:
+		return nil, fmt.Errorf("unsupported storage %q, use file:// or s3://", u.Scheme)
+	}
+}
+
+// validKey reports whether the key is a relative path that stays within the store
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSignV4 uses the example request of the AWS signature version 4 documentation
+func TestSignV4(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signV4(req, hashHex(nil), "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "iam",
+		time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, "+
+		"SignedHeaders=content-type;host;x-amz-date, "+
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7", req.Header.Get("Authorization"))
+}
+
+func TestOpen(t *testing.T) {
+	store, err := Open("file://blobs/images")
+	assert.NoError(t, err)
+	assert.Equal(t, &Local{Dir: "blobs/images"}, store)
+
+	store, err = Open("s3://key:secret@images?region=eu-central-1")
+	assert.NoError(t, err)
+	s3 := store.(*S3)
+	assert.Equal(t, "https://s3.eu-central-1.amazonaws.com", s3.Endpoint)
+	assert.Equal(t, "images", s3.Bucket)
+	assert.Equal(t, "key", s3.AccessKey)
+	assert.Equal(t, "secret", s3.SecretKey)
+
+	_, err = Open("ftp://example.com")
+	assert.Error(t, err)
+}
+
+func TestLocal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	testStore(t, NewLocal(dir))
+}
+
+func TestS3(t *testing.T) {
+	standIn := newS3StandIn("key", "secret")
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	testStore(t, NewS3(server.URL, "us-east-1", "images", "key", "secret"))
+
+	// requests with the wrong credentials are rejected
+	err := NewS3(server.URL, "us-east-1", "images", "key", "wrong").Put(context.Background(), "a.txt", []byte("a"), "text/plain")
+	assert.EqualError(t, err, "storage responded with 403 Forbidden: SignatureDoesNotMatch")
+}
+
+// testStore runs the behavior every store has to implement
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	_, err := store.Get(ctx, "articles/1/image.png")
+	assert.Equal(t, ErrNotFound, err)
+
+	assert.NoError(t, store.Put(ctx, "articles/1/image.png", []byte("first"), "image/png"))
+	assert.NoError(t, store.Put(ctx, "articles/1/image.png", []byte("second"), "image/png"))
+	r, err := store.Get(ctx, "articles/1/image.png")
+	assert.NoError(t, err)
+	data, _ := ioutil.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "second", string(data))
+
+	assert.NoError(t, store.Delete(ctx, "articles/1/image.png"))
+	assert.NoError(t, store.Delete(ctx, "articles/1/image.png"))
+	_, err = store.Get(ctx, "articles/1/image.png")
+	assert.Equal(t, ErrNotFound, err)
+
+	assert.Error(t, store.Put(ctx, "../image.png", []byte("escape"), "image/png"))
+}
+
+// s3StandIn is an in-memory stand-in of an S3 compatible storage checking the signatures of requests
+type s3StandIn struct {
+	accessKey, secretKey string
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newS3StandIn(accessKey, secretKey string) *s3StandIn {
+	return &s3StandIn{accessKey: accessKey, secretKey: secretKey, objects: map[string][]byte{}}
+}
+
+func (s *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	date, _ := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if hashHex(body) != r.Header.Get("X-Amz-Content-Sha256") {
+		http.Error(w, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+		return
+	}
+	check, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.String(), nil)
+	for name, values := range r.Header {
+		if name == "Content-Type" || strings.HasPrefix(name, "X-Amz-") && name != "X-Amz-Date" {
+			check.Header[name] = values
+		}
+	}
+	signV4(check, hashHex(body), s.accessKey, s.secretKey, "us-east-1", "s3", date)
+	if check.Header.Get("Authorization") != r.Header.Get("Authorization") {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[r.URL.Path] = body
+	case http.MethodGet:
+		object, ok := s.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(object)
+	case http.MethodDelete:
+		delete(s.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,146 @@
+package types
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// AuditCreated is recorded when a resource is created
+	AuditCreated = "created"
+	// AuditUpdated is recorded when a resource is updated
+	AuditUpdated = "updated"
+	// AuditDeleted is recorded when a resource is deleted
+	AuditDeleted = "deleted"
+
+	// AuditSystemActor is the actor of changes that weren't requested through the api, such as applied scheduled prices
+	AuditSystemActor = "system"
+	// AuditAnonymousActor is the actor of changes requested without an api token
+	AuditAnonymousActor = "anonymous"
+)
+
+// AuditChange is the value of a field before and after a change
+type AuditChange struct {
+	// The value before the change, omitted if the field didn't exist before
+	Before json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	// The value after the change, omitted if the field doesn't exist anymore
+	After json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+} // @name AuditChange
+
+// AuditChanges maps the names of the changed fields of a resource to their change
+type AuditChanges map[string]*AuditChange // @name AuditChanges
+
+// NewAuditChanges compares the json representations of a resource before and after a change field by field.
+// Before is nil for created and after is nil for deleted resources.
+func NewAuditChanges(before, after interface{}) (AuditChanges, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := AuditChanges{}
+	for name, value := range beforeFields {
+		if !bytes.Equal(value, afterFields[name]) {
+			changes[name] = &AuditChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = &AuditChange{After: value}
+		}
+	}
+	return changes, nil
+}
+
+// jsonFields returns the top level fields of the json representation of the value
+func jsonFields(value interface{}) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if value == nil {
+		return fields, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return fields, json.Unmarshal(b, &fields)
+}
+
+// Value implements the database/sql/driver.Valuer interface
+func (a AuditChanges) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+// Scan implements the database/sql.Scanner interface
+func (a *AuditChanges) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(src, a)
+	case string:
+		return json.Unmarshal([]byte(src), a)
+	default:
+		return fmt.Errorf("can't scan %T into audit changes", src)
+	}
+}
+
+// AuditEntry records a change of a resource in the append-only audit log
+type AuditEntry struct {
+	// The unique id of this entry
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" example:"1"`
+	// The kind of the changed resource
+	Resource string `gorm:"type:varchar;NOT NULL;index:idx_audit_entries_resource" json:"resource" example:"article"`
+	// The id of the changed resource
+	ResourceID int `gorm:"type:integer;NOT NULL;index:idx_audit_entries_resource" json:"resource_id" example:"1"`
+	// The kind of the change
+	Action string `gorm:"type:varchar;NOT NULL" json:"action" example:"updated" enums:"created,updated,deleted"`
+	// The name of the authenticated actor who requested the change, anonymous for requests without a token
+	// and system for changes the api made on its own
+	Actor string `gorm:"type:varchar;NOT NULL;index" json:"actor" example:"backoffice"`
+	// The id of the request that made the change
+	RequestID string `gorm:"type:varchar" json:"request_id,omitempty" example:"host/abcdef-000001"`
+	// The changed fields of the resource with their values before and after the change
+	Changes AuditChanges `gorm:"type:jsonb;NOT NULL;default:'{}'" json:"changes"`
+	// The time of the change
+	CreatedAt time.Time `gorm:"index" json:"created_at" example:"2020-10-01T12:00:00Z"`
+} // @name AuditEntry
+
+// AuditEntryList contains a list of audit entries
+type AuditEntryList struct {
+	// A list of audit entries
+	Items []*AuditEntry `json:"items"`
+	// The id to query the next page
+	NextPageID int `json:"next_page_id,omitempty" example:"10"`
+} // @name AuditEntryList
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (a *AuditEntryList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// AuditFilter restricts the entries of the audit log
+type AuditFilter struct {
+	// Resource selects the entries of this kind of resource
+	Resource string
+	// ResourceID selects the entries of the resource with this id
+	ResourceID int
+	// Actor selects the entries of changes requested by this actor
+	Actor string
+	// From selects the entries of changes at or after this time
+	From *time.Time
+	// To selects the entries of changes before this time
+	To *time.Time
+}
@@ -0,0 +1,167 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MaxBatchOperations is the maximum number of operations of a batch request
+const MaxBatchOperations = 100
+
+// batchMethods contains the http methods operations of a batch request may use
+var batchMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPut:    true,
+	http.MethodPost:   true,
+	http.MethodDelete: true,
+}
+
+// batchRoutes contains the paths operations of a batch request may request. Segments in braces match ids and
+// asterisks match any segment. Streams, exports, images and websockets aren't json responses that could be part
+// of a batch response, and nested batch requests aren't supported.
+var batchRoutes = []string{
+	"/articles",
+	"/articles/search",
+	"/articles/{id}",
+	"/articles/{id}/stock",
+	"/articles/{id}/stock/adjustments",
+	"/articles/{id}/prices",
+	"/articles/{id}/variants",
+	"/articles/{id}/variants/{id}",
+	"/articles/{id}/variants/{id}/stock",
+	"/skus/*",
+	"/orders",
+	"/orders/{id}",
+	"/orders:quote",
+	"/coupons",
+	"/coupons/{id}",
+	"/categories",
+	"/categories/{id}",
+	"/categories/{id}/articles",
+	"/tags",
+	"/tags/{id}",
+	"/customers",
+	"/customers/{id}",
+	"/customers/{id}/orders",
+	"/webhooks",
+	"/webhooks/{id}",
+	"/webhooks/{id}/deliveries",
+	"/webhooks/{id}/deliveries/{id}/redeliver",
+	"/audit",
+	"/cache/stats",
+}
+
+// batchable returns whether operations of a batch request may request the path
+func batchable(path string) bool {
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	for _, route := range batchRoutes {
+		if matchRoute(strings.Split(route, "/"), segments) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchRoute(route, segments []string) bool {
+	if len(route) != len(segments) {
+		return false
+	}
+	for i, segment := range route {
+		switch segment {
+		case "*":
+			if segments[i] == "" {
+				return false
+			}
+		case "{id}":
+			if segments[i] == "" || strings.Trim(segments[i], "0123456789") != "" {
+				return false
+			}
+		default:
+			if segments[i] != segment {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// BatchOperation is a single request of a batch request
+type BatchOperation struct {
+	// The http method of the request
+	Method string `json:"method" example:"PUT" enums:"GET,PUT,POST,DELETE"`
+	// The path of the request including its query
+	Path string `json:"path" example:"/articles"`
+	// The headers of the request, such as Accept. The Authorization header defaults to the one of the batch request.
+	Header map[string]string `json:"header,omitempty"`
+	// The json body of the request, bodies of other media types aren't supported
+	Body json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+} // @name BatchOperation
+
+// BatchRequest is a list of requests that are run one after another
+type BatchRequest struct {
+	// The operations to run in order
+	Operations []*BatchOperation `json:"operations"`
+	// Whether to run all operations in a single database transaction. If an operation fails, the changes of all
+	// operations are rolled back and the remaining operations aren't run.
+	Atomic bool `json:"atomic" example:"true"`
+} // @name BatchRequest
+
+// Bind implements the the github.com/go-chi/render.Binder interface.
+// Only the routes of batchRoutes can be part of a batch request.
+func (b *BatchRequest) Bind(r *http.Request) error {
+	if len(b.Operations) == 0 {
+		return fmt.Errorf("operations must not be empty")
+	}
+	if len(b.Operations) > MaxBatchOperations {
+		return fmt.Errorf("a batch request can't have more than %d operations", MaxBatchOperations)
+	}
+	for i, operation := range b.Operations {
+		if operation == nil {
+			return fmt.Errorf("operation %d must not be empty", i)
+		}
+		operation.Method = strings.ToUpper(operation.Method)
+		if !batchMethods[operation.Method] {
+			return fmt.Errorf("method of operation %d must be one of GET, PUT, POST or DELETE", i)
+		}
+		path := strings.SplitN(operation.Path, "?", 2)[0]
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("path of operation %d must start with /", i)
+		}
+		if !batchable(path) {
+			return fmt.Errorf("operation %d can't request %s in a batch", i, path)
+		}
+		for name, value := range operation.Header {
+			if !strings.EqualFold(name, "Content-Type") {
+				continue
+			}
+			if mediaType, _, err := mime.ParseMediaType(value); err != nil || mediaType != "application/json" {
+				return fmt.Errorf("content type of operation %d must be application/json", i)
+			}
+		}
+	}
+	return nil
+}
+
+// BatchResult is the response to an operation of a batch request
+type BatchResult struct {
+	// The http status code of the response
+	Status int `json:"status" example:"200"`
+	// The body of the response, responses that aren't json are included as string
+	Body json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+} // @name BatchResult
+
+// BatchResponse contains the responses to the operations of a batch request
+type BatchResponse struct {
+	// The responses in the order of the operations
+	Results []*BatchResult `json:"results"`
+	// Whether the changes of the operations were rolled back, because an operation of an atomic batch request failed
+	RolledBack bool `json:"rolled_back,omitempty" example:"false"`
+} // @name BatchResponse
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (b *BatchResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
@@ -0,0 +1,117 @@
+package types
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+// MaxBulkSize is the maximum number of resources written or queried by a single bulk request
+const MaxBulkSize = 100
+
+// Articles is a list of articles written by a single bulk request
+type Articles []*Article
+
+// Render implements the github.com/go-chi/render.Renderer interface. It links the articles.
+func (a Articles) Render(w http.ResponseWriter, r *http.Request) error {
+	for _, article := range a {
+		if err := article.Render(w, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bind implements the the github.com/go-chi/render.Binder interface
+func (a Articles) Bind(r *http.Request) error {
+	if err := checkBulkSize(len(a)); err != nil {
+		return err
+	}
+	for i, article := range a {
+		if article == nil {
+			return fmt.Errorf("article %d must not be empty", i)
+		}
+		if err := article.Bind(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalXML implements the encoding/xml.Marshaler interface
+func (a Articles) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Items []*Article `xml:"article"`
+	}{a}, start)
+}
+
+// UnmarshalXML implements the encoding/xml.Unmarshaler interface
+func (a *Articles) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	list := struct {
+		Items []*Article `xml:"article"`
+	}{}
+	if err := d.DecodeElement(&list, &start); err != nil {
+		return err
+	}
+	*a = list.Items
+	return nil
+}
+
+// Orders is a list of orders written by a single bulk request
+type Orders []*Order
+
+// Render implements the github.com/go-chi/render.Renderer interface. It links the orders.
+func (o Orders) Render(w http.ResponseWriter, r *http.Request) error {
+	for _, order := range o {
+		if err := order.Render(w, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bind implements the the github.com/go-chi/render.Binder interface
+func (o Orders) Bind(r *http.Request) error {
+	if err := checkBulkSize(len(o)); err != nil {
+		return err
+	}
+	for i, order := range o {
+		if order == nil {
+			return fmt.Errorf("order %d must not be empty", i)
+		}
+		if err := order.Bind(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalXML implements the encoding/xml.Marshaler interface
+func (o Orders) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Items []*Order `xml:"order"`
+	}{o}, start)
+}
+
+// UnmarshalXML implements the encoding/xml.Unmarshaler interface
+func (o *Orders) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	list := struct {
+		Items []*Order `xml:"order"`
+	}{}
+	if err := d.DecodeElement(&list, &start); err != nil {
+		return err
+	}
+	*o = list.Items
+	return nil
+}
+
+// checkBulkSize returns an error if a bulk request doesn't contain between one and MaxBulkSize resources
+func checkBulkSize(n int) error {
+	if n == 0 {
+		return fmt.Errorf("the list must not be empty")
+	}
+	if n > MaxBulkSize {
+		return fmt.Errorf("a bulk request can't have more than %d items", MaxBulkSize)
+	}
+	return nil
+}
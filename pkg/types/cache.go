@@ -0,0 +1,26 @@
+package types
+
+import "net/http"
+
+// CacheStats are the statistics of the database cache since the start of the api
+type CacheStats struct {
+	// The number of lookups answered by the cache
+	Hits int64 `json:"hits" example:"90"`
+	// The number of lookups loaded from the database
+	Misses int64 `json:"misses" example:"10"`
+	// The number of lookups that waited for a concurrent load of the same entry instead of querying the database
+	Coalesced int64 `json:"coalesced" example:"3"`
+	// The share of lookups answered by the cache
+	HitRatio float64 `json:"hit_ratio" example:"0.9"`
+	// The number of entries currently held in process
+	Entries int `json:"entries" example:"42"`
+	// The number of entries dropped to make room for new ones
+	Evictions int64 `json:"evictions" example:"0"`
+	// The number of failed requests to the remote cache
+	RemoteErrors int64 `json:"remote_errors" example:"0"`
+} // @name CacheStats
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (c *CacheStats) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
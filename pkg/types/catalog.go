@@ -0,0 +1,124 @@
+package types
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrCategoryHasChildren is returned when a category that still has subcategories is deleted
+	ErrCategoryHasChildren = errors.New("category still has subcategories")
+	// ErrTagTaken is returned when the name of a tag is already used by another tag
+	ErrTagTaken = errors.New("name is already used by another tag")
+)
+
+// Category is a node of the category tree articles are sorted into
+type Category struct {
+	// The unique id of this category
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" xml:"id" example:"1"`
+	// The name of this category
+	Name string `gorm:"type:varchar;NOT NULL" json:"name" xml:"name" example:"Candy"`
+	// The id of the parent category, top level categories don't have one
+	ParentID *int `gorm:"type:integer;index" json:"parent_id,omitempty" xml:"parent_id,omitempty" example:"1"`
+} // @name Category
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (c *Category) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// Bind implements the the github.com/go-chi/render.Binder interface
+func (c *Category) Bind(r *http.Request) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return errors.New("name must not be empty")
+	}
+	if c.ParentID != nil && *c.ParentID == c.ID {
+		return errors.New("a category can't be its own parent")
+	}
+	return nil
+}
+
+// CategoryList contains a list of categories
+type CategoryList struct {
+	// A list of categories
+	Items []*Category `json:"items"`
+	// The id to query the next page
+	NextPageID int `json:"next_page_id,omitempty" example:"10"`
+} // @name CategoryList
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (c *CategoryList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// Tag is a free-form label of articles
+type Tag struct {
+	// The unique id of this tag
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" example:"1"`
+	// The name of this tag, which is unique across all tags
+	Name string `gorm:"type:varchar;NOT NULL;unique_index" json:"name" example:"vegan"`
+} // @name Tag
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (t *Tag) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// Bind implements the the github.com/go-chi/render.Binder interface.
+// The name is normalized to lower case, so that tags match regardless of their case.
+func (t *Tag) Bind(r *http.Request) error {
+	t.Name = NormalizeTag(t.Name)
+	if t.Name == "" {
+		return errors.New("name must not be empty")
+	}
+	return nil
+}
+
+// TagList contains a list of tags
+type TagList struct {
+	// A list of tags
+	Items []*Tag `json:"items"`
+	// The id to query the next page
+	NextPageID int `json:"next_page_id,omitempty" example:"10"`
+} // @name TagList
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (t *TagList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// ArticleCategory links an article to one of its categories
+type ArticleCategory struct {
+	ArticleID  int `gorm:"type:integer;PRIMARY_KEY;auto_increment:false"`
+	CategoryID int `gorm:"type:integer;PRIMARY_KEY;auto_increment:false;index"`
+}
+
+// ArticleTag links an article to one of its tags
+type ArticleTag struct {
+	ArticleID int `gorm:"type:integer;PRIMARY_KEY;auto_increment:false"`
+	TagID     int `gorm:"type:integer;PRIMARY_KEY;auto_increment:false;index"`
+}
+
+// NormalizeTags returns the tags in lower case without surrounding spaces, duplicates and empty tags, sorted by name
+func NormalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// NormalizeTag returns the tag in the form it is stored in
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+)
+
+const (
+	// AddressShipping is an address orders are shipped to
+	AddressShipping = "shipping"
+	// AddressBilling is an address invoices are sent to
+	AddressBilling = "billing"
+)
+
+var (
+	// ErrEmailTaken is returned when the email of a customer is already used by another customer
+	ErrEmailTaken = errors.New("email is already used by another customer")
+	// ErrCustomerHasOrders is returned when a customer that still owns orders is deleted
+	ErrCustomerHasOrders = errors.New("customer still has orders")
+)
+
+// Customer is an account that owns orders
+type Customer struct {
+	// The unique id of this customer
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" xml:"id" example:"1"`
+	// The full name of this customer
+	Name string `gorm:"type:varchar;NOT NULL" json:"name" xml:"name" example:"Jane Doe"`
+	// The email address of this customer, which is unique across all customers
+	Email string `gorm:"type:varchar;NOT NULL;unique_index" json:"email" xml:"email" example:"jane@example.com"`
+	// The addresses of this customer
+	Addresses []*Address `gorm:"foreignkey:CustomerID" json:"addresses,omitempty" xml:"address,omitempty"`
+} // @name Customer
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (c *Customer) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// Bind implements the the github.com/go-chi/render.Binder interface.
+// The email is normalized to lower case, so that uniqueness doesn't depend on its case.
+func (c *Customer) Bind(r *http.Request) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return errors.New("name must not be empty")
+	}
+	address, err := mail.ParseAddress(c.Email)
+	if err != nil || address.Address != strings.TrimSpace(c.Email) {
+		return fmt.Errorf("email %q is invalid", c.Email)
+	}
+	c.Email = strings.ToLower(address.Address)
+	for _, a := range c.Addresses {
+		if err := a.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Address is a postal address of a customer
+type Address struct {
+	// The unique id of this address
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" xml:"id" example:"1"`
+	// The id of the customer this address belongs to
+	CustomerID int `gorm:"type:integer;NOT NULL;index" json:"-" xml:"-"`
+	// What the address is used for
+	Kind string `gorm:"type:varchar;NOT NULL" json:"kind" xml:"kind" example:"shipping" enums:"shipping,billing"`
+	// The street and house number
+	Line1 string `gorm:"type:varchar;NOT NULL" json:"line1" xml:"line1" example:"1 Main St"`
+	// Additional address information
+	Line2 string `gorm:"type:varchar" json:"line2,omitempty" xml:"line2,omitempty" example:"Apt 2"`
+	// The postal code
+	PostalCode string `gorm:"type:varchar" json:"postal_code,omitempty" xml:"postal_code,omitempty" example:"94103"`
+	// The city
+	City string `gorm:"type:varchar;NOT NULL" json:"city" xml:"city" example:"San Francisco"`
+	// The ISO 3166-1 alpha-2 country code
+	Country string `gorm:"type:varchar(2);NOT NULL" json:"country" xml:"country" example:"US"`
+} // @name Address
+
+// validate checks that the required fields of the address are set
+func (a *Address) validate() error {
+	if a.Kind != AddressShipping && a.Kind != AddressBilling {
+		return fmt.Errorf("address kind must be %s or %s", AddressShipping, AddressBilling)
+	}
+	if a.Line1 == "" || a.City == "" {
+		return errors.New("addresses need a line1 and a city")
+	}
+	a.Country = strings.ToUpper(a.Country)
+	if len(a.Country) != 2 {
+		return fmt.Errorf("country %q must be an ISO 3166-1 alpha-2 code", a.Country)
+	}
+	return nil
+}
+
+// CustomerList contains a list of customers
+type CustomerList struct {
+	// A list of customers
+	Items []*Customer `json:"items"`
+	// The id to query the next page
+	NextPageID int `json:"next_page_id,omitempty" example:"10"`
+} // @name CustomerList
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (c *CustomerList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// OrderFilter restricts the orders of a list
+type OrderFilter struct {
+	// CustomerID selects orders of this customer
+	CustomerID *int
+}
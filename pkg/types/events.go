@@ -0,0 +1,274 @@
+package types
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// EventArticleCreated is emitted after a new article was written
+	EventArticleCreated = "article.created"
+	// EventArticleUpdated is emitted after an existing article was written
+	EventArticleUpdated = "article.updated"
+	// EventArticleDeleted is emitted after an article was deleted
+	EventArticleDeleted = "article.deleted"
+	// EventOrderCreated is emitted after a new order was written
+	EventOrderCreated = "order.created"
+	// EventOrderUpdated is emitted after an existing order was written
+	EventOrderUpdated = "order.updated"
+	// EventOrderDeleted is emitted after an order was deleted
+	EventOrderDeleted = "order.deleted"
+	// EventAll subscribes a webhook to all events
+	EventAll = "*"
+)
+
+const (
+	// DeliveryPending marks a delivery that is waiting for its next attempt
+	DeliveryPending = "pending"
+	// DeliveryDelivered marks a delivery that was acknowledged by the receiver
+	DeliveryDelivered = "delivered"
+	// DeliveryDead marks a delivery that failed too many times and won't be retried unless redelivered
+	DeliveryDead = "dead"
+)
+
+// eventTypes contains all event types webhooks can subscribe to
+var eventTypes = map[string]bool{
+	EventArticleCreated: true,
+	EventArticleUpdated: true,
+	EventArticleDeleted: true,
+	EventOrderCreated:   true,
+	EventOrderUpdated:   true,
+	EventOrderDeleted:   true,
+	EventAll:            true,
+}
+
+// Event is a change of a resource that is sent to subscribers
+type Event struct {
+	// The unique id of this event
+	ID string `json:"id" example:"9b2d4f7c1a0e3b5d"`
+	// The type of this event
+	Type string `json:"type" example:"article.created"`
+	// The time at which this event occurred
+	CreatedAt time.Time `json:"created_at" example:"2020-10-01T12:00:00Z"`
+	// The changed resource
+	Data interface{} `json:"data"`
+	// The position of this event in the change log, set once the event is published
+	Sequence int `json:"sequence,omitempty" example:"42"`
+} // @name Event
+
+// NewEvent returns an event of the given type with a random id
+func NewEvent(eventType string, data interface{}) *Event {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return &Event{
+		ID:        hex.EncodeToString(id),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+// DecodeEvent decodes a json encoded event. Its data is kept as raw json.
+func DecodeEvent(b []byte) (*Event, error) {
+	data := json.RawMessage{}
+	event := &Event{Data: &data}
+	if err := json.Unmarshal(b, event); err != nil {
+		return nil, err
+	}
+	event.Data = data
+	return event, nil
+}
+
+// OutboxEvent is an event that was written in the same transaction as the change it describes
+// and is waiting to be published to the sinks of the outbox relay
+type OutboxEvent struct {
+	// The sequence number of this event, which defines the publishing order
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id"`
+	// The id of the event
+	EventID string `gorm:"type:varchar;NOT NULL" json:"event_id"`
+	// The type of the event
+	Type string `gorm:"type:varchar;NOT NULL" json:"type"`
+	// The json encoded event
+	Payload string `gorm:"type:text;NOT NULL" json:"payload"`
+	// The time the event was written
+	CreatedAt time.Time `json:"created_at"`
+	// The names of the sinks the event was published to
+	PublishedTo pq.StringArray `gorm:"type:text[]" json:"published_to"`
+	// The time the event was published to all sinks, unpublished events have none
+	PublishedAt *time.Time `gorm:"index" json:"published_at,omitempty"`
+}
+
+// NewOutboxEvent returns an unpublished outbox event for the event
+func NewOutboxEvent(event *Event) (*OutboxEvent, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return &OutboxEvent{
+		EventID:   event.ID,
+		Type:      event.Type,
+		Payload:   string(payload),
+		CreatedAt: event.CreatedAt,
+	}, nil
+}
+
+// Event decodes the event of the outbox event and sets its sequence. Its data is kept as raw json.
+func (o *OutboxEvent) Event() (*Event, error) {
+	event, err := DecodeEvent([]byte(o.Payload))
+	if err != nil {
+		return nil, err
+	}
+	event.Sequence = o.ID
+	return event, nil
+}
+
+// IsPublishedTo reports whether the event was already published to the sink
+func (o *OutboxEvent) IsPublishedTo(sink string) bool {
+	for _, name := range o.PublishedTo {
+		if name == sink {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook is a subscription of an url to events
+type Webhook struct {
+	// The unique id of this webhook
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" example:"1"`
+	// The url events are posted to
+	URL string `gorm:"type:varchar;NOT NULL" json:"url" example:"https://example.com/hooks"`
+	// The event types this webhook is subscribed to, * subscribes to all events
+	Events pq.StringArray `gorm:"type:text[];NOT NULL" json:"events" swaggertype:"array,string" example:"article.created,order.created"`
+	// The secret used to sign the payloads. It is never returned by the api.
+	Secret string `gorm:"type:varchar;NOT NULL" json:"secret,omitempty" example:"s3cr3t"`
+} // @name Webhook
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (wh *Webhook) Render(w http.ResponseWriter, r *http.Request) error {
+	wh.Secret = ""
+	return nil
+}
+
+// Bind implements the the github.com/go-chi/render.Binder interface
+func (wh *Webhook) Bind(r *http.Request) error {
+	u, err := url.Parse(wh.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https url")
+	}
+	// host names resolving to such addresses are refused when the deliveries are sent
+	if ip := net.ParseIP(u.Hostname()); (ip != nil && !PublicIP(ip)) || strings.EqualFold(u.Hostname(), "localhost") {
+		return fmt.Errorf("url must not point to a loopback, private or link-local address")
+	}
+	if len(wh.Events) == 0 {
+		return fmt.Errorf("events must not be empty")
+	}
+	for _, event := range wh.Events {
+		if !eventTypes[event] {
+			return fmt.Errorf("unknown event type %q", event)
+		}
+	}
+	if wh.Secret == "" {
+		return fmt.Errorf("secret must not be empty")
+	}
+	return nil
+}
+
+// nonPublicNetworks are the private and shared networks webhooks must not point to, as they aren't on the internet
+var nonPublicNetworks = parseNetworks("0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7")
+
+// PublicIP reports whether the ip address is on the internet rather than a loopback, private or link-local address
+// that may belong to internal services
+func PublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// WebhookList contains a list of webhooks
+type WebhookList struct {
+	// A list of webhooks
+	Items []*Webhook `json:"items"`
+	// The id to query the next page
+	NextPageID int `json:"next_page_id,omitempty" example:"10"`
+} // @name WebhookList
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (wl *WebhookList) Render(w http.ResponseWriter, r *http.Request) error {
+	for _, wh := range wl.Items {
+		wh.Secret = ""
+	}
+	return nil
+}
+
+// WebhookDelivery is the delivery of a single event to a webhook
+type WebhookDelivery struct {
+	// The unique id of this delivery
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" example:"1"`
+	// The id of the webhook this delivery is sent to
+	WebhookID int `gorm:"type:integer;NOT NULL;index" json:"webhook_id" example:"1"`
+	// The type of the delivered event
+	EventType string `gorm:"type:varchar;NOT NULL" json:"event_type" example:"article.created"`
+	// The json encoded event that is posted to the webhook
+	Payload string `gorm:"type:text;NOT NULL" json:"payload" example:"{\"type\":\"article.created\"}"`
+	// The state of this delivery
+	Status string `gorm:"type:varchar;NOT NULL;index" json:"status" example:"pending" enums:"pending,delivered,dead"`
+	// The number of failed attempts
+	Attempts int `gorm:"type:integer;NOT NULL" json:"attempts" example:"0"`
+	// The http status code of the last attempt
+	ResponseStatus int `gorm:"type:integer" json:"response_status,omitempty" example:"200"`
+	// The error of the last failed attempt
+	LastError string `gorm:"type:text" json:"last_error,omitempty" example:"unexpected status code 500"`
+	// The time of the next attempt
+	NextAttemptAt time.Time `gorm:"index" json:"next_attempt_at" example:"2020-10-01T12:00:00Z"`
+	// The time this delivery was created
+	CreatedAt time.Time `json:"created_at" example:"2020-10-01T12:00:00Z"`
+	// The time this delivery was acknowledged by the receiver
+	DeliveredAt *time.Time `json:"delivered_at,omitempty" example:"2020-10-01T12:00:00Z"`
+} // @name WebhookDelivery
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (d *WebhookDelivery) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// WebhookDeliveryList contains a list of webhook deliveries
+type WebhookDeliveryList struct {
+	// A list of webhook deliveries
+	Items []*WebhookDelivery `json:"items"`
+	// The id to query the next page
+	NextPageID int `json:"next_page_id,omitempty" example:"10"`
+} // @name WebhookDeliveryList
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (dl *WebhookDeliveryList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
@@ -0,0 +1,123 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MaxExpandDepth is the maximum number of relations an expanded path may follow, such as items.article
+const MaxExpandDepth = 2
+
+// Relations are the related resources that can be embedded into a resource by their field name,
+// together with the relations of the embedded resources
+type Relations map[string]Relations
+
+var (
+	// ArticleRelations are the relations that can be expanded in articles
+	ArticleRelations = Relations{"categories": nil}
+	// OrderRelations are the relations that can be expanded in orders
+	OrderRelations = Relations{
+		"customer": nil,
+		"items": Relations{
+			"article": ArticleRelations,
+			"variant": nil,
+		},
+	}
+)
+
+// Selection selects the fields of resources in a response and the related resources embedded into them
+type Selection struct {
+	// Fields are the json names of the selected fields, all fields are selected if it is nil.
+	// The id and the fields of expanded relations are always selected.
+	Fields []string
+	// Expand are the paths of the embedded relations, such as items.article
+	Expand []string
+}
+
+// NewSelection returns the selection of the fields and the expanded relation paths of the resource. Fields must be
+// json names of the resource and paths must follow the relations up to MaxExpandDepth.
+func NewSelection(resource interface{}, relations Relations, fields, expand []string) (*Selection, error) {
+	s := &Selection{}
+	names := FieldNames(resource)
+	for _, field := range fields {
+		if !contains(names, field) {
+			return nil, fmt.Errorf("%s isn't a field, use one of %s", field, strings.Join(names, ", "))
+		}
+	}
+
+	for _, path := range expand {
+		segments := strings.Split(path, ".")
+		if len(segments) > MaxExpandDepth {
+			return nil, fmt.Errorf("%s can't be expanded, relations can only be expanded %d levels deep", path, MaxExpandDepth)
+		}
+		r := relations
+		for _, segment := range segments {
+			next, ok := r[segment]
+			if !ok {
+				return nil, fmt.Errorf("%s can't be expanded, %s isn't a relation", path, segment)
+			}
+			r = next
+		}
+		s.Expand = append(s.Expand, path)
+	}
+
+	if fields != nil {
+		s.Fields = []string{"id"}
+		for _, field := range fields {
+			s.Fields = appendUnique(s.Fields, field)
+		}
+		for _, path := range s.Expand {
+			s.Fields = appendUnique(s.Fields, strings.SplitN(path, ".", 2)[0])
+		}
+	}
+	return s, nil
+}
+
+// Expands returns the paths expanded below the relation, relative to it. It returns nil if the relation isn't
+// expanded and an empty list if it is expanded without any of its own relations.
+func (s *Selection) Expands(relation string) []string {
+	var paths []string
+	for _, path := range s.Expand {
+		switch {
+		case path == relation:
+			if paths == nil {
+				paths = []string{}
+			}
+		case strings.HasPrefix(path, relation+"."):
+			paths = append(paths, strings.TrimPrefix(path, relation+"."))
+		}
+	}
+	return paths
+}
+
+// FieldNames returns the json names of the fields of the resource, except for its links
+func FieldNames(resource interface{}) []string {
+	t := reflect.TypeOf(resource)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	names := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		if name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; name != "" && name != "-" && name != LinksField {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(values []string, value string) []string {
+	if contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
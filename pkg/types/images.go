@@ -0,0 +1,57 @@
+package types
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ArticleImage is a product image of an article. The image and its thumbnail are kept in the blob storage.
+type ArticleImage struct {
+	// The unique id of this image
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" xml:"id" example:"1"`
+	// The id of the article shown in the image
+	ArticleID int `gorm:"type:integer;NOT NULL;index" json:"article_id" xml:"article_id" example:"1"`
+	// The content type sniffed from the uploaded file
+	ContentType string `gorm:"type:varchar;NOT NULL" json:"content_type" xml:"content_type" example:"image/png"`
+	// The size of the image in bytes
+	Size int `gorm:"type:integer;NOT NULL" json:"size" xml:"size" example:"48213"`
+	// The width of the image in pixels
+	Width int `gorm:"type:integer;NOT NULL" json:"width" xml:"width" example:"800"`
+	// The height of the image in pixels
+	Height int `gorm:"type:integer;NOT NULL" json:"height" xml:"height" example:"600"`
+	// The key of the image in the blob storage
+	Key string `gorm:"type:varchar;NOT NULL" json:"-" xml:"-"`
+	// The key of the thumbnail in the blob storage
+	ThumbnailKey string `gorm:"type:varchar;NOT NULL" json:"-" xml:"-"`
+	// The content type of the thumbnail
+	ThumbnailContentType string `gorm:"type:varchar;NOT NULL" json:"-" xml:"-"`
+	// The url to download the image from
+	URL string `gorm:"-" json:"url" xml:"url" example:"/articles/1/images/1"`
+	// The url to download the thumbnail from
+	ThumbnailURL string `gorm:"-" json:"thumbnail_url" xml:"thumbnail_url" example:"/articles/1/images/1/thumbnail"`
+	// The time the image was uploaded
+	CreatedAt time.Time `json:"created_at" xml:"created_at" example:"2020-10-01T12:00:00Z"`
+} // @name ArticleImage
+
+// SetURLs sets the download urls of the image and its thumbnail, which are served by this api
+func (i *ArticleImage) SetURLs() {
+	i.URL = fmt.Sprintf("/articles/%d/images/%d", i.ArticleID, i.ID)
+	i.ThumbnailURL = i.URL + "/thumbnail"
+}
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (i *ArticleImage) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// ArticleImageList contains a list of article images
+type ArticleImageList struct {
+	// A list of images
+	Items []*ArticleImage `json:"items"`
+} // @name ArticleImageList
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (i *ArticleImageList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
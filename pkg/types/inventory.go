@@ -0,0 +1,136 @@
+package types
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// StockReservation is recorded when an order reserves stock
+	StockReservation = "reservation"
+	// StockRelease is recorded when a canceled order releases its reserved stock
+	StockRelease = "release"
+	// StockRestock is recorded when new stock arrives
+	StockRestock = "restock"
+	// StockCorrection is recorded when a stock count is corrected
+	StockCorrection = "correction"
+	// StockDamage is recorded when stock is written off because it was damaged or lost
+	StockDamage = "damage"
+	// StockReturn is recorded when returned stock is put back on sale
+	StockReturn = "return"
+)
+
+// manualStockReasons contains the reasons of adjustments that can be recorded through the api,
+// reservations and releases are only recorded by orders
+var manualStockReasons = map[string]bool{
+	StockRestock:    true,
+	StockCorrection: true,
+	StockDamage:     true,
+	StockReturn:     true,
+}
+
+// Stock is the inventory of an article
+type Stock struct {
+	// The id of the article
+	ArticleID int `json:"article_id" example:"1"`
+	// The id of the variant of the article
+	VariantID int `json:"variant_id,omitempty" example:"1"`
+	// Whether the stock of the article is tracked. Articles without tracked stock can always be ordered.
+	Tracked bool `json:"tracked" example:"true"`
+	// The quantity that can still be ordered, only set for tracked articles
+	Available *int `json:"available,omitempty" example:"42"`
+	// The quantity reserved by orders
+	Reserved int `json:"reserved" example:"3"`
+} // @name Stock
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (s *Stock) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// StockAdjustment is an entry of the inventory ledger of an article
+type StockAdjustment struct {
+	// The unique id of this adjustment
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" example:"1"`
+	// The id of the adjusted article
+	ArticleID int `gorm:"type:integer;NOT NULL;index" json:"article_id" example:"1"`
+	// The id of the adjusted variant, the stock of the article itself is adjusted if it is empty
+	VariantID int `gorm:"type:integer" json:"variant_id,omitempty" example:"1"`
+	// The change of the available quantity
+	Delta int `gorm:"type:integer;NOT NULL" json:"delta" example:"10"`
+	// The reason of this adjustment
+	Reason string `gorm:"type:varchar;NOT NULL" json:"reason" example:"restock" enums:"reservation,release,restock,correction,damage,return"`
+	// The id of the order that reserved or released the stock
+	OrderID int `gorm:"type:integer" json:"order_id,omitempty" example:"1"`
+	// A free text note on this adjustment
+	Note string `gorm:"type:text" json:"note,omitempty" example:"delivery 2020-10-01"`
+	// The time of this adjustment
+	CreatedAt time.Time `json:"created_at" example:"2020-10-01T12:00:00Z"`
+} // @name StockAdjustment
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (s *StockAdjustment) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// Bind implements the the github.com/go-chi/render.Binder interface
+func (s *StockAdjustment) Bind(r *http.Request) error {
+	if !manualStockReasons[s.Reason] {
+		return fmt.Errorf("reason must be one of restock, correction, damage or return")
+	}
+	if s.Delta == 0 {
+		return fmt.Errorf("delta must not be 0")
+	}
+	return nil
+}
+
+// StockAdjustmentList contains a list of stock adjustments
+type StockAdjustmentList struct {
+	// A list of stock adjustments
+	Items []*StockAdjustment `json:"items"`
+	// The id to query the next page
+	NextPageID int `json:"next_page_id,omitempty" example:"10"`
+} // @name StockAdjustmentList
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (s *StockAdjustmentList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// InsufficientStockError is returned when an article doesn't have enough stock for a change
+type InsufficientStockError struct {
+	ArticleID int
+	VariantID int
+	Requested int
+	Available int
+}
+
+// Error implements the error interface
+func (e *InsufficientStockError) Error() string {
+	if e.VariantID != 0 {
+		return fmt.Sprintf("insufficient stock of variant %d of article %d: requested %d, available %d",
+			e.VariantID, e.ArticleID, e.Requested, e.Available)
+	}
+	return fmt.Sprintf("insufficient stock of article %d: requested %d, available %d", e.ArticleID, e.Requested, e.Available)
+}
+
+// ArticleFilter restricts the articles of a list
+type ArticleFilter struct {
+	// LowStock selects tracked articles with at most this available quantity
+	LowStock *int
+	// CategoryID selects articles of this category or one of its descendants
+	CategoryID *int
+	// Tag selects articles with this tag
+	Tag string
+}
+
+// StockLevel returns the inventory of the article
+func (a *Article) StockLevel() *Stock {
+	return &Stock{
+		ArticleID: a.ID,
+		Tracked:   a.Stock != nil,
+		Available: a.Stock,
+		Reserved:  a.Reserved,
+	}
+}
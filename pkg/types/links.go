@@ -0,0 +1,102 @@
+package types
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// LinksField is the json name of the links of resources, which are part of every representation of a resource
+// rather than a field that can be selected
+const LinksField = "_links"
+
+// Link is a hypermedia link to a resource of this api
+type Link struct {
+	// The absolute url of the linked resource
+	Href string `json:"href" example:"https://example.com/articles/1"`
+} // @name Link
+
+// Links are the hypermedia links of a resource by their relation, such as self, next or prev
+type Links map[string]*Link
+
+// xmlLink is a single link in the xml representation of links
+type xmlLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+// MarshalXML implements the encoding/xml.Marshaler interface. Links are encoded as link elements with a relation,
+// sorted by relation.
+func (l Links) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	rels := make([]string, 0, len(l))
+	for rel := range l {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+	list := struct {
+		Links []xmlLink `xml:"link"`
+	}{}
+	for _, rel := range rels {
+		list.Links = append(list.Links, xmlLink{Rel: rel, Href: l[rel].Href})
+	}
+	return e.EncodeElement(list, start)
+}
+
+// UnmarshalXML implements the encoding/xml.Unmarshaler interface
+func (l *Links) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	list := struct {
+		Links []xmlLink `xml:"link"`
+	}{}
+	if err := d.DecodeElement(&list, &start); err != nil {
+		return err
+	}
+	*l = Links{}
+	for _, link := range list.Links {
+		(*l)[link.Rel] = &Link{Href: link.Href}
+	}
+	return nil
+}
+
+// BaseURL returns the scheme and host the request was sent to, such as https://example.com. Requests through trusted
+// proxies carry the scheme and host of the client in their url and host.
+func BaseURL(r *http.Request) string {
+	scheme := r.URL.Scheme
+	if scheme == "" {
+		scheme = "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+	}
+	return scheme + "://" + r.Host
+}
+
+// pageLinks returns the self link of a page of a list and the links to the next and previous page, if there are
+// any. Pages are selected by the page_id of the url query, which is dropped for the first page.
+func pageLinks(r *http.Request, nextPageID, prevPageID int) Links {
+	base := BaseURL(r)
+	links := Links{"self": {Href: base + r.URL.RequestURI()}}
+	page := func(pageID int) *Link {
+		u := *r.URL
+		query := u.Query()
+		query.Del("page_id")
+		if pageID != 0 {
+			query.Set("page_id", strconv.Itoa(pageID))
+		}
+		u.RawQuery = query.Encode()
+		return &Link{Href: base + u.RequestURI()}
+	}
+	if nextPageID != 0 {
+		links["next"] = page(nextPageID)
+	}
+	if pageID, _ := strconv.Atoi(r.URL.Query().Get("page_id")); pageID != 0 {
+		links["prev"] = page(prevPageID)
+	}
+	return links
+}
+
+// link returns the link to the resource at the path formatted with the arguments
+func link(r *http.Request, format string, a ...interface{}) *Link {
+	return &Link{Href: BaseURL(r) + fmt.Sprintf(format, a...)}
+}
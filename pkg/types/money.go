@@ -0,0 +1,266 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency of amounts that are sent without one, such as legacy plain number prices
+var DefaultCurrency = "USD"
+
+// currencyExponents contains the number of minor unit digits of the supported ISO 4217 currencies
+var currencyExponents = map[string]int{
+	"AUD": 2, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2, "CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2,
+	"INR": 2, "MXN": 2, "NOK": 2, "NZD": 2, "PLN": 2, "SEK": 2, "SGD": 2, "USD": 2, "ZAR": 2,
+	"CLP": 0, "ISK": 0, "JPY": 0, "KRW": 0, "VND": 0,
+	"BHD": 3, "JOD": 3, "KWD": 3, "OMR": 3, "TND": 3,
+}
+
+// decimalPattern matches the plain decimal notation accepted for amounts
+var decimalPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// CurrencyExponent returns the number of minor unit digits of the ISO 4217 currency and whether it is supported
+func CurrencyExponent(currency string) (int, bool) {
+	exp, ok := currencyExponents[currency]
+	return exp, ok
+}
+
+// Money is an exact amount of a currency, stored as an integer number of minor units such as cents.
+// Amounts are never represented as floats: they are parsed from and formatted to decimal strings,
+// and arithmetic that produces fractions of a minor unit rounds half to even.
+// The zero value has no currency and represents a missing amount.
+type Money struct {
+	// The amount in minor units of the currency
+	Amount int64 `gorm:"type:bigint;NOT NULL;default:0" json:"amount" swaggertype:"string" example:"1.99"`
+	// The ISO 4217 code of the currency
+	Currency string `gorm:"type:varchar(3)" json:"currency" example:"USD"`
+} // @name Money
+
+// NewMoney returns the amount of minor units of the currency
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney parses a decimal amount of the currency, such as 1.99. Amounts with more decimals than
+// the currency has minor units are rejected instead of rounded, unless the extra decimals are zeros.
+func ParseMoney(amount string, currency string) (Money, error) {
+	exp, ok := CurrencyExponent(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("unsupported currency %q", currency)
+	}
+	if !decimalPattern.MatchString(amount) {
+		return Money{}, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	whole, frac := amount, ""
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		whole, frac = amount[:i], amount[i+1:]
+	}
+	if len(frac) > exp {
+		if strings.Trim(frac[exp:], "0") != "" {
+			return Money{}, fmt.Errorf("amount %s has more decimals than %s allows", amount, currency)
+		}
+		frac = frac[:exp]
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("amount %s is out of range", amount)
+	}
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+// IsZero reports whether the money is the zero value without a currency
+func (m Money) IsZero() bool {
+	return m.Amount == 0 && m.Currency == ""
+}
+
+// Decimal formats the amount in decimal notation with all minor unit digits of the currency, such as 1.90
+func (m Money) Decimal() string {
+	exp, _ := CurrencyExponent(m.Currency)
+	sign := ""
+	abs := new(big.Int).SetInt64(m.Amount)
+	if m.Amount < 0 {
+		sign = "-"
+		abs.Neg(abs)
+	}
+	digits := abs.String()
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// String implements the fmt.Stringer interface
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// Add returns the sum of both amounts, which must have the same currency. The zero value can be added to any amount.
+func (m Money) Add(o Money) (Money, error) {
+	switch {
+	case m.IsZero():
+		return o, nil
+	case o.IsZero():
+		return m, nil
+	case m.Currency != o.Currency:
+		return Money{}, fmt.Errorf("can't add %s to %s", o.Currency, m.Currency)
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns the difference of both amounts, which must have the same currency
+func (m Money) Sub(o Money) (Money, error) {
+	return m.Add(Money{Amount: -o.Amount, Currency: o.Currency})
+}
+
+// Mul returns the amount multiplied by the quantity
+func (m Money) Mul(quantity int64) Money {
+	return Money{Amount: m.Amount * quantity, Currency: m.Currency}
+}
+
+// Scale returns the amount multiplied by num/den, rounded half to even to whole minor units.
+// It is used for fractions of amounts, such as percentage discounts or taxes.
+func (m Money) Scale(num, den int64) Money {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num))
+	d := big.NewInt(den)
+	if den < 0 {
+		product.Neg(product)
+		d.Neg(d)
+	}
+	q, r := new(big.Int).QuoRem(product, d, new(big.Int))
+
+	// compare twice the remainder with the denominator to decide the rounding direction
+	twice := new(big.Int).Abs(r)
+	twice.Lsh(twice, 1)
+	if c := twice.Cmp(d); c > 0 || (c == 0 && q.Bit(0) == 1) {
+		if product.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return Money{Amount: q.Int64(), Currency: m.Currency}
+}
+
+// Cmp compares both amounts, which must have the same currency, and returns -1, 0 or +1
+func (m Money) Cmp(o Money) (int, error) {
+	if m.Currency != o.Currency {
+		return 0, fmt.Errorf("can't compare %s to %s", o.Currency, m.Currency)
+	}
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. The amount is a decimal string, so that clients
+// don't lose precision by parsing it into a float. The zero value is encoded as null.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if m.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(&struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{
+		Amount:   m.Decimal(),
+		Currency: m.Currency,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Besides objects with a decimal string or number amount,
+// plain numbers and strings are accepted as amounts of the DefaultCurrency. Numbers are parsed from their exact
+// textual representation and never converted to floats.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*m = Money{}
+		return nil
+	}
+
+	raw := struct {
+		Amount   json.RawMessage `json:"amount"`
+		Currency string          `json:"currency"`
+	}{}
+	if len(data) > 0 && data[0] == '{' {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	} else {
+		raw.Amount = data
+	}
+	if raw.Currency == "" {
+		raw.Currency = DefaultCurrency
+	}
+
+	amount := string(bytes.TrimSpace(raw.Amount))
+	if strings.HasPrefix(amount, `"`) {
+		if err := json.Unmarshal(raw.Amount, &amount); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseMoney(amount, raw.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// xmlMoney is the xml representation of an amount
+type xmlMoney struct {
+	Amount   string `xml:"amount"`
+	Currency string `xml:"currency"`
+}
+
+// MarshalXML implements the encoding/xml.Marshaler interface. The amount is a decimal string like in json.
+// The zero value is omitted.
+func (m Money) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if m.IsZero() {
+		return nil
+	}
+	return e.EncodeElement(xmlMoney{Amount: m.Decimal(), Currency: m.Currency}, start)
+}
+
+// UnmarshalXML implements the encoding/xml.Unmarshaler interface. Besides amount and currency elements, a plain
+// decimal is accepted as amount of the DefaultCurrency, like plain strings in json.
+func (m *Money) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	raw := struct {
+		xmlMoney
+		Text string `xml:",chardata"`
+	}{}
+	if err := d.DecodeElement(&raw, &start); err != nil {
+		return err
+	}
+	amount, currency := strings.TrimSpace(raw.Amount), strings.TrimSpace(raw.Currency)
+	if amount == "" {
+		amount = strings.TrimSpace(raw.Text)
+	}
+	if amount == "" && currency == "" {
+		*m = Money{}
+		return nil
+	}
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	parsed, err := ParseMoney(amount, currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
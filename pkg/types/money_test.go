@@ -0,0 +1,137 @@
+package types
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	testcases := map[string]struct {
+		amount   string
+		currency string
+		want     Money
+		wantErr  bool
+	}{
+		"cents":            {amount: "1.99", currency: "USD", want: NewMoney(199, "USD")},
+		"whole":            {amount: "2", currency: "USD", want: NewMoney(200, "USD")},
+		"trailing zeros":   {amount: "1.500", currency: "EUR", want: NewMoney(150, "EUR")},
+		"negative":         {amount: "-0.05", currency: "USD", want: NewMoney(-5, "USD")},
+		"no minor units":   {amount: "500", currency: "JPY", want: NewMoney(500, "JPY")},
+		"too many digits":  {amount: "1.999", currency: "USD", wantErr: true},
+		"invalid":          {amount: "1e2", currency: "USD", wantErr: true},
+		"unknown currency": {amount: "1", currency: "XXX", wantErr: true},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseMoney(test.amount, test.currency)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestMoney_Decimal(t *testing.T) {
+	assert.Equal(t, "1.99", NewMoney(199, "USD").Decimal())
+	assert.Equal(t, "0.05", NewMoney(5, "USD").Decimal())
+	assert.Equal(t, "-0.05", NewMoney(-5, "USD").Decimal())
+	assert.Equal(t, "1.000", NewMoney(1000, "KWD").Decimal())
+	assert.Equal(t, "500", NewMoney(500, "JPY").Decimal())
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	sum, err := NewMoney(199, "USD").Add(NewMoney(1, "USD"))
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(200, "USD"), sum)
+
+	sum, err = Money{}.Add(NewMoney(1, "USD"))
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(1, "USD"), sum)
+
+	_, err = NewMoney(1, "USD").Add(NewMoney(1, "EUR"))
+	assert.Error(t, err)
+
+	assert.Equal(t, NewMoney(597, "USD"), NewMoney(199, "USD").Mul(3))
+
+	// half to even
+	assert.Equal(t, NewMoney(2, "USD"), NewMoney(25, "USD").Scale(1, 10))
+	assert.Equal(t, NewMoney(4, "USD"), NewMoney(35, "USD").Scale(1, 10))
+	assert.Equal(t, NewMoney(-2, "USD"), NewMoney(-25, "USD").Scale(1, 10))
+	assert.Equal(t, NewMoney(33, "USD"), NewMoney(100, "USD").Scale(1, 3))
+
+	c, err := NewMoney(199, "USD").Cmp(NewMoney(200, "USD"))
+	assert.NoError(t, err)
+	assert.Equal(t, -1, c)
+}
+
+func TestMoney_JSON(t *testing.T) {
+	b, err := json.Marshal(NewMoney(199, "USD"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"amount":"1.99","currency":"USD"}`, string(b))
+
+	b, err = json.Marshal(Money{})
+	assert.NoError(t, err)
+	assert.Equal(t, `null`, string(b))
+
+	testcases := map[string]Money{
+		`{"amount":"1.99","currency":"EUR"}`: NewMoney(199, "EUR"),
+		`{"amount":1.99,"currency":"EUR"}`:   NewMoney(199, "EUR"),
+		`{"amount":"1.99"}`:                  NewMoney(199, DefaultCurrency),
+		`1.99`:                               NewMoney(199, DefaultCurrency),
+		`"1.99"`:                             NewMoney(199, DefaultCurrency),
+		`null`:                               {},
+	}
+	for data, want := range testcases {
+		got := Money{}
+		assert.NoError(t, json.Unmarshal([]byte(data), &got), data)
+		assert.Equal(t, want, got, data)
+	}
+
+	assert.Error(t, json.Unmarshal([]byte(`0.001`), &Money{}))
+}
+
+func TestMoney_XML(t *testing.T) {
+	article := struct {
+		XMLName xml.Name `xml:"article"`
+		Price   Money    `xml:"price"`
+	}{Price: NewMoney(199, "USD")}
+	b, err := xml.Marshal(article)
+	assert.NoError(t, err)
+	assert.Equal(t, `<article><price><amount>1.99</amount><currency>USD</currency></price></article>`, string(b))
+
+	article.Price = Money{}
+	b, err = xml.Marshal(article)
+	assert.NoError(t, err)
+	assert.Equal(t, `<article></article>`, string(b))
+
+	testcases := map[string]Money{
+		`<article><price><amount>1.99</amount><currency>EUR</currency></price></article>`: NewMoney(199, "EUR"),
+		`<article><price><amount>1.99</amount></price></article>`:                         NewMoney(199, DefaultCurrency),
+		`<article><price> 1.99 </price></article>`:                                        NewMoney(199, DefaultCurrency),
+		`<article><price></price></article>`:                                              {},
+	}
+	for data, want := range testcases {
+		article.Price = Money{}
+		assert.NoError(t, xml.Unmarshal([]byte(data), &article), data)
+		assert.Equal(t, want, article.Price, data)
+	}
+
+	assert.Error(t, xml.Unmarshal([]byte(`<article><price>0.001</price></article>`), &article))
+}
+
+func TestMoney_UnmarshalJSON_keepsInput(t *testing.T) {
+	data := json.RawMessage(`{"price":{"amount":"1.99","currency":"USD"}}`)
+	want := string(data)
+	article := &struct {
+		Price Money `json:"price"`
+	}{}
+	assert.NoError(t, json.Unmarshal(data, article))
+	assert.Equal(t, want, string(data))
+}
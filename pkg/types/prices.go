@@ -0,0 +1,58 @@
+package types
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ArticlePrice is the price of an article during a period of time. The prices of an article form a timeline
+// without gaps: every price is effective until the next one takes effect.
+type ArticlePrice struct {
+	// The unique id of this price
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" example:"1"`
+	// The id of the article
+	ArticleID int `gorm:"type:integer;NOT NULL;index" json:"article_id" example:"1"`
+	// The price of the article
+	Price Money `gorm:"embedded;embedded_prefix:price_" json:"price"`
+	// The time this price takes effect
+	EffectiveFrom time.Time `gorm:"NOT NULL;index" json:"effective_from" example:"2020-10-01T12:00:00Z"`
+	// The time the next price takes effect, empty for the latest price
+	EffectiveTo *time.Time `json:"effective_to,omitempty" example:"2020-11-01T00:00:00Z"`
+	// Whether the price was applied to the article, scheduled prices are applied once they take effect
+	Applied bool `gorm:"NOT NULL;default:false" json:"applied" example:"true"`
+	// The time this price was recorded
+	CreatedAt time.Time `json:"created_at" example:"2020-10-01T12:00:00Z"`
+} // @name ArticlePrice
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (p *ArticlePrice) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// Bind implements the the github.com/go-chi/render.Binder interface
+func (p *ArticlePrice) Bind(r *http.Request) error {
+	if p.Price.IsZero() {
+		return errors.New("price is required")
+	}
+	if p.Price.Amount < 0 {
+		return errors.New("price must not be negative")
+	}
+	if !p.EffectiveFrom.After(time.Now()) {
+		return errors.New("effective_from must be in the future")
+	}
+	return nil
+}
+
+// ArticlePriceList contains a list of article prices
+type ArticlePriceList struct {
+	// A list of article prices
+	Items []*ArticlePrice `json:"items"`
+	// The id to query the next page
+	NextPageID int `json:"next_page_id,omitempty" example:"10"`
+} // @name ArticlePriceList
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (p *ArticlePriceList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
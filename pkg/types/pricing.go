@@ -0,0 +1,150 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// CouponPercentage coupons take a percentage off the subtotal
+	CouponPercentage = "percentage"
+	// CouponFixed coupons take a fixed amount off the subtotal
+	CouponFixed = "fixed"
+)
+
+// ErrCouponCodeTaken is returned when the code of a coupon is already used by another coupon
+var ErrCouponCodeTaken = errors.New("code is already used by another coupon")
+
+// Coupon is a discount that can be applied to orders by its code
+type Coupon struct {
+	// The unique id of this coupon
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" example:"1"`
+	// The code customers enter to apply the coupon, which is unique across all coupons
+	Code string `gorm:"type:varchar;NOT NULL;unique_index" json:"code" example:"SAVE10"`
+	// Whether the coupon takes a percentage or a fixed amount off
+	Kind string `gorm:"type:varchar;NOT NULL" json:"kind" example:"percentage" enums:"percentage,fixed"`
+	// The percentage taken off by percentage coupons
+	PercentOff int `gorm:"type:integer;NOT NULL;default:0" json:"percent_off,omitempty" example:"10"`
+	// The amount taken off by fixed coupons, which only apply to orders of the same currency
+	AmountOff Money `gorm:"embedded;embedded_prefix:amount_off_" json:"amount_off,omitempty"`
+	// The coupon can't be applied before this time
+	ValidFrom *time.Time `json:"valid_from,omitempty" example:"2020-10-01T00:00:00Z"`
+	// The coupon can't be applied after this time
+	ValidUntil *time.Time `json:"valid_until,omitempty" example:"2020-10-31T23:59:59Z"`
+	// The number of orders the coupon can be applied to, unlimited if it is empty
+	MaxUses *int `gorm:"type:integer" json:"max_uses,omitempty" example:"100"`
+	// The number of orders the coupon was applied to
+	Uses int `gorm:"type:integer;NOT NULL;default:0" json:"uses" example:"3"`
+} // @name Coupon
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (c *Coupon) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// Bind implements the the github.com/go-chi/render.Binder interface.
+// The code is normalized to upper case, so that customers can enter it in any case.
+func (c *Coupon) Bind(r *http.Request) error {
+	c.Code = NormalizeCouponCode(c.Code)
+	if c.Code == "" {
+		return errors.New("code must not be empty")
+	}
+	switch c.Kind {
+	case CouponPercentage:
+		if c.PercentOff <= 0 || c.PercentOff > 100 {
+			return errors.New("percent_off must be between 1 and 100")
+		}
+		c.AmountOff = Money{}
+	case CouponFixed:
+		if c.AmountOff.IsZero() || c.AmountOff.Amount <= 0 {
+			return errors.New("amount_off must be positive")
+		}
+		c.PercentOff = 0
+	default:
+		return fmt.Errorf("kind must be %s or %s", CouponPercentage, CouponFixed)
+	}
+	if c.ValidFrom != nil && c.ValidUntil != nil && c.ValidUntil.Before(*c.ValidFrom) {
+		return errors.New("valid_until must not be before valid_from")
+	}
+	if c.MaxUses != nil && *c.MaxUses < 0 {
+		return errors.New("max_uses must not be negative")
+	}
+	return nil
+}
+
+// NormalizeCouponCode returns the code in the form it is stored in
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CouponList contains a list of coupons
+type CouponList struct {
+	// A list of coupons
+	Items []*Coupon `json:"items"`
+	// The id to query the next page
+	NextPageID int `json:"next_page_id,omitempty" example:"10"`
+} // @name CouponList
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (c *CouponList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// CouponError is returned when a coupon can't be applied to an order
+type CouponError struct {
+	Code   string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *CouponError) Error() string {
+	return fmt.Sprintf("coupon %s can't be applied: %s", e.Code, e.Reason)
+}
+
+// Quote is the calculated price of an order
+type Quote struct {
+	// The priced items of the order
+	Items []*QuoteItem `json:"items"`
+	// The region the tax was calculated for
+	Region string `json:"region,omitempty" example:"US-CA"`
+	// The code of the applied coupon
+	CouponCode string `json:"coupon_code,omitempty" example:"SAVE10"`
+	// The sum of all items before discounts and tax
+	Subtotal Money `json:"subtotal"`
+	// The sum of all discounts
+	Discount Money `json:"discount"`
+	// The sum of all taxes
+	Tax Money `json:"tax"`
+	// The amount to be paid, which is the subtotal minus the discount plus the tax
+	Total Money `json:"total"`
+} // @name Quote
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (q *Quote) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// QuoteItem is a priced item of an order
+type QuoteItem struct {
+	// The id of the article
+	ArticleID int `json:"article_id" example:"1"`
+	// The id of the variant of the article
+	VariantID int `json:"variant_id,omitempty" example:"1"`
+	// The quantity of the article
+	Quantity int `json:"quantity" example:"2"`
+	// The price of a single unit of the article
+	UnitPrice Money `json:"unit_price"`
+	// The unit price times the quantity
+	Subtotal Money `json:"subtotal"`
+	// The share of the coupon discount of this item
+	Discount Money `json:"discount"`
+	// The tax rate in percent applied to the discounted subtotal
+	TaxRate string `json:"tax_rate" example:"7.25"`
+	// The tax of this item
+	Tax Money `json:"tax"`
+	// The discounted subtotal plus the tax
+	Total Money `json:"total"`
+} // @name QuoteItem
@@ -0,0 +1,31 @@
+package types
+
+import "net/http"
+
+// ArticleSearchResult is an article matching a search query
+type ArticleSearchResult struct {
+	// The matching article
+	Article *Article `json:"article"`
+	// The relevance of the article, results are ordered by descending rank
+	Rank float64 `json:"rank" example:"0.66"`
+	// The name of the article with the matching words enclosed in <mark> tags
+	Highlight string `json:"highlight" example:"<mark>Skittles</mark>"`
+} // @name ArticleSearchResult
+
+// ArticleSearchResultList contains a page of search results
+type ArticleSearchResultList struct {
+	// A list of search results
+	Items []*ArticleSearchResult `json:"items"`
+	// The id to query the next page, which is the number of results on the previous pages
+	NextPageID int `json:"next_page_id,omitempty" example:"10"`
+} // @name ArticleSearchResultList
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (a *ArticleSearchResultList) Render(w http.ResponseWriter, r *http.Request) error {
+	for _, result := range a.Items {
+		if err := result.Article.Render(w, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
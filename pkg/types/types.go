@@ -1,7 +1,9 @@
 package types
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/render"
@@ -10,75 +12,246 @@ import (
 // Article is one instance of an article
 type Article struct {
 	// The unique id of this item
-	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" example:"1"`
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" xml:"id" example:"1"`
 	// The name of this item
-	Name string `gorm:"type:varchar;NOT NULL" json:"name" example:"Skittles"`
+	Name string `gorm:"type:varchar;NOT NULL" json:"name" xml:"name" example:"Skittles"`
 	// The price of this item
-	Price float64 `gorm:"type:decimal;NOT NULL" json:"price" example:"1.99"`
+	Price Money `gorm:"embedded;embedded_prefix:price_" json:"price" xml:"price"`
+	// The tax category of this item, which selects the tax rates that apply to it
+	TaxCategory string `gorm:"type:varchar" json:"tax_category,omitempty" xml:"tax_category,omitempty" example:"food"`
+	// The ids of the categories of this item. Omit them to keep the current categories.
+	CategoryIDs []int `gorm:"-" json:"category_ids,omitempty" xml:"category_id,omitempty" example:"1,2"`
+	// The tags of this item. Omit them to keep the current tags.
+	Tags []string `gorm:"-" json:"tags,omitempty" xml:"tag,omitempty" example:"vegan,sweet"`
+	// The variants of this item, such as sizes or flavours
+	Variants []*Variant `gorm:"-" json:"variants,omitempty" xml:"variant,omitempty"`
+	// The product images of this item
+	Images []*ArticleImage `gorm:"-" json:"images,omitempty" xml:"image,omitempty"`
+	// The categories of this item, which are only returned if they are expanded
+	Categories []*Category `gorm:"-" graphql:"-" json:"categories,omitempty" xml:"category,omitempty"`
+	// The quantity that can still be ordered, stock isn't tracked if it is empty
+	Stock *int `gorm:"type:integer" json:"-" xml:"-"`
+	// The quantity reserved by orders
+	Reserved int `gorm:"type:integer;NOT NULL;default:0" json:"-" xml:"-"`
+	// The links to this item and its related resources, which are set when it is returned
+	Links Links `gorm:"-" graphql:"-" json:"_links,omitempty" xml:"links,omitempty"`
 } // @name Article
 
-// Render implements the github.com/go-chi/render.Renderer interface
+// Render implements the github.com/go-chi/render.Renderer interface. It links the article and its related resources.
 func (a *Article) Render(w http.ResponseWriter, r *http.Request) error {
+	// articles that aren't stored yet have no links
+	if a.ID == 0 {
+		return nil
+	}
+	a.Links = Links{
+		"self":     link(r, "/articles/%d", a.ID),
+		"images":   link(r, "/articles/%d/images", a.ID),
+		"variants": link(r, "/articles/%d/variants", a.ID),
+		"stock":    link(r, "/articles/%d/stock", a.ID),
+		"prices":   link(r, "/articles/%d/prices", a.ID),
+	}
 	return nil
 }
 
 // Bind implements the the github.com/go-chi/render.Binder interface
 func (a *Article) Bind(r *http.Request) error {
+	if a.Tags != nil {
+		a.Tags = NormalizeTags(a.Tags)
+	}
 	return nil
 }
 
+// CSVHeader returns the column names used when exporting articles as csv
+func (a *Article) CSVHeader() []string {
+	return []string{"id", "name", "price", "currency"}
+}
+
+// CSVRecord returns the article as a csv record matching CSVHeader
+func (a *Article) CSVRecord() []string {
+	return []string{
+		strconv.Itoa(a.ID),
+		a.Name,
+		a.Price.Decimal(),
+		a.Price.Currency,
+	}
+}
+
 // ArticleList contains a list of articles
 type ArticleList struct {
 	// A list of articles
-	Items []*Article `json:"items"`
+	Items []*Article `json:"items" xml:"items>article"`
 	// The id to query the next page
-	NextPageID int `json:"next_page_id,omitempty" example:"10"`
+	NextPageID int `json:"next_page_id,omitempty" xml:"next_page_id,omitempty" example:"10"`
+	// The id to query the previous page, which is empty for the first page
+	PrevPageID int `json:"prev_page_id,omitempty" xml:"prev_page_id,omitempty" example:"1"`
+	// The links to this page and to the next and previous page
+	Links Links `json:"_links,omitempty" xml:"links,omitempty"`
 } // @name ArticleList
 
-// Render implements the github.com/go-chi/render.Renderer interface
+// Render implements the github.com/go-chi/render.Renderer interface. It links the page and its articles.
 func (a *ArticleList) Render(w http.ResponseWriter, r *http.Request) error {
+	a.Links = pageLinks(r, a.NextPageID, a.PrevPageID)
+	for _, article := range a.Items {
+		if err := article.Render(w, r); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// CSVRecords returns the articles of the list as csv records preceded by the header
+func (a *ArticleList) CSVRecords() [][]string {
+	records := [][]string{(&Article{}).CSVHeader()}
+	for _, article := range a.Items {
+		records = append(records, article.CSVRecord())
+	}
+	return records
+}
+
 // Order is one instance of an order
 type Order struct {
 	// The unique id of this order
-	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" example:"1"`
-	// DateTime is the date and time of this order
-	DateTime time.Time `gorm:"timestamp" json:"lastUpdated,omitempty" example:"0001-01-01 00:00:00+00"`
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" xml:"id" example:"1"`
+	// DateTime is the date and time of this order, which is set when it is created
+	DateTime time.Time `gorm:"timestamp" json:"lastUpdated,omitempty" xml:"lastUpdated" example:"0001-01-01 00:00:00+00"`
+	// The id of the customer who owns this order
+	CustomerID *int `gorm:"type:integer;index" json:"customer_id,omitempty" xml:"customer_id,omitempty" example:"1"`
+	// The customer who owns this order, which is only returned if it is expanded
+	Customer *Customer `gorm:"-" graphql:"-" json:"customer,omitempty" xml:"customer,omitempty"`
+	// The items of this order
+	Items []*OrderItem `gorm:"foreignkey:OrderID" json:"items,omitempty" xml:"item,omitempty"`
+	// The region the order is shipped to, which selects the tax rates
+	Region string `gorm:"type:varchar" json:"region,omitempty" xml:"region,omitempty" example:"US-CA"`
+	// The code of the coupon applied to this order
+	CouponCode string `gorm:"type:varchar;index" json:"coupon_code,omitempty" xml:"coupon_code,omitempty" example:"SAVE10"`
+	// The price of all items before discounts and tax, calculated when the order is written
+	Subtotal Money `gorm:"embedded;embedded_prefix:subtotal_" json:"subtotal" xml:"subtotal"`
+	// The discount of the coupon, calculated when the order is written
+	Discount Money `gorm:"embedded;embedded_prefix:discount_" json:"discount" xml:"discount"`
+	// The tax of all items, calculated when the order is written
+	Tax Money `gorm:"embedded;embedded_prefix:tax_" json:"tax" xml:"tax"`
+	// The amount to be paid, calculated when the order is written
+	Total Money `gorm:"embedded;embedded_prefix:total_" json:"total" xml:"total"`
+	// The links to this order and its related resources, which are set when it is returned
+	Links Links `gorm:"-" graphql:"-" json:"_links,omitempty" xml:"links,omitempty"`
 } // @name Order
 
-// Render implements the github.com/go-chi/render.Renderer interface
+// Render implements the github.com/go-chi/render.Renderer interface. It links the order, its items and their
+// related resources.
 func (o *Order) Render(w http.ResponseWriter, r *http.Request) error {
+	o.Links = Links{}
+	// orders that aren't stored yet, such as quotes, have no link to themselves
+	if o.ID != 0 {
+		o.Links["self"] = link(r, "/orders/%d", o.ID)
+	}
+	if o.CustomerID != nil {
+		o.Links["customer"] = link(r, "/customers/%d", *o.CustomerID)
+	}
+	for _, item := range o.Items {
+		item.Links = Links{"article": link(r, "/articles/%d", item.ArticleID)}
+		if item.VariantID != 0 {
+			item.Links["variant"] = link(r, "/articles/%d/variants/%d", item.ArticleID, item.VariantID)
+		}
+		if item.Article != nil {
+			if err := item.Article.Render(w, r); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
 // Bind implements the the github.com/go-chi/render.Binder interface
 func (o *Order) Bind(r *http.Request) error {
+	o.CouponCode = NormalizeCouponCode(o.CouponCode)
+	for _, item := range o.Items {
+		if item.ArticleID == 0 && item.VariantID == 0 {
+			return fmt.Errorf("items must reference an article or a variant")
+		}
+		if item.Quantity <= 0 {
+			return fmt.Errorf("quantity of article %d must be positive", item.ArticleID)
+		}
+	}
 	return nil
 }
 
+// CSVHeader returns the column names used when exporting orders as csv
+func (o *Order) CSVHeader() []string {
+	return []string{"id", "lastUpdated", "total", "currency"}
+}
+
+// CSVRecord returns the order as a csv record matching CSVHeader
+func (o *Order) CSVRecord() []string {
+	return []string{
+		strconv.Itoa(o.ID),
+		o.DateTime.Format(time.RFC3339),
+		o.Total.Decimal(),
+		o.Total.Currency,
+	}
+}
+
+// OrderItem is one line item of an order
+type OrderItem struct {
+	// The unique id of this order item
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" xml:"id" example:"1"`
+	// The id of the order this item belongs to
+	OrderID int `gorm:"type:integer;NOT NULL;index" json:"-" xml:"-"`
+	// The id of the ordered article, which is taken from the variant if it is empty
+	ArticleID int `gorm:"type:integer;NOT NULL" json:"article_id" xml:"article_id" example:"1"`
+	// The id of the ordered variant of the article
+	VariantID int `gorm:"type:integer" json:"variant_id,omitempty" xml:"variant_id,omitempty" example:"1"`
+	// The ordered quantity of the article
+	Quantity int `gorm:"type:integer;NOT NULL" json:"quantity" xml:"quantity" example:"2"`
+	// The price of a single unit of the article at the time of the order
+	UnitPrice Money `gorm:"embedded;embedded_prefix:unit_price_" json:"unit_price" xml:"unit_price"`
+	// The ordered article, which is only returned if it is expanded
+	Article *Article `gorm:"-" graphql:"-" json:"article,omitempty" xml:"article,omitempty"`
+	// The ordered variant, which is only returned if it is expanded
+	Variant *Variant `gorm:"-" graphql:"-" json:"variant,omitempty" xml:"variant,omitempty"`
+	// The links to the related resources of this item, which are set when its order is returned
+	Links Links `gorm:"-" graphql:"-" json:"_links,omitempty" xml:"links,omitempty"`
+} // @name OrderItem
+
 // OrderList contains a list of orders
 type OrderList struct {
 	// A list of orders
-	Items []*Order `json:"items"`
+	Items []*Order `json:"items" xml:"items>order"`
 	// The id to query the next page
-	NextPageID int `json:"next_page_id,omitempty" example:"10"`
+	NextPageID int `json:"next_page_id,omitempty" xml:"next_page_id,omitempty" example:"10"`
+	// The id to query the previous page, which is empty for the first page
+	PrevPageID int `json:"prev_page_id,omitempty" xml:"prev_page_id,omitempty" example:"1"`
+	// The links to this page and to the next and previous page
+	Links Links `json:"_links,omitempty" xml:"links,omitempty"`
 } // @name OrderList
 
-// Render implements the github.com/go-chi/render.Renderer interface
+// Render implements the github.com/go-chi/render.Renderer interface. It links the page and its orders.
 func (o *OrderList) Render(w http.ResponseWriter, r *http.Request) error {
+	o.Links = pageLinks(r, o.NextPageID, o.PrevPageID)
+	for _, order := range o.Items {
+		if err := order.Render(w, r); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// CSVRecords returns the orders of the list as csv records preceded by the header
+func (o *OrderList) CSVRecords() [][]string {
+	records := [][]string{(&Order{}).CSVHeader()}
+	for _, order := range o.Items {
+		records = append(records, order.CSVRecord())
+	}
+	return records
+}
+
 // ErrResponse renderer type for handling all sorts of errors.
 type ErrResponse struct {
-	Err            error `json:"-"` // low-level runtime error
-	HTTPStatusCode int   `json:"-"` // http response status code
+	Err            error `json:"-" xml:"-"` // low-level runtime error
+	HTTPStatusCode int   `json:"-" xml:"-"` // http response status code
 
-	StatusText string `json:"status" example:"Resource not found."`                                         // user-level status message
-	AppCode    int64  `json:"code,omitempty" example:"404"`                                                 // application-specific error code
-	ErrorText  string `json:"error,omitempty" example:"The requested resource was not found on the server"` // application-level error message, for debugging
+	StatusText string `json:"status" xml:"status" example:"Resource not found."`                                                  // user-level status message
+	AppCode    int64  `json:"code,omitempty" xml:"code,omitempty" example:"404"`                                                  // application-specific error code
+	ErrorText  string `json:"error,omitempty" xml:"error,omitempty" example:"The requested resource was not found on the server"` // application-level error message, for debugging
 } // @name ErrorResponse
 
 // Render implements the github.com/go-chi/render.Renderer interface for ErrResponse
@@ -114,3 +287,61 @@ func ErrNotFound() render.Renderer {
 		StatusText:     "Resource not found.",
 	}
 }
+
+// ErrUnavailable returns a structured http response if a dependency of the request is unavailable
+func ErrUnavailable(err error) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusServiceUnavailable,
+		StatusText:     "Service unavailable.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// ErrUnauthorized returns a structured http response if a request couldn't be authenticated
+func ErrUnauthorized() render.Renderer {
+	return &ErrResponse{
+		HTTPStatusCode: http.StatusUnauthorized,
+		StatusText:     "Unauthorized.",
+	}
+}
+
+// ErrTooLarge returns a structured http response if the body of a request exceeds its size limit
+func ErrTooLarge(err error) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusRequestEntityTooLarge,
+		StatusText:     "Request entity too large.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// ErrUnsupportedMediaType returns a structured http response if the content of a request has an unsupported type
+func ErrUnsupportedMediaType(err error) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusUnsupportedMediaType,
+		StatusText:     "Unsupported media type.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// ErrNotAcceptable returns a structured http response if none of the media types a request accepts can be returned
+func ErrNotAcceptable(err error) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusNotAcceptable,
+		StatusText:     "Not acceptable.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// ErrConflict returns a structured http response if a request conflicts with the current state of a resource
+func ErrConflict(err error) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusConflict,
+		StatusText:     "Conflict.",
+		ErrorText:      err.Error(),
+	}
+}
@@ -0,0 +1,150 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrSKUTaken is returned when the SKU of a variant is already used by another variant
+var ErrSKUTaken = errors.New("sku is already used by another variant")
+
+// skuPattern matches the characters SKUs may consist of
+var skuPattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9._-]*$`)
+
+// Attributes are the properties that distinguish the variants of an article, such as its size or flavour
+type Attributes map[string]string
+
+// Value implements the database/sql/driver.Valuer interface
+func (a Attributes) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+// Scan implements the database/sql.Scanner interface
+func (a *Attributes) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(src, a)
+	case string:
+		return json.Unmarshal([]byte(src), a)
+	default:
+		return fmt.Errorf("can't scan %T into attributes", src)
+	}
+}
+
+// xmlAttribute is a single attribute in the xml representation of attributes
+type xmlAttribute struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// MarshalXML implements the encoding/xml.Marshaler interface. Attributes are encoded as attribute elements
+// with a name, sorted by name.
+func (a Attributes) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := struct {
+		Attributes []xmlAttribute `xml:"attribute"`
+	}{}
+	for _, name := range names {
+		list.Attributes = append(list.Attributes, xmlAttribute{Name: name, Value: a[name]})
+	}
+	return e.EncodeElement(list, start)
+}
+
+// UnmarshalXML implements the encoding/xml.Unmarshaler interface
+func (a *Attributes) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	list := struct {
+		Attributes []xmlAttribute `xml:"attribute"`
+	}{}
+	if err := d.DecodeElement(&list, &start); err != nil {
+		return err
+	}
+	*a = Attributes{}
+	for _, attribute := range list.Attributes {
+		(*a)[attribute.Name] = attribute.Value
+	}
+	return nil
+}
+
+// Variant is a sellable variant of an article, such as a size or flavour, with its own stock keeping unit
+type Variant struct {
+	// The unique id of this variant
+	ID int `gorm:"type:SERIAL;PRIMARY_KEY" json:"id" xml:"id" example:"1"`
+	// The id of the article this is a variant of
+	ArticleID int `gorm:"type:integer;NOT NULL;index" json:"article_id" xml:"article_id" example:"1"`
+	// The stock keeping unit of this variant, which is unique across all variants
+	SKU string `gorm:"column:sku;type:varchar;NOT NULL;unique_index" json:"sku" xml:"sku" example:"SKITTLES-SOUR-100G"`
+	// The properties that distinguish this variant from the other variants of the article
+	Attributes Attributes `gorm:"type:jsonb;NOT NULL;default:'{}'" json:"attributes,omitempty" xml:"attributes,omitempty" swaggertype:"object,string"`
+	// The price of this variant, the price of the article applies if it is empty
+	Price Money `gorm:"embedded;embedded_prefix:price_" json:"price" xml:"price"`
+	// The article this is a variant of, only included when the variant is looked up by its SKU
+	Article *Article `gorm:"-" json:"article,omitempty" xml:"article,omitempty"`
+	// The quantity that can still be ordered, stock isn't tracked if it is empty
+	Stock *int `gorm:"type:integer" json:"-" xml:"-"`
+	// The quantity reserved by orders
+	Reserved int `gorm:"type:integer;NOT NULL;default:0" json:"-" xml:"-"`
+} // @name Variant
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (v *Variant) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// Bind implements the the github.com/go-chi/render.Binder interface.
+// The SKU is normalized to upper case, so that it can be looked up in any case.
+func (v *Variant) Bind(r *http.Request) error {
+	v.SKU = NormalizeSKU(v.SKU)
+	if !skuPattern.MatchString(v.SKU) {
+		return errors.New("sku must consist of letters, digits, dots, dashes and underscores")
+	}
+	if !v.Price.IsZero() && v.Price.Amount <= 0 {
+		return errors.New("price must be positive")
+	}
+	v.Article = nil
+	return nil
+}
+
+// NormalizeSKU returns the SKU in the form it is stored in
+func NormalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
+
+// StockLevel returns the inventory of the variant
+func (v *Variant) StockLevel() *Stock {
+	return &Stock{
+		ArticleID: v.ArticleID,
+		VariantID: v.ID,
+		Tracked:   v.Stock != nil,
+		Available: v.Stock,
+		Reserved:  v.Reserved,
+	}
+}
+
+// VariantList contains a list of variants
+type VariantList struct {
+	// A list of variants
+	Items []*Variant `json:"items"`
+} // @name VariantList
+
+// Render implements the github.com/go-chi/render.Renderer interface
+func (v *VariantList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
@@ -0,0 +1,253 @@
+// Package webhook delivers events to the webhooks subscribed to them
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jonnylangefeld/go-api/pkg/db"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+const (
+	// EventHeader contains the type of the delivered event
+	EventHeader = "X-Webhook-Event"
+	// DeliveryHeader contains the id of the delivery, which stays the same across retries
+	DeliveryHeader = "X-Webhook-Delivery"
+	// SignatureHeader contains the hex encoded HMAC-SHA256 of the payload, prefixed with sha256=
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// Dispatcher stores a delivery for every webhook that is subscribed to an emitted event and
+// delivers them in the background, retrying failed deliveries with exponential backoff
+type Dispatcher struct {
+	// MaxAttempts is the number of failed attempts after which a delivery is marked as dead
+	MaxAttempts int
+	// Backoff is the delay after the first failed attempt, which doubles with every further attempt
+	Backoff time.Duration
+	// MaxBackoff caps the delay between two attempts
+	MaxBackoff time.Duration
+	// Interval is the time between two polls for due deliveries
+	Interval time.Duration
+	// BatchSize is the maximum number of deliveries sent per poll
+	BatchSize int
+
+	db     db.ClientInterface
+	client *http.Client
+	log    *zap.Logger
+	notify chan struct{}
+	now    func() time.Time
+}
+
+// NewDispatcher returns a dispatcher with sensible defaults
+func NewDispatcher(dbClient db.ClientInterface, log *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		MaxAttempts: 8,
+		Backoff:     30 * time.Second,
+		MaxBackoff:  6 * time.Hour,
+		Interval:    5 * time.Second,
+		BatchSize:   50,
+		db:          dbClient,
+		client:      newClient(10 * time.Second),
+		log:         log,
+		notify:      make(chan struct{}, 1),
+		now:         time.Now,
+	}
+}
+
+// newClient returns the http client deliveries are sent with. It only connects to public addresses, so that
+// webhooks can't be used to reach internal services, even if their host names resolve to internal addresses.
+func newClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: publicOnly}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+		},
+	}
+}
+
+// publicOnly refuses connections to loopback, private and link-local addresses
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !types.PublicIP(ip) {
+		return fmt.Errorf("refusing to connect to non-public address %s", host)
+	}
+	return nil
+}
+
+// Emit stores a pending delivery of the event for every subscribed webhook and wakes up the delivery loop
+func (d *Dispatcher) Emit(event *types.Event) error {
+	webhooks := d.db.GetWebhooksForEvent(event.Type)
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	now := d.now()
+	for _, webhook := range webhooks {
+		delivery := &types.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			Status:        types.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+		if err := d.db.SetWebhookDelivery(delivery); err != nil {
+			d.log.Error("couldn't store webhook delivery", zap.Int("webhookId", webhook.ID), zap.String("event", event.Type), zap.Error(err))
+			return err
+		}
+	}
+
+	select {
+	case d.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run delivers due deliveries until the context is canceled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		// keep going without waiting while there are more due deliveries than fit in one batch
+		if full := d.deliverDue(ctx) == d.BatchSize; full && ctx.Err() == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.notify:
+		}
+	}
+}
+
+// deliverDue sends all due deliveries of one batch and returns how many were sent
+func (d *Dispatcher) deliverDue(ctx context.Context) int {
+	deliveries := d.db.GetDueWebhookDeliveries(d.now(), d.BatchSize)
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return 0
+		}
+		d.deliver(ctx, delivery)
+		if err := d.db.SetWebhookDelivery(delivery); err != nil {
+			d.log.Error("couldn't update webhook delivery", zap.Int("deliveryId", delivery.ID), zap.Error(err))
+		}
+	}
+	return len(deliveries)
+}
+
+// deliver posts the delivery to its webhook and updates its state according to the result
+func (d *Dispatcher) deliver(ctx context.Context, delivery *types.WebhookDelivery) {
+	webhook := d.db.GetWebhookByID(delivery.WebhookID)
+	if webhook == nil {
+		delivery.Status = types.DeliveryDead
+		delivery.LastError = "webhook doesn't exist anymore"
+		return
+	}
+
+	status, err := d.post(ctx, webhook, delivery)
+	delivery.ResponseStatus = status
+	if err == nil {
+		now := d.now()
+		delivery.Status = types.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.Attempts++
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.MaxAttempts {
+		delivery.Status = types.DeliveryDead
+		d.log.Warn("webhook delivery is dead", zap.Int("deliveryId", delivery.ID), zap.Int("webhookId", webhook.ID), zap.Error(err))
+		return
+	}
+	delivery.NextAttemptAt = d.now().Add(d.backoff(delivery.Attempts))
+}
+
+// post sends the payload to the webhook and returns the response status code
+func (d *Dispatcher) post(ctx context.Context, webhook *types.Webhook, delivery *types.WebhookDelivery) (int, error) {
+	payload := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.Itoa(delivery.ID))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt after the given number of failed attempts
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.Backoff
+	for i := 1; i < attempts && delay < d.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.MaxBackoff {
+		return d.MaxBackoff
+	}
+	return delay
+}
+
+// Redeliver resets a delivery so that it is sent again with the next poll
+func Redeliver(delivery *types.WebhookDelivery) {
+	delivery.Status = types.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.DeliveredAt = nil
+}
+
+// Sign returns the signature of the payload as sent in the SignatureHeader
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature matches the payload, for use by receivers of webhooks
+func Verify(secret string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, payload)), []byte(signature))
+}
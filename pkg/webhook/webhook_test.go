@@ -0,0 +1,148 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/jonnylangefeld/go-api/pkg/api/mocks"
+	"github.com/jonnylangefeld/go-api/pkg/types"
+)
+
+var testNow = time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestDispatcher(t *testing.T) (*Dispatcher, *mocks.MockClientInterface) {
+	dbClient := mocks.NewMockClientInterface(gomock.NewController(t))
+	d := NewDispatcher(dbClient, zap.NewNop())
+	d.MaxAttempts = 3
+	d.Backoff = time.Minute
+	d.MaxBackoff = time.Hour
+	d.now = func() time.Time {
+		return testNow
+	}
+	// the test servers listen on the loopback interface, which the client of the dispatcher refuses
+	d.client = &http.Client{Timeout: time.Second}
+	return d, dbClient
+}
+
+func TestDispatcher_Emit(t *testing.T) {
+	d, dbClient := newTestDispatcher(t)
+	dbClient.EXPECT().GetWebhooksForEvent(gomock.Eq(types.EventArticleCreated)).Return([]*types.Webhook{{ID: 1}, {ID: 2}})
+
+	var got []*types.WebhookDelivery
+	dbClient.EXPECT().SetWebhookDelivery(gomock.Any()).DoAndReturn(func(delivery *types.WebhookDelivery) error {
+		got = append(got, delivery)
+		return nil
+	}).Times(2)
+
+	event := &types.Event{ID: "1", Type: types.EventArticleCreated, CreatedAt: testNow, Data: &types.Article{ID: 1, Name: "Skittles", Price: types.NewMoney(199, "USD")}}
+	assert.NoError(t, d.Emit(event))
+	assert.Equal(t, 2, len(got))
+	assert.Equal(t, 2, got[1].WebhookID)
+	assert.Equal(t, types.DeliveryPending, got[1].Status)
+	assert.Equal(t, testNow, got[1].NextAttemptAt)
+	assert.Equal(t, `{"id":"1","type":"article.created","created_at":"2020-10-01T12:00:00Z","data":{"id":1,"name":"Skittles","price":{"amount":"1.99","currency":"USD"}}}`, got[1].Payload)
+}
+
+func TestDispatcher_deliver(t *testing.T) {
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if !Verify("secret", body, r.Header.Get(SignatureHeader)) || r.Header.Get(EventHeader) != types.EventOrderCreated {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	testcases := map[string]struct {
+		status       int
+		attempts     int
+		wantStatus   string
+		wantAttempts int
+		wantNext     time.Time
+	}{
+		"delivered": {
+			status:     http.StatusNoContent,
+			wantStatus: types.DeliveryDelivered,
+		},
+		"first failure": {
+			status:       http.StatusInternalServerError,
+			wantStatus:   types.DeliveryPending,
+			wantAttempts: 1,
+			wantNext:     testNow.Add(time.Minute),
+		},
+		"second failure": {
+			status:       http.StatusInternalServerError,
+			attempts:     1,
+			wantStatus:   types.DeliveryPending,
+			wantAttempts: 2,
+			wantNext:     testNow.Add(2 * time.Minute),
+		},
+		"dead": {
+			status:       http.StatusInternalServerError,
+			attempts:     2,
+			wantStatus:   types.DeliveryDead,
+			wantAttempts: 3,
+			wantNext:     testNow,
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			d, dbClient := newTestDispatcher(t)
+			dbClient.EXPECT().GetWebhookByID(gomock.Eq(1)).Return(&types.Webhook{ID: 1, URL: ts.URL, Secret: "secret"})
+
+			status = test.status
+			delivery := &types.WebhookDelivery{
+				ID:            1,
+				WebhookID:     1,
+				EventType:     types.EventOrderCreated,
+				Payload:       `{"type":"order.created"}`,
+				Status:        types.DeliveryPending,
+				Attempts:      test.attempts,
+				NextAttemptAt: testNow,
+			}
+			d.deliver(context.Background(), delivery)
+
+			assert.Equal(t, test.wantStatus, delivery.Status)
+			assert.Equal(t, test.wantAttempts, delivery.Attempts)
+			assert.Equal(t, test.status, delivery.ResponseStatus)
+			if test.wantStatus != types.DeliveryDelivered {
+				assert.Equal(t, test.wantNext, delivery.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestDispatcher_backoff(t *testing.T) {
+	d, _ := newTestDispatcher(t)
+	assert.Equal(t, time.Minute, d.backoff(1))
+	assert.Equal(t, 4*time.Minute, d.backoff(3))
+	assert.Equal(t, time.Hour, d.backoff(10))
+}
+
+func TestDispatcher_publicOnly(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	d, dbClient := newTestDispatcher(t)
+	d.client = newClient(time.Second)
+	dbClient.EXPECT().GetWebhookByID(gomock.Eq(1)).Return(&types.Webhook{ID: 1, URL: ts.URL, Secret: "secret"})
+
+	delivery := &types.WebhookDelivery{ID: 1, WebhookID: 1, EventType: types.EventOrderCreated, Status: types.DeliveryPending}
+	d.deliver(context.Background(), delivery)
+	assert.Equal(t, types.DeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Contains(t, delivery.LastError, "refusing to connect to non-public address 127.0.0.1")
+}
@@ -0,0 +1,421 @@
+// Package websocket implements the server and client side of the WebSocket protocol as defined in RFC 6455
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// The message types defined in RFC 6455, section 11.8
+const (
+	continuationFrame = 0
+	// TextMessage denotes a utf-8 encoded text message
+	TextMessage = 1
+	// BinaryMessage denotes a binary message
+	BinaryMessage = 2
+	// CloseMessage denotes a close control message
+	CloseMessage = 8
+	// PingMessage denotes a ping control message
+	PingMessage = 9
+	// PongMessage denotes a pong control message
+	PongMessage = 10
+)
+
+// The close codes defined in RFC 6455, section 11.7
+const (
+	CloseNormalClosure    = 1000
+	CloseGoingAway        = 1001
+	CloseProtocolError    = 1002
+	CloseNoStatusReceived = 1005
+	CloseInvalidPayload   = 1007
+	ClosePolicyViolation  = 1008
+	CloseMessageTooBig    = 1009
+)
+
+const (
+	// acceptGUID is appended to the key of the handshake to compute the accept header
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// maxControlPayload is the maximum payload size of control frames
+	maxControlPayload = 125
+)
+
+// DefaultMaxMessageSize is the maximum size of a received message, unless configured otherwise
+const DefaultMaxMessageSize = 64 * 1024
+
+// ErrMessageTooBig is returned when a received message exceeds the maximum message size
+var ErrMessageTooBig = errors.New("websocket: message too big")
+
+// CloseError is returned when the peer closed the connection
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+// Error implements the error interface
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed with code %d %s", e.Code, e.Reason)
+}
+
+// Conn is a WebSocket connection. ReadMessage must only be called from one goroutine at a time,
+// while writes are safe to be called concurrently.
+type Conn struct {
+	// MaxMessageSize is the maximum size of a received message
+	MaxMessageSize int
+	// WriteTimeout is the maximum duration of a single write, no timeout is applied if it is 0
+	WriteTimeout time.Duration
+
+	conn   net.Conn
+	br     *bufio.Reader
+	client bool
+
+	writeMu     sync.Mutex
+	closeOnce   sync.Once
+	pongHandler func(data []byte)
+}
+
+func newConn(conn net.Conn, br *bufio.Reader, client bool) *Conn {
+	return &Conn{
+		MaxMessageSize: DefaultMaxMessageSize,
+		conn:           conn,
+		br:             br,
+		client:         client,
+	}
+}
+
+// Upgrade upgrades the http connection to the WebSocket protocol.
+// If the upgrade fails, Upgrade replies to the client with an http error and returns the error.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if err := checkHandshake(r); err != nil {
+		if r.Header.Get("Sec-WebSocket-Version") != "13" {
+			w.Header().Set("Sec-WebSocket-Version", "13")
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, err
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		err := errors.New("websocket: response writer doesn't support hijacking")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, err
+	}
+	netConn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	if rw.Reader.Buffered() > 0 {
+		_ = netConn.Close()
+		return nil, errors.New("websocket: client sent data before the handshake completed")
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n"
+	if _, err := netConn.Write([]byte(response)); err != nil {
+		_ = netConn.Close()
+		return nil, err
+	}
+	return newConn(netConn, rw.Reader, false), nil
+}
+
+// checkHandshake validates the opening handshake of the client as described in RFC 6455, section 4.2.1
+func checkHandshake(r *http.Request) error {
+	if r.Method != http.MethodGet {
+		return errors.New("websocket: handshake must use GET")
+	}
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		return errors.New("websocket: missing upgrade headers")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return errors.New("websocket: unsupported version")
+	}
+	if key, err := base64.StdEncoding.DecodeString(r.Header.Get("Sec-WebSocket-Key")); err != nil || len(key) != 16 {
+		return errors.New("websocket: invalid key")
+	}
+	return nil
+}
+
+// headerContains reports whether the comma separated header contains the token, ignoring case
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header[name] {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// acceptKey computes the value of the Sec-WebSocket-Accept header for the key of the client
+func acceptKey(key string) string {
+	h := sha1.New()
+	_, _ = h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Dial opens a WebSocket connection to the ws or wss url. It is mainly meant for tests and tools.
+func Dial(rawURL string, header http.Header) (*Conn, *http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		return nil, nil, errors.New("websocket: wss isn't supported by Dial")
+	default:
+		return nil, nil, fmt.Errorf("websocket: unsupported scheme %q", u.Scheme)
+	}
+
+	netConn, err := net.Dial("tcp", u.Host)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req := &http.Request{Method: http.MethodGet, URL: u, Host: u.Host, Header: http.Header{}}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	if err := req.Write(netConn); err != nil {
+		_ = netConn.Close()
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		_ = netConn.Close()
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		_ = netConn.Close()
+		return nil, resp, fmt.Errorf("websocket: handshake failed with status %d", resp.StatusCode)
+	}
+	return newConn(netConn, br, true), resp, nil
+}
+
+// SetReadDeadline sets the deadline for future reads, a zero value disables the deadline
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetPongHandler sets the function that is called for every received pong.
+// It is called from within ReadMessage.
+func (c *Conn) SetPongHandler(h func(data []byte)) {
+	c.pongHandler = h
+}
+
+// ReadMessage reads the next text or binary message. Pings are answered and pongs are passed to the pong handler
+// while reading. If the peer closes the connection or violates the protocol, a *CloseError is returned.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	messageType := 0
+	var message []byte
+
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case PingMessage:
+			if err := c.WriteMessage(PongMessage, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			if c.pongHandler != nil {
+				c.pongHandler(payload)
+			}
+			continue
+		case CloseMessage:
+			closeErr := &CloseError{Code: CloseNoStatusReceived}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			_ = c.Close()
+			return 0, nil, closeErr
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.fail(CloseProtocolError, "expected continuation frame")
+			}
+			messageType = opcode
+		case continuationFrame:
+			if messageType == 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+
+		if len(message)+len(payload) > c.MaxMessageSize {
+			_ = c.fail(CloseMessageTooBig, "")
+			return 0, nil, ErrMessageTooBig
+		}
+		message = append(message, payload...)
+		if !fin {
+			continue
+		}
+		if messageType == TextMessage && !utf8.Valid(message) {
+			return 0, nil, c.fail(CloseInvalidPayload, "invalid utf-8")
+		}
+		return messageType, message, nil
+	}
+}
+
+// readFrame reads a single frame and unmasks its payload
+func (c *Conn) readFrame() (bool, int, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.br, header); err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0]&0x80 != 0
+	opcode := int(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "reserved bits set")
+	}
+	// clients must mask their frames, servers must not
+	if masked == c.client {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid masking")
+	}
+
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(c.br, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(c.br, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+	if opcode >= CloseMessage && (!fin || length > maxControlPayload) {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+	}
+	if length > uint64(c.MaxMessageSize) {
+		_ = c.fail(CloseMessageTooBig, "")
+		return false, 0, nil, ErrMessageTooBig
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// fail closes the connection with the code after a protocol violation of the peer
+func (c *Conn) fail(code int, reason string) error {
+	_ = c.CloseWithCode(code, reason)
+	return &CloseError{Code: code, Reason: reason}
+}
+
+// WriteMessage writes a single frame message of the given type
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	if messageType >= CloseMessage && len(data) > maxControlPayload {
+		return errors.New("websocket: control frame payload too big")
+	}
+
+	frame := make([]byte, 0, len(data)+14)
+	frame = append(frame, 0x80|byte(messageType))
+	maskBit := byte(0)
+	if c.client {
+		maskBit = 0x80
+	}
+	switch {
+	case len(data) < 126:
+		frame = append(frame, maskBit|byte(len(data)))
+	case len(data) <= 0xffff:
+		frame = append(frame, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[len(frame)-2:], uint16(len(data)))
+	default:
+		frame = append(frame, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[len(frame)-8:], uint64(len(data)))
+	}
+	if c.client {
+		var mask [4]byte
+		_, _ = rand.Read(mask[:])
+		frame = append(frame, mask[:]...)
+		start := len(frame)
+		frame = append(frame, data...)
+		for i := range data {
+			frame[start+i] ^= mask[i%4]
+		}
+	} else {
+		frame = append(frame, data...)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.WriteTimeout > 0 {
+		_ = c.conn.SetWriteDeadline(time.Now().Add(c.WriteTimeout))
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// WriteClose sends a close message with the code and reason, without closing the underlying connection
+func (c *Conn) WriteClose(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	return c.WriteMessage(CloseMessage, payload)
+}
+
+// Close sends a normal close message and closes the underlying connection
+func (c *Conn) Close() error {
+	return c.CloseWithCode(CloseNormalClosure, "")
+}
+
+// CloseWithCode sends a close message with the code and reason and closes the underlying connection.
+// Only the first call has an effect.
+func (c *Conn) CloseWithCode(code int, reason string) error {
+	err := errors.New("websocket: already closed")
+	c.closeOnce.Do(func() {
+		_ = c.WriteClose(code, reason)
+		err = c.conn.Close()
+	})
+	return err
+}
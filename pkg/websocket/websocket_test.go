@@ -0,0 +1,134 @@
+package websocket
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// echoServer returns a server echoing every message back to the client
+func echoServer(t *testing.T, maxMessageSize int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		conn.MaxMessageSize = maxMessageSize
+		defer conn.Close()
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		}
+	}))
+}
+
+func TestAcceptKey(t *testing.T) {
+	// example of RFC 6455, section 1.3
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func TestUpgrade(t *testing.T) {
+	ts := echoServer(t, DefaultMaxMessageSize)
+	defer ts.Close()
+
+	testcases := map[string]struct {
+		header   http.Header
+		wantCode int
+	}{
+		"plain http request": {
+			wantCode: http.StatusBadRequest,
+		},
+		"unsupported version": {
+			header: http.Header{
+				"Connection":            {"Upgrade"},
+				"Upgrade":               {"websocket"},
+				"Sec-Websocket-Version": {"8"},
+				"Sec-Websocket-Key":     {"dGhlIHNhbXBsZSBub25jZQ=="},
+			},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, ts.URL, nil)
+			req.Header = test.header
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			_ = resp.Body.Close()
+			assert.Equal(t, test.wantCode, resp.StatusCode)
+			assert.Equal(t, "13", resp.Header.Get("Sec-WebSocket-Version"))
+		})
+	}
+}
+
+func TestConn(t *testing.T) {
+	ts := echoServer(t, 1024)
+	defer ts.Close()
+
+	conn, resp, err := Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	defer conn.Close()
+
+	// text and binary messages are echoed, including ones that need an extended length
+	for _, msg := range [][]byte{[]byte("hello"), bytes.Repeat([]byte("a"), 300)} {
+		assert.NoError(t, conn.WriteMessage(TextMessage, msg))
+		messageType, data, err := conn.ReadMessage()
+		assert.NoError(t, err)
+		assert.Equal(t, TextMessage, messageType)
+		assert.Equal(t, msg, data)
+	}
+
+	// pings are answered with pongs carrying the same payload
+	pong := make(chan string, 1)
+	conn.SetPongHandler(func(data []byte) {
+		pong <- string(data)
+	})
+	assert.NoError(t, conn.WriteMessage(PingMessage, []byte("ping")))
+	assert.NoError(t, conn.WriteMessage(BinaryMessage, []byte{1, 2}))
+	messageType, data, err := conn.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, BinaryMessage, messageType)
+	assert.Equal(t, []byte{1, 2}, data)
+	assert.Equal(t, "ping", <-pong)
+
+	// messages above the maximum size close the connection
+	assert.NoError(t, conn.WriteMessage(TextMessage, bytes.Repeat([]byte("a"), 2048)))
+	_, _, err = conn.ReadMessage()
+	assert.Equal(t, &CloseError{Code: CloseMessageTooBig}, err)
+}
+
+func TestConn_fragmented(t *testing.T) {
+	ts := echoServer(t, DefaultMaxMessageSize)
+	defer ts.Close()
+
+	conn, _, err := Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	// a text message split into two frames with a ping in between
+	frames := [][]byte{
+		{0x01, 0x80 | 3, 0, 0, 0, 0, 'f', 'o', 'o'},
+		{0x89, 0x80, 0, 0, 0, 0},
+		{0x80, 0x80 | 3, 0, 0, 0, 0, 'b', 'a', 'r'},
+	}
+	for _, frame := range frames {
+		_, err := conn.conn.Write(frame)
+		assert.NoError(t, err)
+	}
+
+	messageType, data, err := conn.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, TextMessage, messageType)
+	assert.Equal(t, "foobar", string(data))
+}